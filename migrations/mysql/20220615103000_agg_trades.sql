@@ -0,0 +1,34 @@
+-- +up
+CREATE TABLE `agg_trades`
+(
+    `gid`            BIGINT UNSIGNED         NOT NULL AUTO_INCREMENT,
+
+    -- id is the aggregate trade id
+    `id`             BIGINT UNSIGNED         NOT NULL,
+
+    `exchange`       VARCHAR(24)             NOT NULL DEFAULT '',
+
+    `symbol`         VARCHAR(20)             NOT NULL,
+
+    `price`          DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    `quantity`       DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    `quote_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+    -- side is the taker side of the trade
+    `side`           VARCHAR(4)              NOT NULL DEFAULT '',
+
+    `is_buyer`       BOOLEAN                 NOT NULL DEFAULT FALSE,
+
+    `is_maker`       BOOLEAN                 NOT NULL DEFAULT FALSE,
+
+    `traded_at`      DATETIME(3)             NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `id` (`exchange`, `symbol`, `id`),
+    INDEX (`traded_at`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `agg_trades`;
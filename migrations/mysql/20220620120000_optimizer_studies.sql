@@ -0,0 +1,61 @@
+-- +up
+-- +begin
+CREATE TABLE `optimizer_studies`
+(
+    `gid`        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `name`       VARCHAR(128)    NOT NULL,
+
+    -- config is the optimizer config in JSON
+    `config`     TEXT            NOT NULL,
+
+    `created_at` DATETIME        NOT NULL,
+
+    `updated_at` DATETIME        NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `name` (`name`)
+);
+-- +end
+
+-- +begin
+CREATE TABLE `optimizer_trials`
+(
+    `gid`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `study_id`        BIGINT UNSIGNED NOT NULL,
+
+    -- number is the trial number in the study
+    `number`          INT UNSIGNED    NOT NULL,
+
+    `state`           VARCHAR(16)     NOT NULL,
+
+    `value`           DOUBLE          NOT NULL DEFAULT 0,
+
+    -- the params, internal_params, distributions and user_attrs fields are the trial fields in JSON
+    `params`          TEXT            NOT NULL,
+
+    `internal_params` TEXT            NOT NULL,
+
+    `distributions`   TEXT            NOT NULL,
+
+    `user_attrs`      TEXT            NOT NULL,
+
+    `started_at`      DATETIME        NOT NULL,
+
+    `completed_at`    DATETIME        NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `study_number` (`study_id`, `number`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `optimizer_trials`;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS `optimizer_studies`;
+-- +end
@@ -0,0 +1,24 @@
+-- +up
+CREATE TABLE `funding_fees`
+(
+    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `transaction_id` BIGINT UNSIGNED NOT NULL,
+
+    `exchange`       VARCHAR(24)     NOT NULL DEFAULT '',
+
+    `symbol`         VARCHAR(32)     NOT NULL DEFAULT '',
+
+    `asset`          VARCHAR(24)     NOT NULL DEFAULT '',
+
+    -- amount is positive for the received funding fee and negative for the paid funding fee
+    `amount`         DECIMAL(20, 8)  NOT NULL,
+
+    `time`           DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY (`exchange`, `transaction_id`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `funding_fees`;
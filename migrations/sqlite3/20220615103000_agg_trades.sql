@@ -0,0 +1,43 @@
+-- +up
+-- +begin
+CREATE TABLE `agg_trades`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- id is the aggregate trade id
+    `id`             INTEGER        NOT NULL,
+
+    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',
+
+    `symbol`         VARCHAR(20)    NOT NULL,
+
+    `price`          DECIMAL(16, 8) NOT NULL,
+
+    `quantity`       DECIMAL(16, 8) NOT NULL,
+
+    `quote_quantity` DECIMAL(16, 8) NOT NULL,
+
+    -- side is the taker side of the trade
+    `side`           VARCHAR(4)     NOT NULL DEFAULT '',
+
+    `is_buyer`       BOOLEAN        NOT NULL DEFAULT FALSE,
+
+    `is_maker`       BOOLEAN        NOT NULL DEFAULT FALSE,
+
+    `traded_at`      DATETIME(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX `agg_trades_unique_id` ON `agg_trades` (`exchange`, `symbol`, `id`);
+-- +end
+
+-- +begin
+CREATE INDEX `agg_trades_traded_at` ON `agg_trades` (`traded_at`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `agg_trades`;
+-- +end
@@ -0,0 +1,63 @@
+-- +up
+-- +begin
+CREATE TABLE `optimizer_studies`
+(
+    `gid`        INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    `name`       VARCHAR(128) NOT NULL,
+
+    -- config is the optimizer config in JSON
+    `config`     TEXT         NOT NULL,
+
+    `created_at` DATETIME     NOT NULL,
+
+    `updated_at` DATETIME     NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX `optimizer_studies_name` ON `optimizer_studies` (`name`);
+-- +end
+
+-- +begin
+CREATE TABLE `optimizer_trials`
+(
+    `gid`             INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    `study_id`        INTEGER     NOT NULL,
+
+    -- number is the trial number in the study
+    `number`          INTEGER     NOT NULL,
+
+    `state`           VARCHAR(16) NOT NULL,
+
+    `value`           REAL        NOT NULL DEFAULT 0,
+
+    -- the params, internal_params, distributions and user_attrs fields are the trial fields in JSON
+    `params`          TEXT        NOT NULL,
+
+    `internal_params` TEXT        NOT NULL,
+
+    `distributions`   TEXT        NOT NULL,
+
+    `user_attrs`      TEXT        NOT NULL,
+
+    `started_at`      DATETIME    NOT NULL,
+
+    `completed_at`    DATETIME    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX `optimizer_trials_study_number` ON `optimizer_trials` (`study_id`, `number`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `optimizer_trials`;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS `optimizer_studies`;
+-- +end
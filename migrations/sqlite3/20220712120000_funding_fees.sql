@@ -0,0 +1,21 @@
+-- +up
+CREATE TABLE `funding_fees`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    `transaction_id` INTEGER        NOT NULL,
+
+    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',
+
+    `symbol`         VARCHAR(32)    NOT NULL DEFAULT '',
+
+    `asset`          VARCHAR(24)    NOT NULL DEFAULT '',
+
+    -- amount is positive for the received funding fee and negative for the paid funding fee
+    `amount`         DECIMAL(20, 8) NOT NULL,
+
+    `time`           DATETIME(3)    NOT NULL
+);
+
+-- +down
+DROP TABLE IF EXISTS `funding_fees`;
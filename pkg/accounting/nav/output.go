@@ -0,0 +1,146 @@
+package nav
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/c9s/bbgo/pkg/style"
+)
+
+func formatRatio(r float64) string {
+	// avoid formatting the negative zero as -0.00%
+	if r == 0 {
+		r = 0
+	}
+
+	return strconv.FormatFloat(r*100.0, 'f', 2, 64) + "%"
+}
+
+func formatOptionalRatio(r *float64) string {
+	if r == nil {
+		return "n/a"
+	}
+
+	return formatRatio(*r)
+}
+
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// CsvHeader and CsvRecords format the report as the rows of the section, the name, the value and the ratio
+func (r *Report) CsvHeader() []string {
+	return []string{"section", "name", "value", "ratio"}
+}
+
+func (r *Report) CsvRecords() [][]string {
+	var records [][]string
+	add := func(section, name, value, ratio string) {
+		records = append(records, []string{section, name, value, ratio})
+	}
+
+	add("summary", "start time", r.StartTime.Format(time.RFC3339), "")
+	add("summary", "end time", r.EndTime.Format(time.RFC3339), "")
+	add("summary", "start nav", r.StartNAV.String(), "")
+	add("summary", "end nav", r.EndNAV.String(), "")
+	add("summary", "average nav", r.AverageNAV.String(), "")
+	add("summary", "deposits", r.Deposits.String(), "")
+	add("summary", "withdrawals", r.Withdrawals.String(), "")
+	add("return", "time-weighted", "", formatRatio(r.TimeWeightedReturn))
+	add("return", "money-weighted", "", formatOptionalRatio(r.MoneyWeightedReturn))
+	add("return", "money-weighted annualized", "", formatOptionalRatio(r.AnnualizedMoneyWeightedReturn))
+	add("attribution", "net flow", r.NetFlow().String(), "")
+	add("attribution", "market pnl", r.MarketPnL.String(), "")
+	add("attribution", r.Rewards.Name, r.Rewards.Value.String(), formatRatio(r.Rewards.Ratio))
+	for _, d := range r.Drags {
+		add("attribution", d.Name, d.Value.Neg().String(), formatRatio(-d.Ratio))
+	}
+	add("attribution", "other pnl", r.OtherPnL.String(), "")
+
+	for _, a := range r.Assets {
+		add("asset", a.Currency, a.PricePnL.String(), formatRatio(a.Contribution))
+	}
+
+	for _, s := range r.Strategies {
+		add("strategy", s.Strategy+":"+s.InstanceID, s.NetProfit.String(), formatRatio(s.Contribution))
+	}
+
+	return records
+}
+
+// Print renders the report as tables
+func (r *Report) Print(w io.Writer) {
+	summary := table.NewWriter()
+	summary.SetOutputMirror(w)
+	summary.SetStyle(*style.NewDefaultTableStyle())
+	summary.SetTitle("NAV %s ~ %s", r.StartTime.Format(time.RFC3339), r.EndTime.Format(time.RFC3339))
+	summary.AppendRows([]table.Row{
+		{"Start NAV", r.StartNAV.FormatString(2)},
+		{"End NAV", r.EndNAV.FormatString(2)},
+		{"Average NAV", r.AverageNAV.FormatString(2)},
+		{"Deposits", r.Deposits.FormatString(2)},
+		{"Withdrawals", r.Withdrawals.FormatString(2)},
+		{"Time-Weighted Return", formatRatio(r.TimeWeightedReturn)},
+		{"Money-Weighted Return", formatOptionalRatio(r.MoneyWeightedReturn)},
+		{"Money-Weighted Return (Annualized)", formatOptionalRatio(r.AnnualizedMoneyWeightedReturn)},
+	})
+	summary.Render()
+
+	attribution := table.NewWriter()
+	attribution.SetOutputMirror(w)
+	attribution.SetStyle(*style.NewDefaultTableStyle())
+	attribution.SetTitle("NAV Change Attribution (USD)")
+	attribution.AppendHeader(table.Row{"Source", "Value", "% of Average NAV"})
+	attribution.AppendRow(table.Row{"Net Flow", r.NetFlow().FormatString(2), ""})
+	attribution.AppendRow(table.Row{"Market PnL", r.MarketPnL.FormatString(2), ""})
+	attribution.AppendRow(table.Row{"Rewards", r.Rewards.Value.FormatString(2), formatRatio(r.Rewards.Ratio)})
+	for _, d := range r.Drags {
+		attribution.AppendRow(table.Row{"Drag: " + d.Name, d.Value.Neg().FormatString(2), formatRatio(-d.Ratio)})
+	}
+	attribution.AppendRow(table.Row{"Other (trading, funding, unrecorded)", r.OtherPnL.FormatString(2), ""})
+	attribution.AppendFooter(table.Row{"NAV Change", r.EndNAV.Sub(r.StartNAV).FormatString(2), ""})
+	attribution.Render()
+
+	assets := table.NewWriter()
+	assets.SetOutputMirror(w)
+	assets.SetStyle(*style.NewDefaultTableStyle())
+	assets.SetTitle("Asset Contribution")
+	assets.AppendHeader(table.Row{"Currency", "Start Balance", "End Balance", "Start Value", "End Value", "Price PnL", "Contribution"})
+	for _, a := range r.Assets {
+		assets.AppendRow(table.Row{
+			a.Currency,
+			a.StartBalance.String(),
+			a.EndBalance.String(),
+			a.StartValue.FormatString(2),
+			a.EndValue.FormatString(2),
+			a.PricePnL.FormatString(2),
+			formatRatio(a.Contribution),
+		})
+	}
+	assets.Render()
+
+	if len(r.Strategies) == 0 {
+		return
+	}
+
+	strategies := table.NewWriter()
+	strategies.SetOutputMirror(w)
+	strategies.SetStyle(*style.NewDefaultTableStyle())
+	strategies.SetTitle("Strategy Contribution")
+	strategies.AppendHeader(table.Row{"Strategy", "Instance", "Trades", "Profit", "Net Profit", "Contribution"})
+	for _, s := range r.Strategies {
+		strategies.AppendRow(table.Row{
+			s.Strategy,
+			s.InstanceID,
+			strconv.Itoa(s.NumTrades),
+			s.Profit.FormatString(2),
+			s.NetProfit.FormatString(2),
+			formatRatio(s.Contribution),
+		})
+	}
+	strategies.Render()
+}
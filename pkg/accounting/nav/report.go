@@ -0,0 +1,481 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Records are the records of the report time range loaded from the database
+type Records struct {
+	Snapshots []Snapshot
+	Deposits  []types.Deposit
+	Withdraws []types.Withdraw
+	Rewards   []types.Reward
+	Trades    []types.Trade
+	Profits   []types.Profit
+	Interests []types.MarginInterest
+
+	FundingFees []types.FundingFee
+}
+
+// AssetContribution is the NAV change made by the price change of the asset
+type AssetContribution struct {
+	Currency     string           `json:"currency"`
+	StartBalance fixedpoint.Value `json:"startBalance"`
+	EndBalance   fixedpoint.Value `json:"endBalance"`
+	StartValue   fixedpoint.Value `json:"startValue"`
+	EndValue     fixedpoint.Value `json:"endValue"`
+
+	// PricePnL is the value change of the holding at the beginning of each period by the price change
+	PricePnL fixedpoint.Value `json:"pricePnL"`
+
+	// Contribution is the sum of the price pnl ratios to the NAV of each period
+	Contribution float64 `json:"contribution"`
+}
+
+// StrategyContribution is the net profit recorded by the strategy instance
+type StrategyContribution struct {
+	Strategy   string           `json:"strategy"`
+	InstanceID string           `json:"instanceID"`
+	NumTrades  int              `json:"numTrades"`
+	Profit     fixedpoint.Value `json:"profit"`
+	NetProfit  fixedpoint.Value `json:"netProfit"`
+
+	// Contribution is the ratio of the net profit to the average NAV
+	Contribution float64 `json:"contribution"`
+}
+
+// Drag is the cost or the income in USD, the ratio is to the average NAV
+type Drag struct {
+	Name  string           `json:"name"`
+	Value fixedpoint.Value `json:"value"`
+	Ratio float64          `json:"ratio"`
+}
+
+type Report struct {
+	StartTime  time.Time        `json:"startTime"`
+	EndTime    time.Time        `json:"endTime"`
+	StartNAV   fixedpoint.Value `json:"startNAV"`
+	EndNAV     fixedpoint.Value `json:"endNAV"`
+	AverageNAV fixedpoint.Value `json:"averageNAV"`
+
+	Deposits    fixedpoint.Value `json:"deposits"`
+	Withdrawals fixedpoint.Value `json:"withdrawals"`
+
+	TimeWeightedReturn float64 `json:"timeWeightedReturn"`
+
+	// MoneyWeightedReturn is the internal rate of return of the period,
+	// and AnnualizedMoneyWeightedReturn is the annualized one, they are nil if the rate is not solvable
+	MoneyWeightedReturn           *float64 `json:"moneyWeightedReturn"`
+	AnnualizedMoneyWeightedReturn *float64 `json:"annualizedMoneyWeightedReturn"`
+
+	// MarketPnL is the NAV change by the price changes, the sum of the asset price pnl
+	MarketPnL fixedpoint.Value `json:"marketPnL"`
+
+	// OtherPnL is the NAV change not explained by the net flow, the market pnl, the rewards and the drags,
+	// it includes the trading pnl and the changes without records
+	OtherPnL fixedpoint.Value `json:"otherPnL"`
+
+	Assets     []AssetContribution    `json:"assets"`
+	Strategies []StrategyContribution `json:"strategies"`
+
+	Rewards Drag   `json:"rewards"`
+	Drags   []Drag `json:"drags"`
+}
+
+// NetFlow is the deposits minus the withdrawals
+func (r *Report) NetFlow() fixedpoint.Value {
+	return r.Deposits.Sub(r.Withdrawals)
+}
+
+type cashFlow struct {
+	Time   time.Time
+	Amount fixedpoint.Value
+}
+
+// Analyze calculates the report from the records, at least two snapshots are required.
+// The deposits and the withdrawals are treated as the external cash flows at the end of the snapshot periods.
+func Analyze(records Records) (*Report, error) {
+	snapshots := records.Snapshots
+	if len(snapshots) < 2 {
+		return nil, errors.New("at least 2 nav snapshots are required")
+	}
+
+	first := snapshots[0]
+	last := snapshots[len(snapshots)-1]
+	report := &Report{
+		StartTime: first.Time,
+		EndTime:   last.Time,
+		StartNAV:  first.NetAssetValue(),
+		EndNAV:    last.NetAssetValue(),
+	}
+
+	inRange := func(t time.Time) bool {
+		return t.After(first.Time) && !t.After(last.Time)
+	}
+
+	valueOf := func(currency string, amount fixedpoint.Value, t time.Time) fixedpoint.Value {
+		if amount.IsZero() {
+			return fixedpoint.Zero
+		}
+
+		price, ok := priceAt(snapshots, currency, t)
+		if !ok {
+			log.Warnf("unable to find the usd price of %s, %s %s is ignored", currency, amount.String(), currency)
+			return fixedpoint.Zero
+		}
+
+		return amount.Mul(price)
+	}
+
+	var flows []cashFlow
+	for _, d := range records.Deposits {
+		if t := d.Time.Time(); inRange(t) {
+			v := valueOf(d.Asset, d.Amount, t)
+			report.Deposits = report.Deposits.Add(v)
+			flows = append(flows, cashFlow{Time: t, Amount: v})
+		}
+	}
+
+	for _, w := range records.Withdraws {
+		if t := w.ApplyTime.Time(); inRange(t) {
+			v := valueOf(w.Asset, w.Amount, t)
+			report.Withdrawals = report.Withdrawals.Add(v)
+			flows = append(flows, cashFlow{Time: t, Amount: v.Neg()})
+		}
+	}
+
+	sort.Slice(flows, func(i, j int) bool {
+		return flows[i].Time.Before(flows[j].Time)
+	})
+
+	report.AverageNAV = averageNAV(snapshots)
+	report.TimeWeightedReturn = timeWeightedReturn(snapshots, flows)
+	if irr, ok := moneyWeightedReturn(report.StartTime, report.StartNAV, report.EndTime, report.EndNAV, flows); ok {
+		years := report.EndTime.Sub(report.StartTime).Hours() / year.Hours()
+		mwr := math.Pow(1.0+irr, years) - 1.0
+		report.AnnualizedMoneyWeightedReturn = &irr
+		report.MoneyWeightedReturn = &mwr
+	} else {
+		log.Warnf("unable to solve the money-weighted return")
+	}
+
+	report.Assets = assetContributions(snapshots)
+	for _, a := range report.Assets {
+		report.MarketPnL = report.MarketPnL.Add(a.PricePnL)
+	}
+
+	ratio := func(v fixedpoint.Value) float64 {
+		if report.AverageNAV.Sign() <= 0 {
+			return 0
+		}
+
+		return v.Div(report.AverageNAV).Float64()
+	}
+
+	report.Strategies = strategyContributions(records.Profits, inRange, valueOf)
+	for i := range report.Strategies {
+		report.Strategies[i].Contribution = ratio(report.Strategies[i].NetProfit)
+	}
+
+	rewards := fixedpoint.Zero
+	for _, r := range records.Rewards {
+		if t := r.CreatedAt.Time(); inRange(t) {
+			rewards = rewards.Add(valueOf(r.Currency, r.Quantity, t))
+		}
+	}
+	report.Rewards = Drag{Name: "rewards", Value: rewards, Ratio: ratio(rewards)}
+
+	tradingFees := fixedpoint.Zero
+	for _, t := range records.Trades {
+		if tt := t.Time.Time(); inRange(tt) {
+			tradingFees = tradingFees.Add(valueOf(t.FeeCurrency, t.Fee, tt))
+		}
+	}
+
+	interests := fixedpoint.Zero
+	for _, i := range records.Interests {
+		if t := i.Time.Time(); inRange(t) {
+			interests = interests.Add(valueOf(i.Asset, i.Interest, t))
+		}
+	}
+
+	withdrawalFees := fixedpoint.Zero
+	for _, w := range records.Withdraws {
+		if t := w.ApplyTime.Time(); inRange(t) {
+			feeCurrency := w.TransactionFeeCurrency
+			if feeCurrency == "" {
+				feeCurrency = w.Asset
+			}
+
+			withdrawalFees = withdrawalFees.Add(valueOf(feeCurrency, w.TransactionFee, t))
+		}
+	}
+
+	// the funding fee amount is positive for the received funding fee, so the drag is the negative sum
+	fundingFees := fixedpoint.Zero
+	for _, f := range records.FundingFees {
+		if t := f.Time.Time(); inRange(t) {
+			fundingFees = fundingFees.Sub(valueOf(f.Asset, f.Amount, t))
+		}
+	}
+
+	report.Drags = []Drag{
+		{Name: "trading fees", Value: tradingFees, Ratio: ratio(tradingFees)},
+		{Name: "funding fees", Value: fundingFees, Ratio: ratio(fundingFees)},
+		{Name: "margin interests", Value: interests, Ratio: ratio(interests)},
+		{Name: "withdrawal fees", Value: withdrawalFees, Ratio: ratio(withdrawalFees)},
+	}
+
+	// ΔNAV = net flow + market pnl + rewards - drags + other pnl
+	report.OtherPnL = report.EndNAV.Sub(report.StartNAV).
+		Sub(report.NetFlow()).
+		Sub(report.MarketPnL).
+		Sub(rewards)
+	for _, d := range report.Drags {
+		report.OtherPnL = report.OtherPnL.Add(d.Value)
+	}
+
+	return report, nil
+}
+
+func averageNAV(snapshots []Snapshot) fixedpoint.Value {
+	total := fixedpoint.Zero
+	for _, s := range snapshots {
+		total = total.Add(s.NetAssetValue())
+	}
+
+	return total.Div(fixedpoint.NewFromInt(int64(len(snapshots))))
+}
+
+// timeWeightedReturn links the returns of the snapshot periods,
+// the return of each period is (NAV - net flow of the period) / the previous NAV - 1
+func timeWeightedReturn(snapshots []Snapshot, flows []cashFlow) float64 {
+	twr := 1.0
+	f := 0
+	for i := 1; i < len(snapshots); i++ {
+		netFlow := fixedpoint.Zero
+		for ; f < len(flows) && !flows[f].Time.After(snapshots[i].Time); f++ {
+			netFlow = netFlow.Add(flows[f].Amount)
+		}
+
+		prev := snapshots[i-1].NetAssetValue()
+		if prev.Sign() <= 0 {
+			continue
+		}
+
+		twr *= snapshots[i].NetAssetValue().Sub(netFlow).Div(prev).Float64()
+	}
+
+	return twr - 1.0
+}
+
+// moneyWeightedReturn solves the annualized internal rate of return of the investor cash flows by bisection,
+// false is returned if the rate is not found
+func moneyWeightedReturn(startTime time.Time, startNAV fixedpoint.Value, endTime time.Time, endNAV fixedpoint.Value, flows []cashFlow) (float64, bool) {
+	type cf struct {
+		years  float64
+		amount float64
+	}
+
+	// the deposits are paid by the investor and the withdrawals are received by the investor
+	cfs := []cf{{0, -startNAV.Float64()}}
+	for _, f := range flows {
+		cfs = append(cfs, cf{f.Time.Sub(startTime).Hours() / year.Hours(), -f.Amount.Float64()})
+	}
+	cfs = append(cfs, cf{endTime.Sub(startTime).Hours() / year.Hours(), endNAV.Float64()})
+
+	npv := func(rate float64) float64 {
+		v := 0.0
+		for _, c := range cfs {
+			v += c.amount / math.Pow(1.0+rate, c.years)
+		}
+		return v
+	}
+
+	low, high := -0.9999, 1.0
+	for npv(high) > 0 && high < 1e9 {
+		high *= 2
+	}
+
+	fl, fh := npv(low), npv(high)
+	if math.IsNaN(fl) || math.IsNaN(fh) || fl*fh > 0 {
+		return 0, false
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		fm := npv(mid)
+		if math.Abs(fm) < 1e-9 {
+			return mid, true
+		}
+
+		if fl*fm < 0 {
+			high = mid
+		} else {
+			low, fl = mid, fm
+		}
+	}
+
+	return (low + high) / 2, true
+}
+
+// assetContributions calculates the price pnl of the holding at the beginning of each snapshot period
+func assetContributions(snapshots []Snapshot) []AssetContribution {
+	first := snapshots[0]
+	last := snapshots[len(snapshots)-1]
+	contributions := map[string]*AssetContribution{}
+	get := func(currency string) *AssetContribution {
+		c, ok := contributions[currency]
+		if !ok {
+			c = &AssetContribution{
+				Currency:     currency,
+				StartBalance: first.Assets[currency].Balance,
+				StartValue:   first.Assets[currency].InUSD,
+				EndBalance:   last.Assets[currency].Balance,
+				EndValue:     last.Assets[currency].InUSD,
+			}
+			contributions[currency] = c
+		}
+		return c
+	}
+
+	for i := 1; i < len(snapshots); i++ {
+		prev := snapshots[i-1]
+		cur := snapshots[i]
+		nav := prev.NetAssetValue()
+		for currency, a := range prev.Assets {
+			c := get(currency)
+			price, ok := cur.Assets[currency]
+			if !ok || price.Price.IsZero() || a.Price.IsZero() {
+				continue
+			}
+
+			pnl := a.Balance.Mul(price.Price.Sub(a.Price))
+			c.PricePnL = c.PricePnL.Add(pnl)
+			if nav.Sign() > 0 {
+				c.Contribution += pnl.Div(nav).Float64()
+			}
+		}
+	}
+
+	for currency := range last.Assets {
+		get(currency)
+	}
+
+	var result []AssetContribution
+	for _, c := range contributions {
+		result = append(result, *c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PricePnL.Compare(result[j].PricePnL) != 0 {
+			return result[i].PricePnL.Compare(result[j].PricePnL) > 0
+		}
+		return result[i].Currency < result[j].Currency
+	})
+
+	return result
+}
+
+func strategyContributions(profits []types.Profit, inRange func(t time.Time) bool, valueOf func(currency string, amount fixedpoint.Value, t time.Time) fixedpoint.Value) []StrategyContribution {
+	type key struct{ strategy, instanceID string }
+
+	var keys []key
+	contributions := map[key]*StrategyContribution{}
+	for _, p := range profits {
+		if !inRange(p.TradedAt) {
+			continue
+		}
+
+		k := key{p.Strategy, p.StrategyInstanceID}
+		c, ok := contributions[k]
+		if !ok {
+			c = &StrategyContribution{Strategy: p.Strategy, InstanceID: p.StrategyInstanceID}
+			contributions[k] = c
+			keys = append(keys, k)
+		}
+
+		c.NumTrades++
+		c.Profit = c.Profit.Add(valueOf(p.QuoteCurrency, p.Profit, p.TradedAt))
+		c.NetProfit = c.NetProfit.Add(valueOf(p.QuoteCurrency, p.NetProfit, p.TradedAt))
+	}
+
+	var result []StrategyContribution
+	for _, k := range keys {
+		result = append(result, *contributions[k])
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NetProfit.Compare(result[j].NetProfit) > 0
+	})
+
+	return result
+}
+
+// FilterExchanges returns the records of the exchanges, the snapshots are not filtered
+func (r Records) FilterExchanges(exchanges ...types.ExchangeName) Records {
+	set := map[types.ExchangeName]struct{}{}
+	for _, ex := range exchanges {
+		set[ex] = struct{}{}
+	}
+
+	has := func(ex types.ExchangeName) bool {
+		_, ok := set[ex]
+		return ok
+	}
+
+	filtered := Records{Snapshots: r.Snapshots}
+	for _, d := range r.Deposits {
+		if has(d.Exchange) {
+			filtered.Deposits = append(filtered.Deposits, d)
+		}
+	}
+
+	for _, w := range r.Withdraws {
+		if has(w.Exchange) {
+			filtered.Withdraws = append(filtered.Withdraws, w)
+		}
+	}
+
+	for _, rw := range r.Rewards {
+		if has(rw.Exchange) {
+			filtered.Rewards = append(filtered.Rewards, rw)
+		}
+	}
+
+	for _, t := range r.Trades {
+		if has(t.Exchange) {
+			filtered.Trades = append(filtered.Trades, t)
+		}
+	}
+
+	for _, p := range r.Profits {
+		if has(p.Exchange) {
+			filtered.Profits = append(filtered.Profits, p)
+		}
+	}
+
+	for _, i := range r.Interests {
+		if has(i.Exchange) {
+			filtered.Interests = append(filtered.Interests, i)
+		}
+	}
+
+	for _, f := range r.FundingFees {
+		if has(f.Exchange) {
+			filtered.FundingFees = append(filtered.FundingFees, f)
+		}
+	}
+
+	return filtered
+}
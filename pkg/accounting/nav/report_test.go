@@ -0,0 +1,156 @@
+package nav
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testStartTime = time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func testDetail(t time.Time, currency string, balance, price float64) types.NavHistoryDetail {
+	return types.NavHistoryDetail{
+		Session:       "ALL",
+		Time:          types.Time(t),
+		Currency:      currency,
+		NetAsset:      fixedpoint.NewFromFloat(balance),
+		PriceInUSD:    fixedpoint.NewFromFloat(price),
+		NetAssetInUSD: fixedpoint.NewFromFloat(balance * price),
+	}
+}
+
+func testRecords() Records {
+	t0 := testStartTime
+	t1 := t0.Add(24 * time.Hour)
+	t2 := t1.Add(24 * time.Hour)
+
+	return Records{
+		Snapshots: NewSnapshots([]types.NavHistoryDetail{
+			testDetail(t0, "BTC", 1, 100),
+			testDetail(t0, "USDT", 100, 1),
+			testDetail(t1, "BTC", 1, 120),
+			testDetail(t1, "USDT", 100, 1),
+			testDetail(t2, "BTC", 1, 120),
+			testDetail(t2, "USDT", 200, 1),
+		}),
+		Deposits: []types.Deposit{
+			{Exchange: types.ExchangeBinance, Asset: "USDT", Amount: fixedpoint.NewFromInt(100), Time: types.Time(t1.Add(time.Hour))},
+		},
+		Trades: []types.Trade{
+			{Exchange: types.ExchangeBinance, Fee: fixedpoint.NewFromFloat(0.01), FeeCurrency: "BTC", Time: types.Time(t1.Add(2 * time.Hour))},
+		},
+		Profits: []types.Profit{
+			{Exchange: types.ExchangeBinance, Strategy: "grid", StrategyInstanceID: "grid-BTCUSDT", QuoteCurrency: "USDT",
+				Profit: fixedpoint.NewFromInt(3), NetProfit: fixedpoint.NewFromInt(2), TradedAt: t1.Add(3 * time.Hour)},
+			// out of the range
+			{Exchange: types.ExchangeBinance, Strategy: "grid", StrategyInstanceID: "grid-BTCUSDT", QuoteCurrency: "USDT",
+				Profit: fixedpoint.NewFromInt(3), NetProfit: fixedpoint.NewFromInt(2), TradedAt: t0.Add(-time.Hour)},
+		},
+		Interests: []types.MarginInterest{
+			{Exchange: types.ExchangeMax, Asset: "USDT", Interest: fixedpoint.NewFromFloat(0.5), Time: types.Time(t1.Add(time.Hour))},
+		},
+		FundingFees: []types.FundingFee{
+			{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(-0.4), Time: types.Time(t1.Add(4 * time.Hour))},
+			{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(0.1), Time: types.Time(t1.Add(12 * time.Hour))},
+			// out of the range
+			{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(-1), Time: types.Time(t2.Add(time.Hour))},
+		},
+	}
+}
+
+func TestNewSnapshots(t *testing.T) {
+	t0 := testStartTime
+	snapshots := NewSnapshots([]types.NavHistoryDetail{
+		testDetail(t0.Add(time.Hour), "BTC", 1, 100),
+		testDetail(t0, "BTC", 1, 90),
+		testDetail(t0, "BTC", 2, 90),
+	})
+
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, t0, snapshots[0].Time)
+		assert.Equal(t, "3", snapshots[0].Assets["BTC"].Balance.String())
+		assert.Equal(t, "270", snapshots[0].NetAssetValue().String())
+	}
+
+	price, ok := priceAt(snapshots, "BTC", t0.Add(30*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, "90", price.String())
+
+	price, ok = priceAt(snapshots, "BTC", t0.Add(-time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, "90", price.String())
+
+	price, ok = priceAt(snapshots, "USDC", t0)
+	assert.True(t, ok)
+	assert.Equal(t, "1", price.String())
+
+	_, ok = priceAt(snapshots, "ETH", t0)
+	assert.False(t, ok)
+}
+
+func TestAnalyze(t *testing.T) {
+	report, err := Analyze(testRecords())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "200", report.StartNAV.String())
+	assert.Equal(t, "320", report.EndNAV.String())
+	assert.Equal(t, "100", report.Deposits.String())
+
+	// the deposit is excluded from the return of the second period
+	assert.InDelta(t, 0.1, report.TimeWeightedReturn, 1e-6)
+	// the deposit in the middle of the range makes the money-weighted return lower
+	if assert.NotNil(t, report.MoneyWeightedReturn) {
+		assert.InDelta(t, 0.081, *report.MoneyWeightedReturn, 0.001)
+	}
+
+	assert.Equal(t, "20", report.MarketPnL.String())
+	if assert.Len(t, report.Assets, 2) {
+		assert.Equal(t, "BTC", report.Assets[0].Currency)
+		assert.InDelta(t, 0.1, report.Assets[0].Contribution, 1e-6)
+	}
+
+	if assert.Len(t, report.Strategies, 1) {
+		assert.Equal(t, "grid", report.Strategies[0].Strategy)
+		assert.Equal(t, 1, report.Strategies[0].NumTrades)
+		assert.Equal(t, "2", report.Strategies[0].NetProfit.String())
+	}
+
+	if assert.Len(t, report.Drags, 4) {
+		// 0.01 BTC at 120
+		assert.InDelta(t, 1.2, report.Drags[0].Value.Float64(), 1e-6)
+		// 0.4 paid and 0.1 received
+		assert.Equal(t, "funding fees", report.Drags[1].Name)
+		assert.InDelta(t, 0.3, report.Drags[1].Value.Float64(), 1e-6)
+		assert.InDelta(t, 0.5, report.Drags[2].Value.Float64(), 1e-6)
+	}
+
+	// 120 = 100 (net flow) + 20 (market) - 2.0 (drags) + other
+	assert.InDelta(t, 2.0, report.OtherPnL.Float64(), 1e-6)
+	assert.Len(t, report.CsvRecords(), 21)
+}
+
+func TestAnalyze_FilterExchanges(t *testing.T) {
+	report, err := Analyze(testRecords().FilterExchanges(types.ExchangeMax))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, report.Deposits.IsZero())
+	assert.Empty(t, report.Strategies)
+	assert.True(t, report.Drags[1].Value.IsZero())
+	assert.InDelta(t, 0.5, report.Drags[2].Value.Float64(), 1e-6)
+
+	// the deposit is not recorded, so it's counted in the return
+	assert.InDelta(t, (1.1*320.0/220.0)-1.0, report.TimeWeightedReturn, 1e-6)
+}
+
+func TestAnalyze_NotEnoughSnapshots(t *testing.T) {
+	_, err := Analyze(Records{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package nav
+
+import (
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AssetSnapshot is the net asset of the currency recorded in the snapshot
+type AssetSnapshot struct {
+	Currency string           `json:"currency"`
+	Balance  fixedpoint.Value `json:"balance"`
+	Price    fixedpoint.Value `json:"price"`
+	InUSD    fixedpoint.Value `json:"inUSD"`
+}
+
+// Snapshot is the net asset values recorded at the same time
+type Snapshot struct {
+	Time   time.Time                `json:"time"`
+	Assets map[string]AssetSnapshot `json:"assets"`
+}
+
+func (s Snapshot) NetAssetValue() (total fixedpoint.Value) {
+	for _, a := range s.Assets {
+		total = total.Add(a.InUSD)
+	}
+
+	return total
+}
+
+// NewSnapshots groups the nav history details by the record time,
+// the details of the same currency at the same time (e.g., of the sub-accounts) are summed up.
+func NewSnapshots(details []types.NavHistoryDetail) []Snapshot {
+	var snapshots []Snapshot
+	index := map[time.Time]int{}
+	for _, d := range details {
+		t := d.Time.Time()
+		i, ok := index[t]
+		if !ok {
+			i = len(snapshots)
+			index[t] = i
+			snapshots = append(snapshots, Snapshot{Time: t, Assets: map[string]AssetSnapshot{}})
+		}
+
+		a := snapshots[i].Assets[d.Currency]
+		a.Currency = d.Currency
+		a.Balance = a.Balance.Add(d.NetAsset)
+		a.InUSD = a.InUSD.Add(d.NetAssetInUSD)
+		if !d.PriceInUSD.IsZero() {
+			a.Price = d.PriceInUSD
+		}
+		snapshots[i].Assets[d.Currency] = a
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+
+	return snapshots
+}
+
+// priceAt returns the USD price of the currency from the last snapshot before the time,
+// or from the nearest snapshot after the time if the currency is not found.
+func priceAt(snapshots []Snapshot, currency string, t time.Time) (fixedpoint.Value, bool) {
+	if types.IsUSDFiatCurrency(currency) {
+		return fixedpoint.One, true
+	}
+
+	// the index of the first snapshot after the time
+	n := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Time.After(t)
+	})
+
+	for i := n - 1; i >= 0; i-- {
+		if a, ok := snapshots[i].Assets[currency]; ok && !a.Price.IsZero() {
+			return a.Price, true
+		}
+	}
+
+	for i := n; i < len(snapshots); i++ {
+		if a, ok := snapshots[i].Assets[currency]; ok && !a.Price.IsZero() {
+			return a.Price, true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
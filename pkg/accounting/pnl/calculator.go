@@ -0,0 +1,70 @@
+package pnl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Method is the cost basis method of matching the sell trades to the bought quantities
+type Method string
+
+const (
+	MethodAverageCost Method = "average"
+	MethodFIFO        Method = "fifo"
+	MethodLIFO        Method = "lifo"
+
+	// MethodSpecificID disposes the lots identified by the buy trade IDs of each sell trade
+	MethodSpecificID Method = "specific"
+)
+
+var Methods = []Method{MethodAverageCost, MethodFIFO, MethodLIFO, MethodSpecificID}
+
+func ParseMethod(s string) (Method, error) {
+	for _, m := range Methods {
+		if strings.EqualFold(s, string(m)) {
+			return m, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown pnl method %q, available methods: %v", s, Methods)
+}
+
+// Report is the PnL report returned by the calculators
+type Report interface {
+	Print()
+}
+
+// Calculator calculates the PnL report of the symbol from the trades sorted by time
+type Calculator interface {
+	Report(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) Report
+}
+
+func (c *AverageCostCalculator) Report(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) Report {
+	return c.Calculate(symbol, trades, currentPrice)
+}
+
+func (c *LotCalculator) Report(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) Report {
+	return c.Calculate(symbol, trades, currentPrice)
+}
+
+// NewCalculator creates the calculator of the method, the specific lots are only used by the specific identification method
+func NewCalculator(method Method, market types.Market, tradingFeeCurrency string, specificLots map[uint64][]uint64) (Calculator, error) {
+	switch method {
+	case MethodAverageCost:
+		return &AverageCostCalculator{
+			TradingFeeCurrency: tradingFeeCurrency,
+			Market:             market,
+		}, nil
+
+	case MethodFIFO, MethodLIFO:
+		return &LotCalculator{Method: method, Market: market}, nil
+
+	case MethodSpecificID:
+		return &LotCalculator{Method: method, Market: market, SpecificLots: specificLots}, nil
+	}
+
+	return nil, fmt.Errorf("unknown pnl method %q", method)
+}
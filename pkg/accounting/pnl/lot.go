@@ -0,0 +1,323 @@
+package pnl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Lot is the open quantity of a buy trade
+type Lot struct {
+	TradeID uint64           `json:"tradeID"`
+	Time    time.Time        `json:"time"`
+	Price   fixedpoint.Value `json:"price"`
+
+	// Quantity is the remaining quantity of the lot
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Fee is the remaining buy fee in the quote currency allocated to the lot
+	Fee fixedpoint.Value `json:"fee"`
+}
+
+// consume takes the quantity from the lot and returns the fee allocated to it
+func (l *Lot) consume(quantity fixedpoint.Value) fixedpoint.Value {
+	fee := l.Fee
+	if quantity.Compare(l.Quantity) < 0 {
+		fee = l.Fee.Mul(quantity).Div(l.Quantity)
+	}
+
+	l.Quantity = l.Quantity.Sub(quantity)
+	l.Fee = l.Fee.Sub(fee)
+	return fee
+}
+
+// RealizedLot is the quantity of the lot disposed by a sell trade
+type RealizedLot struct {
+	Symbol      string    `json:"symbol"`
+	BuyTradeID  uint64    `json:"buyTradeID"`
+	SellTradeID uint64    `json:"sellTradeID"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	DisposedAt  time.Time `json:"disposedAt"`
+
+	Quantity  fixedpoint.Value `json:"quantity"`
+	BuyPrice  fixedpoint.Value `json:"buyPrice"`
+	SellPrice fixedpoint.Value `json:"sellPrice"`
+
+	// BuyFee and SellFee are the fees in the quote currency allocated to the quantity
+	BuyFee  fixedpoint.Value `json:"buyFee"`
+	SellFee fixedpoint.Value `json:"sellFee"`
+}
+
+// CostBasis is the buy cost of the quantity including the buy fee
+func (l RealizedLot) CostBasis() fixedpoint.Value {
+	return l.BuyPrice.Mul(l.Quantity).Add(l.BuyFee)
+}
+
+// Proceeds is the sell amount of the quantity excluding the sell fee
+func (l RealizedLot) Proceeds() fixedpoint.Value {
+	return l.SellPrice.Mul(l.Quantity).Sub(l.SellFee)
+}
+
+func (l RealizedLot) Profit() fixedpoint.Value {
+	return l.Proceeds().Sub(l.CostBasis())
+}
+
+func (l RealizedLot) HoldingPeriod() time.Duration {
+	return l.DisposedAt.Sub(l.AcquiredAt)
+}
+
+// HoldingDays is the number of the full days the lot is held
+func (l RealizedLot) HoldingDays() int {
+	return int(l.HoldingPeriod() / (24 * time.Hour))
+}
+
+type RealizedLotSlice []RealizedLot
+
+func (s RealizedLotSlice) Profit() (profit fixedpoint.Value) {
+	for _, l := range s {
+		profit = profit.Add(l.Profit())
+	}
+
+	return profit
+}
+
+func (s RealizedLotSlice) CsvHeader() []string {
+	return []string{
+		"symbol", "buy_trade_id", "sell_trade_id", "acquired_at", "disposed_at", "holding_days",
+		"quantity", "buy_price", "sell_price", "buy_fee", "sell_fee", "cost_basis", "proceeds", "profit",
+	}
+}
+
+func (s RealizedLotSlice) CsvRecords() [][]string {
+	var records [][]string
+	for _, l := range s {
+		records = append(records, []string{
+			l.Symbol,
+			strconv.FormatUint(l.BuyTradeID, 10),
+			strconv.FormatUint(l.SellTradeID, 10),
+			l.AcquiredAt.Format(time.RFC3339),
+			l.DisposedAt.Format(time.RFC3339),
+			strconv.Itoa(l.HoldingDays()),
+			l.Quantity.String(),
+			l.BuyPrice.String(),
+			l.SellPrice.String(),
+			l.BuyFee.String(),
+			l.SellFee.String(),
+			l.CostBasis().String(),
+			l.Proceeds().String(),
+			l.Profit().String(),
+		})
+	}
+
+	return records
+}
+
+// LotCalculator calculates the realized PnL of each lot, the sell trades dispose the bought lots in the order of the method.
+// The fees paid in the base or the quote currency are allocated to the lots by the quantity,
+// the fees paid in the other currencies are only collected in the currency fees of the report.
+type LotCalculator struct {
+	Method Method
+	Market types.Market
+
+	// SpecificLots maps the sell trade ID to the buy trade IDs of the lots to dispose, used by the specific identification method.
+	// The quantity not covered by the identified lots is disposed in the FIFO order.
+	SpecificLots map[uint64][]uint64
+}
+
+func (c *LotCalculator) Calculate(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) *LotPnLReport {
+	report := &LotPnLReport{
+		Symbol:       symbol,
+		Market:       c.Market,
+		Method:       c.Method,
+		LastPrice:    currentPrice,
+		CurrencyFees: map[string]fixedpoint.Value{},
+	}
+
+	var lots []*Lot
+	var tradeIDs = map[uint64]struct{}{}
+	for _, trade := range trades {
+		if trade.Symbol != symbol {
+			continue
+		}
+
+		if _, exists := tradeIDs[trade.ID]; exists {
+			log.Warnf("duplicated trade: %+v", trade)
+			continue
+		}
+		tradeIDs[trade.ID] = struct{}{}
+
+		if report.NumTrades == 0 {
+			report.StartTime = trade.Time.Time()
+		}
+		report.NumTrades++
+
+		report.CurrencyFees[trade.FeeCurrency] = report.CurrencyFees[trade.FeeCurrency].Add(trade.Fee)
+
+		quantity := trade.Quantity
+		fee := fixedpoint.Zero
+		switch trade.FeeCurrency {
+		case c.Market.QuoteCurrency:
+			fee = trade.Fee
+
+		case c.Market.BaseCurrency:
+			// the base fee is deducted from the received quantity of the buy, or is sold additionally by the sell
+			fee = trade.Fee.Mul(trade.Price)
+			if trade.IsBuyer {
+				quantity = quantity.Sub(trade.Fee)
+			} else {
+				quantity = quantity.Add(trade.Fee)
+			}
+		}
+
+		if trade.IsBuyer {
+			report.BuyVolume = report.BuyVolume.Add(trade.Quantity)
+			lots = append(lots, &Lot{
+				TradeID:  trade.ID,
+				Time:     trade.Time.Time(),
+				Price:    trade.Price,
+				Quantity: quantity,
+				Fee:      fee,
+			})
+			continue
+		}
+
+		report.SellVolume = report.SellVolume.Add(trade.Quantity)
+
+		// the sell trade is disposed as a lot, so that the sell fee is allocated in the same way
+		sell := &Lot{TradeID: trade.ID, Quantity: quantity, Fee: fee}
+		for _, lot := range c.selectLots(lots, trade.ID) {
+			if sell.Quantity.IsZero() {
+				break
+			}
+
+			q := fixedpoint.Min(lot.Quantity, sell.Quantity)
+			if q.IsZero() {
+				continue
+			}
+
+			report.RealizedLots = append(report.RealizedLots, RealizedLot{
+				Symbol:      symbol,
+				BuyTradeID:  lot.TradeID,
+				SellTradeID: trade.ID,
+				AcquiredAt:  lot.Time,
+				DisposedAt:  trade.Time.Time(),
+				Quantity:    q,
+				BuyPrice:    lot.Price,
+				SellPrice:   trade.Price,
+				BuyFee:      lot.consume(q),
+				SellFee:     sell.consume(q),
+			})
+		}
+
+		if sell.Quantity.Sign() > 0 {
+			log.Warnf("sell trade %d quantity %s is not matched to any lot", trade.ID, sell.Quantity.String())
+			report.UnmatchedQuantity = report.UnmatchedQuantity.Add(sell.Quantity)
+		}
+
+		lots = removeClosedLots(lots)
+	}
+
+	for _, lot := range lots {
+		report.OpenLots = append(report.OpenLots, *lot)
+		report.UnrealizedProfit = report.UnrealizedProfit.Add(currentPrice.Sub(lot.Price).Mul(lot.Quantity).Sub(lot.Fee))
+	}
+
+	report.Profit = report.RealizedLots.Profit()
+	return report
+}
+
+// selectLots returns the open lots in the disposal order of the method
+func (c *LotCalculator) selectLots(lots []*Lot, sellTradeID uint64) []*Lot {
+	switch c.Method {
+	case MethodLIFO:
+		selected := make([]*Lot, 0, len(lots))
+		for i := len(lots) - 1; i >= 0; i-- {
+			selected = append(selected, lots[i])
+		}
+		return selected
+
+	case MethodSpecificID:
+		buyTradeIDs, ok := c.SpecificLots[sellTradeID]
+		if !ok {
+			return lots
+		}
+
+		selected := make([]*Lot, 0, len(lots))
+		identified := map[uint64]struct{}{}
+		for _, buyTradeID := range buyTradeIDs {
+			for _, lot := range lots {
+				if lot.TradeID == buyTradeID {
+					selected = append(selected, lot)
+					identified[buyTradeID] = struct{}{}
+				}
+			}
+
+			if _, ok := identified[buyTradeID]; !ok {
+				log.Warnf("lot of buy trade %d for sell trade %d is not found or already closed", buyTradeID, sellTradeID)
+			}
+		}
+
+		for _, lot := range lots {
+			if _, ok := identified[lot.TradeID]; !ok {
+				selected = append(selected, lot)
+			}
+		}
+		return selected
+	}
+
+	return lots
+}
+
+func removeClosedLots(lots []*Lot) []*Lot {
+	var open []*Lot
+	for _, lot := range lots {
+		if lot.Quantity.Sign() > 0 {
+			open = append(open, lot)
+		}
+	}
+
+	return open
+}
+
+// ReadSpecificLots reads the lot identification CSV of the "sell_trade_id,buy_trade_id" rows,
+// the buy trade IDs of the same sell trade are disposed in the order of the rows.
+func ReadSpecificLots(r io.Reader) (map[uint64][]uint64, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	lots := map[uint64][]uint64{}
+	for i, record := range records {
+		sellTradeID, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			// skip the header
+			if i == 0 {
+				continue
+			}
+
+			return nil, fmt.Errorf("invalid sell trade id at line %d: %w", i+1, err)
+		}
+
+		buyTradeID, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid buy trade id at line %d: %w", i+1, err)
+		}
+
+		lots[sellTradeID] = append(lots[sellTradeID], buyTradeID)
+	}
+
+	return lots, nil
+}
@@ -0,0 +1,109 @@
+package pnl
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type LotPnLReport struct {
+	Symbol    string           `json:"symbol"`
+	Market    types.Market     `json:"market"`
+	Method    Method           `json:"method"`
+	LastPrice fixedpoint.Value `json:"lastPrice"`
+	StartTime time.Time        `json:"startTime"`
+	NumTrades int              `json:"numTrades"`
+
+	BuyVolume  fixedpoint.Value `json:"buyVolume,omitempty"`
+	SellVolume fixedpoint.Value `json:"sellVolume,omitempty"`
+
+	RealizedLots RealizedLotSlice `json:"realizedLots"`
+	OpenLots     []Lot            `json:"openLots"`
+
+	Profit           fixedpoint.Value `json:"profit"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+
+	// UnmatchedQuantity is the sold quantity without the bought lots, e.g., the trades before the queried time range
+	UnmatchedQuantity fixedpoint.Value `json:"unmatchedQuantity"`
+
+	CurrencyFees map[string]fixedpoint.Value `json:"currencyFees"`
+}
+
+func (report *LotPnLReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// TaxYears returns the years of the disposal time of the realized lots
+func (report *LotPnLReport) TaxYears() []int {
+	var years []int
+	seen := map[int]struct{}{}
+	for _, l := range report.RealizedLots {
+		year := l.DisposedAt.Year()
+		if _, ok := seen[year]; !ok {
+			seen[year] = struct{}{}
+			years = append(years, year)
+		}
+	}
+
+	sort.Ints(years)
+	return years
+}
+
+// RealizedLotsOfYear returns the realized lots disposed in the tax year
+func (report *LotPnLReport) RealizedLotsOfYear(year int) (lots RealizedLotSlice) {
+	for _, l := range report.RealizedLots {
+		if l.DisposedAt.Year() == year {
+			lots = append(lots, l)
+		}
+	}
+
+	return lots
+}
+
+func (report LotPnLReport) Print() {
+	color.Green("METHOD: %s", report.Method)
+	color.Green("TRADES SINCE: %v", report.StartTime)
+	color.Green("NUMBER OF TRADES: %d", report.NumTrades)
+
+	color.Green("TOTAL BUY VOLUME: %v", report.BuyVolume)
+	color.Green("TOTAL SELL VOLUME: %v", report.SellVolume)
+
+	color.Green("CURRENT PRICE: %s", types.USD.FormatMoney(report.LastPrice))
+	color.Green("CURRENCY FEES:")
+	for currency, fee := range report.CurrencyFees {
+		color.Green(" - %s: %s", currency, fee.String())
+	}
+
+	color.Green("REALIZED LOTS: %d", len(report.RealizedLots))
+	for _, year := range report.TaxYears() {
+		lots := report.RealizedLotsOfYear(year)
+		color.Green(" - %d: %d lots, profit %s", year, len(lots), types.USD.FormatMoney(lots.Profit()))
+	}
+
+	openQuantity := fixedpoint.Zero
+	for _, l := range report.OpenLots {
+		openQuantity = openQuantity.Add(l.Quantity)
+	}
+	color.Green("OPEN LOTS: %d, quantity %s", len(report.OpenLots), openQuantity.String())
+
+	if report.UnmatchedQuantity.Sign() > 0 {
+		color.Yellow("UNMATCHED SELL QUANTITY: %s", report.UnmatchedQuantity.String())
+	}
+
+	if report.Profit.Sign() > 0 {
+		color.Green("PROFIT: %s", types.USD.FormatMoney(report.Profit))
+	} else {
+		color.Red("PROFIT: %s", types.USD.FormatMoney(report.Profit))
+	}
+
+	if report.UnrealizedProfit.Sign() > 0 {
+		color.Green("UNREALIZED PROFIT: %s", types.USD.FormatMoney(report.UnrealizedProfit))
+	} else {
+		color.Red("UNREALIZED PROFIT: %s", types.USD.FormatMoney(report.UnrealizedProfit))
+	}
+}
@@ -0,0 +1,188 @@
+package pnl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+}
+
+var testStartTime = time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+func testTrade(id uint64, isBuyer bool, price, quantity, fee float64, days int) types.Trade {
+	side := types.SideTypeSell
+	if isBuyer {
+		side = types.SideTypeBuy
+	}
+
+	return types.Trade{
+		ID:          id,
+		Symbol:      "BTCUSDT",
+		Side:        side,
+		IsBuyer:     isBuyer,
+		Price:       fixedpoint.NewFromFloat(price),
+		Quantity:    fixedpoint.NewFromFloat(quantity),
+		Fee:         fixedpoint.NewFromFloat(fee),
+		FeeCurrency: "USDT",
+		Time:        types.Time(testStartTime.AddDate(0, 0, days)),
+	}
+}
+
+func testTrades() []types.Trade {
+	return []types.Trade{
+		testTrade(1, true, 100, 1, 1, 0),
+		testTrade(2, true, 200, 1, 2, 10),
+		testTrade(3, false, 300, 1.5, 3, 40),
+	}
+}
+
+func TestLotCalculator_FIFO(t *testing.T) {
+	calculator := &LotCalculator{Method: MethodFIFO, Market: testMarket}
+	report := calculator.Calculate("BTCUSDT", testTrades(), fixedpoint.NewFromInt(400))
+
+	if assert.Len(t, report.RealizedLots, 2) {
+		first := report.RealizedLots[0]
+		assert.Equal(t, uint64(1), first.BuyTradeID)
+		assert.InDelta(t, 1.0, first.Quantity.Float64(), 1e-6)
+		assert.InDelta(t, 1.0, first.BuyFee.Float64(), 1e-6)
+		assert.InDelta(t, 2.0, first.SellFee.Float64(), 1e-6)
+		// 300 - 2 - (100 + 1)
+		assert.InDelta(t, 197.0, first.Profit().Float64(), 1e-6)
+		assert.Equal(t, 40, first.HoldingDays())
+
+		second := report.RealizedLots[1]
+		assert.Equal(t, uint64(2), second.BuyTradeID)
+		assert.InDelta(t, 0.5, second.Quantity.Float64(), 1e-6)
+		assert.InDelta(t, 1.0, second.BuyFee.Float64(), 1e-6)
+		assert.InDelta(t, 1.0, second.SellFee.Float64(), 1e-6)
+		// 150 - 1 - (100 + 1)
+		assert.InDelta(t, 48.0, second.Profit().Float64(), 1e-6)
+		assert.Equal(t, 30, second.HoldingDays())
+	}
+
+	assert.InDelta(t, 245.0, report.Profit.Float64(), 1e-6)
+
+	if assert.Len(t, report.OpenLots, 1) {
+		assert.Equal(t, uint64(2), report.OpenLots[0].TradeID)
+		assert.InDelta(t, 0.5, report.OpenLots[0].Quantity.Float64(), 1e-6)
+		assert.InDelta(t, 1.0, report.OpenLots[0].Fee.Float64(), 1e-6)
+	}
+
+	// (400 - 200) * 0.5 - 1
+	assert.InDelta(t, 99.0, report.UnrealizedProfit.Float64(), 1e-6)
+}
+
+func TestLotCalculator_LIFO(t *testing.T) {
+	calculator := &LotCalculator{Method: MethodLIFO, Market: testMarket}
+	report := calculator.Calculate("BTCUSDT", testTrades(), fixedpoint.NewFromInt(400))
+
+	if assert.Len(t, report.RealizedLots, 2) {
+		assert.Equal(t, uint64(2), report.RealizedLots[0].BuyTradeID)
+		assert.InDelta(t, 1.0, report.RealizedLots[0].Quantity.Float64(), 1e-6)
+		assert.Equal(t, uint64(1), report.RealizedLots[1].BuyTradeID)
+		assert.InDelta(t, 0.5, report.RealizedLots[1].Quantity.Float64(), 1e-6)
+	}
+
+	// the total of the realized and the unrealized profit is the same as FIFO
+	// (300 - 2 - 202) + (150 - 1 - 50.5)
+	assert.InDelta(t, 194.5, report.Profit.Float64(), 1e-6)
+	// (400 - 100) * 0.5 - 0.5
+	assert.InDelta(t, 149.5, report.UnrealizedProfit.Float64(), 1e-6)
+}
+
+func TestLotCalculator_SpecificID(t *testing.T) {
+	calculator := &LotCalculator{
+		Method:       MethodSpecificID,
+		Market:       testMarket,
+		SpecificLots: map[uint64][]uint64{3: {2}},
+	}
+	report := calculator.Calculate("BTCUSDT", testTrades(), fixedpoint.NewFromInt(400))
+
+	// the identified lot is disposed first, and the rest is disposed in FIFO
+	if assert.Len(t, report.RealizedLots, 2) {
+		assert.Equal(t, uint64(2), report.RealizedLots[0].BuyTradeID)
+		assert.Equal(t, uint64(1), report.RealizedLots[1].BuyTradeID)
+	}
+}
+
+func TestLotCalculator_BaseFee(t *testing.T) {
+	buy := testTrade(1, true, 100, 1, 0, 0)
+	buy.Fee = fixedpoint.NewFromFloat(0.01)
+	buy.FeeCurrency = "BTC"
+	sell := testTrade(2, false, 200, 0.99, 0, 1)
+
+	calculator := &LotCalculator{Method: MethodFIFO, Market: testMarket}
+	report := calculator.Calculate("BTCUSDT", []types.Trade{buy, sell}, fixedpoint.NewFromInt(200))
+
+	if assert.Len(t, report.RealizedLots, 1) {
+		lot := report.RealizedLots[0]
+		assert.InDelta(t, 0.99, lot.Quantity.Float64(), 1e-6)
+		// the cost basis is the paid quote amount
+		assert.InDelta(t, 100.0, lot.CostBasis().Float64(), 1e-6)
+	}
+
+	assert.Empty(t, report.OpenLots)
+	assert.InDelta(t, 98.0, report.Profit.Float64(), 1e-6)
+}
+
+func TestLotCalculator_Unmatched(t *testing.T) {
+	calculator := &LotCalculator{Method: MethodFIFO, Market: testMarket}
+	report := calculator.Calculate("BTCUSDT", []types.Trade{
+		testTrade(1, true, 100, 1, 0, 0),
+		testTrade(2, false, 200, 2, 0, 1),
+	}, fixedpoint.NewFromInt(200))
+
+	assert.Len(t, report.RealizedLots, 1)
+	assert.InDelta(t, 1.0, report.UnmatchedQuantity.Float64(), 1e-6)
+}
+
+func TestLotPnLReport_TaxYears(t *testing.T) {
+	calculator := &LotCalculator{Method: MethodFIFO, Market: testMarket}
+	report := calculator.Calculate("BTCUSDT", []types.Trade{
+		testTrade(1, true, 100, 2, 0, 0),
+		testTrade(2, false, 200, 1, 0, 10),
+		testTrade(3, false, 300, 1, 0, 60),
+	}, fixedpoint.NewFromInt(200))
+
+	assert.Equal(t, []int{2021, 2022}, report.TaxYears())
+
+	lots := report.RealizedLotsOfYear(2022)
+	if assert.Len(t, lots, 1) {
+		assert.Equal(t, uint64(3), lots[0].SellTradeID)
+	}
+
+	records := lots.CsvRecords()
+	if assert.Len(t, records, 1) {
+		assert.Len(t, records[0], len(lots.CsvHeader()))
+		assert.Equal(t, "60", records[0][5])
+	}
+}
+
+func TestReadSpecificLots(t *testing.T) {
+	lots, err := ReadSpecificLots(strings.NewReader("sell_trade_id,buy_trade_id\n3,2\n3,1\n5,4\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[uint64][]uint64{3: {2, 1}, 5: {4}}, lots)
+	}
+
+	_, err = ReadSpecificLots(strings.NewReader("3,2\n3,x\n"))
+	assert.Error(t, err)
+}
+
+func TestParseMethod(t *testing.T) {
+	m, err := ParseMethod("FIFO")
+	assert.NoError(t, err)
+	assert.Equal(t, MethodFIFO, m)
+
+	_, err = ParseMethod("hifo")
+	assert.Error(t, err)
+}
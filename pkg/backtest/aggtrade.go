@@ -0,0 +1,243 @@
+package backtest
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const aggTradeImportBatchSize = 1000
+
+// AggTradeCSVReader reads the aggregate trades from the Binance public trade dump (https://data.binance.vision),
+// the columns are:
+//
+//	agg_trade_id, price, quantity, first_trade_id, last_trade_id, transact_time, is_buyer_maker[, is_best_match]
+//
+// The header line of the futures dump files is skipped.
+type AggTradeCSVReader struct {
+	Exchange types.ExchangeName
+	Symbol   string
+
+	reader *csv.Reader
+}
+
+func NewAggTradeCSVReader(reader io.Reader, exchange types.ExchangeName, symbol string) *AggTradeCSVReader {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+	return &AggTradeCSVReader{
+		Exchange: exchange,
+		Symbol:   symbol,
+		reader:   csvReader,
+	}
+}
+
+// Read returns the next aggregate trade, io.EOF is returned at the end of the file
+func (r *AggTradeCSVReader) Read() (*types.Trade, error) {
+	for {
+		record, err := r.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 7 {
+			return nil, fmt.Errorf("unexpected aggregate trade record: %v", record)
+		}
+
+		id, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil {
+			// skip the header line
+			continue
+		}
+
+		price, err := fixedpoint.NewFromString(record[1])
+		if err != nil {
+			return nil, err
+		}
+
+		quantity, err := fixedpoint.NewFromString(record[2])
+		if err != nil {
+			return nil, err
+		}
+
+		ts, err := strconv.ParseInt(record[5], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		isBuyerMaker, err := strconv.ParseBool(strings.ToLower(record[6]))
+		if err != nil {
+			return nil, err
+		}
+
+		return newAggTrade(r.Exchange, r.Symbol, id, price, quantity, parseAggTradeTime(ts), isBuyerMaker), nil
+	}
+}
+
+// parseAggTradeTime parses the trade timestamp, the newer spot dump files use the microsecond timestamp
+func parseAggTradeTime(ts int64) time.Time {
+	if ts > 1e15 {
+		return time.UnixMicro(ts)
+	}
+
+	return time.UnixMilli(ts)
+}
+
+// newAggTrade converts the aggregate trade to the market trade, the same as the aggTrade stream event
+func newAggTrade(exchange types.ExchangeName, symbol string, id uint64, price, quantity fixedpoint.Value, tradeTime time.Time, isBuyerMaker bool) *types.Trade {
+	side := types.SideTypeBuy
+	if isBuyerMaker {
+		side = types.SideTypeSell
+	}
+
+	return &types.Trade{
+		ID:            id,
+		Exchange:      exchange,
+		Symbol:        symbol,
+		Side:          side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		IsBuyer:       !isBuyerMaker,
+		IsMaker:       isBuyerMaker,
+		Time:          types.Time(tradeTime),
+	}
+}
+
+// FindAggTradeFiles finds the aggregate trade dump files of the symbol,
+// the files are located at {dir}/{exchange}/{symbol}-aggTrades-*.{csv,zip} and sorted by the file name.
+func FindAggTradeFiles(dir string, exchange types.ExchangeName, symbol string) ([]string, error) {
+	var files []string
+	for _, ext := range []string{".csv", ".zip"} {
+		matches, err := filepath.Glob(filepath.Join(dir, exchange.String(), symbol+"-aggTrades-*"+ext))
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, matches...)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ImportAggTrades imports the aggregate trade dump files into the database,
+// the trades that are already imported are skipped, so the import can be run repeatedly with new or older files.
+func ImportAggTrades(ctx context.Context, srv *service.BacktestService, dir string, exchange types.ExchangeName, symbol string) error {
+	files, err := FindAggTradeFiles(dir, exchange, symbol)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		log.Warnf("aggregate trade files of %s %s are not found in %s", exchange, symbol, dir)
+		return nil
+	}
+
+	for _, file := range files {
+		log.Infof("importing aggregate trades from %s", file)
+
+		n, err := importAggTradeFile(ctx, srv, file, exchange, symbol)
+		if err != nil {
+			return fmt.Errorf("aggregate trade file %s import error: %w", file, err)
+		}
+
+		log.Infof("imported %d aggregate trades from %s", n, file)
+	}
+
+	return nil
+}
+
+func importAggTradeFile(ctx context.Context, srv *service.BacktestService, file string, exchange types.ExchangeName, symbol string) (int, error) {
+	reader, err := openAggTradeFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	defer reader.Close()
+
+	var n int
+	var trades []types.Trade
+	csvReader := NewAggTradeCSVReader(reader, exchange, symbol)
+	for {
+		trade, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return n, err
+		}
+
+		trades = append(trades, *trade)
+
+		if len(trades) >= aggTradeImportBatchSize {
+			inserted, err := srv.InsertNewAggTrades(ctx, trades)
+			if err != nil {
+				return n, err
+			}
+
+			n += inserted
+			trades = trades[:0]
+
+			if err := ctx.Err(); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	inserted, err := srv.InsertNewAggTrades(ctx, trades)
+	return n + inserted, err
+}
+
+type zipFileReadCloser struct {
+	io.ReadCloser
+	zipReader *zip.ReadCloser
+}
+
+func (r *zipFileReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if err2 := r.zipReader.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// openAggTradeFile opens the csv file, or the first csv file in the zip archive
+func openAggTradeFile(file string) (io.ReadCloser, error) {
+	if filepath.Ext(file) != ".zip" {
+		return os.Open(file)
+	}
+
+	zipReader, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zipReader.File {
+		if filepath.Ext(f.Name) != ".csv" {
+			continue
+		}
+
+		reader, err := f.Open()
+		if err != nil {
+			_ = zipReader.Close()
+			return nil, err
+		}
+
+		return &zipFileReadCloser{ReadCloser: reader, zipReader: zipReader}, nil
+	}
+
+	_ = zipReader.Close()
+	return nil, fmt.Errorf("csv file is not found in the zip archive %s", file)
+}
@@ -0,0 +1,105 @@
+package backtest
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAggTradeCSVReader(t *testing.T) {
+	data := `agg_trade_id,price,quantity,first_trade_id,last_trade_id,transact_time,is_buyer_maker
+1000,20000.50,0.5,2000,2001,1654041600000,true
+1001,20000.20,0.1,2002,2002,1654041600500123,False
+`
+	reader := NewAggTradeCSVReader(strings.NewReader(data), types.ExchangeBinance, "BTCUSDT")
+
+	trade, err := reader.Read()
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(1000), trade.ID)
+		assert.Equal(t, "20000.5", trade.Price.String())
+		assert.Equal(t, "10000.25", trade.QuoteQuantity.String())
+		assert.Equal(t, types.SideTypeSell, trade.Side)
+		assert.True(t, trade.IsMaker)
+		assert.Equal(t, time.UnixMilli(1654041600000), trade.Time.Time())
+	}
+
+	trade, err = reader.Read()
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.SideTypeBuy, trade.Side)
+		assert.True(t, trade.IsBuyer)
+		assert.Equal(t, time.UnixMicro(1654041600500123), trade.Time.Time())
+	}
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestExchange_replayMarketTrades(t *testing.T) {
+	engine := &SimplePriceMatching{
+		account:      getTestAccount(),
+		Market:       getTestMarket(),
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+
+	stream := types.NewStandardStream()
+	e := &Exchange{
+		sourceName:       types.ExchangeBinance,
+		account:          engine.account,
+		matchingBooks:    map[string]*SimplePriceMatching{"BTCUSDT": engine},
+		MarketDataStream: &stream,
+	}
+
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	tradeC := make(chan types.Trade, 10)
+	tradeC <- *newAggTrade(types.ExchangeBinance, "BTCUSDT", 1, fixedpoint.NewFromFloat(19900.0), fixedpoint.One, t1.Add(10*time.Second), true)
+	tradeC <- *newAggTrade(types.ExchangeBinance, "BTCUSDT", 2, fixedpoint.NewFromFloat(20100.0), fixedpoint.One, t1.Add(20*time.Second), false)
+	tradeC <- *newAggTrade(types.ExchangeBinance, "BTCUSDT", 3, fixedpoint.NewFromFloat(20000.0), fixedpoint.One, t1.Add(70*time.Second), false)
+	close(tradeC)
+
+	e.marketTrades = newMarketTradeReplay(tradeC, map[string]map[types.Channel]struct{}{
+		"BTCUSDT": {types.AggTradeChannel: {}},
+	})
+
+	order, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19950.0, 0.1))
+	assert.NoError(t, err)
+
+	var aggTrades []types.Trade
+	stream.OnAggTrade(func(trade types.Trade) {
+		aggTrades = append(aggTrades, trade)
+
+		// the order is filled by the first trade
+		o, _ := engine.getOrder(order.OrderID)
+		assert.Equal(t, types.OrderStatusFilled, o.Status)
+	})
+
+	var marketTrades []types.Trade
+	stream.OnMarketTrade(func(trade types.Trade) {
+		marketTrades = append(marketTrades, trade)
+	})
+
+	e.replayMarketTrades(t1.Add(time.Minute))
+	assert.Len(t, aggTrades, 2)
+	assert.Len(t, marketTrades, 0, "market trade channel is not subscribed")
+	assert.Equal(t, "20100", engine.lastPrice.String())
+	assert.True(t, engine.marketTradeMatched)
+
+	// the kline of the same period does not match the orders again
+	sellOrder, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeSell, 20200.0, 0.1))
+	assert.NoError(t, err)
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20300, 19900, 20100))
+	assert.False(t, engine.marketTradeMatched)
+
+	o, _ := engine.getOrder(sellOrder.OrderID)
+	assert.Equal(t, types.OrderStatusNew, o.Status)
+
+	e.replayMarketTrades(t1.Add(2 * time.Minute))
+	assert.Len(t, aggTrades, 3)
+}
@@ -0,0 +1,118 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type BookEventType string
+
+const (
+	BookEventTypeSnapshot BookEventType = "snapshot"
+	BookEventTypeUpdate   BookEventType = "update"
+	BookEventTypeTrade    BookEventType = "trade"
+)
+
+// BookEvent is the recorded market event for the order book replay matching engine.
+// The recorded events are stored in the JSON lines format, one event per line, for example:
+//
+//	{"time":1656633600000,"type":"snapshot","bids":[["19000.0","1.2"]],"asks":[["19001.0","0.5"]]}
+//	{"time":1656633600100,"type":"update","bids":[["19000.0","0"]],"asks":[["19001.0","0.7"]]}
+//	{"time":1656633600200,"type":"trade","side":"buy","price":"19001.0","quantity":"0.1"}
+//
+// For update events, the volume is the new total volume of the price level, zero volume removes the price level.
+type BookEvent struct {
+	Time types.MillisecondTimestamp `json:"time"`
+	Type BookEventType              `json:"type"`
+
+	// Bids and Asks are used by the snapshot and update events
+	Bids types.PriceVolumeSlice `json:"bids,omitempty"`
+	Asks types.PriceVolumeSlice `json:"asks,omitempty"`
+
+	// Side is the taker side of the trade event
+	Side     types.SideType   `json:"side,omitempty"`
+	Price    fixedpoint.Value `json:"price,omitempty"`
+	Quantity fixedpoint.Value `json:"quantity,omitempty"`
+}
+
+// BookEventReader reads the recorded book events from a JSON lines source
+type BookEventReader struct {
+	closer  io.Closer
+	scanner *bufio.Scanner
+	next    *BookEvent
+}
+
+func NewBookEventReader(reader io.Reader) *BookEventReader {
+	scanner := bufio.NewScanner(reader)
+
+	// snapshot events could be very long
+	scanner.Buffer(make([]byte, 64*1024), 32*1024*1024)
+
+	r := &BookEventReader{scanner: scanner}
+	if closer, ok := reader.(io.Closer); ok {
+		r.closer = closer
+	}
+
+	return r
+}
+
+func OpenBookEventFile(filename string) (*BookEventReader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBookEventReader(f), nil
+}
+
+// Peek returns the next event without consuming it, io.EOF is returned when there is no more event
+func (r *BookEventReader) Peek() (*BookEvent, error) {
+	if r.next != nil {
+		return r.next, nil
+	}
+
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var evt BookEvent
+		if err := json.Unmarshal(line, &evt); err != nil {
+			return nil, err
+		}
+
+		r.next = &evt
+		return r.next, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// Next returns the next event and consumes it
+func (r *BookEventReader) Next() (*BookEvent, error) {
+	evt, err := r.Peek()
+	if err != nil {
+		return nil, err
+	}
+
+	r.next = nil
+	return evt, nil
+}
+
+func (r *BookEventReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+
+	return nil
+}
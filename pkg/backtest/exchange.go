@@ -30,6 +30,8 @@ package backtest
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
@@ -41,6 +43,7 @@ import (
 	"github.com/c9s/bbgo/pkg/cache"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -51,6 +54,8 @@ var ErrUnimplemented = errors.New("unimplemented method")
 var ErrNegativeQuantity = errors.New("order quantity can not be negative")
 var ErrZeroQuantity = errors.New("order quantity can not be zero")
 
+var defaultMaintenanceMarginRate = fixedpoint.NewFromFloat(0.004)
+
 type Exchange struct {
 	sourceName     types.ExchangeName
 	publicExchange types.Exchange
@@ -71,6 +76,14 @@ type Exchange struct {
 	matchingBooks      map[string]*SimplePriceMatching
 	matchingBooksMutex sync.Mutex
 
+	// margin is the simulated cross margin account, it's only set when the margin account is configured
+	margin *marginAccount
+
+	// marketTrades replays the aggregate trades, it's only set when the trade channels are subscribed
+	marketTrades *marketTradeReplay
+
+	userDataStream types.StandardStreamEmitter
+
 	markets types.MarketMap
 
 	Src *ExchangeDataSource
@@ -84,15 +97,43 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 		return nil, err
 	}
 
+	switch config.MatchingEngine {
+	case "", bbgo.BacktestMatchingEngineKLine:
+	case bbgo.BacktestMatchingEngineOrderBook:
+		if config.OrderBookDataDir == "" {
+			return nil, errors.New("orderBookDataDir is required for the orderbook matching engine")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported matching engine: %s", config.MatchingEngine)
+	}
+
 	startTime := config.StartTime.Time()
 	configAccount := config.GetAccount(sourceName.String())
 
+	if configAccount.Slippage != nil && getSlippageFunction(configAccount.Slippage) == nil {
+		return nil, fmt.Errorf("unsupported slippage model: %s", configAccount.Slippage.Model)
+	}
+
+	if configAccount.Futures != nil && configAccount.Margin != nil {
+		return nil, errors.New("futures and margin can not be simulated in the same account")
+	}
+
+	if configAccount.Futures != nil && configAccount.Futures.Leverage.Sign() < 0 {
+		return nil, fmt.Errorf("futures leverage can not be negative: %s", configAccount.Futures.Leverage.String())
+	}
+
 	account := &types.Account{
 		MakerFeeRate: configAccount.MakerFeeRate,
 		TakerFeeRate: configAccount.TakerFeeRate,
 		AccountType:  types.AccountTypeSpot,
 	}
 
+	if configAccount.Futures != nil {
+		account.AccountType = types.AccountTypeFutures
+	} else if configAccount.Margin != nil {
+		account.AccountType = types.AccountTypeMargin
+	}
+
 	balances := configAccount.Balances.BalanceMap()
 	account.UpdateBalances(balances)
 
@@ -108,6 +149,10 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 		trades:         make(map[string][]types.Trade),
 	}
 
+	if configAccount.Margin != nil {
+		e.margin = newMarginAccount(sourceName, account, configAccount.Margin, e.assetPrice)
+	}
+
 	e.resetMatchingBooks()
 	return e, nil
 }
@@ -126,6 +171,7 @@ func (e *Exchange) addClosedOrder(order types.Order) {
 
 func (e *Exchange) resetMatchingBooks() {
 	e.matchingBooksMutex.Lock()
+	e.closeBookReplays()
 	e.matchingBooks = make(map[string]*SimplePriceMatching)
 	for symbol, market := range e.markets {
 		e._addMatchingBook(symbol, market)
@@ -140,17 +186,99 @@ func (e *Exchange) addMatchingBook(symbol string, market types.Market) {
 }
 
 func (e *Exchange) _addMatchingBook(symbol string, market types.Market) {
+	if previous, ok := e.matchingBooks[symbol]; ok {
+		previous.closeBookReplay()
+	}
+
+	configAccount := e.config.GetAccount(e.sourceName.String())
 	matching := &SimplePriceMatching{
-		currentTime:     e.currentTime,
-		account:         e.account,
-		Market:          market,
-		closedOrders:    make(map[uint64]types.Order),
-		feeModeFunction: getFeeModeFunction(e.config.FeeMode),
+		currentTime:      e.currentTime,
+		account:          e.account,
+		Market:           market,
+		closedOrders:     make(map[uint64]types.Order),
+		feeModeFunction:  getFeeModeFunction(e.config.FeeMode),
+		orderLatency:     configAccount.OrderLatency.Duration(),
+		cancelLatency:    configAccount.CancelLatency.Duration(),
+		slippageFunction: getSlippageFunction(configAccount.Slippage),
+		margin:           e.margin,
+	}
+
+	if e.config.MatchingEngine == bbgo.BacktestMatchingEngineOrderBook {
+		if reader, ok := e.openBookEvents(symbol); ok {
+			matching.bookReplay = newOrderBookReplay(symbol, reader)
+		}
+	}
+
+	if configAccount.Futures != nil {
+		leverage := configAccount.Futures.Leverage
+		if leverage.IsZero() {
+			leverage = fixedpoint.One
+		}
+
+		maintenanceMarginRate := configAccount.Futures.MaintenanceMarginRate
+		if maintenanceMarginRate.IsZero() {
+			maintenanceMarginRate = defaultMaintenanceMarginRate
+		}
+
+		matching.futures = newFuturesPosition(leverage, maintenanceMarginRate)
 	}
 
 	e.matchingBooks[symbol] = matching
 }
 
+// IsFutures returns true if the backtest account is simulated as a futures account
+func (e *Exchange) IsFutures() bool {
+	return e.config.GetAccount(e.sourceName.String()).Futures != nil
+}
+
+// loadFundingRates loads the historical funding rates of the given symbols for the futures positions
+func (e *Exchange) loadFundingRates(ctx context.Context, symbols []string, startTime, endTime time.Time) error {
+	fundingService, ok := e.publicExchange.(types.FundingRateHistoryService)
+	if !ok {
+		log.Warnf("exchange %s does not support funding rate history, funding fees are not simulated", e.sourceName)
+		return nil
+	}
+
+	for _, symbol := range symbols {
+		matching, ok := e.matchingBook(symbol)
+		if !ok || matching.futures == nil {
+			continue
+		}
+
+		var rates []types.FundingRate
+		key := fmt.Sprintf("%s-%s-funding-rates-%d-%d", e.sourceName, symbol, startTime.Unix(), endTime.Unix())
+		err := cache.WithCache(key, &rates, func() (interface{}, error) {
+			return fundingService.QueryFundingRates(ctx, symbol, startTime, endTime)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "can not query the funding rates of %s", symbol)
+		}
+
+		log.Infof("loaded %d funding rates of %s", len(rates), symbol)
+		matching.futures.setFundingRates(rates)
+	}
+
+	return nil
+}
+
+// openBookEvents opens the recorded book events of the given symbol,
+// symbols without the recorded book events are matched by the kline matching engine
+func (e *Exchange) openBookEvents(symbol string) (*BookEventReader, bool) {
+	filename := filepath.Join(e.config.OrderBookDataDir, e.sourceName.String(), symbol+".jsonl")
+	if _, err := os.Stat(filename); err != nil {
+		return nil, false
+	}
+
+	reader, err := OpenBookEventFile(filename)
+	if err != nil {
+		log.WithError(err).Errorf("can not open the book event file %s", filename)
+		return nil, false
+	}
+
+	log.Infof("using the order book replay matching engine for %s with %s", symbol, filename)
+	return reader, true
+}
+
 func (e *Exchange) NewStream() types.Stream {
 	return &types.BacktestStream{
 		StandardStreamEmitter: &types.StandardStream{},
@@ -204,7 +332,10 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 		return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
 	}
 
-	return append(matching.bidOrders, matching.askOrders...), nil
+	orders = append(orders, matching.bidOrders...)
+	orders = append(orders, matching.askOrders...)
+	orders = append(orders, matching.pendingOrders...)
+	return orders, nil
 }
 
 func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
@@ -300,6 +431,52 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 	return nil, nil
 }
 
+// QueryPositionRisk returns the leverage and the liquidation price of the simulated futures position
+func (e *Exchange) QueryPositionRisk(ctx context.Context, symbol string) (*types.PositionRisk, error) {
+	matching, ok := e.matchingBook(symbol)
+	if !ok {
+		return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
+	}
+
+	if matching.futures == nil {
+		return nil, fmt.Errorf("%s is not a futures account", e.sourceName)
+	}
+
+	return &types.PositionRisk{
+		Leverage:         matching.futures.leverage,
+		LiquidationPrice: matching.futures.liquidationPrice(),
+	}, nil
+}
+
+// FuturesReport returns the summary of the simulated futures position, nil is returned for the spot account
+func (e *Exchange) FuturesReport(symbol string) *FuturesReport {
+	matching, ok := e.matchingBook(symbol)
+	if !ok || matching.futures == nil {
+		return nil
+	}
+
+	p := matching.futures
+	return &FuturesReport{
+		Leverage:         p.leverage,
+		Position:         p.base,
+		EntryPrice:       p.entryPrice,
+		LiquidationPrice: p.liquidationPrice(),
+		UnrealizedProfit: p.unrealizedProfit(matching.lastPrice),
+		FundingFee:       p.fundingFee,
+		Liquidations:     p.liquidations,
+	}
+}
+
+// SlippageCost returns the accumulated cost of the simulated slippage of the given symbol in the quote currency
+func (e *Exchange) SlippageCost(symbol string) fixedpoint.Value {
+	matching, ok := e.matchingBook(symbol)
+	if !ok {
+		return fixedpoint.Zero
+	}
+
+	return matching.slippageCost
+}
+
 func (e *Exchange) matchingBook(symbol string) (*SimplePriceMatching, bool) {
 	e.matchingBooksMutex.Lock()
 	m, ok := e.matchingBooks[symbol]
@@ -308,6 +485,8 @@ func (e *Exchange) matchingBook(symbol string) (*SimplePriceMatching, bool) {
 }
 
 func (e *Exchange) BindUserData(userDataStream types.StandardStreamEmitter) {
+	e.userDataStream = userDataStream
+
 	userDataStream.OnTradeUpdate(func(trade types.Trade) {
 		e.addTrade(trade)
 	})
@@ -325,6 +504,7 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, requiredInt
 	log.Infof("collecting backtest configurations...")
 
 	loadedSymbols := map[string]struct{}{}
+	tradeChannels := map[string]map[types.Channel]struct{}{}
 	loadedIntervals := map[types.Interval]struct{}{
 		// 1m interval is required for the backtest matching engine
 		requiredInterval: {},
@@ -342,6 +522,12 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, requiredInt
 		case types.KLineChannel:
 			loadedIntervals[sub.Options.Interval] = struct{}{}
 
+		case types.MarketTradeChannel, types.AggTradeChannel:
+			if _, ok := tradeChannels[sub.Symbol]; !ok {
+				tradeChannels[sub.Symbol] = map[types.Channel]struct{}{}
+			}
+			tradeChannels[sub.Symbol][sub.Channel] = struct{}{}
+
 		default:
 			// Since Environment is not yet been injected at this point, no hard error
 			log.Errorf("stream channel %s is not supported in backtest", sub.Channel)
@@ -358,6 +544,28 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, requiredInt
 		intervals = append(intervals, interval)
 	}
 
+	if e.IsFutures() {
+		if err := e.loadFundingRates(context.Background(), symbols, startTime, endTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tradeChannels) > 0 {
+		var tradeSymbols []string
+		for symbol := range tradeChannels {
+			tradeSymbols = append(tradeSymbols, symbol)
+		}
+
+		log.Infof("querying aggregate trades from database with exchange: %v symbols: %v for back-testing", e.Name(), tradeSymbols)
+		tradeC, errC := e.srv.QueryAggTradesCh(startTime, endTime, e.Name(), tradeSymbols)
+		go func() {
+			if err := <-errC; err != nil {
+				log.WithError(err).Error("backtest trade data feed error")
+			}
+		}()
+		e.marketTrades = newMarketTradeReplay(tradeC, tradeChannels)
+	}
+
 	log.Infof("querying klines from database with exchange: %v symbols: %v and intervals: %v for back-testing", e.Name(), symbols, intervals)
 	klineC, errC := e.srv.QueryKLinesCh(startTime, endTime, e, symbols, intervals)
 	go func() {
@@ -383,9 +591,15 @@ func (e *Exchange) ConsumeKLine(k types.KLine, requiredInterval types.Interval)
 		if requiredKline.Interval != requiredInterval {
 			panic(fmt.Sprintf("expect required kline interval %s, got interval %s", requiredInterval.String(), requiredKline.Interval.String()))
 		}
+		// the market trades of the kline period are replayed before the kline is closed
+		e.replayMarketTrades(requiredKline.EndTime.Time())
+
 		e.currentTime = requiredKline.EndTime.Time()
 		// here we generate trades and order updates
 		matching.processKLine(requiredKline)
+		if e.margin != nil {
+			e.processMargin()
+		}
 		matching.nextKLine = &k
 		for _, kline := range matching.klineCache {
 			e.MarketDataStream.EmitKLineClosed(kline)
@@ -399,7 +613,18 @@ func (e *Exchange) ConsumeKLine(k types.KLine, requiredInterval types.Interval)
 	matching.klineCache[k.Interval] = k
 }
 
+// closeBookReplays closes the book event readers of the matching books, the caller should hold matchingBooksMutex
+func (e *Exchange) closeBookReplays() {
+	for _, matching := range e.matchingBooks {
+		matching.closeBookReplay()
+	}
+}
+
 func (e *Exchange) CloseMarketData() error {
+	e.matchingBooksMutex.Lock()
+	e.closeBookReplays()
+	e.matchingBooksMutex.Unlock()
+
 	if err := e.MarketDataStream.Close(); err != nil {
 		log.WithError(err).Error("stream close error")
 		return err
@@ -0,0 +1,266 @@
+package backtest
+
+import (
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// futuresPosition simulates the isolated perpetual futures position (USDT-margined, one-way mode) of a matching engine.
+//
+// The quote balance is used as the margin wallet:
+//
+// - open orders lock the initial margin, (order quantity * price / leverage), in the quote balance.
+// - when the order is filled, the locked order margin becomes the position margin.
+// - when the position is reduced, the position margin is released and the realized profit is added to the quote balance.
+// - the position is liquidated when the position margin plus the unrealized profit is less than the maintenance margin.
+// - the funding fee is paid or received at the funding time by the historical funding rates.
+//
+// The kline close price is used as the mark price.
+type futuresPosition struct {
+	leverage              fixedpoint.Value
+	maintenanceMarginRate fixedpoint.Value
+
+	// base is the signed position quantity, positive for long and negative for short
+	base       fixedpoint.Value
+	entryPrice fixedpoint.Value
+
+	fundingRates     []types.FundingRate
+	nextFundingIndex int
+
+	// fundingFee is the accumulated funding fee, positive for the received funding fee
+	fundingFee   fixedpoint.Value
+	liquidations int
+}
+
+func newFuturesPosition(leverage, maintenanceMarginRate fixedpoint.Value) *futuresPosition {
+	return &futuresPosition{
+		leverage:              leverage,
+		maintenanceMarginRate: maintenanceMarginRate,
+	}
+}
+
+func (p *futuresPosition) setFundingRates(rates []types.FundingRate) {
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].FundingTime.Before(rates[j].FundingTime)
+	})
+
+	p.fundingRates = rates
+	p.nextFundingIndex = 0
+}
+
+// margin returns the initial margin of the given notional value
+func (p *futuresPosition) margin(quoteQuantity fixedpoint.Value) fixedpoint.Value {
+	return quoteQuantity.Div(p.leverage)
+}
+
+func (p *futuresPosition) unrealizedProfit(markPrice fixedpoint.Value) fixedpoint.Value {
+	return markPrice.Sub(p.entryPrice).Mul(p.base)
+}
+
+// liquidationPrice returns the mark price when the position margin plus the unrealized profit equals to the maintenance margin
+func (p *futuresPosition) liquidationPrice() fixedpoint.Value {
+	one := fixedpoint.One
+	switch p.base.Sign() {
+	case 1:
+		return p.entryPrice.Mul(one.Sub(one.Div(p.leverage))).Div(one.Sub(p.maintenanceMarginRate))
+	case -1:
+		return p.entryPrice.Mul(one.Add(one.Div(p.leverage))).Div(one.Add(p.maintenanceMarginRate))
+	}
+
+	return fixedpoint.Zero
+}
+
+// lockOrderBalance locks the balance required by the order of the given quantity and quote quantity
+func (m *SimplePriceMatching) lockOrderBalance(side types.SideType, quantity, quoteQuantity fixedpoint.Value) error {
+	if m.futures != nil {
+		return m.account.LockBalance(m.Market.QuoteCurrency, m.futures.margin(quoteQuantity))
+	}
+
+	switch side {
+	case types.SideTypeBuy:
+		return m.account.LockBalance(m.Market.QuoteCurrency, quoteQuantity)
+
+	case types.SideTypeSell:
+		return m.account.LockBalance(m.Market.BaseCurrency, quantity)
+	}
+
+	return nil
+}
+
+// unlockOrderBalance unlocks the balance locked by the order of the given quantity and quote quantity
+func (m *SimplePriceMatching) unlockOrderBalance(side types.SideType, quantity, quoteQuantity fixedpoint.Value) error {
+	if m.futures != nil {
+		m.unlockMargin(m.futures.margin(quoteQuantity))
+		return nil
+	}
+
+	switch side {
+	case types.SideTypeBuy:
+		return m.account.UnlockBalance(m.Market.QuoteCurrency, quoteQuantity)
+
+	case types.SideTypeSell:
+		return m.account.UnlockBalance(m.Market.BaseCurrency, quantity)
+	}
+
+	return nil
+}
+
+// unlockMargin unlocks the margin, the amount is capped by the locked balance to tolerate the rounding error
+func (m *SimplePriceMatching) unlockMargin(amount fixedpoint.Value) {
+	balance, ok := m.account.Balance(m.Market.QuoteCurrency)
+	if !ok {
+		return
+	}
+
+	amount = fixedpoint.Min(amount, balance.Locked)
+	if amount.Sign() <= 0 {
+		return
+	}
+
+	if err := m.account.UnlockBalance(m.Market.QuoteCurrency, amount); err != nil {
+		klineMatchingLogger.WithError(err).Errorf("can not unlock the futures margin")
+	}
+}
+
+// executeFuturesTrade updates the futures position and the margin wallet by the given trade,
+// hasOrderMargin is false for the liquidation trades since the liquidation orders do not lock the order margin.
+func (m *SimplePriceMatching) executeFuturesTrade(trade types.Trade, hasOrderMargin bool) {
+	p := m.futures
+
+	direction := fixedpoint.One
+	if !trade.IsBuyer {
+		direction = direction.Neg()
+	}
+
+	closing := fixedpoint.Zero
+	if p.base.Sign() != 0 && p.base.Sign() != direction.Sign() {
+		closing = fixedpoint.Min(p.base.Abs(), trade.Quantity)
+	}
+	opening := trade.Quantity.Sub(closing)
+
+	if closing.Sign() > 0 {
+		profit := trade.Price.Sub(p.entryPrice).Mul(closing)
+		if p.base.Sign() < 0 {
+			profit = profit.Neg()
+		}
+
+		// the order margin and the position margin of the closed part are released
+		releasedMargin := p.margin(p.entryPrice.Mul(closing))
+		if hasOrderMargin {
+			releasedMargin = releasedMargin.Add(p.margin(trade.Price.Mul(closing)))
+		}
+
+		m.unlockMargin(releasedMargin)
+		m.account.AddBalance(m.Market.QuoteCurrency, profit)
+
+		p.base = p.base.Add(closing.Mul(direction))
+		if p.base.IsZero() {
+			p.entryPrice = fixedpoint.Zero
+		}
+	}
+
+	// the order margin of the opening part becomes the position margin
+	if opening.Sign() > 0 {
+		base := p.base.Add(opening.Mul(direction))
+		p.entryPrice = p.entryPrice.Mul(p.base.Abs()).Add(trade.Price.Mul(opening)).Div(base.Abs())
+		p.base = base
+	}
+
+	switch trade.FeeCurrency {
+	case m.Market.QuoteCurrency:
+		m.account.AddBalance(m.Market.QuoteCurrency, trade.Fee.Neg())
+
+	case m.Market.BaseCurrency:
+		m.account.AddBalance(m.Market.QuoteCurrency, trade.Fee.Mul(trade.Price).Neg())
+	}
+
+	m.EmitTradeUpdate(trade)
+	m.EmitBalanceUpdate(m.account.Balances())
+}
+
+// processFutures pays the funding fee and checks the liquidation by the given kline
+func (m *SimplePriceMatching) processFutures(kline types.KLine) {
+	p := m.futures
+
+	// the liquidation is checked by the worst price of the kline
+	switch p.base.Sign() {
+	case 1:
+		if liquidationPrice := p.liquidationPrice(); kline.Low.Compare(liquidationPrice) <= 0 {
+			m.liquidate(liquidationPrice)
+		}
+
+	case -1:
+		if liquidationPrice := p.liquidationPrice(); kline.High.Compare(liquidationPrice) >= 0 {
+			m.liquidate(liquidationPrice)
+		}
+	}
+
+	for p.nextFundingIndex < len(p.fundingRates) {
+		rate := p.fundingRates[p.nextFundingIndex]
+		if rate.FundingTime.After(kline.EndTime.Time()) {
+			break
+		}
+
+		p.nextFundingIndex++
+
+		// the long position pays the funding fee when the funding rate is positive
+		fee := p.base.Mul(kline.Close).Mul(rate.FundingRate).Neg()
+		if fee.IsZero() {
+			continue
+		}
+
+		p.fundingFee = p.fundingFee.Add(fee)
+		m.account.AddBalance(m.Market.QuoteCurrency, fee)
+		m.EmitBalanceUpdate(m.account.Balances())
+	}
+}
+
+// liquidate closes the position at the liquidation price, the rest of the position margin is charged as the liquidation fee
+func (m *SimplePriceMatching) liquidate(price fixedpoint.Value) {
+	p := m.futures
+	quantity := p.base.Abs()
+
+	side := types.SideTypeSell
+	if p.base.Sign() < 0 {
+		side = types.SideTypeBuy
+	}
+
+	klineMatchingLogger.Warnf("%s futures position %s is liquidated at %s", m.Market.Symbol, p.base.String(), price.String())
+
+	order := m.newOrder(types.SubmitOrder{
+		Symbol:     m.Market.Symbol,
+		Side:       side,
+		Type:       types.OrderTypeMarket,
+		Quantity:   quantity,
+		Price:      price,
+		Market:     m.Market,
+		ReduceOnly: true,
+		Tag:        "liquidation",
+	}, incOrderID())
+
+	trade := m.newTradeFromOrder(&order, false, price)
+	trade.Fee = quantity.Mul(price).Mul(p.maintenanceMarginRate)
+	trade.FeeCurrency = m.Market.QuoteCurrency
+	m.executeFuturesTrade(trade, false)
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = quantity
+	order.IsWorking = false
+	m.closedOrders[order.OrderID] = order
+	m.EmitOrderUpdate(order)
+
+	p.liquidations++
+}
+
+// FuturesReport is the simulated futures position summary of a symbol
+type FuturesReport struct {
+	Leverage         fixedpoint.Value `json:"leverage"`
+	Position         fixedpoint.Value `json:"position"`
+	EntryPrice       fixedpoint.Value `json:"entryPrice"`
+	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+	FundingFee       fixedpoint.Value `json:"fundingFee"`
+	Liquidations     int              `json:"liquidations"`
+}
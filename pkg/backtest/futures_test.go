@@ -0,0 +1,167 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newFuturesTestEngine(leverage float64) *SimplePriceMatching {
+	account := &types.Account{}
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	return &SimplePriceMatching{
+		account:      account,
+		Market:       getTestMarket(),
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(20000.0),
+		futures:      newFuturesPosition(fixedpoint.NewFromFloat(leverage), fixedpoint.NewFromFloat(0.004)),
+	}
+}
+
+func TestSimplePriceMatching_Futures_ShortPosition(t *testing.T) {
+	engine := newFuturesTestEngine(10)
+
+	// open a short position without holding the base asset, the margin is locked in the quote currency
+	_, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, trade) {
+		assert.True(t, trade.IsFutures)
+	}
+	assert.Equal(t, "-1", engine.futures.base.String())
+	assert.Equal(t, "20000", engine.futures.entryPrice.String())
+	assert.InDelta(t, 21912.3505, engine.futures.liquidationPrice().Float64(), 0.0001)
+
+	usdt, _ := engine.account.Balance("USDT")
+	assert.Equal(t, "8000", usdt.Available.String())
+	assert.Equal(t, "2000", usdt.Locked.String())
+
+	// close the position with a limit buy order
+	order, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19000, 1.0))
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+
+	usdt, _ = engine.account.Balance("USDT")
+	assert.Equal(t, "3900", usdt.Locked.String())
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, time.Now(), 19500, 19600, 18900, 19100))
+	assert.True(t, engine.futures.base.IsZero())
+
+	// both the order margin and the position margin are released, and the profit is realized
+	usdt, _ = engine.account.Balance("USDT")
+	assert.Equal(t, "11000", usdt.Available.String())
+	assert.True(t, usdt.Locked.IsZero())
+}
+
+func TestSimplePriceMatching_Futures_FundingFee(t *testing.T) {
+	engine := newFuturesTestEngine(5)
+
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	engine.futures.setFundingRates([]types.FundingRate{
+		{FundingRate: fixedpoint.NewFromFloat(-0.0002), FundingTime: t1.Add(8 * time.Hour)},
+		{FundingRate: fixedpoint.NewFromFloat(0.0001), FundingTime: t1},
+	})
+
+	_, _, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+
+	// the long position pays the positive funding rate
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1, 20000, 20100, 19900, 20000))
+	assert.Equal(t, "-2", engine.futures.fundingFee.String())
+
+	usdt, _ := engine.account.Balance("USDT")
+	assert.Equal(t, "5998", usdt.Available.String())
+	assert.Equal(t, "4000", usdt.Locked.String())
+
+	// the long position receives the negative funding rate
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1.Add(8*time.Hour), 20000, 20100, 19900, 20000))
+	assert.Equal(t, "2", engine.futures.fundingFee.String())
+}
+
+func TestSimplePriceMatching_Futures_Liquidation(t *testing.T) {
+	engine := newFuturesTestEngine(10)
+
+	var orders []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+
+	_, _, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 18072.2891, engine.futures.liquidationPrice().Float64(), 0.0001)
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, time.Now(), 20000, 20100, 18000, 18500))
+	assert.True(t, engine.futures.base.IsZero())
+	assert.Equal(t, 1, engine.futures.liquidations)
+
+	if assert.NotEmpty(t, orders) {
+		lastOrder := orders[len(orders)-1]
+		assert.Equal(t, types.SideTypeSell, lastOrder.Side)
+		assert.Equal(t, types.OrderStatusFilled, lastOrder.Status)
+		assert.True(t, lastOrder.ReduceOnly)
+	}
+
+	// the whole position margin is lost
+	usdt, _ := engine.account.Balance("USDT")
+	assert.InDelta(t, 8000.0, usdt.Available.Float64(), 0.0001)
+	assert.True(t, usdt.Locked.IsZero())
+}
+
+func TestSimplePriceMatching_Futures_OrderBookReplay(t *testing.T) {
+	engine := newFuturesTestEngine(10)
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine.bookReplay = newOrderBookReplay("BTCUSDT", NewBookEventReader(strings.NewReader(
+		`{"time":1625097600000,"type":"snapshot","bids":[["19999","5.0"]],"asks":[["20001","5.0"]]}`,
+	)))
+	engine.futures.setFundingRates([]types.FundingRate{
+		{FundingRate: fixedpoint.NewFromFloat(0.0001), FundingTime: t1.Add(30 * time.Minute)},
+	})
+
+	engine.replayBookEvents(t1)
+	assert.True(t, engine.bookReplay.loaded)
+
+	// the market order walks through the replayed order book
+	_, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, trade) {
+		assert.Equal(t, "20001", trade.Price.String())
+	}
+	assert.Equal(t, "1", engine.futures.base.String())
+
+	// the funding fee is still paid when the orders are matched by the order book replay
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1, 20000, 20100, 19900, 20000))
+	assert.Equal(t, "-2", engine.futures.fundingFee.String())
+
+	// and the position is still liquidated by the kline low
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1.Add(time.Hour), 20000, 20100, 17000, 17500))
+	assert.True(t, engine.futures.base.IsZero())
+	assert.Equal(t, 1, engine.futures.liquidations)
+}
@@ -0,0 +1,113 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// delayedAction is an order submission or an order cancellation delayed by the simulated latency,
+// the action is executed by the matching engine when the simulated time reaches the arrival time.
+type delayedAction struct {
+	time   time.Time
+	order  types.Order
+	cancel bool
+}
+
+func (m *SimplePriceMatching) delayPlaceOrder(o types.SubmitOrder, orderID uint64) (*types.Order, *types.Trade, error) {
+	// the order is acknowledged, but it does not exist in the matching engine until it arrives.
+	order := m.newOrder(o, orderID)
+
+	m.mu.Lock()
+	m.delayedActions = append(m.delayedActions, delayedAction{
+		time:  m.currentTime.Add(m.orderLatency),
+		order: order,
+	})
+	m.mu.Unlock()
+
+	return &order, nil, nil
+}
+
+func (m *SimplePriceMatching) delayCancelOrder(o types.Order) (types.Order, error) {
+	m.mu.Lock()
+	m.delayedActions = append(m.delayedActions, delayedAction{
+		time:   m.currentTime.Add(m.cancelLatency),
+		order:  o,
+		cancel: true,
+	})
+	m.mu.Unlock()
+
+	return o, nil
+}
+
+// processDelayedActions executes the delayed actions arrived before the given time
+func (m *SimplePriceMatching) processDelayedActions(until time.Time) {
+	m.mu.Lock()
+	actions := m.delayedActions
+	m.delayedActions = nil
+	m.mu.Unlock()
+
+	if len(actions) == 0 {
+		return
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].time.Before(actions[j].time)
+	})
+
+	currentTime := m.currentTime
+
+	var rest []delayedAction
+	for i, action := range actions {
+		if action.time.After(until) {
+			rest = actions[i:]
+			break
+		}
+
+		m.currentTime = action.time
+		m.executeDelayedAction(action)
+	}
+
+	m.currentTime = currentTime
+
+	// the actions created by the callbacks are appended after the rest actions
+	m.mu.Lock()
+	m.delayedActions = append(rest, m.delayedActions...)
+	m.mu.Unlock()
+}
+
+func (m *SimplePriceMatching) executeDelayedAction(action delayedAction) {
+	if action.cancel {
+		if _, err := m.cancelOrder(action.order); err != nil {
+			// the order might be filled before the cancellation arrives
+			klineMatchingLogger.WithError(err).Debugf("delayed cancellation failed")
+		}
+		return
+	}
+
+	if _, _, err := m.placeOrder(action.order.SubmitOrder, action.order.OrderID); err != nil {
+		klineMatchingLogger.WithError(err).Errorf("delayed order is rejected: %+v", action.order)
+
+		order := action.order
+		order.Status = types.OrderStatusRejected
+		order.IsWorking = false
+		order.UpdateTime = types.Time(m.currentTime)
+		m.closedOrders[order.OrderID] = order
+		m.EmitOrderUpdate(order)
+	}
+}
+
+// getDelayedOrder returns the order that is submitted but not arrived yet
+func (m *SimplePriceMatching) getDelayedOrder(orderID uint64) (types.Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, action := range m.delayedActions {
+		if !action.cancel && action.order.OrderID == orderID {
+			return action.order, true
+		}
+	}
+
+	return types.Order{}, false
+}
@@ -0,0 +1,71 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSimplePriceMatching_OrderLatency(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := &SimplePriceMatching{
+		account:       account,
+		Market:        market,
+		currentTime:   t1,
+		closedOrders:  make(map[uint64]types.Order),
+		lastPrice:     fixedpoint.NewFromFloat(19000.0),
+		orderLatency:  100 * time.Millisecond,
+		cancelLatency: 100 * time.Millisecond,
+	}
+
+	var orderUpdates []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orderUpdates = append(orderUpdates, order)
+	})
+
+	// the market order is not executed until it arrives at the matching engine
+	order, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+	assert.Len(t, orderUpdates, 0)
+
+	_, ok := engine.getOrder(order.OrderID)
+	assert.True(t, ok)
+
+	// executed at the open price of the next kline
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 19500, 19600, 19400, 19550))
+	if assert.Len(t, orderUpdates, 2) {
+		assert.Equal(t, types.OrderStatusFilled, orderUpdates[1].Status)
+		assert.Equal(t, "19500", orderUpdates[1].Price.String())
+	}
+
+	// the cancellation arrives after the order is filled by the open price
+	t2 := t1.Add(time.Minute)
+	order, _, err = engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19000.0, 0.1))
+	assert.NoError(t, err)
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t2, 19550, 19600, 19500, 19550))
+	assert.Len(t, engine.bidOrders, 1)
+
+	_, err = engine.CancelOrder(*order)
+	assert.NoError(t, err)
+	assert.Len(t, engine.bidOrders, 1)
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t2.Add(time.Minute), 18950, 19600, 18900, 19550))
+	assert.Len(t, engine.bidOrders, 0)
+
+	closedOrder, ok := engine.getOrder(order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderStatusFilled, closedOrder.Status)
+}
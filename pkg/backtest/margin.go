@@ -0,0 +1,534 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var (
+	defaultMarginMaxLeverage            = fixedpoint.NewFromInt(3)
+	defaultMarginLiquidationMarginLevel = fixedpoint.NewFromFloat(1.1)
+	defaultMarginInterestRate           = fixedpoint.NewFromFloat(0.0002)
+
+	hoursPerDay = fixedpoint.NewFromInt(24)
+)
+
+// marginAccount simulates the cross margin account shared by the matching engines of the backtest exchange.
+//
+// - the borrowed amount is limited by the net asset value and the max leverage.
+// - the interest is accrued hourly by the daily interest rate of the asset, and it's repaid before the principle.
+// - margin level = total asset value / (total borrowed + total interest), valued in the USD stable coins.
+// - when the margin level drops below the liquidation margin level, the account is liquidated to repay the debts.
+type marginAccount struct {
+	exchange types.ExchangeName
+	account  *types.Account
+
+	maxLeverage            fixedpoint.Value
+	liquidationMarginLevel fixedpoint.Value
+	defaultInterestRate    fixedpoint.Value
+	interestRates          map[string]fixedpoint.Value
+
+	// assetPrice returns the price of the asset in the USD stable coin
+	assetPrice func(asset string) (fixedpoint.Value, bool)
+
+	lastInterestTime time.Time
+	transactionID    uint64
+
+	loans        []types.MarginLoan
+	repays       []types.MarginRepay
+	interests    []types.MarginInterest
+	liquidations []types.MarginLiquidation
+}
+
+func (a *marginAccount) interestRate(asset string) fixedpoint.Value {
+	if rate, ok := a.interestRates[asset]; ok {
+		return rate
+	}
+
+	return a.defaultInterestRate
+}
+
+func (a *marginAccount) updateBalance(currency string, update func(balance *types.Balance)) {
+	balance, _ := a.account.Balance(currency)
+	balance.Currency = currency
+	update(&balance)
+	balance.NetAsset = balance.Net()
+	a.account.UpdateBalances(types.BalanceMap{currency: balance})
+}
+
+// valuation returns the total asset value and the total debt value of the account
+func (a *marginAccount) valuation() (assetValue, debtValue fixedpoint.Value) {
+	for currency, balance := range a.account.Balances() {
+		if balance.Total().IsZero() && balance.Debt().IsZero() {
+			continue
+		}
+
+		price, ok := a.assetPrice(currency)
+		if !ok {
+			continue
+		}
+
+		assetValue = assetValue.Add(balance.Total().Mul(price))
+		debtValue = debtValue.Add(balance.Debt().Mul(price))
+	}
+
+	return assetValue, debtValue
+}
+
+// marginLevel returns the margin level of the account, zero is returned if the account has no debt
+func (a *marginAccount) marginLevel() fixedpoint.Value {
+	assetValue, debtValue := a.valuation()
+	if debtValue.Sign() <= 0 {
+		return fixedpoint.Zero
+	}
+
+	return assetValue.Div(debtValue)
+}
+
+func (a *marginAccount) maxBorrowable(asset string) (fixedpoint.Value, error) {
+	price, ok := a.assetPrice(asset)
+	if !ok {
+		return fixedpoint.Zero, fmt.Errorf("can not borrow %s, the asset price is not available", asset)
+	}
+
+	assetValue, debtValue := a.valuation()
+	maxDebtValue := assetValue.Sub(debtValue).Mul(a.maxLeverage.Sub(fixedpoint.One))
+	return fixedpoint.Max(maxDebtValue.Sub(debtValue).Div(price), fixedpoint.Zero), nil
+}
+
+func (a *marginAccount) borrow(asset string, amount fixedpoint.Value, now time.Time) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("borrow amount %s must be positive", amount.String())
+	}
+
+	maxBorrowable, err := a.maxBorrowable(asset)
+	if err != nil {
+		return err
+	}
+
+	if amount.Compare(maxBorrowable) > 0 {
+		return fmt.Errorf("can not borrow %s %s, exceeds the max borrowable amount %s", amount.String(), asset, maxBorrowable.String())
+	}
+
+	a.updateBalance(asset, func(balance *types.Balance) {
+		balance.Available = balance.Available.Add(amount)
+		balance.Borrowed = balance.Borrowed.Add(amount)
+	})
+
+	a.transactionID++
+	a.loans = append(a.loans, types.MarginLoan{
+		Exchange:      a.exchange,
+		TransactionID: a.transactionID,
+		Asset:         asset,
+		Principle:     amount,
+		Time:          types.Time(now),
+	})
+	return nil
+}
+
+// repay repays the interest first and then the principle, the amount is capped by the debt
+func (a *marginAccount) repay(asset string, amount fixedpoint.Value, now time.Time) error {
+	balance, ok := a.account.Balance(asset)
+	if !ok || balance.Debt().IsZero() {
+		return fmt.Errorf("there is no %s debt to repay", asset)
+	}
+
+	amount = fixedpoint.Min(amount, balance.Debt())
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("repay amount %s must be positive", amount.String())
+	}
+
+	if amount.Compare(balance.Available) > 0 {
+		return fmt.Errorf("insufficient available balance %s for repay: want to repay %s, available %s", asset, amount.String(), balance.Available.String())
+	}
+
+	a.updateBalance(asset, func(balance *types.Balance) {
+		interest := fixedpoint.Min(amount, balance.Interest)
+		balance.Available = balance.Available.Sub(amount)
+		balance.Interest = balance.Interest.Sub(interest)
+		balance.Borrowed = balance.Borrowed.Sub(amount.Sub(interest))
+	})
+
+	a.transactionID++
+	a.repays = append(a.repays, types.MarginRepay{
+		Exchange:      a.exchange,
+		TransactionID: a.transactionID,
+		Asset:         asset,
+		Principle:     amount,
+		Time:          types.Time(now),
+	})
+	return nil
+}
+
+// repayAll repays the debt of the asset with the available balance
+func (a *marginAccount) repayAll(asset string, now time.Time) {
+	balance, ok := a.account.Balance(asset)
+	if !ok || balance.Debt().IsZero() || balance.Available.Sign() <= 0 {
+		return
+	}
+
+	if err := a.repay(asset, fixedpoint.Min(balance.Available, balance.Debt()), now); err != nil {
+		log.WithError(err).Errorf("can not repay %s", asset)
+	}
+}
+
+// accrueInterest accrues the interest of the borrowed assets for each hour passed
+func (a *marginAccount) accrueInterest(now time.Time) (accrued bool) {
+	if a.lastInterestTime.IsZero() {
+		a.lastInterestTime = now.Truncate(time.Hour)
+		return false
+	}
+
+	for t := a.lastInterestTime.Add(time.Hour); !t.After(now); t = t.Add(time.Hour) {
+		a.lastInterestTime = t
+
+		for currency, balance := range a.account.Balances() {
+			if balance.Borrowed.Sign() <= 0 {
+				continue
+			}
+
+			rate := a.interestRate(currency)
+			interest := balance.Borrowed.Mul(rate).Div(hoursPerDay)
+			if interest.IsZero() {
+				continue
+			}
+
+			a.updateBalance(currency, func(balance *types.Balance) {
+				balance.Interest = balance.Interest.Add(interest)
+			})
+
+			a.interests = append(a.interests, types.MarginInterest{
+				Exchange:     a.exchange,
+				Asset:        currency,
+				Principle:    balance.Borrowed,
+				Interest:     interest,
+				InterestRate: rate,
+				Time:         types.Time(t),
+			})
+			accrued = true
+		}
+	}
+
+	return accrued
+}
+
+// borrowForOrder borrows the insufficient balance for the order with the MARGIN_BUY side effect
+func (m *SimplePriceMatching) borrowForOrder(o types.SubmitOrder, quantity, quoteQuantity fixedpoint.Value) error {
+	if m.margin == nil || o.MarginSideEffect != types.SideEffectTypeMarginBuy {
+		return nil
+	}
+
+	currency, required := m.Market.QuoteCurrency, quoteQuantity
+	if o.Side == types.SideTypeSell {
+		currency, required = m.Market.BaseCurrency, quantity
+	}
+
+	balance, _ := m.account.Balance(currency)
+	if balance.Available.Compare(required) >= 0 {
+		return nil
+	}
+
+	return m.margin.borrow(currency, required.Sub(balance.Available), m.currentTime)
+}
+
+// autoRepay repays the debt of the received asset for the order with the AUTO_REPAY side effect
+func (m *SimplePriceMatching) autoRepay(o types.SubmitOrder, trade types.Trade) {
+	if m.margin == nil || o.MarginSideEffect != types.SideEffectTypeAutoRepay {
+		return
+	}
+
+	if trade.IsBuyer {
+		m.margin.repayAll(m.Market.BaseCurrency, m.currentTime)
+	} else {
+		m.margin.repayAll(m.Market.QuoteCurrency, m.currentTime)
+	}
+
+	m.EmitBalanceUpdate(m.account.Balances())
+}
+
+// MarginReport is the summary of the simulated margin account
+type MarginReport struct {
+	Loans        int                         `json:"loans"`
+	Repays       int                         `json:"repays"`
+	Liquidations int                         `json:"liquidations"`
+	Interests    map[string]fixedpoint.Value `json:"interests"`
+}
+
+func newMarginAccount(exchange types.ExchangeName, account *types.Account, config *bbgo.BacktestMargin, assetPrice func(asset string) (fixedpoint.Value, bool)) *marginAccount {
+	a := &marginAccount{
+		exchange:               exchange,
+		account:                account,
+		maxLeverage:            config.MaxLeverage,
+		liquidationMarginLevel: config.LiquidationMarginLevel,
+		defaultInterestRate:    config.InterestRate,
+		interestRates:          config.InterestRates,
+		assetPrice:             assetPrice,
+	}
+
+	if a.maxLeverage.IsZero() {
+		a.maxLeverage = defaultMarginMaxLeverage
+	}
+
+	if a.liquidationMarginLevel.IsZero() {
+		a.liquidationMarginLevel = defaultMarginLiquidationMarginLevel
+	}
+
+	if a.defaultInterestRate.IsZero() {
+		a.defaultInterestRate = defaultMarginInterestRate
+	}
+
+	return a
+}
+
+// IsMargin returns true if the backtest account is simulated as a cross margin account
+func (e *Exchange) IsMargin() bool {
+	return e.margin != nil
+}
+
+// assetPrice returns the last price of the asset quoted in the USD stable coins
+func (e *Exchange) assetPrice(asset string) (fixedpoint.Value, bool) {
+	if types.IsUSDFiatCurrency(asset) {
+		return fixedpoint.One, true
+	}
+
+	for _, quote := range types.USDFiatCurrencies {
+		if matching, ok := e.matchingBook(asset + quote); ok && matching.lastPrice.Sign() > 0 {
+			return matching.lastPrice, true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
+
+func (e *Exchange) emitBalanceUpdate() {
+	if e.userDataStream != nil {
+		e.userDataStream.EmitBalanceUpdate(e.account.Balances())
+	}
+}
+
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if e.margin == nil {
+		return fmt.Errorf("%s is not a margin account", e.sourceName)
+	}
+
+	if err := e.margin.borrow(asset, amount, e.currentTime); err != nil {
+		return err
+	}
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if e.margin == nil {
+		return fmt.Errorf("%s is not a margin account", e.sourceName)
+	}
+
+	if err := e.margin.repay(asset, amount, e.currentTime); err != nil {
+		return err
+	}
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	if e.margin == nil {
+		return fixedpoint.Zero, fmt.Errorf("%s is not a margin account", e.sourceName)
+	}
+
+	return e.margin.maxBorrowable(asset)
+}
+
+func inTimeRange(t time.Time, startTime, endTime *time.Time) bool {
+	if startTime != nil && t.Before(*startTime) {
+		return false
+	}
+
+	if endTime != nil && t.After(*endTime) {
+		return false
+	}
+
+	return true
+}
+
+func (e *Exchange) QueryLoanHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (loans []types.MarginLoan, err error) {
+	if e.margin == nil {
+		return nil, nil
+	}
+
+	for _, loan := range e.margin.loans {
+		if loan.Asset == asset && inTimeRange(loan.Time.Time(), startTime, endTime) {
+			loans = append(loans, loan)
+		}
+	}
+
+	return loans, nil
+}
+
+func (e *Exchange) QueryRepayHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (repays []types.MarginRepay, err error) {
+	if e.margin == nil {
+		return nil, nil
+	}
+
+	for _, repay := range e.margin.repays {
+		if repay.Asset == asset && inTimeRange(repay.Time.Time(), startTime, endTime) {
+			repays = append(repays, repay)
+		}
+	}
+
+	return repays, nil
+}
+
+func (e *Exchange) QueryLiquidationHistory(ctx context.Context, startTime, endTime *time.Time) (liquidations []types.MarginLiquidation, err error) {
+	if e.margin == nil {
+		return nil, nil
+	}
+
+	for _, liquidation := range e.margin.liquidations {
+		if inTimeRange(liquidation.UpdatedTime.Time(), startTime, endTime) {
+			liquidations = append(liquidations, liquidation)
+		}
+	}
+
+	return liquidations, nil
+}
+
+func (e *Exchange) QueryInterestHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (interests []types.MarginInterest, err error) {
+	if e.margin == nil {
+		return nil, nil
+	}
+
+	for _, interest := range e.margin.interests {
+		if interest.Asset == asset && inTimeRange(interest.Time.Time(), startTime, endTime) {
+			interests = append(interests, interest)
+		}
+	}
+
+	return interests, nil
+}
+
+// processMargin accrues the interest and checks the margin level of the margin account
+func (e *Exchange) processMargin() {
+	if e.margin.accrueInterest(e.currentTime) {
+		e.emitBalanceUpdate()
+	}
+
+	marginLevel := e.margin.marginLevel()
+	e.account.MarginLevel = marginLevel
+	if marginLevel.IsZero() || marginLevel.Compare(e.margin.liquidationMarginLevel) >= 0 {
+		return
+	}
+
+	log.Warnf("margin level %s is lower than the liquidation margin level %s, liquidating the margin account",
+		marginLevel.String(), e.margin.liquidationMarginLevel.String())
+	e.liquidateMargin()
+	e.account.MarginLevel = e.margin.marginLevel()
+}
+
+// liquidateMargin cancels all the open orders, sells the assets without debt and buys back the borrowed assets to repay the debts
+func (e *Exchange) liquidateMargin() {
+	e.matchingBooksMutex.Lock()
+	var books []*SimplePriceMatching
+	for _, matching := range e.matchingBooks {
+		if len(matching.bidOrders) > 0 || len(matching.askOrders) > 0 || matching.lastPrice.Sign() > 0 {
+			books = append(books, matching)
+		}
+	}
+	e.matchingBooksMutex.Unlock()
+
+	for _, matching := range books {
+		for _, o := range append(matching.bidOrders, matching.askOrders...) {
+			if _, err := matching.cancelOrder(o); err != nil {
+				log.WithError(err).Errorf("can not cancel the order for the liquidation: %+v", o)
+			}
+		}
+	}
+
+	for _, matching := range books {
+		if matching.lastPrice.IsZero() || !types.IsUSDFiatCurrency(matching.Market.QuoteCurrency) {
+			continue
+		}
+
+		base, _ := e.account.Balance(matching.Market.BaseCurrency)
+		quote, _ := e.account.Balance(matching.Market.QuoteCurrency)
+
+		switch {
+		case base.Debt().Sign() > 0 && base.Available.Compare(base.Debt()) < 0:
+			// buy back the borrowed base asset
+			quantity := matching.Market.TruncateQuantity(base.Debt().Sub(base.Available))
+			if quantity.Compare(base.Debt().Sub(base.Available)) < 0 {
+				quantity = quantity.Add(matching.Market.StepSize)
+			}
+			e.liquidateMarginAsset(matching, types.SideTypeBuy, quantity)
+
+		case base.Debt().IsZero() && base.Available.Sign() > 0 && quote.Debt().Sign() > 0:
+			// sell the base asset to repay the borrowed quote asset
+			e.liquidateMarginAsset(matching, types.SideTypeSell, matching.Market.TruncateQuantity(base.Available))
+		}
+	}
+
+	for currency := range e.account.Balances() {
+		e.margin.repayAll(currency, e.currentTime)
+	}
+
+	e.emitBalanceUpdate()
+}
+
+func (e *Exchange) liquidateMarginAsset(matching *SimplePriceMatching, side types.SideType, quantity fixedpoint.Value) {
+	if quantity.Compare(matching.Market.MinQuantity) < 0 {
+		return
+	}
+
+	order, _, err := matching.placeOrder(types.SubmitOrder{
+		Symbol:   matching.Market.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   matching.Market,
+		Tag:      "liquidation",
+	}, incOrderID())
+	if err != nil {
+		log.WithError(err).Errorf("can not liquidate %s %s %s", side, quantity.String(), matching.Market.BaseCurrency)
+		return
+	}
+
+	e.addClosedOrder(*order)
+	e.margin.liquidations = append(e.margin.liquidations, types.MarginLiquidation{
+		Exchange:         e.sourceName,
+		AveragePrice:     order.Price,
+		ExecutedQuantity: order.ExecutedQuantity,
+		OrderID:          order.OrderID,
+		Price:            order.Price,
+		Quantity:         order.Quantity,
+		Side:             order.Side,
+		Symbol:           order.Symbol,
+		TimeInForce:      types.TimeInForceIOC,
+		UpdatedTime:      types.Time(e.currentTime),
+	})
+}
+
+// MarginReport returns the summary of the simulated margin account, nil is returned for the non-margin account
+func (e *Exchange) MarginReport() *MarginReport {
+	if e.margin == nil {
+		return nil
+	}
+
+	report := &MarginReport{
+		Loans:        len(e.margin.loans),
+		Repays:       len(e.margin.repays),
+		Liquidations: len(e.margin.liquidations),
+		Interests:    make(map[string]fixedpoint.Value),
+	}
+
+	for _, interest := range e.margin.interests {
+		report.Interests[interest.Asset] = report.Interests[interest.Asset].Add(interest.Interest)
+	}
+
+	return report
+}
@@ -0,0 +1,132 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMarginTestExchange() (*Exchange, *SimplePriceMatching) {
+	account := &types.Account{AccountType: types.AccountTypeMargin}
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	engine := &SimplePriceMatching{
+		account:      account,
+		Market:       getTestMarket(),
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+
+	e := &Exchange{
+		sourceName:    types.ExchangeBinance,
+		account:       account,
+		closedOrders:  make(map[string][]types.Order),
+		matchingBooks: map[string]*SimplePriceMatching{"BTCUSDT": engine},
+		currentTime:   time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	e.margin = newMarginAccount(types.ExchangeBinance, account, &bbgo.BacktestMargin{}, e.assetPrice)
+	engine.margin = e.margin
+	return e, engine
+}
+
+func TestSimplePriceMatching_Margin_SideEffect(t *testing.T) {
+	e, engine := newMarginTestExchange()
+	ctx := context.Background()
+
+	maxBorrowable, err := e.QueryMarginAssetMaxBorrowable(ctx, "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", maxBorrowable.String())
+
+	// borrow BTC to sell
+	_, _, err = engine.PlaceOrder(types.SubmitOrder{
+		Symbol:           "BTCUSDT",
+		Side:             types.SideTypeSell,
+		Type:             types.OrderTypeMarket,
+		Quantity:         fixedpoint.NewFromFloat(0.5),
+		MarginSideEffect: types.SideEffectTypeMarginBuy,
+	})
+	assert.NoError(t, err)
+
+	btc, _ := engine.account.Balance("BTC")
+	assert.Equal(t, "0.5", btc.Borrowed.String())
+	assert.True(t, btc.Available.IsZero())
+
+	usdt, _ := engine.account.Balance("USDT")
+	assert.Equal(t, "20000", usdt.Available.String())
+
+	// the interest is accrued hourly
+	t1 := time.Date(2022, 6, 1, 0, 30, 0, 0, time.UTC)
+	engine.margin.accrueInterest(t1)
+	engine.margin.accrueInterest(t1.Add(2 * time.Hour))
+
+	interests, err := e.QueryInterestHistory(ctx, "BTC", nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, interests, 2)
+
+	btc, _ = engine.account.Balance("BTC")
+	assert.InDelta(t, 0.00000833, btc.Interest.Float64(), 1e-8)
+
+	// buy back and repay, the interest is repaid first
+	_, _, err = engine.PlaceOrder(types.SubmitOrder{
+		Symbol:           "BTCUSDT",
+		Side:             types.SideTypeBuy,
+		Type:             types.OrderTypeMarket,
+		Quantity:         fixedpoint.NewFromFloat(0.5),
+		MarginSideEffect: types.SideEffectTypeAutoRepay,
+	})
+	assert.NoError(t, err)
+
+	btc, _ = engine.account.Balance("BTC")
+	assert.True(t, btc.Available.IsZero())
+	assert.True(t, btc.Interest.IsZero())
+	assert.InDelta(t, 0.00000833, btc.Borrowed.Float64(), 1e-8)
+
+	repays, err := e.QueryRepayHistory(ctx, "BTC", nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, repays, 1)
+}
+
+func TestExchange_Margin_Liquidation(t *testing.T) {
+	e, engine := newMarginTestExchange()
+	ctx := context.Background()
+
+	assert.NoError(t, e.BorrowMarginAsset(ctx, "BTC", fixedpoint.One))
+	assert.Error(t, e.BorrowMarginAsset(ctx, "BTC", fixedpoint.One), "exceeds the max borrowable amount")
+
+	_, _, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+	})
+	assert.NoError(t, err)
+
+	e.processMargin()
+	assert.Equal(t, "1.5", e.account.MarginLevel.String())
+
+	// the margin level drops to 30000 / 28000 < 1.1
+	engine.lastPrice = fixedpoint.NewFromFloat(28000.0)
+	e.processMargin()
+
+	liquidations, err := e.QueryLiquidationHistory(ctx, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, liquidations, 1) {
+		assert.Equal(t, types.SideTypeBuy, liquidations[0].Side)
+		assert.Equal(t, "1", liquidations[0].Quantity.String())
+	}
+
+	btc, _ := e.account.Balance("BTC")
+	assert.True(t, btc.Debt().IsZero())
+
+	usdt, _ := e.account.Balance("USDT")
+	assert.Equal(t, "2000", usdt.Available.String())
+	assert.True(t, e.account.MarginLevel.IsZero())
+}
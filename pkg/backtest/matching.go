@@ -58,6 +58,15 @@ type SimplePriceMatching struct {
 	askOrders    []types.Order
 	closedOrders map[uint64]types.Order
 
+	// pendingOrders are the bracket orders waiting for the entry order to be filled
+	pendingOrders []types.Order
+
+	// filledGroupOrders are the filled orders of the order groups to be processed
+	filledGroupOrders []types.Order
+
+	// marketTradeMatched is set when the orders are matched by the replayed market trades of the current kline
+	marketTradeMatched bool
+
 	klineCache  map[types.Interval]types.KLine
 	lastPrice   fixedpoint.Value
 	lastKLine   types.KLine
@@ -66,6 +75,25 @@ type SimplePriceMatching struct {
 
 	feeModeFunction FeeModeFunction
 
+	// bookReplay is set when the order book replay matching engine is used
+	bookReplay *orderBookReplay
+
+	// orderLatency and cancelLatency delay the order submissions and cancellations
+	orderLatency   time.Duration
+	cancelLatency  time.Duration
+	delayedActions []delayedAction
+
+	slippageFunction SlippageFunction
+
+	// slippageCost is the accumulated cost of the simulated slippage in the quote currency
+	slippageCost fixedpoint.Value
+
+	// futures is set when the account is configured as a futures account
+	futures *futuresPosition
+
+	// margin is the cross margin account shared by the matching engines, it's set when the margin account is configured
+	margin *marginAccount
+
 	account *types.Account
 
 	tradeUpdateCallbacks   []func(trade types.Trade)
@@ -74,6 +102,24 @@ type SimplePriceMatching struct {
 }
 
 func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
+	if m.cancelLatency > 0 {
+		return m.delayCancelOrder(o)
+	}
+
+	return m.cancelOrder(o)
+}
+
+func (m *SimplePriceMatching) cancelOrder(o types.Order) (types.Order, error) {
+	if pendingOrder, ok := m.removePendingOrder(o.OrderID); ok {
+		// the pending bracket order does not lock any balance
+		pendingOrder.Status = types.OrderStatusCanceled
+		pendingOrder.IsWorking = false
+		pendingOrder.UpdateTime = types.Time(m.currentTime)
+		m.closedOrders[pendingOrder.OrderID] = pendingOrder
+		m.EmitOrderUpdate(pendingOrder)
+		return pendingOrder, nil
+	}
+
 	found := false
 
 	switch o.Side {
@@ -83,6 +129,8 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.bidOrders {
 			if o.OrderID == order.OrderID {
+				// use the order from the book, since it might be partially filled
+				o.ExecutedQuantity = order.ExecutedQuantity
 				found = true
 				continue
 			}
@@ -96,6 +144,7 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.askOrders {
 			if o.OrderID == order.OrderID {
+				o.ExecutedQuantity = order.ExecutedQuantity
 				found = true
 				continue
 			}
@@ -109,26 +158,52 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		return o, fmt.Errorf("cancel order failed, order %d not found: %+v", o.OrderID, o)
 	}
 
-	switch o.Side {
-	case types.SideTypeBuy:
-		if err := m.account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(o.Quantity)); err != nil {
-			return o, err
-		}
+	if m.bookReplay != nil {
+		delete(m.bookReplay.queue, o.OrderID)
+	}
 
-	case types.SideTypeSell:
-		if err := m.account.UnlockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
-			return o, err
-		}
+	remainingQuantity := o.Quantity.Sub(o.ExecutedQuantity)
+	if err := m.unlockOrderBalance(o.Side, remainingQuantity, stopOrderLockPrice(o).Mul(remainingQuantity)); err != nil {
+		return o, err
 	}
 
 	o.Status = types.OrderStatusCanceled
+	o.IsWorking = false
+	m.closedOrders[o.OrderID] = o
 	m.EmitOrderUpdate(o)
 	m.EmitBalanceUpdate(m.account.Balances())
+
+	m.cancelBracketOrders(o)
 	return o, nil
 }
 
 // PlaceOrder returns the created order object, executed trade (if any) and error
 func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *types.Trade, error) {
+	// start from one
+	orderID := incOrderID()
+
+	if m.orderLatency > 0 {
+		return m.delayPlaceOrder(o, orderID)
+	}
+
+	return m.placeOrder(o, orderID)
+}
+
+func (m *SimplePriceMatching) placeOrder(o types.SubmitOrder, orderID uint64) (*types.Order, *types.Trade, error) {
+	if err := m.checkStopOrder(o); err != nil {
+		return nil, nil, err
+	}
+
+	if m.hasBracketEntryOrder(o) {
+		return m.placePendingOrder(o, orderID)
+	}
+
+	if m.bookReplay != nil && m.bookReplay.loaded && isBookMatchingOrderType(o.Type) {
+		order, trade, err := m.placeOrderOnBook(o, orderID)
+		m.processOrderGroups()
+		return order, trade, err
+	}
+
 	if o.Type == types.OrderTypeMarket {
 		if m.lastPrice.IsZero() {
 			panic("unexpected error: for market order, the last price can not be zero")
@@ -142,7 +217,7 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 
 	switch o.Type {
 	case types.OrderTypeMarket:
-		price = m.Market.TruncatePrice(m.lastPrice)
+		price = m.Market.TruncatePrice(m.slippedPrice(o.Side, m.lastPrice, o.Quantity))
 
 	case types.OrderTypeStopMarket:
 		// the actual price might be different.
@@ -165,29 +240,22 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		return nil, nil, fmt.Errorf("order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), m.Market.MinNotional.String(), o)
 	}
 
-	switch o.Side {
-	case types.SideTypeBuy:
-		if err := m.account.LockBalance(m.Market.QuoteCurrency, quoteQuantity); err != nil {
-			return nil, nil, err
-		}
+	if err := m.borrowForOrder(o, o.Quantity, quoteQuantity); err != nil {
+		return nil, nil, err
+	}
 
-	case types.SideTypeSell:
-		if err := m.account.LockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
-			return nil, nil, err
-		}
+	if err := m.lockOrderBalance(o.Side, o.Quantity, quoteQuantity); err != nil {
+		return nil, nil, err
 	}
 
 	m.EmitBalanceUpdate(m.account.Balances())
 
-	// start from one
-	orderID := incOrderID()
 	order := m.newOrder(o, orderID)
 
 	if isTaker {
-		var price fixedpoint.Value
 		if order.Type == types.OrderTypeMarket {
-			order.Price = m.Market.TruncatePrice(m.lastPrice)
-			price = order.Price
+			order.Price = price
+			m.addSlippageCost(price, o.Quantity)
 		} else if order.Type == types.OrderTypeLimit {
 			// if limit order's price is with the range of next kline
 			// we assume it will be traded as a maker trade, and is traded at its original price
@@ -198,7 +266,8 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 			} else if m.nextKLine != nil && m.nextKLine.Low.Compare(order.Price) < 0 && order.Side == types.SideTypeSell {
 				order.AveragePrice = order.Price
 			} else {
-				order.AveragePrice = m.Market.TruncatePrice(m.lastPrice)
+				order.AveragePrice = m.Market.TruncatePrice(m.limitTakerPrice(o.Side, o.Price, o.Quantity))
+				m.addSlippageCost(order.AveragePrice, o.Quantity)
 			}
 			price = order.AveragePrice
 		}
@@ -212,6 +281,7 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		// emit trade before we publish order
 		trade := m.newTradeFromOrder(&order2, false, price)
 		m.executeTrade(trade)
+		m.autoRepay(o, trade)
 
 		// unlock the rest balances for limit taker
 		if order.Type == types.OrderTypeLimit {
@@ -225,7 +295,7 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 				// the executed price is lower than the given price, so we will use less quote currency to buy the base asset.
 				amount := order.Price.Sub(order.AveragePrice).Mul(order.Quantity)
 				if amount.Sign() > 0 {
-					if err := m.account.UnlockBalance(m.Market.QuoteCurrency, amount); err != nil {
+					if err := m.unlockOrderBalance(o.Side, fixedpoint.Zero, amount); err != nil {
 						return nil, nil, err
 					}
 					m.EmitBalanceUpdate(m.account.Balances())
@@ -234,8 +304,9 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 			case types.SideTypeSell:
 				// limit sell taker, the order price is lower than the current best bid price
 				// the executed price is higher than the given price, so we will get more quote currency back
+				// the futures margin is locked by the order price, the realized profit is settled by the trade price
 				amount := order.AveragePrice.Sub(order.Price).Mul(order.Quantity)
-				if amount.Sign() > 0 {
+				if amount.Sign() > 0 && m.futures == nil {
 					m.account.AddBalance(m.Market.QuoteCurrency, amount)
 					m.EmitBalanceUpdate(m.account.Balances())
 				}
@@ -248,6 +319,9 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		order2.IsWorking = false
 		m.EmitOrderUpdate(order2)
 
+		m.addFilledGroupOrder(order2)
+		m.processOrderGroups()
+
 		// let the exchange emit the "FILLED" order update (we need the closed order)
 		// m.EmitOrderUpdate(order2)
 		return &order2, &trade, nil
@@ -272,6 +346,11 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 }
 
 func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
+	if m.futures != nil {
+		m.executeFuturesTrade(trade, true)
+		return
+	}
+
 	var err error
 	// execute trade, update account balances
 	if trade.IsBuyer {
@@ -310,6 +389,47 @@ func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
 	m.EmitBalanceUpdate(m.account.Balances())
 }
 
+// slippedPrice returns the taker price with the simulated slippage
+func (m *SimplePriceMatching) slippedPrice(side types.SideType, price, quantity fixedpoint.Value) fixedpoint.Value {
+	if m.slippageFunction == nil {
+		return price
+	}
+
+	slippage := m.slippageFunction(side, price, quantity, m.lastKLine)
+	switch side {
+	case types.SideTypeBuy:
+		return price.Add(slippage)
+
+	case types.SideTypeSell:
+		return price.Sub(slippage)
+	}
+
+	return price
+}
+
+// limitTakerPrice returns the slipped price of the limit taker order, the price is capped by the order price
+func (m *SimplePriceMatching) limitTakerPrice(side types.SideType, orderPrice, quantity fixedpoint.Value) fixedpoint.Value {
+	price := m.slippedPrice(side, m.lastPrice, quantity)
+	switch side {
+	case types.SideTypeBuy:
+		return fixedpoint.Min(price, orderPrice)
+
+	case types.SideTypeSell:
+		return fixedpoint.Max(price, orderPrice)
+	}
+
+	return price
+}
+
+// addSlippageCost accumulates the cost of the price difference between the executed price and the last price
+func (m *SimplePriceMatching) addSlippageCost(executedPrice, quantity fixedpoint.Value) {
+	if m.slippageFunction == nil {
+		return
+	}
+
+	m.slippageCost = m.slippageCost.Add(executedPrice.Sub(m.lastPrice).Abs().Mul(quantity))
+}
+
 func (m *SimplePriceMatching) getFeeRate(isMaker bool) (feeRate fixedpoint.Value) {
 	// BINANCE uses 0.1% for both maker and taker
 	// MAX uses 0.050% for maker and 0.15% for taker
@@ -353,6 +473,7 @@ func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool
 		Time:          types.Time(m.currentTime),
 		Fee:           fee,
 		FeeCurrency:   feeCurrency,
+		IsFutures:     m.futures != nil,
 	}
 }
 
@@ -360,101 +481,19 @@ func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool
 func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
 	klineMatchingLogger.Debugf("kline buy to price %s", price.String())
 
-	var bidOrders []types.Order
-	for _, o := range m.bidOrders {
-		switch o.Type {
-
-		case types.OrderTypeStopMarket:
-			// the price is still lower than the stop price, we will put the order back to the list
-			if price.Compare(o.StopPrice) < 0 {
-				// not triggering it, put it back
-				bidOrders = append(bidOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
-			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
-
-		case types.OrderTypeStopLimit:
-			// the price is still lower than the stop price, we will put the order back to the list
-			if price.Compare(o.StopPrice) < 0 {
-				bidOrders = append(bidOrders, o)
-				break
-			}
-
-			// convert this order to limit order
-			// we use value object here, so it's a copy
-			o.Type = types.OrderTypeLimit
-
-			// is it a taker order?
-			// higher than the current price, then it's a taker order
-			if o.Price.Compare(price) >= 0 {
-				// limit buy taker order, move it to the closed order
-				// we assume that we have no price slippage here, so the latest price will be the executed price
-				o.AveragePrice = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
-				// keep it as a maker order
-				bidOrders = append(bidOrders, o)
-			}
-		default:
-			bidOrders = append(bidOrders, o)
-		}
-	}
-	m.bidOrders = bidOrders
+	// the buy stop orders are triggered when the price goes up to the stop price
+	closedOrders, trades = m.triggerKLineStopOrders(types.SideTypeBuy, price)
 
+	var filledOrders []types.Order
 	var askOrders []types.Order
 	for _, o := range m.askOrders {
 		switch o.Type {
 
-		case types.OrderTypeStopMarket:
-			// should we trigger the order
-			if price.Compare(o.StopPrice) < 0 {
-				// not triggering it, put it back
-				askOrders = append(askOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
-			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
-
-		case types.OrderTypeStopLimit:
-			// should we trigger the order?
-			if price.Compare(o.StopPrice) < 0 {
-				askOrders = append(askOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeLimit
-
-			// is it a taker order?
-			// higher than the current price, then it's a taker order
-			if o.Price.Compare(price) <= 0 {
-				// limit sell order as taker, move it to the closed order
-				// we assume that we have no price slippage here, so the latest price will be the executed price
-				// TODO: simulate slippage here
-				o.AveragePrice = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
-				// maker order
-				askOrders = append(askOrders, o)
-			}
-
 		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
 			if price.Compare(o.Price) >= 0 {
 				o.ExecutedQuantity = o.Quantity
 				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				filledOrders = append(filledOrders, o)
 			} else {
 				askOrders = append(askOrders, o)
 			}
@@ -468,24 +507,11 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 	m.askOrders = askOrders
 	m.lastPrice = price
 
-	for i := range closedOrders {
-		o := closedOrders[i]
-		executedPrice := o.Price
-		if !o.AveragePrice.IsZero() {
-			executedPrice = o.AveragePrice
-		}
-
-		trade := m.newTradeFromOrder(&o, !isTakerOrder(o), executedPrice)
-		m.executeTrade(trade)
-		closedOrders[i] = o
-
-		trades = append(trades, trade)
-
-		m.EmitOrderUpdate(o)
-
-		m.closedOrders[o.OrderID] = o
-	}
+	filledOrders, filledTrades := m.executeFilledOrders(filledOrders)
+	closedOrders = append(closedOrders, filledOrders...)
+	trades = append(trades, filledTrades...)
 
+	m.processOrderGroups()
 	return closedOrders, trades
 }
 
@@ -494,97 +520,19 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
 	klineMatchingLogger.Debugf("kline sell to price %s", price.String())
 
-	// in this section we handle --- the price goes lower, and we trigger the stop sell
-	var askOrders []types.Order
-	for _, o := range m.askOrders {
-		switch o.Type {
-
-		case types.OrderTypeStopMarket:
-			// should we trigger the order
-			if price.Compare(o.StopPrice) > 0 {
-				askOrders = append(askOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
-			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
-
-		case types.OrderTypeStopLimit:
-			// if the price is lower than the stop price
-			// we should trigger the stop sell order
-			if price.Compare(o.StopPrice) > 0 {
-				askOrders = append(askOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeLimit
-
-			// handle TAKER SELL
-			// if the order price is lower than the current price
-			// it's a taker order
-			if o.Price.Compare(price) <= 0 {
-				o.AveragePrice = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
-				askOrders = append(askOrders, o)
-			}
-
-		default:
-			askOrders = append(askOrders, o)
-		}
-	}
-	m.askOrders = askOrders
+	// the sell stop orders are triggered when the price goes down to the stop price
+	closedOrders, trades = m.triggerKLineStopOrders(types.SideTypeSell, price)
 
+	var filledOrders []types.Order
 	var bidOrders []types.Order
 	for _, o := range m.bidOrders {
 		switch o.Type {
 
-		case types.OrderTypeStopMarket:
-			// price goes down and if the stop price is still lower than the current price
-			// or the stop price is not touched
-			// then we should skip this order
-			if price.Compare(o.StopPrice) > 0 {
-				bidOrders = append(bidOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
-			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
-
-		case types.OrderTypeStopLimit:
-			// price goes down and if the stop price is still lower than the current price
-			// or the stop price is not touched
-			// then we should skip this order
-			if price.Compare(o.StopPrice) > 0 {
-				bidOrders = append(bidOrders, o)
-				break
-			}
-
-			o.Type = types.OrderTypeLimit
-
-			// handle TAKER order
-			if o.Price.Compare(price) >= 0 {
-				o.AveragePrice = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
-				bidOrders = append(bidOrders, o)
-			}
-
 		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
 			if price.Compare(o.Price) <= 0 {
 				o.ExecutedQuantity = o.Quantity
 				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				filledOrders = append(filledOrders, o)
 			} else {
 				bidOrders = append(bidOrders, o)
 			}
@@ -597,8 +545,18 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 	m.bidOrders = bidOrders
 	m.lastPrice = price
 
-	for i := range closedOrders {
-		o := closedOrders[i]
+	filledOrders, filledTrades := m.executeFilledOrders(filledOrders)
+	closedOrders = append(closedOrders, filledOrders...)
+	trades = append(trades, filledTrades...)
+
+	m.processOrderGroups()
+	return closedOrders, trades
+}
+
+// executeFilledOrders executes the trades of the orders filled by the kline price
+func (m *SimplePriceMatching) executeFilledOrders(filledOrders []types.Order) (closedOrders []types.Order, trades []types.Trade) {
+	for i := range filledOrders {
+		o := filledOrders[i]
 		executedPrice := o.Price
 		if !o.AveragePrice.IsZero() {
 			executedPrice = o.AveragePrice
@@ -606,13 +564,15 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 
 		trade := m.newTradeFromOrder(&o, !isTakerOrder(o), executedPrice)
 		m.executeTrade(trade)
-		closedOrders[i] = o
+		m.autoRepay(o.SubmitOrder, trade)
+		closedOrders = append(closedOrders, o)
 
 		trades = append(trades, trade)
 
 		m.EmitOrderUpdate(o)
 
 		m.closedOrders[o.OrderID] = o
+		m.addFilledGroupOrder(o)
 	}
 
 	return closedOrders, trades
@@ -623,6 +583,14 @@ func (m *SimplePriceMatching) getOrder(orderID uint64) (types.Order, bool) {
 		return o, true
 	}
 
+	if o, ok := m.getDelayedOrder(orderID); ok {
+		return o, true
+	}
+
+	if o, ok := m.getPendingOrder(orderID); ok {
+		return o, true
+	}
+
 	for _, o := range m.bidOrders {
 		if o.OrderID == orderID {
 			return o, true
@@ -639,6 +607,36 @@ func (m *SimplePriceMatching) getOrder(orderID uint64) (types.Order, bool) {
 }
 
 func (m *SimplePriceMatching) processKLine(kline types.KLine) {
+	// the order book replay matching engine matches the orders by the recorded book events,
+	// the klines are only used for matching the orders before the first book snapshot is loaded.
+	if m.bookReplay != nil {
+		m.replayBookEvents(kline.EndTime.Time())
+		if m.bookReplay.loaded {
+			m.processDelayedActions(kline.EndTime.Time())
+			m.currentTime = kline.EndTime.Time()
+			m.lastPrice = kline.Close
+			m.lastKLine = kline
+
+			if m.futures != nil {
+				m.processFutures(kline)
+			}
+			return
+		}
+	}
+
+	// the orders are already matched by the replayed market trades of this kline
+	if m.marketTradeMatched {
+		m.marketTradeMatched = false
+		m.processDelayedActions(kline.EndTime.Time())
+		m.currentTime = kline.EndTime.Time()
+		m.lastKLine = kline
+
+		if m.futures != nil {
+			m.processFutures(kline)
+		}
+		return
+	}
+
 	m.currentTime = kline.EndTime.Time()
 
 	if m.lastPrice.IsZero() {
@@ -651,6 +649,9 @@ func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 		}
 	}
 
+	// the delayed orders and cancellations arrive after the open price
+	m.processDelayedActions(kline.EndTime.Time())
+
 	switch kline.Direction() {
 	case types.DirectionDown:
 		if kline.High.Compare(kline.Open) >= 0 {
@@ -683,6 +684,10 @@ func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 	}
 
 	m.lastKLine = kline
+
+	if m.futures != nil {
+		m.processFutures(kline)
+	}
 }
 
 func (m *SimplePriceMatching) newOrder(o types.SubmitOrder, orderID uint64) types.Order {
@@ -693,6 +698,7 @@ func (m *SimplePriceMatching) newOrder(o types.SubmitOrder, orderID uint64) type
 		Status:           types.OrderStatusNew,
 		ExecutedQuantity: fixedpoint.Zero,
 		IsWorking:        true,
+		IsFutures:        m.futures != nil,
 		CreationTime:     types.Time(m.currentTime),
 		UpdateTime:       types.Time(m.currentTime),
 	}
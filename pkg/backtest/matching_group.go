@@ -0,0 +1,197 @@
+package backtest
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The orders with the same GroupID are linked as an order group when the group contains stop orders:
+//
+// - OCO (one-cancels-the-other): when an order of the group is filled, the other open orders of the same side in the group are canceled,
+//   for example, the take profit limit sell order and the stop loss sell order.
+// - bracket: the stop orders and the reduce-only orders submitted to a group with an open entry order of the opposite side are pending,
+//   they are placed when the entry order is filled, and they are canceled when the entry order is canceled without any fill.
+//
+// The groups without stop orders are not linked, since the group id is also used for canceling the orders of a strategy.
+
+// isExitOrder returns true if the order could be a take profit or a stop loss order of a bracket group
+func isExitOrder(o types.SubmitOrder) bool {
+	return isStopOrderType(o.Type) || o.ReduceOnly
+}
+
+// isLinkedStopOrder returns true if the order is a stop order or a triggered stop limit order
+func isLinkedStopOrder(o types.Order) bool {
+	return isStopOrderType(o.Type) || o.StopPrice.Sign() > 0
+}
+
+func (m *SimplePriceMatching) groupOrders(groupID uint32) (orders []types.Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range m.bidOrders {
+		if o.GroupID == groupID {
+			orders = append(orders, o)
+		}
+	}
+
+	for _, o := range m.askOrders {
+		if o.GroupID == groupID {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders
+}
+
+// hasBracketEntryOrder returns true if there is an open entry order of the opposite side in the group
+func (m *SimplePriceMatching) hasBracketEntryOrder(o types.SubmitOrder) bool {
+	if o.GroupID == 0 || !isExitOrder(o) {
+		return false
+	}
+
+	for _, entry := range m.groupOrders(o.GroupID) {
+		if entry.Side != o.Side && !isExitOrder(entry.SubmitOrder) && entry.ExecutedQuantity.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// placePendingOrder holds the bracket exit order until the entry order is filled
+func (m *SimplePriceMatching) placePendingOrder(o types.SubmitOrder, orderID uint64) (*types.Order, *types.Trade, error) {
+	order := m.newOrder(o, orderID)
+
+	m.mu.Lock()
+	m.pendingOrders = append(m.pendingOrders, order)
+	m.mu.Unlock()
+
+	m.EmitOrderUpdate(order)
+	return &order, nil, nil
+}
+
+func (m *SimplePriceMatching) removePendingOrder(orderID uint64) (types.Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, o := range m.pendingOrders {
+		if o.OrderID == orderID {
+			m.pendingOrders = append(m.pendingOrders[:i:i], m.pendingOrders[i+1:]...)
+			return o, true
+		}
+	}
+
+	return types.Order{}, false
+}
+
+func (m *SimplePriceMatching) popPendingOrders(groupID uint32) (orders []types.Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rest []types.Order
+	for _, o := range m.pendingOrders {
+		if o.GroupID == groupID {
+			orders = append(orders, o)
+		} else {
+			rest = append(rest, o)
+		}
+	}
+
+	m.pendingOrders = rest
+	return orders
+}
+
+func (m *SimplePriceMatching) getPendingOrder(orderID uint64) (types.Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range m.pendingOrders {
+		if o.OrderID == orderID {
+			return o, true
+		}
+	}
+
+	return types.Order{}, false
+}
+
+// addFilledGroupOrder queues the filled order of a group, the queued orders are processed by processOrderGroups
+// after the order book is updated, so that the linked orders are not canceled while the book is being matched.
+func (m *SimplePriceMatching) addFilledGroupOrder(o types.Order) {
+	if o.GroupID == 0 {
+		return
+	}
+
+	m.filledGroupOrders = append(m.filledGroupOrders, o)
+}
+
+// processOrderGroups cancels the OCO orders and places the bracket orders of the filled group orders
+func (m *SimplePriceMatching) processOrderGroups() {
+	for len(m.filledGroupOrders) > 0 {
+		o := m.filledGroupOrders[0]
+		m.filledGroupOrders = m.filledGroupOrders[1:]
+
+		m.cancelOCOOrders(o)
+
+		if o.Status == types.OrderStatusFilled {
+			m.placeBracketOrders(o.GroupID)
+		}
+	}
+}
+
+func (m *SimplePriceMatching) cancelOCOOrders(filled types.Order) {
+	orders := m.groupOrders(filled.GroupID)
+
+	isLinked := isLinkedStopOrder(filled)
+	for _, o := range orders {
+		isLinked = isLinked || isLinkedStopOrder(o)
+	}
+
+	if !isLinked {
+		return
+	}
+
+	for _, o := range orders {
+		if o.OrderID == filled.OrderID || o.Side != filled.Side {
+			continue
+		}
+
+		if _, err := m.cancelOrder(o); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("can not cancel the oco order: %+v", o)
+		}
+	}
+}
+
+func (m *SimplePriceMatching) placeBracketOrders(groupID uint32) {
+	for _, o := range m.popPendingOrders(groupID) {
+		if _, _, err := m.placeOrder(o.SubmitOrder, o.OrderID); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("bracket order is rejected: %+v", o)
+			m.rejectOrder(o)
+		}
+	}
+}
+
+// cancelBracketOrders cancels the pending bracket orders when the entry order is canceled without any fill
+func (m *SimplePriceMatching) cancelBracketOrders(entry types.Order) {
+	if entry.GroupID == 0 || isExitOrder(entry.SubmitOrder) {
+		return
+	}
+
+	for _, o := range m.groupOrders(entry.GroupID) {
+		// there is still an entry order in the group
+		if o.Side == entry.Side && !isExitOrder(o.SubmitOrder) {
+			return
+		}
+	}
+
+	if entry.ExecutedQuantity.Sign() > 0 {
+		m.placeBracketOrders(entry.GroupID)
+		return
+	}
+
+	for _, o := range m.popPendingOrders(entry.GroupID) {
+		o.Status = types.OrderStatusCanceled
+		o.IsWorking = false
+		o.UpdateTime = types.Time(m.currentTime)
+		m.closedOrders[o.OrderID] = o
+		m.EmitOrderUpdate(o)
+	}
+}
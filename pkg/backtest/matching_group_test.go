@@ -0,0 +1,118 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newGroupTestEngine() *SimplePriceMatching {
+	return &SimplePriceMatching{
+		account:      getTestAccount(),
+		Market:       getTestMarket(),
+		currentTime:  time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+}
+
+func newStopMarketOrder(side types.SideType, stopPrice, quantity float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:    "BTCUSDT",
+		Side:      side,
+		Type:      types.OrderTypeStopMarket,
+		Quantity:  fixedpoint.NewFromFloat(quantity),
+		StopPrice: fixedpoint.NewFromFloat(stopPrice),
+	}
+}
+
+func TestSimplePriceMatching_OCOOrders(t *testing.T) {
+	engine := newGroupTestEngine()
+
+	takeProfit := newLimitOrder("BTCUSDT", types.SideTypeSell, 21000.0, 0.1)
+	takeProfit.GroupID = 1
+	takeProfitOrder, _, err := engine.PlaceOrder(takeProfit)
+	assert.NoError(t, err)
+
+	stopLoss := newStopMarketOrder(types.SideTypeSell, 19000.0, 0.1)
+	stopLoss.GroupID = 1
+	stopLossOrder, _, err := engine.PlaceOrder(stopLoss)
+	assert.NoError(t, err)
+	assert.Len(t, engine.askOrders, 2)
+
+	closedOrders, trades := engine.sellToPrice(fixedpoint.NewFromFloat(18900.0))
+	assert.Len(t, closedOrders, 1)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "19000", trades[0].Price.String())
+	}
+
+	// the take profit order is canceled by the filled stop loss order
+	assert.Len(t, engine.askOrders, 0)
+	assert.Equal(t, types.OrderStatusFilled, engine.closedOrders[stopLossOrder.OrderID].Status)
+	assert.Equal(t, types.OrderStatusCanceled, engine.closedOrders[takeProfitOrder.OrderID].Status)
+}
+
+func TestSimplePriceMatching_BracketOrders(t *testing.T) {
+	engine := newGroupTestEngine()
+
+	entry := newLimitOrder("BTCUSDT", types.SideTypeBuy, 19500.0, 0.1)
+	entry.GroupID = 2
+	_, _, err := engine.PlaceOrder(entry)
+	assert.NoError(t, err)
+
+	takeProfit := newLimitOrder("BTCUSDT", types.SideTypeSell, 21000.0, 0.1)
+	takeProfit.GroupID = 2
+	takeProfit.ReduceOnly = true
+	takeProfitOrder, _, err := engine.PlaceOrder(takeProfit)
+	assert.NoError(t, err)
+
+	stopLoss := newStopMarketOrder(types.SideTypeSell, 19000.0, 0.1)
+	stopLoss.GroupID = 2
+	_, _, err = engine.PlaceOrder(stopLoss)
+	assert.NoError(t, err)
+
+	// the exit orders are pending until the entry order is filled
+	assert.Len(t, engine.pendingOrders, 2)
+	assert.Len(t, engine.askOrders, 0)
+
+	_, exists := engine.getOrder(takeProfitOrder.OrderID)
+	assert.True(t, exists, "pending orders should be queryable")
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, engine.currentTime, 20000, 20100, 19400, 19800))
+	assert.Len(t, engine.pendingOrders, 0)
+	assert.Len(t, engine.askOrders, 2)
+
+	// the take profit order is filled and the stop loss order is canceled
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, engine.currentTime, 19800, 21100, 19700, 21000))
+	assert.Len(t, engine.askOrders, 0)
+	assert.Equal(t, types.OrderStatusFilled, engine.closedOrders[takeProfitOrder.OrderID].Status)
+}
+
+func TestSimplePriceMatching_CancelBracketEntryOrder(t *testing.T) {
+	engine := newGroupTestEngine()
+
+	entry := newLimitOrder("BTCUSDT", types.SideTypeBuy, 19500.0, 0.1)
+	entry.GroupID = 3
+	entryOrder, _, err := engine.PlaceOrder(entry)
+	assert.NoError(t, err)
+
+	stopLoss := newStopMarketOrder(types.SideTypeSell, 19000.0, 0.1)
+	stopLoss.GroupID = 3
+	stopLossOrder, _, err := engine.PlaceOrder(stopLoss)
+	assert.NoError(t, err)
+	assert.Len(t, engine.pendingOrders, 1)
+
+	_, err = engine.CancelOrder(*entryOrder)
+	assert.NoError(t, err)
+
+	// the pending stop loss order is canceled with the entry order
+	assert.Len(t, engine.pendingOrders, 0)
+	assert.Equal(t, types.OrderStatusCanceled, engine.closedOrders[stopLossOrder.OrderID].Status)
+
+	btc, _ := engine.account.Balance("BTC")
+	assert.True(t, btc.Locked.IsZero())
+}
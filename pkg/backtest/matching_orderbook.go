@@ -0,0 +1,521 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// orderBookReplay is the state of the order book replay matching engine.
+//
+// Instead of matching the orders by the kline OHLC prices, the orders are matched against the recorded
+// order book and the recorded market trades:
+//
+//  1. taker orders walk through the price levels of the recorded order book, and can be partially filled.
+//  2. resting limit orders are queued behind the volume of the same price level when they are placed,
+//     the queue moves forward when the market trades consume the price level or when the price level volume shrinks.
+//  3. resting limit orders are filled when the market trades go through its price, or when the opposite side of the book crosses its price.
+type orderBookReplay struct {
+	reader *BookEventReader
+	book   *types.SliceOrderBook
+
+	// loaded is true when the first snapshot is loaded,
+	// before that, the orders are matched by the kline matching logics.
+	loaded bool
+
+	// queue is the volume queued before our resting order at the same price level, keyed by the order ID
+	queue map[uint64]fixedpoint.Value
+}
+
+func newOrderBookReplay(symbol string, reader *BookEventReader) *orderBookReplay {
+	return &orderBookReplay{
+		reader: reader,
+		book:   types.NewSliceOrderBook(symbol),
+		queue:  make(map[uint64]fixedpoint.Value),
+	}
+}
+
+// closeBookReplay closes the book event reader of the order book replay, the replay is detached after it's closed
+func (m *SimplePriceMatching) closeBookReplay() {
+	if m.bookReplay == nil {
+		return
+	}
+
+	if err := m.bookReplay.reader.Close(); err != nil {
+		log.WithError(err).Error("book event file close error")
+	}
+
+	m.bookReplay = nil
+}
+
+func isBookMatchingOrderType(orderType types.OrderType) bool {
+	switch orderType {
+	case types.OrderTypeMarket, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		return true
+	}
+
+	return false
+}
+
+// replayBookEvents replays the recorded book events until the given time
+func (m *SimplePriceMatching) replayBookEvents(until time.Time) {
+	r := m.bookReplay
+	for {
+		evt, err := r.reader.Peek()
+		if err != nil {
+			if err != io.EOF {
+				klineMatchingLogger.WithError(err).Errorf("%s book event read error", m.Market.Symbol)
+			}
+			return
+		}
+
+		if evt.Time.Time().After(until) {
+			return
+		}
+
+		m.processDelayedActions(evt.Time.Time())
+
+		_, _ = r.reader.Next()
+		m.processBookEvent(*evt)
+	}
+}
+
+func (m *SimplePriceMatching) processBookEvent(evt BookEvent) {
+	r := m.bookReplay
+	m.currentTime = evt.Time.Time()
+
+	switch evt.Type {
+
+	case BookEventTypeSnapshot:
+		r.book.Load(types.SliceOrderBook{Symbol: m.Market.Symbol, Bids: evt.Bids, Asks: evt.Asks})
+		r.loaded = true
+		m.matchCrossedOrders()
+		m.updateQueuePositions()
+
+	case BookEventTypeUpdate:
+		if !r.loaded {
+			return
+		}
+
+		r.book.Update(types.SliceOrderBook{Symbol: m.Market.Symbol, Bids: evt.Bids, Asks: evt.Asks})
+		m.matchCrossedOrders()
+		m.updateQueuePositions()
+
+	case BookEventTypeTrade:
+		m.lastPrice = evt.Price
+		if !r.loaded {
+			return
+		}
+
+		m.triggerStopOrders(evt.Price)
+
+		switch evt.Side {
+		case types.SideTypeBuy:
+			m.matchMarketTrade(types.SideTypeSell, evt.Price, evt.Quantity)
+
+		case types.SideTypeSell:
+			m.matchMarketTrade(types.SideTypeBuy, evt.Price, evt.Quantity)
+
+		default:
+			// unknown taker side, only one side could be matched in a sane order book
+			m.matchMarketTrade(types.SideTypeBuy, evt.Price, evt.Quantity)
+			m.matchMarketTrade(types.SideTypeSell, evt.Price, evt.Quantity)
+		}
+
+	default:
+		klineMatchingLogger.Errorf("unknown book event type: %s", evt.Type)
+	}
+
+	m.processOrderGroups()
+}
+
+// updateQueuePositions caps the queue position of the resting orders by the current price level volume,
+// the volume behind our order is not tracked, so we assume the canceled volume was queued before our order.
+func (m *SimplePriceMatching) updateQueuePositions() {
+	r := m.bookReplay
+	for _, o := range m.bidOrders {
+		m.capQueuePosition(o, r.book.Bids, true)
+	}
+
+	for _, o := range m.askOrders {
+		m.capQueuePosition(o, r.book.Asks, false)
+	}
+}
+
+func (m *SimplePriceMatching) capQueuePosition(o types.Order, pvs types.PriceVolumeSlice, descending bool) {
+	r := m.bookReplay
+	ahead, ok := r.queue[o.OrderID]
+	if !ok {
+		return
+	}
+
+	volume := fixedpoint.Zero
+	if pv, _ := pvs.Find(o.Price, descending); pv.Price.Eq(o.Price) {
+		volume = pv.Volume
+	}
+
+	r.queue[o.OrderID] = fixedpoint.Min(ahead, volume)
+}
+
+// matchCrossedOrders fills the resting orders that are crossed by the opposite side of the book
+func (m *SimplePriceMatching) matchCrossedOrders() {
+	r := m.bookReplay
+
+	for i := range m.bidOrders {
+		o := &m.bidOrders[i]
+		if !isBookMatchingOrderType(o.Type) {
+			continue
+		}
+
+		for _, pv := range r.book.Asks.Copy() {
+			remaining := o.Quantity.Sub(o.ExecutedQuantity)
+			if remaining.Sign() <= 0 || pv.Price.Compare(o.Price) > 0 {
+				break
+			}
+
+			quantity := fixedpoint.Min(remaining, pv.Volume)
+			m.consumeBookVolume(types.SideTypeSell, pv.Price, quantity)
+			m.fillOrder(o, o.Price, quantity, true)
+		}
+	}
+
+	for i := range m.askOrders {
+		o := &m.askOrders[i]
+		if !isBookMatchingOrderType(o.Type) {
+			continue
+		}
+
+		for _, pv := range r.book.Bids.Copy() {
+			remaining := o.Quantity.Sub(o.ExecutedQuantity)
+			if remaining.Sign() <= 0 || pv.Price.Compare(o.Price) < 0 {
+				break
+			}
+
+			quantity := fixedpoint.Min(remaining, pv.Volume)
+			m.consumeBookVolume(types.SideTypeBuy, pv.Price, quantity)
+			m.fillOrder(o, o.Price, quantity, true)
+		}
+	}
+
+	m.bidOrders = m.removeFilledOrders(m.bidOrders)
+	m.askOrders = m.removeFilledOrders(m.askOrders)
+}
+
+// matchMarketTrade fills our resting orders on the given side by the market trade.
+// The orders with better price are filled first, and the orders at the trade price are filled only
+// after the volume queued before them is consumed.
+func (m *SimplePriceMatching) matchMarketTrade(side types.SideType, price, quantity fixedpoint.Value) {
+	r := m.bookReplay
+
+	orders := m.bidOrders
+	if side == types.SideTypeSell {
+		orders = m.askOrders
+	}
+
+	var candidates []int
+	for i, o := range orders {
+		if !isBookMatchingOrderType(o.Type) {
+			continue
+		}
+
+		if (side == types.SideTypeBuy && o.Price.Compare(price) >= 0) || (side == types.SideTypeSell && o.Price.Compare(price) <= 0) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// price priority, then time priority
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := orders[candidates[i]].Price, orders[candidates[j]].Price
+		if side == types.SideTypeBuy {
+			return a.Compare(b) > 0
+		}
+		return a.Compare(b) < 0
+	})
+
+	available := quantity
+	for _, idx := range candidates {
+		if available.Sign() <= 0 {
+			break
+		}
+
+		o := &orders[idx]
+		if o.Price.Eq(price) {
+			ahead := r.queue[o.OrderID]
+			if ahead.Compare(available) >= 0 {
+				r.queue[o.OrderID] = ahead.Sub(available)
+				available = fixedpoint.Zero
+				break
+			}
+
+			available = available.Sub(ahead)
+			r.queue[o.OrderID] = fixedpoint.Zero
+		}
+
+		fillQuantity := fixedpoint.Min(o.Quantity.Sub(o.ExecutedQuantity), available)
+		available = available.Sub(fillQuantity)
+		m.fillOrder(o, o.Price, fillQuantity, true)
+	}
+
+	if side == types.SideTypeBuy {
+		m.bidOrders = m.removeFilledOrders(orders)
+	} else {
+		m.askOrders = m.removeFilledOrders(orders)
+	}
+}
+
+// fillOrder executes the given quantity of the order, and emits the trade and the order update
+func (m *SimplePriceMatching) fillOrder(o *types.Order, price, quantity fixedpoint.Value, isMaker bool) types.Trade {
+	// the trade is created from the filled part of the order
+	filled := *o
+	filled.Price = price
+	filled.Quantity = quantity
+
+	trade := m.newTradeFromOrder(&filled, isMaker, price)
+	m.executeTrade(trade)
+	m.autoRepay(o.SubmitOrder, trade)
+
+	o.UpdateTime = filled.UpdateTime
+	o.ExecutedQuantity = o.ExecutedQuantity.Add(quantity)
+	if o.ExecutedQuantity.Compare(o.Quantity) >= 0 {
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+	} else {
+		o.Status = types.OrderStatusPartiallyFilled
+	}
+
+	m.EmitOrderUpdate(*o)
+	m.addFilledGroupOrder(*o)
+	return trade
+}
+
+// removeFilledOrders moves the filled orders to the closed orders
+func (m *SimplePriceMatching) removeFilledOrders(orders []types.Order) (openOrders []types.Order) {
+	for _, o := range orders {
+		if o.Status == types.OrderStatusFilled {
+			m.closedOrders[o.OrderID] = o
+			delete(m.bookReplay.queue, o.OrderID)
+			continue
+		}
+
+		openOrders = append(openOrders, o)
+	}
+
+	return openOrders
+}
+
+// consumeBookVolume removes the volume taken by our orders from the recorded order book,
+// the consumed volume will be restored by the next snapshot or the next update of the price level.
+func (m *SimplePriceMatching) consumeBookVolume(side types.SideType, price, quantity fixedpoint.Value) {
+	book := m.bookReplay.book
+	switch side {
+	case types.SideTypeBuy:
+		book.Bids = consumePriceVolume(book.Bids, price, quantity, true)
+	case types.SideTypeSell:
+		book.Asks = consumePriceVolume(book.Asks, price, quantity, false)
+	}
+}
+
+func consumePriceVolume(pvs types.PriceVolumeSlice, price, quantity fixedpoint.Value, descending bool) types.PriceVolumeSlice {
+	pv, _ := pvs.Find(price, descending)
+	if !pv.Price.Eq(price) {
+		return pvs
+	}
+
+	volume := pv.Volume.Sub(quantity)
+	if volume.Sign() <= 0 {
+		return pvs.Remove(price, descending)
+	}
+
+	return pvs.Upsert(types.PriceVolume{Price: price, Volume: volume}, descending)
+}
+
+// triggerStopOrders converts the stop orders triggered by the market trade price into market or limit orders,
+// and then matches them against the recorded order book.
+func (m *SimplePriceMatching) triggerStopOrders(price fixedpoint.Value) {
+	var triggered []types.Order
+
+	m.mu.Lock()
+	var bidOrders []types.Order
+	for _, o := range m.bidOrders {
+		if isStopOrderType(o.Type) && price.Compare(o.StopPrice) >= 0 {
+			triggered = append(triggered, o)
+			continue
+		}
+		bidOrders = append(bidOrders, o)
+	}
+	m.bidOrders = bidOrders
+
+	var askOrders []types.Order
+	for _, o := range m.askOrders {
+		if isStopOrderType(o.Type) && price.Compare(o.StopPrice) <= 0 {
+			triggered = append(triggered, o)
+			continue
+		}
+		askOrders = append(askOrders, o)
+	}
+	m.askOrders = askOrders
+	m.mu.Unlock()
+
+	for _, o := range triggered {
+		// unlock the balance locked by the stop order, the converted order locks its own balance
+		if err := m.unlockOrderBalance(o.Side, o.Quantity, stopOrderLockPrice(o).Mul(o.Quantity)); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("can not unlock the balance of the triggered stop order: %+v", o)
+		}
+
+		submitOrder := o.SubmitOrder
+		if o.Type == types.OrderTypeStopMarket {
+			submitOrder.Type = types.OrderTypeMarket
+		} else {
+			submitOrder.Type = types.OrderTypeLimit
+		}
+
+		if _, _, err := m.placeOrderOnBook(submitOrder, o.OrderID); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("triggered stop order is rejected: %+v", o)
+			m.rejectOrder(o)
+		}
+	}
+}
+
+func isStopOrderType(orderType types.OrderType) bool {
+	return orderType == types.OrderTypeStopMarket || orderType == types.OrderTypeStopLimit
+}
+
+// placeOrderOnBook places the order and matches it against the recorded order book
+func (m *SimplePriceMatching) placeOrderOnBook(o types.SubmitOrder, orderID uint64) (*types.Order, *types.Trade, error) {
+	r := m.bookReplay
+
+	o.Quantity = m.Market.TruncateQuantity(o.Quantity)
+	if o.Type != types.OrderTypeMarket {
+		o.Price = m.Market.TruncatePrice(o.Price)
+	}
+
+	if o.Quantity.Compare(m.Market.MinQuantity) < 0 {
+		return nil, nil, fmt.Errorf("order quantity %s is less than minQuantity %s, order: %+v", o.Quantity.String(), m.Market.MinQuantity.String(), o)
+	}
+
+	// collect the price levels that can be taken by this order
+	var fills types.PriceVolumeSlice
+	var takenQuantity, takenAmount fixedpoint.Value
+	for _, pv := range r.book.SideBook(o.Side.Reverse()) {
+		remaining := o.Quantity.Sub(takenQuantity)
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		if o.Type != types.OrderTypeMarket {
+			if (o.Side == types.SideTypeBuy && pv.Price.Compare(o.Price) > 0) || (o.Side == types.SideTypeSell && pv.Price.Compare(o.Price) < 0) {
+				break
+			}
+		}
+
+		quantity := fixedpoint.Min(remaining, pv.Volume)
+		fills = append(fills, types.PriceVolume{Price: pv.Price, Volume: quantity})
+		takenQuantity = takenQuantity.Add(quantity)
+		takenAmount = takenAmount.Add(quantity.Mul(pv.Price))
+	}
+
+	switch o.Type {
+	case types.OrderTypeMarket:
+		if len(fills) == 0 {
+			return nil, nil, fmt.Errorf("no liquidity for the market order, order: %+v", o)
+		}
+
+	case types.OrderTypeLimitMaker:
+		if len(fills) > 0 {
+			return nil, nil, fmt.Errorf("limit maker order would immediately match and take, order: %+v", o)
+		}
+	}
+
+	// the market order is checked by the executable amount
+	quoteQuantity := o.Quantity.Mul(o.Price)
+	if o.Type == types.OrderTypeMarket {
+		quoteQuantity = takenAmount
+	}
+
+	if quoteQuantity.Compare(m.Market.MinNotional) < 0 {
+		return nil, nil, fmt.Errorf("order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), m.Market.MinNotional.String(), o)
+	}
+
+	lockQuantity := o.Quantity
+	if o.Type == types.OrderTypeMarket {
+		lockQuantity = takenQuantity
+	}
+
+	if err := m.borrowForOrder(o, lockQuantity, quoteQuantity); err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.lockOrderBalance(o.Side, lockQuantity, quoteQuantity); err != nil {
+		return nil, nil, err
+	}
+
+	m.EmitBalanceUpdate(m.account.Balances())
+
+	order := m.newOrder(o, orderID)
+	if order.Type == types.OrderTypeMarket {
+		order.Price = fills[0].Price
+	}
+
+	m.EmitOrderUpdate(order)
+
+	var firstTrade *types.Trade
+	for _, fill := range fills {
+		m.consumeBookVolume(o.Side.Reverse(), fill.Price, fill.Volume)
+		trade := m.fillOrder(&order, fill.Price, fill.Volume, false)
+		if firstTrade == nil {
+			firstTrade = &trade
+		}
+
+		// limit buy taker, the executed price is lower than the order price, the price difference should be unlocked
+		if order.Type == types.OrderTypeLimit && order.Side == types.SideTypeBuy {
+			amount := order.Price.Sub(fill.Price).Mul(fill.Volume)
+			if amount.Sign() > 0 {
+				if err := m.unlockOrderBalance(order.Side, fixedpoint.Zero, amount); err != nil {
+					return nil, nil, err
+				}
+				m.EmitBalanceUpdate(m.account.Balances())
+			}
+		}
+	}
+
+	if takenQuantity.Sign() > 0 {
+		order.AveragePrice = takenAmount.Div(takenQuantity)
+	}
+
+	if order.Status == types.OrderStatusFilled {
+		m.closedOrders[order.OrderID] = order
+		return &order, firstTrade, nil
+	}
+
+	// the rest of the market order is expired since there is no more liquidity
+	if order.Type == types.OrderTypeMarket {
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+		m.closedOrders[order.OrderID] = order
+		m.EmitOrderUpdate(order)
+		return &order, firstTrade, nil
+	}
+
+	// the rest of the limit order is queued behind the volume of the same price level
+	ahead := fixedpoint.Zero
+	descending := order.Side == types.SideTypeBuy
+	if pv, _ := r.book.SideBook(order.Side).Find(order.Price, descending); pv.Price.Eq(order.Price) {
+		ahead = pv.Volume
+	}
+
+	m.mu.Lock()
+	r.queue[order.OrderID] = ahead
+	switch order.Side {
+	case types.SideTypeBuy:
+		m.bidOrders = append(m.bidOrders, order)
+	case types.SideTypeSell:
+		m.askOrders = append(m.askOrders, order)
+	}
+	m.mu.Unlock()
+
+	return &order, firstTrade, nil
+}
@@ -0,0 +1,184 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const testBookEvents = `
+{"time":1625097600000,"type":"snapshot","bids":[["18999","1.0"],["18998","2.0"]],"asks":[["19001","0.5"],["19002","1.0"]]}
+{"time":1625097610000,"type":"trade","side":"sell","price":"18999","quantity":"0.6"}
+{"time":1625097620000,"type":"update","bids":[["18999","0.3"]]}
+{"time":1625097630000,"type":"trade","side":"sell","price":"18999","quantity":"0.5"}
+{"time":1625097640000,"type":"trade","side":"sell","price":"18998","quantity":"1.0"}
+`
+
+func newOrderBookReplayTestEngine(events string) *SimplePriceMatching {
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		account:      getTestAccount(),
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		bookReplay:   newOrderBookReplay(market.Symbol, NewBookEventReader(strings.NewReader(events))),
+	}
+	return engine
+}
+
+func TestBookEventReader(t *testing.T) {
+	reader := NewBookEventReader(strings.NewReader(testBookEvents))
+
+	evt, err := reader.Peek()
+	if assert.NoError(t, err) {
+		assert.Equal(t, BookEventTypeSnapshot, evt.Type)
+		assert.Len(t, evt.Bids, 2)
+		assert.Len(t, evt.Asks, 2)
+	}
+
+	_, _ = reader.Next()
+	evt, err = reader.Next()
+	if assert.NoError(t, err) {
+		assert.Equal(t, BookEventTypeTrade, evt.Type)
+		assert.Equal(t, types.SideTypeSell, evt.Side)
+		assert.Equal(t, "18999", evt.Price.String())
+		assert.Equal(t, "0.6", evt.Quantity.String())
+	}
+}
+
+func TestSimplePriceMatching_OrderBookReplay_QueuePosition(t *testing.T) {
+	engine := newOrderBookReplayTestEngine(testBookEvents)
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	engine.replayBookEvents(t1)
+	assert.True(t, engine.bookReplay.loaded)
+
+	var trades []types.Trade
+	engine.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	// queued behind 1.0 BTC at 18999
+	order, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 18999, 0.5))
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+	assert.Equal(t, "1", engine.bookReplay.queue[order.OrderID].String())
+
+	// 0.6 traded, 0.4 left before our order
+	engine.replayBookEvents(t1.Add(10 * time.Second))
+	assert.Len(t, trades, 0)
+	assert.Equal(t, "0.4", engine.bookReplay.queue[order.OrderID].String())
+
+	// the price level shrinks to 0.3 by cancellations
+	engine.replayBookEvents(t1.Add(20 * time.Second))
+	assert.Equal(t, "0.3", engine.bookReplay.queue[order.OrderID].String())
+
+	// 0.5 traded, 0.2 filled
+	engine.replayBookEvents(t1.Add(30 * time.Second))
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "0.2", trades[0].Quantity.String())
+		assert.Equal(t, "18999", trades[0].Price.String())
+		assert.True(t, trades[0].IsMaker)
+	}
+
+	if assert.Len(t, engine.bidOrders, 1) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, engine.bidOrders[0].Status)
+		assert.Equal(t, "0.2", engine.bidOrders[0].ExecutedQuantity.String())
+	}
+
+	// the market trades through our price, the rest is filled
+	engine.replayBookEvents(t1.Add(40 * time.Second))
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, "0.3", trades[1].Quantity.String())
+	}
+	assert.Len(t, engine.bidOrders, 0)
+
+	closedOrder, ok := engine.getOrder(order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderStatusFilled, closedOrder.Status)
+
+	usdt, ok := engine.account.Balance("USDT")
+	assert.True(t, ok)
+	assert.True(t, usdt.Locked.IsZero())
+}
+
+func TestSimplePriceMatching_OrderBookReplay_TakerOrder(t *testing.T) {
+	engine := newOrderBookReplayTestEngine(testBookEvents)
+	engine.replayBookEvents(time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	// limit maker orders can not take the liquidity
+	_, _, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimitMaker,
+		Quantity: fixedpoint.NewFromFloat(0.1),
+		Price:    fixedpoint.NewFromFloat(19001),
+	})
+	assert.Error(t, err)
+
+	// walk through 2 price levels, and the rest is queued at 19001.5
+	order, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19001.5, 2.0))
+	assert.NoError(t, err)
+	if assert.NotNil(t, trade) {
+		assert.Equal(t, "19001", trade.Price.String())
+		assert.Equal(t, "0.5", trade.Quantity.String())
+		assert.False(t, trade.IsMaker)
+	}
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, "0.5", order.ExecutedQuantity.String())
+	assert.Len(t, engine.bidOrders, 1)
+
+	// the rest of the order locks 1.5 * 19001.5
+	usdt, _ := engine.account.Balance("USDT")
+	assert.Equal(t, "28502.25", usdt.Locked.String())
+
+	retOrder, err := engine.CancelOrder(*order)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusCanceled, retOrder.Status)
+
+	usdt, _ = engine.account.Balance("USDT")
+	assert.True(t, usdt.Locked.IsZero())
+
+	// market sell order walks through the bid levels
+	order, trade, err = engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(2.5),
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, trade) {
+		assert.Equal(t, "18999", trade.Price.String())
+		assert.Equal(t, "1", trade.Quantity.String())
+	}
+	assert.Equal(t, types.OrderStatusFilled, order.Status)
+	assert.Equal(t, "2.5", order.ExecutedQuantity.String())
+	assert.Equal(t, "18998.4", order.AveragePrice.String())
+}
+
+type testBookEventSource struct {
+	*strings.Reader
+	closed bool
+}
+
+func (s *testBookEventSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestSimplePriceMatching_CloseBookReplay(t *testing.T) {
+	source := &testBookEventSource{Reader: strings.NewReader(testBookEvents)}
+	engine := newOrderBookReplayTestEngine("")
+	engine.bookReplay = newOrderBookReplay("BTCUSDT", NewBookEventReader(source))
+
+	e := &Exchange{matchingBooks: map[string]*SimplePriceMatching{"BTCUSDT": engine}}
+	e.resetMatchingBooks()
+
+	assert.True(t, source.closed)
+	assert.Nil(t, engine.bookReplay)
+}
@@ -0,0 +1,160 @@
+package backtest
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// isStopTriggered returns true if the stop order is triggered at the given price,
+// the buy stop orders are triggered when the price goes up to the stop price,
+// and the sell stop orders are triggered when the price goes down to the stop price.
+func isStopTriggered(side types.SideType, stopPrice, price fixedpoint.Value) bool {
+	switch side {
+	case types.SideTypeBuy:
+		return price.Compare(stopPrice) >= 0
+
+	case types.SideTypeSell:
+		return price.Compare(stopPrice) <= 0
+	}
+
+	return false
+}
+
+// stopOrderLockPrice returns the price used for locking the balance of the stop order
+func stopOrderLockPrice(o types.Order) fixedpoint.Value {
+	if o.Type == types.OrderTypeStopMarket {
+		return o.StopPrice
+	}
+
+	return o.Price
+}
+
+// checkStopOrder rejects the stop order that would be triggered immediately, the same as the live exchanges
+func (m *SimplePriceMatching) checkStopOrder(o types.SubmitOrder) error {
+	if !isStopOrderType(o.Type) || m.lastPrice.IsZero() {
+		return nil
+	}
+
+	if o.StopPrice.Sign() <= 0 {
+		return fmt.Errorf("stop price %s must be positive, order: %+v", o.StopPrice.String(), o)
+	}
+
+	if isStopTriggered(o.Side, o.StopPrice, m.lastPrice) {
+		return fmt.Errorf("stop order would immediately trigger: last price %s, stop price %s", m.lastPrice.String(), o.StopPrice.String())
+	}
+
+	return nil
+}
+
+// triggerKLineStopOrders triggers the stop orders of the given side crossed by the kline price movement.
+//
+// - stop market orders are filled at the stop price, with the simulated slippage.
+// - stop limit orders are converted into limit orders, the marketable ones are filled at the stop price (not worse than the limit price),
+//   and the rest are kept in the book as limit orders.
+func (m *SimplePriceMatching) triggerKLineStopOrders(side types.SideType, price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
+	var triggered []types.Order
+
+	m.mu.Lock()
+	var orders []types.Order
+	if side == types.SideTypeBuy {
+		orders = m.bidOrders
+	} else {
+		orders = m.askOrders
+	}
+
+	var rest []types.Order
+	for _, o := range orders {
+		if isStopOrderType(o.Type) && isStopTriggered(side, o.StopPrice, price) {
+			triggered = append(triggered, o)
+			continue
+		}
+
+		rest = append(rest, o)
+	}
+
+	if side == types.SideTypeBuy {
+		m.bidOrders = rest
+	} else {
+		m.askOrders = rest
+	}
+	m.mu.Unlock()
+
+	for _, o := range triggered {
+		lockPrice := stopOrderLockPrice(o)
+
+		var executedPrice fixedpoint.Value
+
+		switch o.Type {
+		case types.OrderTypeStopMarket:
+			o.Type = types.OrderTypeMarket
+			executedPrice = m.Market.TruncatePrice(m.slippedPrice(o.Side, o.StopPrice, o.Quantity))
+
+		case types.OrderTypeStopLimit:
+			o.Type = types.OrderTypeLimit
+
+			// the limit price is not reached, the converted limit order is kept in the book with the same locked balance
+			if (o.Side == types.SideTypeBuy && o.Price.Compare(o.StopPrice) < 0) ||
+				(o.Side == types.SideTypeSell && o.Price.Compare(o.StopPrice) > 0) {
+				m.mu.Lock()
+				if o.Side == types.SideTypeBuy {
+					m.bidOrders = append(m.bidOrders, o)
+				} else {
+					m.askOrders = append(m.askOrders, o)
+				}
+				m.mu.Unlock()
+				continue
+			}
+
+			executedPrice = m.slippedPrice(o.Side, o.StopPrice, o.Quantity)
+			if o.Side == types.SideTypeBuy {
+				executedPrice = fixedpoint.Min(executedPrice, o.Price)
+			} else {
+				executedPrice = fixedpoint.Max(executedPrice, o.Price)
+			}
+			executedPrice = m.Market.TruncatePrice(executedPrice)
+		}
+
+		// re-lock the balance by the executed price, since the executed price might be different from the locked price
+		if err := m.unlockOrderBalance(o.Side, o.Quantity, lockPrice.Mul(o.Quantity)); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("can not unlock the balance of the triggered stop order: %+v", o)
+		}
+
+		if err := m.lockOrderBalance(o.Side, o.Quantity, executedPrice.Mul(o.Quantity)); err != nil {
+			klineMatchingLogger.WithError(err).Errorf("triggered stop order is rejected: %+v", o)
+			m.rejectOrder(o)
+			continue
+		}
+
+		if m.slippageFunction != nil {
+			m.slippageCost = m.slippageCost.Add(executedPrice.Sub(o.StopPrice).Abs().Mul(o.Quantity))
+		}
+
+		o.AveragePrice = executedPrice
+		o.ExecutedQuantity = o.Quantity
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+
+		trade := m.newTradeFromOrder(&o, false, executedPrice)
+		m.executeTrade(trade)
+		m.autoRepay(o.SubmitOrder, trade)
+
+		m.EmitOrderUpdate(o)
+		m.closedOrders[o.OrderID] = o
+		m.addFilledGroupOrder(o)
+
+		closedOrders = append(closedOrders, o)
+		trades = append(trades, trade)
+	}
+
+	return closedOrders, trades
+}
+
+func (m *SimplePriceMatching) rejectOrder(o types.Order) {
+	o.Status = types.OrderStatusRejected
+	o.IsWorking = false
+	o.UpdateTime = types.Time(m.currentTime)
+	m.closedOrders[o.OrderID] = o
+	m.EmitOrderUpdate(o)
+}
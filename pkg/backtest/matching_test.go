@@ -297,7 +297,7 @@ func TestSimplePriceMatching_StopLimitOrderBuy(t *testing.T) {
 
 	assert.Equal(t, types.OrderStatusFilled, closedOrders[0].Status)
 	assert.Equal(t, types.OrderTypeLimit, closedOrders[0].Type)
-	assert.Equal(t, "21001", trades[0].Price.String())
+	assert.Equal(t, "21000", trades[0].Price.String(), "the stop limit buy order should be filled at the stop price")
 	assert.Equal(t, "22000", closedOrders[0].Price.String(), "order.Price should not be adjusted")
 
 	assert.Equal(t, fixedpoint.NewFromFloat(21001.0).String(), engine.lastPrice.String())
@@ -311,6 +311,10 @@ func TestSimplePriceMatching_StopLimitOrderBuy(t *testing.T) {
 		StopPrice:   fixedpoint.NewFromFloat(21000.0),
 		TimeInForce: types.TimeInForceGTC,
 	}
+	_, _, err = engine.PlaceOrder(stopOrder2)
+	assert.Error(t, err, "the stop buy order below the current price would immediately trigger")
+
+	stopOrder2.StopPrice = fixedpoint.NewFromFloat(21500.0)
 	createdOrder, trade, err = engine.PlaceOrder(stopOrder2)
 	assert.NoError(t, err)
 	assert.Nil(t, trade, "place stop order should not trigger the stop buy")
@@ -318,8 +322,14 @@ func TestSimplePriceMatching_StopLimitOrderBuy(t *testing.T) {
 	assert.Len(t, engine.bidOrders, 2)
 
 	closedOrders, trades = engine.sellToPrice(fixedpoint.NewFromFloat(20500.0))
+	assert.Len(t, closedOrders, 0, "price going down should not trigger the stop buy order")
+	assert.Len(t, trades, 0)
+
+	closedOrders, trades = engine.buyToPrice(fixedpoint.NewFromFloat(21600.0))
 	assert.Len(t, closedOrders, 1, "should trigger the stop buy order")
-	assert.Len(t, trades, 1, "should have stop order trade executed")
+	if assert.Len(t, trades, 1, "should have stop order trade executed") {
+		assert.Equal(t, "21500", trades[0].Price.String())
+	}
 	assert.Len(t, engine.bidOrders, 1, "should left one bid order")
 }
 
@@ -370,10 +380,10 @@ func TestSimplePriceMatching_StopLimitOrderSell(t *testing.T) {
 	assert.Equal(t, types.OrderStatusFilled, closedOrders[0].Status)
 	assert.Equal(t, types.OrderTypeLimit, closedOrders[0].Type)
 	assert.Equal(t, "20000", closedOrders[0].Price.String(), "limit order price should not be changed")
-	assert.Equal(t, "20990", trades[0].Price.String())
+	assert.Equal(t, "21000", trades[0].Price.String(), "the stop limit sell order should be filled at the stop price")
 	assert.Equal(t, "20990", engine.lastPrice.String())
 
-	// place a stop limit sell order with a higher price than the current price
+	// place a stop limit sell order with a higher stop price than the current price
 	stopOrder2 := types.SubmitOrder{
 		Symbol:      market.Symbol,
 		Side:        types.SideTypeSell,
@@ -384,19 +394,27 @@ func TestSimplePriceMatching_StopLimitOrderSell(t *testing.T) {
 		TimeInForce: types.TimeInForceGTC,
 	}
 
+	_, _, err = engine.PlaceOrder(stopOrder2)
+	assert.Error(t, err, "the stop sell order above the current price would immediately trigger")
+
+	stopOrder2.StopPrice = fixedpoint.NewFromFloat(20500.0)
 	createdOrder, trade, err = engine.PlaceOrder(stopOrder2)
 	assert.NoError(t, err)
 	assert.Nil(t, trade, "place stop order should not trigger the stop sell")
 	assert.NotNil(t, createdOrder, "place stop order should not trigger the stop sell")
 
 	closedOrders, trades = engine.buyToPrice(fixedpoint.NewFromFloat(21000.0))
+	assert.Len(t, closedOrders, 0, "price going up should not trigger the stop sell order")
+	assert.Len(t, trades, 0)
+
+	closedOrders, trades = engine.sellToPrice(fixedpoint.NewFromFloat(20400.0))
 	if assert.Len(t, closedOrders, 1, "should trigger the stop sell order") {
 		assert.Len(t, trades, 1, "should have stop order trade executed")
 		assert.Equal(t, types.SideTypeSell, closedOrders[0].Side)
 		assert.Equal(t, types.OrderStatusFilled, closedOrders[0].Status)
 		assert.Equal(t, types.OrderTypeLimit, closedOrders[0].Type)
-		assert.Equal(t, "21000", trades[0].Price.String(), "trade price should be the kline price not the order price")
-		assert.Equal(t, "21000", engine.lastPrice.String(), "engine last price should be updated correctly")
+		assert.Equal(t, "20500", trades[0].Price.String(), "trade price should be the stop price")
+		assert.Equal(t, "20400", engine.lastPrice.String(), "engine last price should be updated correctly")
 	}
 }
 
@@ -434,7 +452,7 @@ func TestSimplePriceMatching_StopMarketOrderSell(t *testing.T) {
 
 	assert.Equal(t, types.OrderStatusFilled, closedOrders[0].Status)
 	assert.Equal(t, types.OrderTypeMarket, closedOrders[0].Type)
-	assert.Equal(t, fixedpoint.NewFromFloat(20990.0), trades[0].Price, "trade price should be adjusted to the last price")
+	assert.Equal(t, fixedpoint.NewFromFloat(21000.0), trades[0].Price, "trade price should be the stop price")
 }
 
 func TestSimplePriceMatching_PlaceLimitOrder(t *testing.T) {
@@ -49,6 +49,15 @@ type SummaryReport struct {
 	TotalGrossProfit fixedpoint.Value `json:"totalGrossProfit,omitempty"`
 	TotalGrossLoss   fixedpoint.Value `json:"totalGrossLoss,omitempty"`
 
+	// TotalSlippageCost is the simulated slippage cost aggregated from the symbol reports
+	TotalSlippageCost fixedpoint.Value `json:"totalSlippageCost,omitempty"`
+
+	// MaxDrawdown is the max drawdown ratio of the hourly equity curve
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown,omitempty"`
+
+	// MarginReports are the simulated margin account summaries by session
+	MarginReports map[string]*MarginReport `json:"marginReports,omitempty"`
+
 	SymbolReports []SessionSymbolReport `json:"symbolReports,omitempty"`
 
 	Manifests Manifests `json:"manifests,omitempty"`
@@ -83,6 +92,14 @@ type SessionSymbolReport struct {
 	Sortino         fixedpoint.Value          `json:"sortinoRatio"`
 	ProfitFactor    fixedpoint.Value          `json:"profitFactor"`
 	WinningRatio    fixedpoint.Value          `json:"winningRatio"`
+
+	// OrderLatency, CancelLatency and SlippageCost are the execution costs simulated by the matching engine
+	OrderLatency  time.Duration    `json:"orderLatency,omitempty"`
+	CancelLatency time.Duration    `json:"cancelLatency,omitempty"`
+	SlippageCost  fixedpoint.Value `json:"slippageCost,omitempty"`
+
+	// Futures is the simulated futures position, it's only set for the futures account
+	Futures *FuturesReport `json:"futures,omitempty"`
 }
 
 func (r *SessionSymbolReport) InitialEquityValue() fixedpoint.Value {
@@ -90,7 +107,14 @@ func (r *SessionSymbolReport) InitialEquityValue() fixedpoint.Value {
 }
 
 func (r *SessionSymbolReport) FinalEquityValue() fixedpoint.Value {
-	return InQuoteAsset(r.FinalBalances, r.Market, r.LastPrice)
+	equity := InQuoteAsset(r.FinalBalances, r.Market, r.LastPrice)
+
+	// the margin is kept in the quote balance, the position value is not
+	if r.Futures != nil {
+		equity = equity.Add(r.Futures.UnrealizedProfit)
+	}
+
+	return equity
 }
 
 func (r *SessionSymbolReport) Print(wantBaseAssetBaseline bool) {
@@ -121,6 +145,28 @@ func (r *SessionSymbolReport) Print(wantBaseAssetBaseline bool) {
 		color.Red("ASSET DECREASED: %v %s (%s)", finalQuoteAsset.Sub(initQuoteAsset), r.Market.QuoteCurrency, finalQuoteAsset.Sub(initQuoteAsset).Div(initQuoteAsset).FormatPercentage(2))
 	}
 
+	if r.OrderLatency > 0 || r.CancelLatency > 0 {
+		color.Green("ORDER LATENCY: %s, CANCEL LATENCY: %s", r.OrderLatency, r.CancelLatency)
+	}
+
+	if r.SlippageCost.Sign() > 0 {
+		color.Red("SLIPPAGE COST: %v %s", r.SlippageCost, r.Market.QuoteCurrency)
+	}
+
+	if r.Futures != nil {
+		color.Green("FUTURES POSITION: %v %s @ %v (LEVERAGE: %vx, LIQUIDATION PRICE: %v)", r.Futures.Position, r.Market.BaseCurrency, r.Futures.EntryPrice, r.Futures.Leverage, r.Futures.LiquidationPrice)
+
+		if r.Futures.FundingFee.Sign() >= 0 {
+			color.Green("FUNDING FEE: +%v %s", r.Futures.FundingFee, r.Market.QuoteCurrency)
+		} else {
+			color.Red("FUNDING FEE: %v %s", r.Futures.FundingFee, r.Market.QuoteCurrency)
+		}
+
+		if r.Futures.Liquidations > 0 {
+			color.Red("LIQUIDATIONS: %d", r.Futures.Liquidations)
+		}
+	}
+
 	if r.Sharpe.Sign() > 0 {
 		color.Green("REALIZED SHARPE RATIO: %s", r.Sharpe.FormatString(4))
 	} else {
@@ -226,7 +272,7 @@ func AddReportIndexRun(outputDirectory string, run Run) error {
 func InQuoteAsset(balances types.BalanceMap, market types.Market, price fixedpoint.Value) fixedpoint.Value {
 	quote := balances[market.QuoteCurrency]
 	base := balances[market.BaseCurrency]
-	return base.Total().Mul(price).Add(quote.Total())
+	return base.Net().Mul(price).Add(quote.Net())
 }
 
 func getReportIndexPath(outputDirectory string) string {
@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var basisPoint = fixedpoint.NewFromFloat(0.0001)
+
+// SlippageFunction returns the price slippage (always positive) of the taker order
+// executed at the given price, the kline is the last kline of the matching engine
+type SlippageFunction func(side types.SideType, price, quantity fixedpoint.Value, kline types.KLine) fixedpoint.Value
+
+func newFixedSlippageFunction(bps fixedpoint.Value) SlippageFunction {
+	return func(_ types.SideType, price, _ fixedpoint.Value, _ types.KLine) fixedpoint.Value {
+		return price.Mul(bps).Mul(basisPoint)
+	}
+}
+
+func newVolumeSlippageFunction(ratio fixedpoint.Value) SlippageFunction {
+	return func(_ types.SideType, price, quantity fixedpoint.Value, kline types.KLine) fixedpoint.Value {
+		if kline.Volume.IsZero() {
+			return fixedpoint.Zero
+		}
+
+		return price.Mul(ratio).Mul(quantity).Div(kline.Volume)
+	}
+}
+
+func newRangeSlippageFunction(ratio fixedpoint.Value) SlippageFunction {
+	return func(_ types.SideType, _, _ fixedpoint.Value, kline types.KLine) fixedpoint.Value {
+		return kline.High.Sub(kline.Low).Mul(ratio)
+	}
+}
+
+func getSlippageFunction(slippage *bbgo.BacktestSlippage) SlippageFunction {
+	if slippage == nil {
+		return nil
+	}
+
+	switch slippage.Model {
+
+	case bbgo.BacktestSlippageModelFixed:
+		return newFixedSlippageFunction(slippage.BPS)
+
+	case bbgo.BacktestSlippageModelVolume:
+		return newVolumeSlippageFunction(slippage.Ratio)
+
+	case bbgo.BacktestSlippageModelRange:
+		return newRangeSlippageFunction(slippage.Ratio)
+
+	default:
+		return nil
+	}
+}
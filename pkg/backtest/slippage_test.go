@@ -0,0 +1,61 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestGetSlippageFunction(t *testing.T) {
+	kline := newKLine("BTCUSDT", types.Interval1m, time.Now(), 19000, 19100, 18900, 19050)
+	kline.Volume = fixedpoint.NewFromFloat(100.0)
+
+	price := fixedpoint.NewFromFloat(20000.0)
+	quantity := fixedpoint.NewFromFloat(10.0)
+
+	f := getSlippageFunction(&bbgo.BacktestSlippage{Model: bbgo.BacktestSlippageModelFixed, BPS: fixedpoint.NewFromFloat(5)})
+	assert.Equal(t, "10", f(types.SideTypeBuy, price, quantity, kline).String())
+
+	f = getSlippageFunction(&bbgo.BacktestSlippage{Model: bbgo.BacktestSlippageModelVolume, Ratio: fixedpoint.NewFromFloat(0.01)})
+	assert.Equal(t, "20", f(types.SideTypeBuy, price, quantity, kline).String())
+
+	f = getSlippageFunction(&bbgo.BacktestSlippage{Model: bbgo.BacktestSlippageModelRange, Ratio: fixedpoint.NewFromFloat(0.1)})
+	assert.Equal(t, "20", f(types.SideTypeBuy, price, quantity, kline).String())
+
+	assert.Nil(t, getSlippageFunction(nil))
+	assert.Nil(t, getSlippageFunction(&bbgo.BacktestSlippage{Model: "unknown"}))
+}
+
+func TestSimplePriceMatching_MarketOrderSlippage(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		account:          account,
+		Market:           market,
+		closedOrders:     make(map[uint64]types.Order),
+		lastPrice:        fixedpoint.NewFromFloat(20000.0),
+		slippageFunction: newFixedSlippageFunction(fixedpoint.NewFromFloat(10)),
+	}
+
+	order, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.5),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusFilled, order.Status)
+	assert.Equal(t, "20020", trade.Price.String())
+	assert.Equal(t, "10", engine.slippageCost.String())
+
+	// the limit taker price is capped by the order price
+	_, trade, err = engine.PlaceOrder(newLimitOrder(market.Symbol, types.SideTypeSell, 19990.0, 0.5))
+	assert.NoError(t, err)
+	assert.Equal(t, "19990", trade.Price.String())
+	assert.Equal(t, "15", engine.slippageCost.String())
+}
@@ -0,0 +1,97 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// marketTradeReplay replays the aggregate trades loaded from the database to the market data stream,
+// the trades are replayed in the time order before the kline of the same period is closed.
+type marketTradeReplay struct {
+	C    chan types.Trade
+	next *types.Trade
+
+	// channels is the subscribed trade channels of the symbols
+	channels map[string]map[types.Channel]struct{}
+}
+
+func newMarketTradeReplay(c chan types.Trade, channels map[string]map[types.Channel]struct{}) *marketTradeReplay {
+	return &marketTradeReplay{
+		C:        c,
+		channels: channels,
+	}
+}
+
+// pop returns the next trade before (or at) the given time
+func (r *marketTradeReplay) pop(until time.Time) (types.Trade, bool) {
+	if r.next == nil {
+		trade, ok := <-r.C
+		if !ok {
+			return types.Trade{}, false
+		}
+
+		r.next = &trade
+	}
+
+	if r.next.Time.Time().After(until) {
+		return types.Trade{}, false
+	}
+
+	trade := *r.next
+	r.next = nil
+	return trade, true
+}
+
+// replayMarketTrades matches the orders by the trades before the given time, and emits the trades to the market data stream
+func (e *Exchange) replayMarketTrades(until time.Time) {
+	if e.marketTrades == nil {
+		return
+	}
+
+	for {
+		trade, ok := e.marketTrades.pop(until)
+		if !ok {
+			return
+		}
+
+		if matching, ok := e.matchingBook(trade.Symbol); ok {
+			e.currentTime = trade.Time.Time()
+			matching.processMarketTrade(trade)
+			if e.margin != nil {
+				e.processMargin()
+			}
+		}
+
+		channels := e.marketTrades.channels[trade.Symbol]
+		if _, ok := channels[types.MarketTradeChannel]; ok {
+			e.MarketDataStream.EmitMarketTrade(trade)
+		}
+
+		if _, ok := channels[types.AggTradeChannel]; ok {
+			e.MarketDataStream.EmitAggTrade(trade)
+		}
+	}
+}
+
+// processMarketTrade matches the orders by the replayed market trade price, tick by tick.
+// The orders are not matched by the kline of the same period again, see processKLine.
+func (m *SimplePriceMatching) processMarketTrade(trade types.Trade) {
+	// the order book replay matching engine matches the orders by the recorded trade events
+	if m.bookReplay != nil {
+		return
+	}
+
+	m.currentTime = trade.Time.Time()
+	m.processDelayedActions(m.currentTime)
+
+	if m.lastPrice.IsZero() {
+		m.lastPrice = trade.Price
+	} else if trade.Price.Compare(m.lastPrice) > 0 {
+		m.buyToPrice(trade.Price)
+	} else if trade.Price.Compare(m.lastPrice) < 0 {
+		m.sellToPrice(trade.Price)
+	}
+
+	m.marketTradeMatched = true
+}
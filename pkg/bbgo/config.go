@@ -122,6 +122,17 @@ const (
 	BacktestFeeModeToken // BackTestFeeMode = "token"
 )
 
+type BacktestMatchingEngine string
+
+const (
+	// BacktestMatchingEngineKLine matches the orders by the kline OHLC prices, it's the default matching engine.
+	BacktestMatchingEngineKLine BacktestMatchingEngine = "kline"
+
+	// BacktestMatchingEngineOrderBook replays the recorded order book snapshots, updates and market trades
+	// to match the orders, with queue position and partial fills.
+	BacktestMatchingEngineOrderBook BacktestMatchingEngine = "orderbook"
+)
+
 type Backtest struct {
 	StartTime types.LooseFormatTime  `json:"startTime,omitempty" yaml:"startTime,omitempty"`
 	EndTime   *types.LooseFormatTime `json:"endTime,omitempty" yaml:"endTime,omitempty"`
@@ -135,6 +146,18 @@ type Backtest struct {
 
 	FeeMode BacktestFeeMode `json:"feeMode" yaml:"feeMode"`
 
+	// MatchingEngine selects the matching engine of the backtest exchange, "kline" (default) or "orderbook"
+	MatchingEngine BacktestMatchingEngine `json:"matchingEngine,omitempty" yaml:"matchingEngine,omitempty"`
+
+	// OrderBookDataDir is the directory of the recorded order book events, used by the "orderbook" matching engine.
+	// the event files are located at {OrderBookDataDir}/{exchange}/{symbol}.jsonl
+	OrderBookDataDir string `json:"orderBookDataDir,omitempty" yaml:"orderBookDataDir,omitempty"`
+
+	// AggTradeDataDir is the directory of the aggregate trade dump files, the files are imported into the database by --sync,
+	// and replayed to the strategies subscribing the trade channels.
+	// the dump files are located at {AggTradeDataDir}/{exchange}/{symbol}-aggTrades-*.{csv,zip}
+	AggTradeDataDir string `json:"aggTradeDataDir,omitempty" yaml:"aggTradeDataDir,omitempty"`
+
 	Accounts map[string]BacktestAccount `json:"accounts" yaml:"accounts"`
 	Symbols  []string                   `json:"symbols" yaml:"symbols"`
 	Sessions []string                   `json:"sessions" yaml:"sessions"`
@@ -162,6 +185,70 @@ type BacktestAccount struct {
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate,omitempty" yaml:"takerFeeRate,omitempty"`
 
 	Balances BacktestAccountBalanceMap `json:"balances" yaml:"balances"`
+
+	// OrderLatency is the simulated latency between the order submission and the order arriving at the matching engine
+	OrderLatency types.Duration `json:"orderLatency,omitempty" yaml:"orderLatency,omitempty"`
+
+	// CancelLatency is the simulated latency between the order cancellation and the cancellation arriving at the matching engine
+	CancelLatency types.Duration `json:"cancelLatency,omitempty" yaml:"cancelLatency,omitempty"`
+
+	// Slippage is the slippage model applied to the taker orders matched by the kline matching engine
+	Slippage *BacktestSlippage `json:"slippage,omitempty" yaml:"slippage,omitempty"`
+
+	// Futures simulates the account as an USDT-margined perpetual futures account
+	Futures *BacktestFutures `json:"futures,omitempty" yaml:"futures,omitempty"`
+
+	// Margin simulates the account as a cross margin account, the assets can be borrowed by the margin side effect or the borrow api
+	Margin *BacktestMargin `json:"margin,omitempty" yaml:"margin,omitempty"`
+}
+
+// BacktestMargin is the settings of the simulated cross margin account
+type BacktestMargin struct {
+	// MaxLeverage limits the max borrowable amount, debt <= net asset value * (max leverage - 1), defaults to 3
+	MaxLeverage fixedpoint.Value `json:"maxLeverage,omitempty" yaml:"maxLeverage,omitempty"`
+
+	// LiquidationMarginLevel is the margin level that triggers the forced liquidation, defaults to 1.1
+	LiquidationMarginLevel fixedpoint.Value `json:"liquidationMarginLevel,omitempty" yaml:"liquidationMarginLevel,omitempty"`
+
+	// InterestRate is the default daily interest rate of the borrowed assets, defaults to 0.0002
+	InterestRate fixedpoint.Value `json:"interestRate,omitempty" yaml:"interestRate,omitempty"`
+
+	// InterestRates overrides the daily interest rate by asset
+	InterestRates map[string]fixedpoint.Value `json:"interestRates,omitempty" yaml:"interestRates,omitempty"`
+}
+
+// BacktestFutures is the isolated margin settings of the simulated perpetual futures positions
+type BacktestFutures struct {
+	// Leverage is the initial leverage of the positions, defaults to 1
+	Leverage fixedpoint.Value `json:"leverage,omitempty" yaml:"leverage,omitempty"`
+
+	// MaintenanceMarginRate is the maintenance margin ratio of the position value, defaults to 0.004
+	MaintenanceMarginRate fixedpoint.Value `json:"maintenanceMarginRate,omitempty" yaml:"maintenanceMarginRate,omitempty"`
+}
+
+type BacktestSlippageModel string
+
+const (
+	// BacktestSlippageModelFixed slips the price by a fixed basis points
+	BacktestSlippageModelFixed BacktestSlippageModel = "fixed"
+
+	// BacktestSlippageModelVolume slips the price proportional to the ratio of the order quantity to the kline volume
+	BacktestSlippageModelVolume BacktestSlippageModel = "volume"
+
+	// BacktestSlippageModelRange slips the price by a ratio of the kline high-low range
+	BacktestSlippageModelRange BacktestSlippageModel = "range"
+)
+
+type BacktestSlippage struct {
+	Model BacktestSlippageModel `json:"model" yaml:"model"`
+
+	// BPS is the slippage in basis points, used by the fixed model
+	BPS fixedpoint.Value `json:"bps,omitempty" yaml:"bps,omitempty"`
+
+	// Ratio is used by the volume model and the range model.
+	// volume model: slippage = price * ratio * order quantity / kline volume
+	// range model: slippage = (kline high - kline low) * ratio
+	Ratio fixedpoint.Value `json:"ratio,omitempty" yaml:"ratio,omitempty"`
 }
 
 var DefaultBacktestAccount = BacktestAccount{
@@ -297,6 +384,9 @@ type SyncConfig struct {
 
 	MarginAssets []string `json:"marginAssets" yaml:"marginAssets"`
 
+	// FundingFeeHistory is for syncing the funding fee history of the sync symbols on the futures sessions
+	FundingFeeHistory bool `json:"fundingFeeHistory" yaml:"fundingFeeHistory"`
+
 	// Since is the date where you want to start syncing data
 	Since *types.LooseFormatTime `json:"since,omitempty"`
 
@@ -91,6 +91,8 @@ type Environment struct {
 	WithdrawService *service.WithdrawService
 	DepositService  *service.DepositService
 
+	FundingFeeService *service.FundingFeeService
+
 	// startTime is the time of start point (which is used in the backtest)
 	startTime time.Time
 
@@ -185,13 +187,15 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 	environ.MarginService = &service.MarginService{DB: db}
 	environ.WithdrawService = &service.WithdrawService{DB: db}
 	environ.DepositService = &service.DepositService{DB: db}
+	environ.FundingFeeService = &service.FundingFeeService{DB: db}
 	environ.SyncService = &service.SyncService{
-		TradeService:    environ.TradeService,
-		OrderService:    environ.OrderService,
-		RewardService:   environ.RewardService,
-		MarginService:   environ.MarginService,
-		WithdrawService: &service.WithdrawService{DB: db},
-		DepositService:  &service.DepositService{DB: db},
+		TradeService:      environ.TradeService,
+		OrderService:      environ.OrderService,
+		RewardService:     environ.RewardService,
+		MarginService:     environ.MarginService,
+		WithdrawService:   &service.WithdrawService{DB: db},
+		DepositService:    &service.DepositService{DB: db},
+		FundingFeeService: environ.FundingFeeService,
 	}
 
 	return nil
@@ -347,6 +351,11 @@ func (environ *Environment) BindSync(config *SyncConfig) {
 	}
 
 	for _, session := range environ.sessions {
+		// the simulated trades of the paper trading sessions are not recorded
+		if session.Paper != nil {
+			continue
+		}
+
 		// avoid using the iterator variable.
 		s2 := session
 		// if trade sync is on, we will write all received trades
@@ -460,6 +469,17 @@ func (environ *Environment) syncWithUserConfig(ctx context.Context, userConfig *
 				return err
 			}
 		}
+
+		if userConfig.Sync.FundingFeeHistory && session.Paper == nil {
+			symbols, err := session.getSessionSymbols(syncSymbols...)
+			if err != nil {
+				return err
+			}
+
+			if err := environ.SyncService.SyncFundingFeeHistory(ctx, session.Exchange, since, symbols...); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -588,6 +608,11 @@ func (environ *Environment) SyncSession(ctx context.Context, session *ExchangeSe
 }
 
 func (environ *Environment) syncSession(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) error {
+	// the paper trading session has no trading history on the exchange
+	if session.Paper != nil {
+		return nil
+	}
+
 	symbols, err := session.getSessionSymbols(defaultSymbols...)
 	if err != nil {
 		return err
@@ -0,0 +1,156 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLineLoader loads the historical klines before the indicators are updated by the market data stream
+type KLineLoader interface {
+	// LoadKLines returns at most limit klines ending at the end time in the ascending order
+	LoadKLines(ctx context.Context, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error)
+}
+
+// ExchangeKLineLoader loads the klines from the exchange api, 1000 klines per query
+type ExchangeKLineLoader struct {
+	Exchange types.Exchange
+}
+
+func (l *ExchangeKLineLoader) LoadKLines(ctx context.Context, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error) {
+	var klines []types.KLine
+	for len(klines) < limit {
+		e := endTime
+		if len(klines) > 0 {
+			e = klines[0].StartTime.Time().Add(-time.Millisecond)
+		}
+
+		batch, err := l.Exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
+			EndTime: &e,
+			Limit:   minInt(limit-len(klines), 1000),
+		})
+		if err != nil {
+			return klines, err
+		}
+
+		// the exchanges may return the kline containing the end time, drop the klines after it
+		var older []types.KLine
+		for _, k := range batch {
+			if !k.StartTime.Time().After(e) {
+				older = append(older, k)
+			}
+		}
+
+		if len(older) == 0 {
+			break
+		}
+
+		klines = append(older, klines...)
+	}
+
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+
+	return klines, nil
+}
+
+// DatabaseKLineLoader loads the klines synced by the backtest service, the klines are loaded by the fallback loader
+// if the database doesn't have enough klines or the synced klines don't reach the end time.
+type DatabaseKLineLoader struct {
+	Service  *service.BacktestService
+	Exchange types.ExchangeName
+	Fallback KLineLoader
+}
+
+func (l *DatabaseKLineLoader) LoadKLines(ctx context.Context, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error) {
+	klines, err := l.Service.QueryKLinesBackward(l.Exchange, symbol, interval, endTime, limit)
+	if err == nil && len(klines) >= limit && !klines[len(klines)-1].EndTime.Time().Before(endTime.Add(-interval.Duration())) {
+		return klines, nil
+	}
+
+	if l.Fallback == nil {
+		return klines, err
+	}
+
+	if err != nil {
+		log.WithError(err).Warnf("unable to load %s %s klines from the database, loading them from the fallback", symbol, interval)
+	}
+
+	return l.Fallback.LoadKLines(ctx, symbol, interval, endTime, limit)
+}
+
+// newKLineLoader returns the kline loader of the session, the back-test exchange already queries the back-test database,
+// and the live session prefers the database when it's configured.
+func newKLineLoader(environ *Environment, session *ExchangeSession) KLineLoader {
+	exchangeLoader := &ExchangeKLineLoader{Exchange: session.Exchange}
+	if environ.BacktestService != nil || environ.DatabaseService == nil {
+		return exchangeLoader
+	}
+
+	return &DatabaseKLineLoader{
+		Service:  &service.BacktestService{DB: environ.DatabaseService.DB},
+		Exchange: session.ExchangeName,
+		Fallback: exchangeLoader,
+	}
+}
+
+// IndicatorPreloader warms up the indicators with the historical klines before the market data stream connects.
+// The klines in the market data store are pushed to the indicator, and if they don't cover the lookback of the indicator,
+// the older klines are loaded by the loader.
+type IndicatorPreloader struct {
+	Symbol string
+	Store  *MarketDataStore
+	Loader KLineLoader
+
+	// EndTime is the end time of the loaded klines when the store has no kline of the interval
+	EndTime time.Time
+}
+
+// Preload pushes the historical klines of the interval to the indicator
+func (p *IndicatorPreloader) Preload(ctx context.Context, inc indicator.KLinePusher, interval types.Interval) error {
+	var klines []types.KLine
+	if p.Store != nil {
+		if window, ok := p.Store.KLinesOfInterval(interval); ok {
+			klines = append(klines, (*window)...)
+		}
+	}
+
+	var err error
+	if missing := indicator.Lookback(inc) - len(klines); missing > 0 && p.Loader != nil {
+		endTime := p.EndTime
+		if len(klines) > 0 {
+			endTime = klines[0].StartTime.Time().Add(-time.Millisecond)
+		}
+
+		if !endTime.IsZero() {
+			var history []types.KLine
+			history, err = p.Loader.LoadKLines(ctx, p.Symbol, interval, endTime, missing)
+			if err != nil {
+				err = errors.Wrapf(err, "unable to load %s %s klines for the indicator warm-up", p.Symbol, interval)
+			}
+
+			klines = append(history, klines...)
+		}
+	}
+
+	for _, k := range klines {
+		inc.PushK(k)
+	}
+
+	return err
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,123 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+var preloadStartTime = time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newPreloadKLine(i int) types.KLine {
+	return types.KLine{
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1m,
+		StartTime: types.Time(preloadStartTime.Add(time.Duration(i) * time.Minute)),
+		EndTime:   types.Time(preloadStartTime.Add(time.Duration(i+1)*time.Minute - time.Millisecond)),
+		Close:     fixedpoint.NewFromInt(int64(i + 1)),
+		Closed:    true,
+	}
+}
+
+type fakeKLineLoader struct {
+	klines []types.KLine
+
+	endTime time.Time
+	limit   int
+}
+
+func (l *fakeKLineLoader) LoadKLines(ctx context.Context, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error) {
+	l.endTime = endTime
+	l.limit = limit
+
+	var klines []types.KLine
+	for _, k := range l.klines {
+		if !k.StartTime.Time().After(endTime) {
+			klines = append(klines, k)
+		}
+	}
+
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+func TestIndicatorPreloader_Preload(t *testing.T) {
+	loader := &fakeKLineLoader{}
+	for i := 0; i < 20; i++ {
+		loader.klines = append(loader.klines, newPreloadKLine(i))
+	}
+
+	store := NewMarketDataStore("BTCUSDT")
+	for i := 20; i < 23; i++ {
+		store.AddKLine(newPreloadKLine(i))
+	}
+
+	preloader := &IndicatorPreloader{Symbol: "BTCUSDT", Store: store, Loader: loader}
+
+	ewma := &indicator.EWMA{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 3}}
+	assert.Equal(t, 9, indicator.Lookback(ewma))
+	assert.NoError(t, preloader.Preload(context.Background(), ewma, types.Interval1m))
+
+	// 6 klines are loaded before the first kline of the store
+	assert.Equal(t, 6, loader.limit)
+	assert.Equal(t, preloadStartTime.Add(20*time.Minute-time.Millisecond), loader.endTime)
+	assert.Equal(t, 9, ewma.Length())
+	assert.InDelta(t, 15.0, ewma.Values[0], 1e-6)
+
+	// the store covers the lookback, the loader is not used
+	loader.limit = 0
+	sma := &indicator.SMA{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 3}}
+	assert.NoError(t, preloader.Preload(context.Background(), sma, types.Interval1m))
+	assert.Equal(t, 0, loader.limit)
+	assert.InDelta(t, 22.0, sma.Last(), 1e-6)
+}
+
+func TestExchangeKLineLoader_LoadKLines(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var klines []types.KLine
+	for i := 0; i < 1500; i++ {
+		klines = append(klines, newPreloadKLine(i))
+	}
+
+	endTime := preloadStartTime.Add(1500 * time.Minute)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().
+		QueryKLines(gomock.Any(), "BTCUSDT", types.Interval1m, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+			var result []types.KLine
+			for _, k := range klines {
+				if !k.StartTime.Time().After(*options.EndTime) {
+					result = append(result, k)
+				}
+			}
+
+			if len(result) > options.Limit {
+				result = result[len(result)-options.Limit:]
+			}
+			return result, nil
+		}).Times(2)
+
+	loader := &ExchangeKLineLoader{Exchange: mockEx}
+	loaded, err := loader.LoadKLines(context.Background(), "BTCUSDT", types.Interval1m, endTime, 1200)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, loaded, 1200) {
+		assert.Equal(t, klines[300].StartTime, loaded[0].StartTime)
+		assert.Equal(t, klines[1499].StartTime, loaded[1199].StartTime)
+	}
+}
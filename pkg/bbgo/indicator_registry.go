@@ -0,0 +1,128 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// IndicatorRegistry constructs the indicators of the strategy from the declarative config, keyed by the names used in
+// the strategy code:
+//
+//	indicators:
+//	  fast: { type: ema, interval: 5m, window: 20 }
+//	  boll: { type: boll, interval: 1h, window: 21, k: 2 }
+//
+// The trader binds the registry field of the strategy: the kline intervals are subscribed, the indicators are
+// preloaded from the market data store and updated by the closed klines of the market data stream.
+type IndicatorRegistry struct {
+	Configs map[string]indicator.Config
+
+	indicators map[string]indicator.KLinePusher
+}
+
+func NewIndicatorRegistry(configs map[string]indicator.Config) *IndicatorRegistry {
+	return &IndicatorRegistry{Configs: configs}
+}
+
+func (r *IndicatorRegistry) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Configs)
+}
+
+func (r *IndicatorRegistry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Configs)
+}
+
+// Keys returns the sorted keys of the configured indicators
+func (r *IndicatorRegistry) Keys() []string {
+	var keys []string
+	for key := range r.Configs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks if all the configured indicators can be constructed
+func (r *IndicatorRegistry) Validate() error {
+	for _, key := range r.Keys() {
+		if _, err := indicator.New(r.Configs[key]); err != nil {
+			return fmt.Errorf("indicator %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe subscribes the kline intervals of the configured indicators
+func (r *IndicatorRegistry) Subscribe(session *ExchangeSession, symbol string) {
+	for _, config := range r.Configs {
+		session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: config.Interval})
+	}
+}
+
+// Bind constructs the configured indicators, warms them up by the preloader
+// and binds them to the closed klines of the stream.
+func (r *IndicatorRegistry) Bind(ctx context.Context, stream types.Stream, preloader *IndicatorPreloader) error {
+	indicators := make(map[string]indicator.KLinePusher, len(r.Configs))
+	for _, key := range r.Keys() {
+		config := r.Configs[key]
+		inc, err := indicator.New(config)
+		if err != nil {
+			return fmt.Errorf("indicator %s: %w", key, err)
+		}
+
+		if err := preloadAndBind(ctx, inc, config.Interval, stream, preloader); err != nil {
+			log.WithError(err).Warnf("indicator %s warm-up error", key)
+		}
+		indicators[key] = inc
+	}
+
+	r.indicators = indicators
+	return nil
+}
+
+// Get returns the bound indicator of the key, the caller should assert the indicator type, e.g., inc.(*indicator.EWMA)
+func (r *IndicatorRegistry) Get(key string) (indicator.KLinePusher, bool) {
+	inc, ok := r.indicators[key]
+	return inc, ok
+}
+
+// Series returns the bound indicator of the key as a series
+func (r *IndicatorRegistry) Series(key string) (types.SeriesExtend, bool) {
+	inc, ok := r.indicators[key]
+	if !ok {
+		return nil, false
+	}
+
+	series, ok := inc.(types.SeriesExtend)
+	return series, ok
+}
+
+// findIndicatorRegistry returns the first non-nil *IndicatorRegistry field of the strategy
+func findIndicatorRegistry(strategy interface{}) *IndicatorRegistry {
+	var registry *IndicatorRegistry
+	_ = dynamic.IterateFields(strategy, func(ft reflect.StructField, fv reflect.Value) error {
+		if r, ok := fv.Interface().(*IndicatorRegistry); ok && r != nil && registry == nil {
+			registry = r
+		}
+		return nil
+	})
+	return registry
+}
+
+// preloadAndBind warms up the indicator by the preloader and binds it to the closed klines of the stream
+func preloadAndBind(ctx context.Context, inc indicator.KLinePusher, interval types.Interval, stream types.Stream, preloader *IndicatorPreloader) error {
+	err := preloader.Preload(ctx, inc, interval)
+	stream.OnKLineClosed(types.KLineWith(preloader.Symbol, interval, inc.PushK))
+	return err
+}
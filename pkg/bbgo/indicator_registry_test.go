@@ -0,0 +1,89 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type indicatorStrategy struct {
+	Symbol     string             `json:"symbol"`
+	Indicators *IndicatorRegistry `json:"indicators"`
+}
+
+func TestIndicatorRegistry(t *testing.T) {
+	var s indicatorStrategy
+	err := json.Unmarshal([]byte(`{
+		"symbol": "BTCUSDT",
+		"indicators": {
+			"fast": {"type": "ema", "interval": "5m", "window": 3},
+			"boll": {"type": "boll", "interval": "1h", "window": 3, "k": 1.5}
+		}
+	}`), &s)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	registry := findIndicatorRegistry(&s)
+	if !assert.NotNil(t, registry) {
+		return
+	}
+	assert.Equal(t, []string{"boll", "fast"}, registry.Keys())
+	assert.NoError(t, registry.Validate())
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newKLine := func(i int, price float64) types.KLine {
+		return types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval5m,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * 5 * time.Minute)),
+			EndTime:   types.Time(startTime.Add(time.Duration(i+1)*5*time.Minute - time.Millisecond)),
+			Close:     fixedpoint.NewFromFloat(price),
+			Closed:    true,
+		}
+	}
+
+	store := NewMarketDataStore("BTCUSDT")
+	for i, price := range []float64{10, 11, 12} {
+		store.AddKLine(newKLine(i, price))
+	}
+
+	stream := &types.StandardStream{}
+	if !assert.NoError(t, registry.Bind(context.Background(), stream, &IndicatorPreloader{Symbol: "BTCUSDT", Store: store})) {
+		return
+	}
+
+	inc, ok := registry.Get("fast")
+	if assert.True(t, ok) {
+		ewma := inc.(*indicator.EWMA)
+		assert.Equal(t, 3, ewma.Length())
+
+		stream.EmitKLineClosed(newKLine(3, 13))
+		assert.Equal(t, 4, ewma.Length())
+	}
+
+	inc, ok = registry.Get("boll")
+	if assert.True(t, ok) {
+		assert.Equal(t, 1.5, inc.(*indicator.BOLL).K)
+	}
+
+	_, ok = registry.Series("fast")
+	assert.True(t, ok)
+
+	_, ok = registry.Get("slow")
+	assert.False(t, ok)
+}
+
+func TestIndicatorRegistry_Validate(t *testing.T) {
+	registry := NewIndicatorRegistry(map[string]indicator.Config{
+		"fast": {Type: "unknown", IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 3}},
+	})
+	assert.Error(t, registry.Validate())
+}
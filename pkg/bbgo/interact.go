@@ -26,6 +26,10 @@ type PositionReader interface {
 	CurrentPosition() *types.Position
 }
 
+type EquityCurveReader interface {
+	CurrentEquityCurve() *types.EquityCurve
+}
+
 type closePositionContext struct {
 	signature  string
 	closer     PositionCloser
@@ -160,6 +164,41 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		return nil
 	})
 
+	i.PrivateCommand("/equity", "Show Equity Curve", func(reply interact.Reply) error {
+		strategies, err := filterStrategies(it.exchangeStrategies, func(s SingleExchangeStrategy) bool {
+			return testInterface(s, (*EquityCurveReader)(nil)) || hasTypeField(s, &types.EquityCurve{})
+		})
+
+		if err == nil && len(strategies) > 0 {
+			reply.AddMultipleButtons(generateStrategyButtonsForm(strategies))
+			reply.Message("Please choose one strategy")
+		} else {
+			reply.Message("No strategy supports EquityCurveReader")
+		}
+		return nil
+	}).Next(func(signature string, reply interact.Reply) error {
+		defer func() {
+			if kc, ok := reply.(interact.KeyboardController); ok {
+				kc.RemoveKeyboard()
+			}
+		}()
+
+		strategy, ok := it.exchangeStrategies[signature]
+		if !ok {
+			reply.Message("Strategy not found")
+			return fmt.Errorf("strategy %s not found", signature)
+		}
+
+		curve := findEquityCurve(strategy)
+		if curve == nil {
+			reply.Message(fmt.Sprintf("Strategy %q has no equity curve", signature))
+			return fmt.Errorf("strategy %s has no equity curve", signature)
+		}
+
+		reply.Message(curve.PlainText())
+		return nil
+	})
+
 	i.PrivateCommand("/resetposition", "Reset position", func(reply interact.Reply) error {
 		strategies, err := filterStrategies(it.exchangeStrategies, func(s SingleExchangeStrategy) bool {
 			return testInterface(s, (*PositionResetter)(nil)) || hasTypeField(s, &types.Position{})
@@ -591,6 +630,22 @@ func filterStrategies(exchangeStrategies map[string]SingleExchangeStrategy, filt
 	return retStrategies, nil
 }
 
+// findEquityCurve returns the equity curve from the EquityCurveReader interface or the first *types.EquityCurve field of the strategy
+func findEquityCurve(strategy interface{}) *types.EquityCurve {
+	if reader, ok := strategy.(EquityCurveReader); ok {
+		return reader.CurrentEquityCurve()
+	}
+
+	var curve *types.EquityCurve
+	_ = dynamic.IterateFields(strategy, func(ft reflect.StructField, fv reflect.Value) error {
+		if c, ok := fv.Interface().(*types.EquityCurve); ok && c != nil && curve == nil {
+			curve = c
+		}
+		return nil
+	})
+	return curve
+}
+
 func hasTypeField(obj interface{}, typ interface{}) bool {
 	targetType := reflect.TypeOf(typ)
 	found := false
@@ -12,8 +12,9 @@ import (
 )
 
 type myStrategy struct {
-	Symbol   string `json:"symbol"`
-	Position *types.Position
+	Symbol      string `json:"symbol"`
+	Position    *types.Position
+	EquityCurve *types.EquityCurve
 }
 
 func (m *myStrategy) ID() string {
@@ -56,3 +57,13 @@ func Test_testInterface(t *testing.T) {
 	ok := testInterface(s, (*PositionCloser)(nil))
 	assert.True(t, ok)
 }
+
+func Test_findEquityCurve(t *testing.T) {
+	s := &myStrategy{
+		Symbol: "BTCUSDT",
+	}
+	assert.Nil(t, findEquityCurve(s))
+
+	s.EquityCurve = &types.EquityCurve{Symbol: "BTCUSDT"}
+	assert.Equal(t, s.EquityCurve, findEquityCurve(s))
+}
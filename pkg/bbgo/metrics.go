@@ -1,6 +1,11 @@
 package bbgo
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+	"github.com/c9s/bbgo/pkg/types"
+)
 
 var (
 	metricsConnectionStatus = prometheus.NewGaugeVec(
@@ -97,6 +102,84 @@ var (
 			"currency",  // for balance
 		},
 	)
+
+	metricsStrategyEquity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_equity",
+			Help: "bbgo strategy instance mark-to-market equity in the quote currency",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
+
+	metricsStrategyUnrealizedProfit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_unrealized_profit",
+			Help: "bbgo strategy instance unrealized profit in the quote currency",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
+
+	metricsStrategyDrawdown = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_drawdown",
+			Help: "bbgo strategy instance drawdown amount from the equity peak",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
+
+	metricsStrategyMaxDrawdown = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_max_drawdown",
+			Help: "bbgo strategy instance max drawdown amount from the equity peak",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
+
+	metricsStrategyMaxDrawdownRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_max_drawdown_ratio",
+			Help: "bbgo strategy instance max drawdown ratio of the equity",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
+
+	metricsStrategyUnderwaterSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_strategy_underwater_seconds",
+			Help: "bbgo strategy instance duration of the equity below the peak in seconds",
+		},
+		[]string{
+			"exchange",    // exchange session name
+			"strategy",    // strategy ID
+			"instance_id", // strategy instance ID
+			"symbol",
+		},
+	)
 )
 
 func init() {
@@ -108,5 +191,98 @@ func init() {
 		metricsTradesTotal,
 		metricsTradingVolume,
 		metricsLastUpdateTimeBalance,
+		metricsStrategyEquity,
+		metricsStrategyUnrealizedProfit,
+		metricsStrategyDrawdown,
+		metricsStrategyMaxDrawdown,
+		metricsStrategyMaxDrawdownRatio,
+		metricsStrategyUnderwaterSeconds,
+		&requestBudgetCollector{},
+	)
+}
+
+var (
+	metricsRequestWeightUsedDesc = prometheus.NewDesc(
+		"bbgo_api_request_weight_used",
+		"bbgo api request weight used in the current rate limit window",
+		[]string{"exchange"}, nil,
+	)
+
+	metricsRequestWeightLimitDesc = prometheus.NewDesc(
+		"bbgo_api_request_weight_limit",
+		"bbgo api request weight limit of the rate limit window",
+		[]string{"exchange"}, nil,
+	)
+
+	metricsRequestBansDesc = prometheus.NewDesc(
+		"bbgo_api_request_bans_total",
+		"bbgo api bans by the exchange rate limit",
+		[]string{"exchange"}, nil,
+	)
+
+	metricsRequestsDesc = prometheus.NewDesc(
+		"bbgo_api_requests_total",
+		"bbgo api requests acquired from the rate limit budget",
+		[]string{"exchange", "priority"}, nil,
 	)
+
+	metricsRequestsThrottledDesc = prometheus.NewDesc(
+		"bbgo_api_requests_throttled_total",
+		"bbgo api requests queued by the rate limit budget",
+		[]string{"exchange", "priority"}, nil,
+	)
+
+	metricsRequestsWaitingDesc = prometheus.NewDesc(
+		"bbgo_api_requests_waiting",
+		"bbgo api requests waiting for the rate limit budget",
+		[]string{"exchange", "priority"}, nil,
+	)
+)
+
+// requestBudgetCollector collects the usage of the api request budgets at scrape time
+type requestBudgetCollector struct{}
+
+func (c *requestBudgetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metricsRequestWeightUsedDesc
+	ch <- metricsRequestWeightLimitDesc
+	ch <- metricsRequestBansDesc
+	ch <- metricsRequestsDesc
+	ch <- metricsRequestsThrottledDesc
+	ch <- metricsRequestsWaitingDesc
+}
+
+func (c *requestBudgetCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, budget := range ratelimit.Budgets() {
+		stats := budget.Stats()
+		ch <- prometheus.MustNewConstMetric(metricsRequestWeightUsedDesc, prometheus.GaugeValue, float64(stats.Used), stats.Name)
+		ch <- prometheus.MustNewConstMetric(metricsRequestWeightLimitDesc, prometheus.GaugeValue, float64(stats.Limit), stats.Name)
+		ch <- prometheus.MustNewConstMetric(metricsRequestBansDesc, prometheus.CounterValue, float64(stats.Bans), stats.Name)
+
+		for _, priority := range ratelimit.Priorities {
+			ch <- prometheus.MustNewConstMetric(metricsRequestsDesc, prometheus.CounterValue, float64(stats.Requests[priority]), stats.Name, priority.String())
+			ch <- prometheus.MustNewConstMetric(metricsRequestsThrottledDesc, prometheus.CounterValue, float64(stats.Throttled[priority]), stats.Name, priority.String())
+			ch <- prometheus.MustNewConstMetric(metricsRequestsWaitingDesc, prometheus.GaugeValue, float64(stats.Waiting[priority]), stats.Name, priority.String())
+		}
+	}
+}
+
+func updateEquityMetrics(exchange, strategy, strategyInstanceID string, curve *types.EquityCurve, sample types.EquitySample) {
+	labels := prometheus.Labels{
+		"exchange":    exchange,
+		"strategy":    strategy,
+		"instance_id": strategyInstanceID,
+		"symbol":      curve.Symbol,
+	}
+
+	metricsStrategyEquity.With(labels).Set(sample.Equity.Float64())
+	metricsStrategyUnrealizedProfit.With(labels).Set(sample.UnrealizedProfit.Float64())
+
+	curve.Lock()
+	drawdown, maxDrawdown, maxDrawdownRatio := curve.Drawdown, curve.MaxDrawdown, curve.DrawdownRatio.Max
+	curve.Unlock()
+
+	metricsStrategyDrawdown.With(labels).Set(drawdown.Float64())
+	metricsStrategyMaxDrawdown.With(labels).Set(maxDrawdown.Float64())
+	metricsStrategyMaxDrawdownRatio.With(labels).Set(maxDrawdownRatio.Float64())
+	metricsStrategyUnderwaterSeconds.With(labels).Set(curve.UnderwaterDuration(sample.Time.Time()).Seconds())
 }
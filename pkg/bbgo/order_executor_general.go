@@ -153,6 +153,23 @@ func (e *GeneralOrderExecutor) BindProfitStats(profitStats *types.ProfitStats) {
 	})
 }
 
+// BindEquityCurve samples the mark-to-market equity of the position to the equity curve on each closed kline of the interval,
+// the kline of the interval must be subscribed by the strategy. The realized net profit is added to the curve by the trade collector.
+func (e *GeneralOrderExecutor) BindEquityCurve(curve *types.EquityCurve, interval types.Interval) {
+	e.tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
+		if profit == nil {
+			return
+		}
+
+		curve.AddProfit(profit.NetProfit)
+	})
+
+	e.session.MarketDataStream.OnKLineClosed(types.KLineWith(e.symbol, interval, func(kline types.KLine) {
+		sample := curve.Update(kline.EndTime.Time(), e.position.UnrealizedProfit(kline.Close))
+		updateEquityMetrics(e.session.Name, e.strategy, e.strategyInstanceID, curve, sample)
+	}))
+}
+
 func (e *GeneralOrderExecutor) Bind() {
 	e.activeMakerOrders.BindStream(e.session.UserDataStream)
 	e.orderStore.BindStream(e.session.UserDataStream)
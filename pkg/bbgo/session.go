@@ -17,7 +17,9 @@ import (
 	"github.com/c9s/bbgo/pkg/util/templateutil"
 
 	exchange2 "github.com/c9s/bbgo/pkg/exchange"
+	"github.com/c9s/bbgo/pkg/exchange/paper"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -25,6 +27,12 @@ import (
 
 var KLinePreloadLimit int64 = 1000
 
+// PaperTrading is the settings of the paper trading session
+type PaperTrading struct {
+	// Balances is the initial balances of the simulated account
+	Balances BacktestAccountBalanceMap `json:"balances" yaml:"balances"`
+}
+
 // ExchangeSession presents the exchange connection Session
 // It also maintains and collects the data returned from the stream.
 type ExchangeSession struct {
@@ -56,6 +64,10 @@ type ExchangeSession struct {
 	IsolatedFutures       bool   `json:"isolatedFutures,omitempty" yaml:"isolatedFutures,omitempty"`
 	IsolatedFuturesSymbol string `json:"isolatedFuturesSymbol,omitempty" yaml:"isolatedFuturesSymbol,omitempty"`
 
+	// Paper runs the session as a paper trading session, the market data is streamed from the exchange,
+	// while the orders are matched by the local matching engine with the simulated balances, no api key is required.
+	Paper *PaperTrading `json:"paper,omitempty" yaml:"paper,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -104,6 +116,10 @@ type ExchangeSession struct {
 	// standard indicators of each market
 	standardIndicatorSets map[string]*StandardIndicatorSet
 
+	// klineLoader and klinePreloadEndTime are used for the indicator warm-up
+	klineLoader         KLineLoader
+	klinePreloadEndTime time.Time
+
 	orderStores map[string]*OrderStore
 
 	usedSymbols        map[string]struct{}
@@ -329,6 +345,11 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 		return nil
 	}
 
+	if session.klineLoader == nil {
+		session.klineLoader = newKLineLoader(environ, session)
+		session.klinePreloadEndTime = environ.startTime
+	}
+
 	market, ok := session.markets[symbol]
 	if !ok {
 		return fmt.Errorf("market %s is not defined", symbol)
@@ -389,6 +410,8 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 
 	if _, ok := session.standardIndicatorSets[symbol]; !ok {
 		standardIndicatorSet := NewStandardIndicatorSet(symbol, session.MarketDataStream, marketDataStore)
+		standardIndicatorSet.preloader = session.IndicatorPreloader(symbol)
+		standardIndicatorSet.ctx = ctx
 		session.standardIndicatorSets[symbol] = standardIndicatorSet
 	}
 
@@ -471,10 +494,29 @@ func (session *ExchangeSession) StandardIndicatorSet(symbol string) *StandardInd
 
 	store, _ := session.MarketDataStore(symbol)
 	set = NewStandardIndicatorSet(symbol, session.MarketDataStream, store)
+	set.preloader = session.IndicatorPreloader(symbol)
 	session.standardIndicatorSets[symbol] = set
 	return set
 }
 
+// IndicatorPreloader returns the preloader that warms up the indicators of the symbol with the klines in the market data store
+// and the older klines loaded from the database or the exchange.
+func (session *ExchangeSession) IndicatorPreloader(symbol string) *IndicatorPreloader {
+	store, _ := session.MarketDataStore(symbol)
+	return &IndicatorPreloader{
+		Symbol:  symbol,
+		Store:   store,
+		Loader:  session.klineLoader,
+		EndTime: session.klinePreloadEndTime,
+	}
+}
+
+// PreloadIndicator pushes the historical klines of the interval to the indicator, it should be called before the
+// market data stream connects, e.g., in the Run method of the strategy.
+func (session *ExchangeSession) PreloadIndicator(ctx context.Context, symbol string, interval types.Interval, inc indicator.KLinePusher) error {
+	return session.IndicatorPreloader(symbol).Preload(ctx, inc, interval)
+}
+
 func (session *ExchangeSession) Position(symbol string) (pos *types.Position, ok bool) {
 	pos, ok = session.positions[symbol]
 	if ok {
@@ -689,7 +731,8 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 	var err error
 	var exchangeName = session.ExchangeName
 	if ex == nil {
-		if session.PublicOnly {
+		if session.PublicOnly || session.Paper != nil {
+			// the paper trading session only uses the public market data of the exchange
 			ex, err = exchange2.NewPublic(exchangeName)
 		} else {
 			if session.Key != "" && session.Secret != "" {
@@ -731,6 +774,23 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 		}
 	}
 
+	if session.Paper != nil {
+		if session.Margin || session.Futures {
+			return fmt.Errorf("paper trading session %s does not support margin or futures", name)
+		}
+
+		paperExchange := paper.New(ex, session.Paper.Balances.BalanceMap())
+		feeRates := paperExchange.DefaultFeeRates()
+		if !session.MakerFeeRate.IsZero() {
+			feeRates.MakerFeeRate = session.MakerFeeRate
+		}
+		if !session.TakerFeeRate.IsZero() {
+			feeRates.TakerFeeRate = session.TakerFeeRate
+		}
+		paperExchange.SetFeeRates(feeRates)
+		ex = paperExchange
+	}
+
 	session.Name = name
 	session.Exchange = ex
 	session.UserDataStream = ex.NewStream()
@@ -0,0 +1,42 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/paper"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeSession_InitExchange_Paper(t *testing.T) {
+	session := &ExchangeSession{
+		ExchangeName: types.ExchangeBinance,
+		MakerFeeRate: fixedpoint.NewFromFloat(0.0002),
+		Paper: &PaperTrading{
+			Balances: BacktestAccountBalanceMap{"USDT": fixedpoint.NewFromInt(10000)},
+		},
+	}
+
+	// no api key is required for the paper trading session
+	if !assert.NoError(t, session.InitExchange("binance-paper", nil)) {
+		return
+	}
+
+	ex, ok := session.Exchange.(*paper.Exchange)
+	if assert.True(t, ok) {
+		assert.Equal(t, types.ExchangeBinance, ex.Name())
+		assert.Equal(t, fixedpoint.NewFromFloat(0.0002), ex.DefaultFeeRates().MakerFeeRate)
+	}
+
+	assert.IsType(t, &paper.Stream{}, session.UserDataStream)
+	assert.True(t, session.MarketDataStream.GetPublicOnly())
+
+	session = &ExchangeSession{
+		ExchangeName: types.ExchangeBinance,
+		Futures:      true,
+		Paper:        &PaperTrading{},
+	}
+	assert.Error(t, session.InitExchange("binance-paper", nil))
+}
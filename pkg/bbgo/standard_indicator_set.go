@@ -1,6 +1,8 @@
 package bbgo
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/indicator"
@@ -31,8 +33,12 @@ type StandardIndicatorSet struct {
 	iwIndicators   map[indicatorKey]indicator.KLinePusher
 	macdIndicators map[indicator.MACDConfig]*indicator.MACD
 
-	stream types.Stream
-	store  *MarketDataStore
+	stream    types.Stream
+	store     *MarketDataStore
+	preloader *IndicatorPreloader
+
+	// ctx is the context of the symbol initialization, it cancels the indicator warm-up
+	ctx context.Context
 }
 
 type indicatorKey struct {
@@ -52,13 +58,19 @@ func NewStandardIndicatorSet(symbol string, stream types.Stream, store *MarketDa
 }
 
 func (s *StandardIndicatorSet) initAndBind(inc indicator.KLinePusher, interval types.Interval) {
-	if klines, ok := s.store.KLinesOfInterval(interval); ok {
-		for _, k := range *klines {
-			inc.PushK(k)
-		}
+	preloader := s.preloader
+	if preloader == nil {
+		preloader = &IndicatorPreloader{Symbol: s.Symbol, Store: s.store}
 	}
 
-	s.stream.OnKLineClosed(types.KLineWith(s.Symbol, interval, inc.PushK))
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := preloadAndBind(ctx, inc, interval, s.stream, preloader); err != nil {
+		logrus.WithError(err).Warnf("%s %T indicator warm-up error", s.Symbol, inc)
+	}
 }
 
 func (s *StandardIndicatorSet) allocateSimpleIndicator(t indicator.KLinePusher, iw types.IntervalWindow, id string) indicator.KLinePusher {
@@ -260,6 +260,15 @@ func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 			if symbol, ok := dynamic.LookupSymbolField(rs); ok {
 				log.Infof("found symbol %s based strategy from %s", symbol, rs.Type())
 
+				indicatorRegistry := findIndicatorRegistry(strategy)
+				if indicatorRegistry != nil {
+					if err := indicatorRegistry.Validate(); err != nil {
+						return errors.Wrapf(err, "invalid indicators of %T", strategy)
+					}
+
+					indicatorRegistry.Subscribe(session, symbol)
+				}
+
 				if err := session.initSymbol(ctx, trader.environment, symbol); err != nil {
 					return errors.Wrapf(err, "failed to inject object into %T when initSymbol", strategy)
 				}
@@ -288,6 +297,12 @@ func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 				); err != nil {
 					return errors.Wrapf(err, "failed to inject object into %T", strategy)
 				}
+
+				if indicatorRegistry != nil {
+					if err := indicatorRegistry.Bind(ctx, session.MarketDataStream, session.IndicatorPreloader(symbol)); err != nil {
+						return errors.Wrapf(err, "failed to bind indicators of %T", strategy)
+					}
+				}
 			}
 		}
 	}
@@ -266,6 +266,8 @@ var BacktestCmd = &cobra.Command{
 				return errors.Wrap(err, "failed to create backtest exchange")
 			}
 			session := environ.AddExchange(name.String(), backtestExchange)
+			session.Futures = backtestExchange.IsFutures()
+			session.Margin = backtestExchange.IsMargin()
 			exchangeFromConfig := userConfig.Sessions[name.String()]
 			if exchangeFromConfig != nil {
 				session.UseHeikinAshi = exchangeFromConfig.UseHeikinAshi
@@ -343,6 +345,29 @@ var BacktestCmd = &cobra.Command{
 			}
 		})
 
+		// drawdown tracking -- update the session equity per 1h kline
+		var sessionDrawdowns = make(map[string]*types.Drawdown)
+		kLineHandlers = append(kLineHandlers, func(k types.KLine, exSource *backtest.ExchangeDataSource) {
+			if k.Interval != types.Interval1h {
+				return
+			}
+
+			balances, err := exSource.Exchange.QueryAccountBalances(ctx)
+			if err != nil {
+				log.WithError(err).Errorf("query back-test account balance error")
+				return
+			}
+
+			drawdown, ok := sessionDrawdowns[exSource.Session.Name]
+			if !ok {
+				drawdown = &types.Drawdown{}
+				sessionDrawdowns[exSource.Session.Name] = drawdown
+			}
+
+			assets := balances.Assets(exSource.Session.AllLastPrices(), k.EndTime.Time())
+			drawdown.Update(assets.InUSD())
+		})
+
 		if generatingReport {
 			if reportFileInSubDir {
 				// reportDir = filepath.Join(reportDir, backtestSessionName)
@@ -523,6 +548,21 @@ var BacktestCmd = &cobra.Command{
 			summaryReport.Intervals = append(summaryReport.Intervals, interval)
 		}
 
+		for _, drawdown := range sessionDrawdowns {
+			summaryReport.MaxDrawdown = fixedpoint.Max(summaryReport.MaxDrawdown, drawdown.Max)
+		}
+
+		for _, session := range environ.Sessions() {
+			if backtestExchange, ok := session.Exchange.(*backtest.Exchange); ok {
+				if marginReport := backtestExchange.MarginReport(); marginReport != nil {
+					if summaryReport.MarginReports == nil {
+						summaryReport.MarginReports = make(map[string]*backtest.MarginReport)
+					}
+					summaryReport.MarginReports[session.Name] = marginReport
+				}
+			}
+		}
+
 		for _, session := range environ.Sessions() {
 			for symbol, trades := range session.Trades {
 				tradeState := sessionTradeStats[session.Name][symbol]
@@ -542,6 +582,7 @@ var BacktestCmd = &cobra.Command{
 				summaryReport.FinalEquityValue = summaryReport.FinalEquityValue.Add(symbolReport.FinalEquityValue())
 				summaryReport.TotalGrossProfit.Add(symbolReport.PnL.GrossProfit)
 				summaryReport.TotalGrossLoss.Add(symbolReport.PnL.GrossLoss)
+				summaryReport.TotalSlippageCost = summaryReport.TotalSlippageCost.Add(symbolReport.SlippageCost)
 
 				// write report to a file
 				if generatingReport {
@@ -585,6 +626,14 @@ var BacktestCmd = &cobra.Command{
 			color.Green("END TIME: %s\n", endTime.Format(time.RFC1123))
 			color.Green("INITIAL TOTAL BALANCE: %v\n", initTotalBalances)
 			color.Green("FINAL TOTAL BALANCE: %v\n", finalTotalBalances)
+			color.Green("MAX DRAWDOWN: %s\n", summaryReport.MaxDrawdown.Percentage())
+			for sessionName, marginReport := range summaryReport.MarginReports {
+				color.Green("%s MARGIN LOANS: %d, REPAYS: %d, INTERESTS: %v\n", sessionName, marginReport.Loans, marginReport.Repays, marginReport.Interests)
+				if marginReport.Liquidations > 0 {
+					color.Red("%s MARGIN LIQUIDATIONS: %d\n", sessionName, marginReport.Liquidations)
+				}
+			}
+
 			for _, symbolReport := range summaryReport.SymbolReports {
 				symbolReport.Print(wantBaseAssetBaseline)
 			}
@@ -645,6 +694,11 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 		Sortino:      sortinoRatio,
 		ProfitFactor: profitFactor,
 		WinningRatio: winningRatio,
+
+		OrderLatency:  accountConfig.OrderLatency.Duration(),
+		CancelLatency: accountConfig.CancelLatency.Duration(),
+		SlippageCost:  backtestExchange.SlippageCost(symbol),
+		Futures:       backtestExchange.FuturesReport(symbol),
 	}
 
 	for _, s := range session.Subscriptions {
@@ -726,6 +780,12 @@ func sync(ctx context.Context, userConfig *bbgo.Config, backtestService *service
 					return err
 				}
 			}
+
+			if len(userConfig.Backtest.AggTradeDataDir) > 0 {
+				if err := backtest.ImportAggTrades(ctx, backtestService, userConfig.Backtest.AggTradeDataDir, sourceExchange.Name(), symbol); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
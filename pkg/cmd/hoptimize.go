@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/c9s/bbgo/pkg/optimizer"
+	"github.com/c9s/bbgo/pkg/service"
 	"github.com/fatih/color"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -20,9 +21,12 @@ func init() {
 	hoptimizeCmd.Flags().String("optimizer-config", "optimizer.yaml", "config file")
 	hoptimizeCmd.Flags().String("name", "", "assign an optimization session name")
 	hoptimizeCmd.Flags().Bool("json-keep-all", false, "keep all results of trials")
-	hoptimizeCmd.Flags().String("output", "output", "backtest report output directory")
+	hoptimizeCmd.PersistentFlags().String("output", "output", "backtest report output directory")
 	hoptimizeCmd.Flags().Bool("json", false, "print optimizer metrics in json format")
 	hoptimizeCmd.Flags().Bool("tsv", false, "print optimizer metrics in csv format")
+	hoptimizeCmd.Flags().Bool("pareto-front", false, "print the Pareto-optimal trials of the multiple objectives only")
+	hoptimizeCmd.Flags().String("resume", "", "resume the persisted optimization study by the session name")
+	hoptimizeCmd.PersistentFlags().String("study-db", "", "the sqlite3 file of the optimization studies, the study is only persisted when it's set or --resume is given, default to the configured database or hoptimize.sqlite3 in the output directory")
 	RootCmd.AddCommand(hoptimizeCmd)
 }
 
@@ -69,6 +73,23 @@ var hoptimizeCmd = &cobra.Command{
 			return err
 		}
 
+		paretoFrontOnly, err := cmd.Flags().GetBool("pareto-front")
+		if err != nil {
+			return err
+		}
+
+		resumeSessionName, err := cmd.Flags().GetString("resume")
+		if err != nil {
+			return err
+		}
+
+		if len(resumeSessionName) > 0 {
+			if len(optSessionName) > 0 && optSessionName != resumeSessionName {
+				return fmt.Errorf("the session name %s conflicts with the resumed study %s", optSessionName, resumeSessionName)
+			}
+			optSessionName = resumeSessionName
+		}
+
 		yamlBody, err := ioutil.ReadFile(configFile)
 		if err != nil {
 			return err
@@ -105,37 +126,61 @@ var hoptimizeCmd = &cobra.Command{
 		if len(optSessionName) == 0 {
 			optSessionName = fmt.Sprintf("bbgo-hpopt-%v", time.Now().UnixMilli())
 		}
+
+		studyDB, err := cmd.Flags().GetString("study-db")
+		if err != nil {
+			return err
+		}
+
+		// the study is only persisted when it's requested
+		var studyService *service.OptimizerStudyService
+		if len(studyDB) > 0 || len(resumeSessionName) > 0 {
+			studyService, err = newOptimizerStudyService(ctx, cmd, outputDirectory)
+			if err != nil {
+				return err
+			}
+		}
+
 		tempDirNameFormat := fmt.Sprintf("%s-config-*", optSessionName)
 		configDir, err := os.MkdirTemp("", tempDirNameFormat)
 		if err != nil {
 			return err
 		}
 
-		executor := &optimizer.LocalProcessExecutor{
-			Config:    optConfig.Executor.LocalExecutorConfig,
-			Bin:       os.Args[0],
-			WorkDir:   ".",
-			ConfigDir: configDir,
-			OutputDir: outputDirectory,
+		executor, err := newOptimizerExecutor(ctx, optConfig, configDir, outputDirectory)
+		if err != nil {
+			return err
 		}
+		defer closeOptimizerExecutor(executor)
 
 		optz := &optimizer.HyperparameterOptimizer{
-			SessionName: optSessionName,
-			Config:      optConfig,
+			SessionName:  optSessionName,
+			Config:       optConfig,
+			StudyService: studyService,
+			Resume:       len(resumeSessionName) > 0,
 		}
 
 		if err := executor.Prepare(configJson); err != nil {
 			return err
 		}
 
-		report, err := optz.Run(ctx, executor, configJson)
+		var report *optimizer.HyperparameterOptimizeReport
+		if optConfig.WalkForward != nil {
+			report, err = optz.RunWalkForward(ctx, executor, configJson)
+		} else {
+			report, err = optz.Run(ctx, executor, configJson)
+		}
 		log.Info("All test trial finished.")
 		if err != nil {
 			return err
 		}
 
+		if paretoFrontOnly {
+			report.Trials = report.ParetoFront
+		}
+
 		if printJsonFormat {
-			if !jsonKeepAll {
+			if !jsonKeepAll && !paretoFrontOnly {
 				report.Trials = nil
 			}
 			out, err := json.MarshalIndent(report, "", "  ")
@@ -153,7 +198,14 @@ var hoptimizeCmd = &cobra.Command{
 			color.Green("OPTIMIZER REPORT")
 			color.Green("===============================================\n")
 			color.Green("SESSION NAME: %s\n", report.Name)
-			color.Green("OPTIMIZE OBJECTIVE: %s\n", report.Objective)
+			if len(report.Objectives) > 0 {
+				color.Green("OPTIMIZE OBJECTIVES:")
+				for _, objective := range report.Objectives {
+					color.Green("  - %s: %s, weight %s", objective.By, objective.Direction, objective.Weight.String())
+				}
+			} else {
+				color.Green("OPTIMIZE OBJECTIVE: %s\n", report.Objective)
+			}
 			color.Green("BEST OBJECTIVE VALUE: %s\n", report.Best.Value)
 			color.Green("OPTIMAL PARAMETERS:")
 			for _, selectorConfig := range optConfig.Matrix {
@@ -164,6 +216,28 @@ var hoptimizeCmd = &cobra.Command{
 					color.Red("  - %s: (invalid parameter definition)", label)
 				}
 			}
+
+			if len(report.ParetoFront) > 0 {
+				color.Green("PARETO FRONT:")
+				for _, trial := range report.ParetoFront {
+					color.Green("  - TRIAL #%d: VALUE: %s, METRICS: %v, PARAMETERS: %v", *trial.ID, trial.Value.String(), trial.Metrics, trial.Parameters)
+				}
+			}
+
+			if report.OutOfSampleProfit != nil {
+				color.Green("WALK-FORWARD WINDOWS:")
+				for _, trial := range report.Trials {
+					wf := trial.WalkForward
+					color.Green("  - %s ~ %s: IN-SAMPLE VALUE: %s, OUT-OF-SAMPLE VALUE: %s, OUT-OF-SAMPLE PROFIT: %s",
+						wf.OutOfSampleStartTime.Format(time.RFC3339), wf.OutOfSampleEndTime.Format(time.RFC3339),
+						trial.Value.String(), wf.OutOfSampleValue.String(), wf.OutOfSampleProfit.String())
+				}
+				color.Green("TOTAL OUT-OF-SAMPLE PROFIT: %s", report.OutOfSampleProfit.String())
+				color.Green("PARAMETER STABILITY (COEFFICIENT OF VARIATION):")
+				for label, cv := range report.ParameterStability {
+					color.Green("  - %s: %s", label, cv.String())
+				}
+			}
 		}
 
 		return nil
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/optimizer"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/style"
+)
+
+func init() {
+	hoptimizeExportCmd.Flags().Bool("tsv", false, "export the trials in tsv format")
+	hoptimizeCmd.AddCommand(hoptimizeListCmd)
+	hoptimizeCmd.AddCommand(hoptimizeExportCmd)
+}
+
+// newOptimizerStudyService connects to the database of the optimization studies, the sqlite3 file given by the
+// --study-db flag is used first, then the configured database, then hoptimize.sqlite3 in the output directory
+func newOptimizerStudyService(ctx context.Context, cmd *cobra.Command, outputDirectory string) (*service.OptimizerStudyService, error) {
+	studyDB, err := cmd.Flags().GetString("study-db")
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseService *service.DatabaseService
+	if len(studyDB) == 0 {
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return nil, err
+		}
+
+		databaseService = environ.DatabaseService
+		if databaseService == nil {
+			if err := os.MkdirAll(outputDirectory, 0755); err != nil {
+				return nil, err
+			}
+
+			studyDB = filepath.Join(outputDirectory, "hoptimize.sqlite3")
+		}
+	}
+
+	if databaseService == nil {
+		databaseService = service.NewDatabaseService("sqlite3", studyDB)
+		if err := databaseService.Connect(); err != nil {
+			return nil, err
+		}
+
+		if err := databaseService.Upgrade(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return service.NewOptimizerStudyService(databaseService.DB), nil
+}
+
+var hoptimizeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the persisted optimization studies",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		outputDirectory, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		studyService, err := newOptimizerStudyService(ctx, cmd, outputDirectory)
+		if err != nil {
+			return err
+		}
+
+		summaries, err := studyService.QueryStudySummaries(ctx)
+		if err != nil {
+			return err
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(*style.NewDefaultTableStyle())
+		t.AppendHeader(table.Row{"Name", "Trials", "Complete Trials", "Best Value", "Created At", "Updated At"})
+		for _, summary := range summaries {
+			bestValue := "-"
+			if summary.BestValue.Valid {
+				bestValue = fmt.Sprintf("%f", summary.BestValue.Float64)
+			}
+
+			t.AppendRow(table.Row{
+				summary.Name,
+				summary.NumOfTrials,
+				summary.NumOfCompleteTrials,
+				bestValue,
+				summary.CreatedAt.Format(time.RFC3339),
+				summary.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		t.Render()
+		return nil
+	},
+}
+
+var hoptimizeExportCmd = &cobra.Command{
+	Use:   "export [study]",
+	Short: "export the trials of the persisted optimization study",
+	Args:  cobra.ExactArgs(1),
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		outputDirectory, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		printTsvFormat, err := cmd.Flags().GetBool("tsv")
+		if err != nil {
+			return err
+		}
+
+		studyService, err := newOptimizerStudyService(ctx, cmd, outputDirectory)
+		if err != nil {
+			return err
+		}
+
+		report, err := optimizer.LoadStudyReport(ctx, studyService, args[0])
+		if err != nil {
+			return err
+		}
+
+		if printTsvFormat {
+			return optimizer.FormatResultsTsv(os.Stdout, report.Parameters, report.Trials)
+		}
+
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+		return nil
+	},
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
@@ -84,20 +86,17 @@ var optimizeCmd = &cobra.Command{
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
-		_ = ctx
 
 		configDir, err := os.MkdirTemp("", "bbgo-config-*")
 		if err != nil {
 			return err
 		}
 
-		executor := &optimizer.LocalProcessExecutor{
-			Config:    optConfig.Executor.LocalExecutorConfig,
-			Bin:       os.Args[0],
-			WorkDir:   ".",
-			ConfigDir: configDir,
-			OutputDir: outputDirectory,
+		executor, err := newOptimizerExecutor(ctx, optConfig, configDir, outputDirectory)
+		if err != nil {
+			return err
 		}
+		defer closeOptimizerExecutor(executor)
 
 		optz := &optimizer.GridOptimizer{
 			Config: optConfig,
@@ -144,3 +143,45 @@ var optimizeCmd = &cobra.Command{
 		return nil
 	},
 }
+
+type optimizerExecutor interface {
+	optimizer.Executor
+
+	// Prepare prepares the backtest data before running the trials
+	Prepare(configJson []byte) error
+}
+
+// newOptimizerExecutor creates the executor by the executor type, the coordinator of the remote executor is started
+// and stopped when the context is canceled.
+func newOptimizerExecutor(ctx context.Context, optConfig *optimizer.Config, configDir, outputDirectory string) (optimizerExecutor, error) {
+	switch optConfig.Executor.Type {
+	case optimizer.ExecutorTypeRemote:
+		executor, err := optimizer.NewRemoteExecutor(optConfig.Executor.RemoteExecutorConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := executor.Start(ctx); err != nil {
+			return nil, err
+		}
+
+		return executor, nil
+
+	default:
+		return &optimizer.LocalProcessExecutor{
+			Config:    optConfig.Executor.LocalExecutorConfig,
+			Bin:       os.Args[0],
+			WorkDir:   ".",
+			ConfigDir: configDir,
+			OutputDir: outputDirectory,
+		}, nil
+	}
+}
+
+func closeOptimizerExecutor(executor optimizerExecutor) {
+	if closer, ok := executor.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.WithError(err).Error("optimizer executor close error")
+		}
+	}
+}
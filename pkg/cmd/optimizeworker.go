@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/optimizer"
+)
+
+func init() {
+	optimizeWorkerCmd.Flags().String("coordinator", "http://localhost:9091", "the coordinator url of the remote optimizer executor")
+	optimizeWorkerCmd.Flags().String("token", "", "the shared token of the coordinator")
+	optimizeWorkerCmd.Flags().String("name", "", "the worker name, default to the hostname")
+	optimizeWorkerCmd.Flags().Int("processes", 1, "the number of the concurrent backtest processes")
+	optimizeWorkerCmd.Flags().Duration("poll-interval", 0, "the interval of polling the coordinator when there is no pending trial")
+	optimizeWorkerCmd.Flags().String("output", "output", "backtest report output directory")
+	RootCmd.AddCommand(optimizeWorkerCmd)
+}
+
+var optimizeWorkerCmd = &cobra.Command{
+	Use:   "optimize-worker",
+	Short: "run the optimizer worker, which pulls the trials from the coordinator of the remote executor",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coordinatorURL, err := cmd.Flags().GetString("coordinator")
+		if err != nil {
+			return err
+		}
+
+		token, err := cmd.Flags().GetString("token")
+		if err != nil {
+			return err
+		}
+
+		workerName, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+
+		numOfProcesses, err := cmd.Flags().GetInt("processes")
+		if err != nil {
+			return err
+		}
+
+		pollInterval, err := cmd.Flags().GetDuration("poll-interval")
+		if err != nil {
+			return err
+		}
+
+		outputDirectory, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		if len(workerName) == 0 {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return err
+			}
+			workerName = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+			<-c
+			log.Info("stopping the optimizer worker...")
+			cancel()
+		}()
+
+		configDir, err := os.MkdirTemp("", "bbgo-worker-config-*")
+		if err != nil {
+			return err
+		}
+
+		executor := &optimizer.LocalProcessExecutor{
+			Config:    &optimizer.LocalExecutorConfig{MaxNumberOfProcesses: numOfProcesses},
+			Bin:       os.Args[0],
+			WorkDir:   ".",
+			ConfigDir: configDir,
+			OutputDir: outputDirectory,
+		}
+
+		worker := &optimizer.RemoteWorker{
+			CoordinatorURL: coordinatorURL,
+			Name:           workerName,
+			Token:          token,
+			Executor:       executor,
+			NumOfProcesses: numOfProcesses,
+			PollInterval:   pollInterval,
+		}
+
+		log.Infof("worker %s is waiting for the coordinator %s", workerName, coordinatorURL)
+		configJson, err := worker.FetchConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		// sync the backtest data before pulling the trials
+		if err := executor.Prepare(configJson); err != nil {
+			return err
+		}
+
+		log.Infof("worker %s is ready, pulling trials with %d processes", workerName, numOfProcesses)
+		if err := worker.Run(ctx); err != nil && err != context.Canceled {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -400,7 +400,7 @@ func init() {
 
 	getOrderCmd.Flags().String("session", "", "the exchange session name for sync")
 	getOrderCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
-	getOrderCmd.Flags().String("order-id", "", "order id")
+	getOrderCmd.Flags().String("order-id", "", "order id, or the order uuid for the exchanges identifying the orders by the uuid, e.g., kucoin")
 
 	submitOrderCmd.Flags().String("session", "", "the exchange session name for sync")
 	submitOrderCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
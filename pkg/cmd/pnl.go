@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -24,13 +27,16 @@ func init() {
 	PnLCmd.Flags().Bool("sync", false, "sync before loading trades")
 	PnLCmd.Flags().String("since", "", "query trades from a time point")
 	PnLCmd.Flags().Uint64("limit", 0, "number of trades")
+	PnLCmd.Flags().String("method", string(pnl.MethodAverageCost), "cost basis method: average, fifo, lifo or specific")
+	PnLCmd.Flags().String("lots", "", "the csv file of the sell_trade_id,buy_trade_id rows for the specific identification method")
+	PnLCmd.Flags().String("csv-dir", "", "export the realized lots of each tax year as csv files to the directory, not supported by the average method")
 	RootCmd.AddCommand(PnLCmd)
 }
 
 var PnLCmd = &cobra.Command{
 	Use:          "pnl",
-	Short:        "Average Cost Based PnL Calculator",
-	Long:         "This command calculates the average cost-based profit from your total trades",
+	Short:        "PnL Calculator",
+	Long:         "This command calculates the profit from your total trades with the average cost, FIFO, LIFO or specific identification method",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -86,6 +92,48 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		methodOpt, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		method, err := pnl.ParseMethod(methodOpt)
+		if err != nil {
+			return err
+		}
+
+		lotsFile, err := cmd.Flags().GetString("lots")
+		if err != nil {
+			return err
+		}
+
+		var specificLots map[uint64][]uint64
+		if method == pnl.MethodSpecificID {
+			if len(lotsFile) == 0 {
+				return errors.New("--lots [FILE] is required by the specific identification method")
+			}
+
+			f, err := os.Open(lotsFile)
+			if err != nil {
+				return err
+			}
+
+			specificLots, err = pnl.ReadSpecificLots(f)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		csvDir, err := cmd.Flags().GetString("csv-dir")
+		if err != nil {
+			return err
+		}
+
+		if len(csvDir) > 0 && method == pnl.MethodAverageCost {
+			return errors.New("--csv-dir is not supported by the average cost method")
+		}
+
 		environ := bbgo.NewEnvironment()
 
 		if err := environ.ConfigureDatabase(ctx); err != nil {
@@ -190,16 +238,58 @@ var PnLCmd = &cobra.Command{
 		}
 
 		currentPrice := currentTick.Last
-		calculator := &pnl.AverageCostCalculator{
-			TradingFeeCurrency: tradingFeeCurrency,
-			Market:             market,
+		calculator, err := pnl.NewCalculator(method, market, tradingFeeCurrency, specificLots)
+		if err != nil {
+			return err
 		}
 
-		report := calculator.Calculate(symbol, trades, currentPrice)
+		report := calculator.Report(symbol, trades, currentPrice)
 		report.Print()
 
+		if lotReport, ok := report.(*pnl.LotPnLReport); ok && len(csvDir) > 0 {
+			if err := writeLotReportCsvFiles(csvDir, lotReport); err != nil {
+				return err
+			}
+		}
+
 		log.Warnf("note that if you're using cross-exchange arbitrage, the PnL won't be accurate")
 		log.Warnf("withdrawal and deposits are not considered in the PnL")
 		return nil
 	},
 }
+
+// writeLotReportCsvFiles writes the realized lots of each tax year to the csv file named by the symbol, the method and the year
+func writeLotReportCsvFiles(dir string, report *pnl.LotPnLReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, year := range report.TaxYears() {
+		filename := filepath.Join(dir, fmt.Sprintf("%s-%s-%d.csv", report.Symbol, report.Method, year))
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+
+		lots := report.RealizedLotsOfYear(year)
+		w := csv.NewWriter(f)
+		if err := w.Write(lots.CsvHeader()); err != nil {
+			_ = f.Close()
+			return err
+		}
+
+		// WriteAll flushes the writer
+		if err := w.WriteAll(lots.CsvRecords()); err != nil {
+			_ = f.Close()
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		log.Infof("%d realized lots of %d are written to %s", len(lots), year, filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/accounting/nav"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	navReportCmd.Flags().String("session", "ALL", "the session of the nav history, ALL is the total of the sessions recorded by xnav")
+	navReportCmd.Flags().String("since", "", "report from a time point, defaults to 30 days ago")
+	navReportCmd.Flags().String("until", "", "report until a time point, defaults to now")
+	navReportCmd.Flags().String("format", "table", "output format: table, csv or json")
+	navReportCmd.Flags().String("output", "", "write the report to the file instead of stdout")
+	reportCmd.AddCommand(navReportCmd)
+	RootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "generate reports from the synchronized data",
+}
+
+// go run ./cmd/bbgo report nav --since 2022-01-01 --format csv
+var navReportCmd = &cobra.Command{
+	Use:          "nav",
+	Short:        "net asset value returns and performance attribution",
+	Long:         "This command reads the nav history recorded by xnav, the trades, the deposits, the withdrawals, the rewards, the profits and the margin interests from the database, and reports the time-weighted and the money-weighted returns, the asset and the strategy contributions and the fee and the interest drags.",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		until := time.Now()
+		since := until.AddDate(0, 0, -30)
+
+		sinceOpt, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		if sinceOpt != "" {
+			lt, err := types.ParseLooseFormatTime(sinceOpt)
+			if err != nil {
+				return err
+			}
+			since = lt.Time()
+		}
+
+		untilOpt, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if untilOpt != "" {
+			lt, err := types.ParseLooseFormatTime(untilOpt)
+			if err != nil {
+				return err
+			}
+			until = lt.Time()
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "table", "csv", "json":
+		default:
+			return fmt.Errorf("unsupported format %q, available formats: table, csv and json", format)
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return errors.New("database is not configured")
+		}
+
+		details, err := environ.AccountService.QueryNavHistory(sessionName, since, until)
+		if err != nil {
+			return err
+		}
+
+		records := nav.Records{Snapshots: nav.NewSnapshots(details)}
+		if len(records.Snapshots) < 2 {
+			return fmt.Errorf("%d nav snapshots of session %s are found, at least 2 snapshots are required, please run the xnav strategy to record the nav history", len(records.Snapshots), sessionName)
+		}
+
+		if records.Deposits, err = environ.DepositService.QueryByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		if records.Withdraws, err = environ.WithdrawService.QueryByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		if records.Rewards, err = environ.RewardService.QueryByTimeRange(ctx, since, until); err != nil {
+			return err
+		}
+
+		if records.Trades, err = environ.TradeService.QueryByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		if records.Profits, err = environ.ProfitService.QueryByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		if records.Interests, err = environ.MarginService.QueryInterestsByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		if records.FundingFees, err = environ.FundingFeeService.QueryByTimeRange(since, until); err != nil {
+			return err
+		}
+
+		// the records of a single session are filtered by the exchange of the session
+		if sessionName != "ALL" {
+			var exchanges []types.ExchangeName
+			for _, d := range details {
+				exchanges = append(exchanges, d.Exchange)
+			}
+			records = records.FilterExchanges(exchanges...)
+		}
+
+		log.Infof("loaded %d nav snapshots, %d deposits, %d withdrawals, %d rewards, %d trades, %d profits, %d margin interests and %d funding fees",
+			len(records.Snapshots), len(records.Deposits), len(records.Withdraws), len(records.Rewards),
+			len(records.Trades), len(records.Profits), len(records.Interests), len(records.FundingFees))
+
+		report, err := nav.Analyze(records)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		switch format {
+		case "json":
+			out, err := report.JSON()
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(w, string(out))
+			return err
+
+		case "csv":
+			cw := csv.NewWriter(w)
+			if err := cw.Write(report.CsvHeader()); err != nil {
+				return err
+			}
+
+			return cw.WriteAll(report.CsvRecords())
+		}
+
+		report.Print(w)
+		return nil
+	},
+}
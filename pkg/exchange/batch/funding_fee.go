@@ -0,0 +1,37 @@
+package batch
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type FundingFeeBatchQuery struct {
+	types.FundingFeeHistory
+}
+
+func (e *FundingFeeBatchQuery) Query(ctx context.Context, symbol string, startTime, endTime time.Time) (c chan types.FundingFee, errC chan error) {
+	query := &AsyncTimeRangedBatchQuery{
+		Type:        types.FundingFee{},
+		Limiter:     rate.NewLimiter(rate.Every(5*time.Second), 2),
+		JumpIfEmpty: time.Hour * 24 * 30,
+		Q: func(startTime, endTime time.Time) (interface{}, error) {
+			return e.QueryFundingFeeHistory(ctx, symbol, &startTime, &endTime)
+		},
+		T: func(obj interface{}) time.Time {
+			return time.Time(obj.(types.FundingFee).Time)
+		},
+		ID: func(obj interface{}) string {
+			fee := obj.(types.FundingFee)
+			return strconv.FormatUint(fee.TransactionID, 10)
+		},
+	}
+
+	c = make(chan types.FundingFee, 100)
+	errC = query.Query(ctx, c, startTime, endTime)
+	return c, errC
+}
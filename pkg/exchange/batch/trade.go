@@ -18,6 +18,10 @@ type TradeBatchQuery struct {
 }
 
 func (e TradeBatchQuery) Query(ctx context.Context, symbol string, options *types.TradeQueryOptions) (c chan types.Trade, errC chan error) {
+	// copy the options, the query advances the start time and the last trade id of its own copy
+	opts := *options
+	options = &opts
+
 	if options.EndTime == nil {
 		now := time.Now()
 		options.EndTime = &now
@@ -28,6 +32,8 @@ func (e TradeBatchQuery) Query(ctx context.Context, symbol string, options *type
 	query := &AsyncTimeRangedBatchQuery{
 		Type: types.Trade{},
 		Q: func(startTime, endTime time.Time) (interface{}, error) {
+			// advance the start time for the exchanges that query the trades by the time range instead of the last trade id
+			options.StartTime = &startTime
 			return e.ExchangeTradeHistoryService.QueryTrades(ctx, symbol, options)
 		},
 		T: func(obj interface{}) time.Time {
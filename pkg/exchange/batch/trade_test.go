@@ -0,0 +1,51 @@
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// fakeTradeHistoryService returns a trade a minute after the start time until the last trade id reaches 3
+type fakeTradeHistoryService struct{}
+
+func (s *fakeTradeHistoryService) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	if options.LastTradeID >= 3 {
+		return nil, nil
+	}
+
+	return []types.Trade{{
+		ID:     options.LastTradeID + 1,
+		Symbol: symbol,
+		Time:   types.Time(options.StartTime.Add(time.Minute)),
+	}}, nil
+}
+
+func (s *fakeTradeHistoryService) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return nil, nil
+}
+
+func TestTradeBatchQuery_Options(t *testing.T) {
+	startTime := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	options := &types.TradeQueryOptions{StartTime: &startTime}
+
+	query := &TradeBatchQuery{ExchangeTradeHistoryService: &fakeTradeHistoryService{}}
+	tradeC, errC := query.Query(context.Background(), "BTCUSDT", options)
+
+	var trades []types.Trade
+	for trade := range tradeC {
+		trades = append(trades, trade)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Len(t, trades, 3)
+
+	// the options of the caller are not modified
+	assert.Equal(t, startTime, *options.StartTime)
+	assert.Nil(t, options.EndTime)
+	assert.Equal(t, uint64(0), options.LastTradeID)
+}
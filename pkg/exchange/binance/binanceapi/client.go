@@ -45,7 +45,13 @@ var defaultTransport = &http.Transport{
 
 var DefaultHttpClient = &http.Client{
 	Timeout:   defaultHTTPTimeout,
-	Transport: defaultTransport,
+	Transport: newRateLimitTransport(defaultTransport, SpotRequestWeightBudget, spotRequestWeight),
+}
+
+// DefaultFuturesHttpClient is the http client of the futures api, the futures api weights are counted separately
+var DefaultFuturesHttpClient = &http.Client{
+	Timeout:   defaultHTTPTimeout,
+	Transport: newRateLimitTransport(defaultTransport, FuturesRequestWeightBudget, futuresRequestWeight),
 }
 
 type RestClient struct {
@@ -0,0 +1,112 @@
+package binanceapi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// usedWeightHeader is the header of the used request weight of the ip in the current minute
+const usedWeightHeader = "X-MBX-USED-WEIGHT-1M"
+
+// SpotRequestWeightBudget is the ip request weight budget of the spot and margin api
+var SpotRequestWeightBudget = ratelimit.NewBudget("binance", 1200, time.Minute)
+
+// FuturesRequestWeightBudget is the ip request weight budget of the usdt-m futures api
+var FuturesRequestWeightBudget = ratelimit.NewBudget("binance-futures", 2400, time.Minute)
+
+// spotRequestWeights is the weights of the spot api endpoints that are not 1
+var spotRequestWeights = map[string]int{
+	"GET /api/v3/exchangeInfo": 10,
+	"GET /api/v3/order":        2,
+	"GET /api/v3/allOrders":    10,
+	"GET /api/v3/myTrades":     10,
+	"GET /api/v3/account":      10,
+}
+
+// futuresRequestWeights is the weights of the futures api endpoints that are not 1
+var futuresRequestWeights = map[string]int{
+	"GET /fapi/v1/allOrders":    5,
+	"GET /fapi/v1/userTrades":   5,
+	"GET /fapi/v2/account":      5,
+	"GET /fapi/v2/balance":      5,
+	"GET /fapi/v2/positionRisk": 5,
+	"GET /fapi/v1/income":       30,
+}
+
+// spotRequestWeight returns the weight of the spot api request
+func spotRequestWeight(req *http.Request) int {
+	query := req.URL.Query()
+	switch req.URL.Path {
+	case "/api/v3/depth":
+		return depthWeight(query.Get("limit"), []int{100, 500, 1000}, []int{1, 5, 10, 50})
+
+	case "/api/v3/ticker/24hr":
+		if len(query.Get("symbol")) == 0 {
+			return 40
+		}
+
+	case "/api/v3/openOrders":
+		if req.Method == http.MethodGet {
+			if len(query.Get("symbol")) == 0 {
+				return 40
+			}
+			return 3
+		}
+	}
+
+	if weight, ok := spotRequestWeights[req.Method+" "+req.URL.Path]; ok {
+		return weight
+	}
+
+	return 1
+}
+
+// futuresRequestWeight returns the weight of the futures api request
+func futuresRequestWeight(req *http.Request) int {
+	query := req.URL.Query()
+	switch req.URL.Path {
+	case "/fapi/v1/depth":
+		return depthWeight(query.Get("limit"), []int{50, 100, 500}, []int{2, 5, 10, 20})
+
+	case "/fapi/v1/ticker/24hr", "/fapi/v1/openOrders":
+		if req.Method == http.MethodGet && len(query.Get("symbol")) == 0 {
+			return 40
+		}
+	}
+
+	if weight, ok := futuresRequestWeights[req.Method+" "+req.URL.Path]; ok {
+		return weight
+	}
+
+	return 1
+}
+
+// depthWeight returns the weight of the depth request by the limit,
+// the weights are one more than the limit tiers, the last weight is used for the limits over the last tier
+func depthWeight(limit string, tiers []int, weights []int) int {
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		// the default limit is in the first tier
+		return weights[0]
+	}
+
+	for i, tier := range tiers {
+		if n <= tier {
+			return weights[i]
+		}
+	}
+
+	return weights[len(weights)-1]
+}
+
+func newRateLimitTransport(base http.RoundTripper, budget *ratelimit.Budget, weight func(req *http.Request) int) *ratelimit.Transport {
+	return &ratelimit.Transport{
+		Base:       base,
+		Budget:     budget,
+		Weight:     weight,
+		UsedWeight: ratelimit.HeaderUsedWeight(usedWeightHeader),
+	}
+}
@@ -0,0 +1,38 @@
+package binanceapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_spotRequestWeight(t *testing.T) {
+	newRequest := func(method, uri string) *http.Request {
+		req, _ := http.NewRequest(method, "https://api.binance.com"+uri, nil)
+		return req
+	}
+
+	assert.Equal(t, 1, spotRequestWeight(newRequest(http.MethodPost, "/api/v3/order")))
+	assert.Equal(t, 2, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/order?symbol=BTCUSDT")))
+	assert.Equal(t, 10, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/myTrades?symbol=BTCUSDT")))
+	assert.Equal(t, 3, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/openOrders?symbol=BTCUSDT")))
+	assert.Equal(t, 40, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/openOrders")))
+	assert.Equal(t, 1, spotRequestWeight(newRequest(http.MethodDelete, "/api/v3/openOrders?symbol=BTCUSDT")))
+	assert.Equal(t, 1, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/depth?symbol=BTCUSDT")))
+	assert.Equal(t, 10, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/depth?symbol=BTCUSDT&limit=1000")))
+	assert.Equal(t, 50, spotRequestWeight(newRequest(http.MethodGet, "/api/v3/depth?symbol=BTCUSDT&limit=5000")))
+}
+
+func Test_futuresRequestWeight(t *testing.T) {
+	newRequest := func(method, uri string) *http.Request {
+		req, _ := http.NewRequest(method, "https://fapi.binance.com"+uri, nil)
+		return req
+	}
+
+	assert.Equal(t, 1, futuresRequestWeight(newRequest(http.MethodPost, "/fapi/v1/order")))
+	assert.Equal(t, 5, futuresRequestWeight(newRequest(http.MethodGet, "/fapi/v2/positionRisk")))
+	assert.Equal(t, 40, futuresRequestWeight(newRequest(http.MethodGet, "/fapi/v1/openOrders")))
+	assert.Equal(t, 2, futuresRequestWeight(newRequest(http.MethodGet, "/fapi/v1/depth?symbol=BTCUSDT")))
+	assert.Equal(t, 20, futuresRequestWeight(newRequest(http.MethodGet, "/fapi/v1/depth?symbol=BTCUSDT&limit=1000")))
+}
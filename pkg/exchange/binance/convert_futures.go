@@ -287,3 +287,19 @@ func convertPositionRisk(risk *futures.PositionRisk) (*types.PositionRisk, error
 		LiquidationPrice: liquidationPrice,
 	}, nil
 }
+
+func toGlobalFundingFee(record *futures.IncomeHistory) (types.FundingFee, error) {
+	amount, err := fixedpoint.NewFromString(record.Income)
+	if err != nil {
+		return types.FundingFee{}, errors.Wrapf(err, "unable to parse the funding fee income %q", record.Income)
+	}
+
+	return types.FundingFee{
+		Exchange:      types.ExchangeBinance,
+		Symbol:        record.Symbol,
+		Asset:         record.Asset,
+		Amount:        amount,
+		TransactionID: uint64(record.TranID),
+		Time:          types.Time(time.UnixMilli(record.Time)),
+	}, nil
+}
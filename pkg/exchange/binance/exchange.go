@@ -98,7 +98,7 @@ func New(key, secret string) *Exchange {
 	client.Debug = viper.GetBool("debug-binance-client")
 
 	var futuresClient = binance.NewFuturesClient(key, secret)
-	futuresClient.HTTPClient = binanceapi.DefaultHttpClient
+	futuresClient.HTTPClient = binanceapi.DefaultFuturesHttpClient
 	futuresClient.Debug = viper.GetBool("debug-binance-futures-client")
 
 	if isBinanceUs() {
@@ -1659,6 +1659,47 @@ func (e *Exchange) QueryFundingRateHistory(ctx context.Context, symbol string) (
 	}, nil
 }
 
+// QueryFundingRates queries the funding rate history of the given futures symbol in the time range
+func (e *Exchange) QueryFundingRates(ctx context.Context, symbol string, startTime, endTime time.Time) ([]types.FundingRate, error) {
+	var fundingRates []types.FundingRate
+	for startTime.Before(endTime) {
+		rates, err := e.futuresClient.NewFundingRateService().
+			Symbol(symbol).
+			StartTime(startTime.UnixMilli()).
+			EndTime(endTime.UnixMilli()).
+			Limit(1000).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rates) == 0 {
+			break
+		}
+
+		for _, rate := range rates {
+			fundingRate, err := fixedpoint.NewFromString(rate.FundingRate)
+			if err != nil {
+				return nil, err
+			}
+
+			fundingRates = append(fundingRates, types.FundingRate{
+				FundingRate: fundingRate,
+				FundingTime: time.Unix(0, rate.FundingTime*int64(time.Millisecond)),
+				Time:        time.Unix(0, rate.Time*int64(time.Millisecond)),
+			})
+		}
+
+		if len(rates) < 1000 {
+			break
+		}
+
+		startTime = time.Unix(0, (rates[len(rates)-1].FundingTime+1)*int64(time.Millisecond))
+	}
+
+	return fundingRates, nil
+}
+
 func (e *Exchange) QueryPositionRisk(ctx context.Context, symbol string) (*types.PositionRisk, error) {
 	// when symbol is set, only one position risk will be returned.
 	risks, err := e.futuresClient.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
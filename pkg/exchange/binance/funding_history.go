@@ -0,0 +1,52 @@
+package binance
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const incomeTypeFundingFee = "FUNDING_FEE"
+
+// QueryFundingFeeHistory queries the funding fees of the USDT-M futures symbol from the income history,
+// binance only keeps the income history of the last 3 months
+func (e *Exchange) QueryFundingFeeHistory(ctx context.Context, symbol string, startTime, endTime *time.Time) ([]types.FundingFee, error) {
+	req := e.futuresClient.NewGetIncomeHistoryService()
+	req.Symbol(symbol)
+	req.IncomeType(incomeTypeFundingFee)
+	req.Limit(1000)
+
+	if startTime != nil {
+		req.StartTime(startTime.UnixMilli())
+	}
+
+	if startTime != nil && endTime != nil {
+		duration := endTime.Sub(*startTime)
+		if duration > time.Hour*24*30 {
+			t := startTime.Add(time.Hour * 24 * 30)
+			endTime = &t
+		}
+	}
+
+	if endTime != nil {
+		req.EndTime(endTime.UnixMilli())
+	}
+
+	records, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fees []types.FundingFee
+	for _, record := range records {
+		fee, err := toGlobalFundingFee(record)
+		if err != nil {
+			return fees, err
+		}
+
+		fees = append(fees, fee)
+	}
+
+	return fees, nil
+}
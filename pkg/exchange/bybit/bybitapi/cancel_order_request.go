@@ -0,0 +1,19 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+//go:generate requestgen -method POST -url "/v5/order/cancel" -type CancelOrderRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrderResponse
+type CancelOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category `param:"category" validValues:"spot,linear"`
+	symbol      string   `param:"symbol"`
+	orderID     *string  `param:"orderId"`
+	orderLinkID *string  `param:"orderLinkId"`
+}
+
+func (c *RestClient) NewCancelOrderRequest() *CancelOrderRequest {
+	return &CancelOrderRequest{client: c}
+}
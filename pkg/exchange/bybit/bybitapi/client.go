@@ -0,0 +1,239 @@
+package bybitapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultHTTPTimeout = time.Second * 10
+const RestBaseURL = "https://api.bybit.com"
+const TestnetRestBaseURL = "https://api-testnet.bybit.com"
+const DebugRequestResponse = false
+
+// defaultRecvWindow is the milliseconds that the signed request is valid after the timestamp
+const defaultRecvWindow = 5000
+
+var dialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+var defaultTransport = &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	DialContext:           dialer.DialContext,
+	MaxIdleConns:          100,
+	MaxConnsPerHost:       100,
+	MaxIdleConnsPerHost:   100,
+	ExpectContinueTimeout: 0,
+	ForceAttemptHTTP2:     true,
+	TLSClientConfig:       &tls.Config{},
+}
+
+// RequestBudget is the request budget of the bybit api, bybit limits 600 requests per 5 seconds for each ip
+var RequestBudget = ratelimit.NewBudget("bybit", 600, 5*time.Second)
+
+var DefaultHttpClient = &http.Client{
+	Timeout:   defaultHTTPTimeout,
+	Transport: ratelimit.NewTransport(defaultTransport, RequestBudget),
+}
+
+type RestClient struct {
+	requestgen.BaseAPIClient
+
+	Key, Secret string
+
+	recvWindow int
+}
+
+func NewClient(baseURL string) *RestClient {
+	if len(baseURL) == 0 {
+		baseURL = RestBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &RestClient{
+		BaseAPIClient: requestgen.BaseAPIClient{
+			BaseURL:    u,
+			HttpClient: DefaultHttpClient,
+		},
+		recvWindow: defaultRecvWindow,
+	}
+}
+
+func (c *RestClient) Auth(key, secret string) {
+	c.Key = key
+	// pragma: allowlist nextline secret
+	c.Secret = secret
+}
+
+// NewRequest create new API request. Relative url can be provided in refURL.
+func (c *RestClient) NewRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	return http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+}
+
+// SendRequest sends the request and checks the return code of the response,
+// bybit responds the API errors with the http status 200 and a non-zero retCode
+func (c *RestClient) SendRequest(req *http.Request) (*requestgen.Response, error) {
+	response, err := c.BaseAPIClient.SendRequest(req)
+	if DebugRequestResponse {
+		logrus.Debugf("-> request: %+v", req)
+		if response != nil {
+			logrus.Debugf("<- response: %s", string(response.Body))
+		}
+	}
+
+	if err != nil {
+		return response, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return response, err
+	}
+
+	if apiResponse.RetCode != 0 {
+		return response, &APIError{Code: apiResponse.RetCode, Message: apiResponse.RetMsg}
+	}
+
+	return response, nil
+}
+
+// NewAuthenticatedRequest creates new http request for authenticated routes.
+// The v5 API signs the string of timestamp + api key + recv window + (query string or request body),
+// see https://bybit-exchange.github.io/docs/v5/guide#authentication
+func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 {
+		return nil, errors.New("empty api key")
+	}
+
+	if len(c.Secret) == 0 {
+		return nil, errors.New("empty api secret")
+	}
+
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawQuery string
+	if params != nil {
+		rawQuery = params.Encode()
+		rel.RawQuery = rawQuery
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recvWindow := strconv.Itoa(c.recvWindow)
+
+	toSign := timestamp + c.Key + recvWindow
+	if method == http.MethodGet {
+		toSign += rawQuery
+	} else {
+		toSign += string(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	req.Header.Add("Accept", "application/json")
+
+	// Build authentication headers
+	req.Header.Add("X-BAPI-API-KEY", c.Key)
+	req.Header.Add("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Add("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Add("X-BAPI-SIGN", Sign(c.Secret, toSign))
+	return req, nil
+}
+
+// Sign uses sha256 to sign the payload with the given secret, the signature is hex encoded.
+// It's also used for authenticating the private websocket connection.
+func Sign(secret, payload string) string {
+	var sig = hmac.New(sha256.New, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%x", sig.Sum(nil))
+}
+
+func castPayload(payload interface{}) ([]byte, error) {
+	if payload != nil {
+		switch v := payload.(type) {
+		case string:
+			return []byte(v), nil
+
+		case []byte:
+			return v, nil
+
+		default:
+			body, err := json.Marshal(v)
+			return body, err
+		}
+	}
+
+	return nil, nil
+}
+
+type APIResponse struct {
+	RetCode int                        `json:"retCode"`
+	RetMsg  string                     `json:"retMsg"`
+	Result  json.RawMessage            `json:"result"`
+	Time    types.MillisecondTimestamp `json:"time"`
+}
+
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit api error: code %d, message: %s", e.Code, e.Message)
+}
@@ -0,0 +1,64 @@
+package bybitapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", "1658385579423keyabc5000category=spot&symbol=BTCUSDT")
+	assert.Equal(t, "9c5d9490c6925a75338b5b8660409ea0a0a6cfe0a6102011d5a7f4c16446f034", signature)
+}
+
+func TestClient_NewAuthenticatedRequest(t *testing.T) {
+	client := NewClient("")
+	client.Auth("key", "secret")
+
+	ctx := context.Background()
+	query := url.Values{}
+	query.Set("category", "spot")
+	query.Set("symbol", "BTCUSDT")
+
+	req, err := client.NewAuthenticatedRequest(ctx, "GET", "/v5/order/realtime", query, nil)
+	if assert.NoError(t, err) {
+		timestamp := req.Header.Get("X-BAPI-TIMESTAMP")
+		assert.Equal(t, "key", req.Header.Get("X-BAPI-API-KEY"))
+		assert.Equal(t, "5000", req.Header.Get("X-BAPI-RECV-WINDOW"))
+		assert.Equal(t, Sign("secret", timestamp+"key5000category=spot&symbol=BTCUSDT"), req.Header.Get("X-BAPI-SIGN"))
+		assert.Equal(t, "https://api.bybit.com/v5/order/realtime?category=spot&symbol=BTCUSDT", req.URL.String())
+	}
+
+	// the request body is signed for the POST requests
+	payload := map[string]interface{}{"category": "spot"}
+	req, err = client.NewAuthenticatedRequest(ctx, "POST", "/v5/order/cancel", nil, payload)
+	if assert.NoError(t, err) {
+		timestamp := req.Header.Get("X-BAPI-TIMESTAMP")
+		assert.Equal(t, Sign("secret", timestamp+`key5000{"category":"spot"}`), req.Header.Get("X-BAPI-SIGN"))
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	}
+
+	_, err = NewClient("").NewAuthenticatedRequest(ctx, "GET", "/v5/order/realtime", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestClient_SendRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"retCode":10001,"retMsg":"params error: symbol invalid","result":{},"retExtInfo":{},"time":1672211918471}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.NewGetTickersRequest().Category(CategorySpot).Symbol("FOO").Do(context.Background())
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, 10001, apiErr.Code)
+			assert.Equal(t, "params error: symbol invalid", apiErr.Message)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package bybitapi
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Execution struct {
+	Symbol      string                     `json:"symbol"`
+	OrderID     string                     `json:"orderId"`
+	OrderLinkID string                     `json:"orderLinkId"`
+	Side        Side                       `json:"side"`
+	OrderType   OrderType                  `json:"orderType"`
+	OrderPrice  fixedpoint.Value           `json:"orderPrice"`
+	OrderQty    fixedpoint.Value           `json:"orderQty"`
+	ExecID      string                     `json:"execId"`
+	ExecType    string                     `json:"execType"`
+	ExecPrice   fixedpoint.Value           `json:"execPrice"`
+	ExecQty     fixedpoint.Value           `json:"execQty"`
+	ExecValue   fixedpoint.Value           `json:"execValue"`
+	ExecFee     fixedpoint.Value           `json:"execFee"`
+	FeeRate     fixedpoint.Value           `json:"feeRate"`
+	FeeCurrency string                     `json:"feeCurrency"`
+	IsMaker     bool                       `json:"isMaker"`
+	ExecTime    types.MillisecondTimestamp `json:"execTime"`
+}
+
+type ExecutionListPage struct {
+	Category       Category    `json:"category"`
+	List           []Execution `json:"list"`
+	NextPageCursor string      `json:"nextPageCursor"`
+}
+
+//go:generate requestgen -method GET -url "/v5/execution/list" -type GetExecutionsRequest -responseType .APIResponse -responseDataField Result -responseDataType .ExecutionListPage
+type GetExecutionsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category   `param:"category,query" validValues:"spot,linear"`
+	symbol      *string    `param:"symbol,query"`
+	orderID     *string    `param:"orderId,query"`
+	orderLinkID *string    `param:"orderLinkId,query"`
+	startTime   *time.Time `param:"startTime,query,milliseconds"`
+	endTime     *time.Time `param:"endTime,query,milliseconds"`
+	limit       *uint64    `param:"limit,query"`
+	cursor      *string    `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetExecutionsRequest() *GetExecutionsRequest {
+	return &GetExecutionsRequest{client: c}
+}
@@ -0,0 +1,58 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type LotSizeFilter struct {
+	// BasePrecision and QuotePrecision are only available for the spot instruments
+	BasePrecision  fixedpoint.Value `json:"basePrecision"`
+	QuotePrecision fixedpoint.Value `json:"quotePrecision"`
+
+	// QtyStep is only available for the contract instruments
+	QtyStep fixedpoint.Value `json:"qtyStep"`
+
+	MinOrderQty fixedpoint.Value `json:"minOrderQty"`
+	MaxOrderQty fixedpoint.Value `json:"maxOrderQty"`
+	MinOrderAmt fixedpoint.Value `json:"minOrderAmt"`
+	MaxOrderAmt fixedpoint.Value `json:"maxOrderAmt"`
+}
+
+type PriceFilter struct {
+	MinPrice fixedpoint.Value `json:"minPrice"`
+	MaxPrice fixedpoint.Value `json:"maxPrice"`
+	TickSize fixedpoint.Value `json:"tickSize"`
+}
+
+type Instrument struct {
+	Symbol        string        `json:"symbol"`
+	ContractType  string        `json:"contractType"`
+	Status        string        `json:"status"`
+	BaseCoin      string        `json:"baseCoin"`
+	QuoteCoin     string        `json:"quoteCoin"`
+	SettleCoin    string        `json:"settleCoin"`
+	LotSizeFilter LotSizeFilter `json:"lotSizeFilter"`
+	PriceFilter   PriceFilter   `json:"priceFilter"`
+}
+
+type InstrumentsInfo struct {
+	Category       Category     `json:"category"`
+	List           []Instrument `json:"list"`
+	NextPageCursor string       `json:"nextPageCursor"`
+}
+
+//go:generate requestgen -method GET -url "/v5/market/instruments-info" -type GetInstrumentsInfoRequest -responseType .APIResponse -responseDataField Result -responseDataType .InstrumentsInfo
+type GetInstrumentsInfoRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category,query" validValues:"spot,linear"`
+	symbol   *string  `param:"symbol,query"`
+	limit    *uint64  `param:"limit,query"`
+	cursor   *string  `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetInstrumentsInfoRequest() *GetInstrumentsInfoRequest {
+	return &GetInstrumentsInfoRequest{client: c}
+}
@@ -0,0 +1,68 @@
+package bybitapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type KLine struct {
+	StartTime types.MillisecondTimestamp
+	Open      fixedpoint.Value
+	High      fixedpoint.Value
+	Low       fixedpoint.Value
+	Close     fixedpoint.Value
+	Volume    fixedpoint.Value
+	Turnover  fixedpoint.Value
+}
+
+// UnmarshalJSON parses the kline from the array format:
+// [startTime, openPrice, highPrice, lowPrice, closePrice, volume, turnover]
+func (k *KLine) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	if len(fields) < 7 {
+		return fmt.Errorf("unexpected kline length %d, data: %s", len(fields), data)
+	}
+
+	targets := []json.Unmarshaler{&k.StartTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.Turnover}
+	for i, target := range targets {
+		if err := target.UnmarshalJSON(fields[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type KLines struct {
+	Category Category `json:"category"`
+	Symbol   string   `json:"symbol"`
+
+	// List is sorted in the reverse order by the start time
+	List []KLine `json:"list"`
+}
+
+//go:generate requestgen -method GET -url "/v5/market/kline" -type GetKLinesRequest -responseType .APIResponse -responseDataField Result -responseDataType .KLines
+type GetKLinesRequest struct {
+	client requestgen.APIClient
+
+	category  Category   `param:"category,query" validValues:"spot,linear"`
+	symbol    string     `param:"symbol,query"`
+	interval  string     `param:"interval,query"`
+	startTime *time.Time `param:"start,query,milliseconds"`
+	endTime   *time.Time `param:"end,query,milliseconds"`
+	limit     *uint64    `param:"limit,query"`
+}
+
+func (c *RestClient) NewGetKLinesRequest() *GetKLinesRequest {
+	return &GetKLinesRequest{client: c}
+}
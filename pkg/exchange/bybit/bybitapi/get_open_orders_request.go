@@ -0,0 +1,51 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Order struct {
+	OrderID      string                     `json:"orderId"`
+	OrderLinkID  string                     `json:"orderLinkId"`
+	Symbol       string                     `json:"symbol"`
+	Side         Side                       `json:"side"`
+	OrderType    OrderType                  `json:"orderType"`
+	TimeInForce  TimeInForce                `json:"timeInForce"`
+	OrderStatus  OrderStatus                `json:"orderStatus"`
+	Price        fixedpoint.Value           `json:"price"`
+	Qty          fixedpoint.Value           `json:"qty"`
+	AvgPrice     fixedpoint.Value           `json:"avgPrice"`
+	LeavesQty    fixedpoint.Value           `json:"leavesQty"`
+	CumExecQty   fixedpoint.Value           `json:"cumExecQty"`
+	CumExecValue fixedpoint.Value           `json:"cumExecValue"`
+	CumExecFee   fixedpoint.Value           `json:"cumExecFee"`
+	ReduceOnly   bool                       `json:"reduceOnly"`
+	CreatedTime  types.MillisecondTimestamp `json:"createdTime"`
+	UpdatedTime  types.MillisecondTimestamp `json:"updatedTime"`
+}
+
+type OrderListPage struct {
+	Category       Category `json:"category"`
+	List           []Order  `json:"list"`
+	NextPageCursor string   `json:"nextPageCursor"`
+}
+
+//go:generate requestgen -method GET -url "/v5/order/realtime" -type GetOpenOrdersRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrderListPage
+type GetOpenOrdersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category `param:"category,query" validValues:"spot,linear"`
+	symbol      *string  `param:"symbol,query"`
+	settleCoin  *string  `param:"settleCoin,query"`
+	orderID     *string  `param:"orderId,query"`
+	orderLinkID *string  `param:"orderLinkId,query"`
+	limit       *uint64  `param:"limit,query"`
+	cursor      *string  `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetOpenOrdersRequest() *GetOpenOrdersRequest {
+	return &GetOpenOrdersRequest{client: c}
+}
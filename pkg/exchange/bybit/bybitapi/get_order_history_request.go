@@ -0,0 +1,26 @@
+package bybitapi
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+)
+
+//go:generate requestgen -method GET -url "/v5/order/history" -type GetOrderHistoryRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrderListPage
+type GetOrderHistoryRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category     `param:"category,query" validValues:"spot,linear"`
+	symbol      *string      `param:"symbol,query"`
+	orderID     *string      `param:"orderId,query"`
+	orderLinkID *string      `param:"orderLinkId,query"`
+	orderStatus *OrderStatus `param:"orderStatus,query"`
+	startTime   *time.Time   `param:"startTime,query,milliseconds"`
+	endTime     *time.Time   `param:"endTime,query,milliseconds"`
+	limit       *uint64      `param:"limit,query"`
+	cursor      *string      `param:"cursor,query"`
+}
+
+func (c *RestClient) NewGetOrderHistoryRequest() *GetOrderHistoryRequest {
+	return &GetOrderHistoryRequest{client: c}
+}
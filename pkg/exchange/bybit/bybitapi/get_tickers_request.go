@@ -0,0 +1,44 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Ticker struct {
+	Symbol       string           `json:"symbol"`
+	Bid1Price    fixedpoint.Value `json:"bid1Price"`
+	Bid1Size     fixedpoint.Value `json:"bid1Size"`
+	Ask1Price    fixedpoint.Value `json:"ask1Price"`
+	Ask1Size     fixedpoint.Value `json:"ask1Size"`
+	LastPrice    fixedpoint.Value `json:"lastPrice"`
+	PrevPrice24H fixedpoint.Value `json:"prevPrice24h"`
+	HighPrice24H fixedpoint.Value `json:"highPrice24h"`
+	LowPrice24H  fixedpoint.Value `json:"lowPrice24h"`
+	Volume24H    fixedpoint.Value `json:"volume24h"`
+	Turnover24H  fixedpoint.Value `json:"turnover24h"`
+
+	// FundingRate, NextFundingTime and MarkPrice are only available for the contract tickers
+	FundingRate     fixedpoint.Value           `json:"fundingRate"`
+	NextFundingTime types.MillisecondTimestamp `json:"nextFundingTime"`
+	MarkPrice       fixedpoint.Value           `json:"markPrice"`
+}
+
+type Tickers struct {
+	Category Category `json:"category"`
+	List     []Ticker `json:"list"`
+}
+
+//go:generate requestgen -method GET -url "/v5/market/tickers" -type GetTickersRequest -responseType .APIResponse -responseDataField Result -responseDataType .Tickers
+type GetTickersRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category,query" validValues:"spot,linear"`
+	symbol   *string  `param:"symbol,query"`
+}
+
+func (c *RestClient) NewGetTickersRequest() *GetTickersRequest {
+	return &GetTickersRequest{client: c}
+}
@@ -0,0 +1,43 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type CoinBalance struct {
+	Coin                string           `json:"coin"`
+	Equity              fixedpoint.Value `json:"equity"`
+	WalletBalance       fixedpoint.Value `json:"walletBalance"`
+	Free                fixedpoint.Value `json:"free"`
+	Locked              fixedpoint.Value `json:"locked"`
+	AvailableToWithdraw fixedpoint.Value `json:"availableToWithdraw"`
+	BorrowAmount        fixedpoint.Value `json:"borrowAmount"`
+	AccruedInterest     fixedpoint.Value `json:"accruedInterest"`
+	TotalOrderIM        fixedpoint.Value `json:"totalOrderIM"`
+	TotalPositionIM     fixedpoint.Value `json:"totalPositionIM"`
+	UnrealisedPnl       fixedpoint.Value `json:"unrealisedPnl"`
+}
+
+type WalletBalance struct {
+	AccountType AccountType      `json:"accountType"`
+	TotalEquity fixedpoint.Value `json:"totalEquity"`
+	Coins       []CoinBalance    `json:"coin"`
+}
+
+type WalletBalances struct {
+	List []WalletBalance `json:"list"`
+}
+
+//go:generate requestgen -method GET -url "/v5/account/wallet-balance" -type GetWalletBalanceRequest -responseType .APIResponse -responseDataField Result -responseDataType .WalletBalances
+type GetWalletBalanceRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	accountType AccountType `param:"accountType,query" validValues:"SPOT,CONTRACT,UNIFIED"`
+	coin        *string     `param:"coin,query"`
+}
+
+func (c *RestClient) NewGetWalletBalanceRequest() *GetWalletBalanceRequest {
+	return &GetWalletBalanceRequest{client: c}
+}
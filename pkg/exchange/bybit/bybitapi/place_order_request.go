@@ -0,0 +1,32 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type OrderResponse struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+}
+
+//go:generate requestgen -method POST -url "/v5/order/create" -type PlaceOrderRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrderResponse
+type PlaceOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category     `param:"category" validValues:"spot,linear"`
+	symbol      string       `param:"symbol"`
+	side        Side         `param:"side" validValues:"Buy,Sell"`
+	orderType   OrderType    `param:"orderType" validValues:"Market,Limit"`
+	quantity    string       `param:"qty"`
+	price       *string      `param:"price"`
+	timeInForce *TimeInForce `param:"timeInForce" validValues:"GTC,IOC,FOK,PostOnly"`
+	orderLinkID *string      `param:"orderLinkId"`
+	reduceOnly  *bool        `param:"reduceOnly"`
+
+	// marketUnit is only used by the spot market order, the qty of the market buy order is in the quote coin by default
+	marketUnit *MarketUnit `param:"marketUnit" validValues:"baseCoin,quoteCoin"`
+}
+
+func (c *RestClient) NewPlaceOrderRequest() *PlaceOrderRequest {
+	return &PlaceOrderRequest{client: c}
+}
@@ -0,0 +1,311 @@
+// Code generated by "requestgen -method POST -url /v5/order/create -type PlaceOrderRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrderResponse"; DO NOT EDIT.
+
+package bybitapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (p *PlaceOrderRequest) Category(category Category) *PlaceOrderRequest {
+	p.category = category
+	return p
+}
+
+func (p *PlaceOrderRequest) Symbol(symbol string) *PlaceOrderRequest {
+	p.symbol = symbol
+	return p
+}
+
+func (p *PlaceOrderRequest) Side(side Side) *PlaceOrderRequest {
+	p.side = side
+	return p
+}
+
+func (p *PlaceOrderRequest) OrderType(orderType OrderType) *PlaceOrderRequest {
+	p.orderType = orderType
+	return p
+}
+
+func (p *PlaceOrderRequest) Quantity(quantity string) *PlaceOrderRequest {
+	p.quantity = quantity
+	return p
+}
+
+func (p *PlaceOrderRequest) Price(price string) *PlaceOrderRequest {
+	p.price = &price
+	return p
+}
+
+func (p *PlaceOrderRequest) TimeInForce(timeInForce TimeInForce) *PlaceOrderRequest {
+	p.timeInForce = &timeInForce
+	return p
+}
+
+func (p *PlaceOrderRequest) OrderLinkID(orderLinkID string) *PlaceOrderRequest {
+	p.orderLinkID = &orderLinkID
+	return p
+}
+
+func (p *PlaceOrderRequest) ReduceOnly(reduceOnly bool) *PlaceOrderRequest {
+	p.reduceOnly = &reduceOnly
+	return p
+}
+
+func (p *PlaceOrderRequest) MarketUnit(marketUnit MarketUnit) *PlaceOrderRequest {
+	p.marketUnit = &marketUnit
+	return p
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (p *PlaceOrderRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check category field -> json key category
+	category := p.category
+
+	// TEMPLATE check-valid-values
+	switch category {
+	case "spot", "linear":
+		params["category"] = category
+
+	default:
+		return nil, fmt.Errorf("category value %v is invalid", category)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of category
+	params["category"] = category
+	// check symbol field -> json key symbol
+	symbol := p.symbol
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check side field -> json key side
+	side := p.side
+
+	// TEMPLATE check-valid-values
+	switch side {
+	case "Buy", "Sell":
+		params["side"] = side
+
+	default:
+		return nil, fmt.Errorf("side value %v is invalid", side)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of side
+	params["side"] = side
+	// check orderType field -> json key orderType
+	orderType := p.orderType
+
+	// TEMPLATE check-valid-values
+	switch orderType {
+	case "Market", "Limit":
+		params["orderType"] = orderType
+
+	default:
+		return nil, fmt.Errorf("orderType value %v is invalid", orderType)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of orderType
+	params["orderType"] = orderType
+	// check quantity field -> json key qty
+	quantity := p.quantity
+
+	// assign parameter of quantity
+	params["qty"] = quantity
+	// check price field -> json key price
+	if p.price != nil {
+		price := *p.price
+
+		// assign parameter of price
+		params["price"] = price
+	} else {
+	}
+	// check timeInForce field -> json key timeInForce
+	if p.timeInForce != nil {
+		timeInForce := *p.timeInForce
+
+		// TEMPLATE check-valid-values
+		switch timeInForce {
+		case "GTC", "IOC", "FOK", "PostOnly":
+			params["timeInForce"] = timeInForce
+
+		default:
+			return nil, fmt.Errorf("timeInForce value %v is invalid", timeInForce)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of timeInForce
+		params["timeInForce"] = timeInForce
+	} else {
+	}
+	// check orderLinkID field -> json key orderLinkId
+	if p.orderLinkID != nil {
+		orderLinkID := *p.orderLinkID
+
+		// assign parameter of orderLinkID
+		params["orderLinkId"] = orderLinkID
+	} else {
+	}
+	// check reduceOnly field -> json key reduceOnly
+	if p.reduceOnly != nil {
+		reduceOnly := *p.reduceOnly
+
+		// assign parameter of reduceOnly
+		params["reduceOnly"] = reduceOnly
+	} else {
+	}
+	// check marketUnit field -> json key marketUnit
+	if p.marketUnit != nil {
+		marketUnit := *p.marketUnit
+
+		// TEMPLATE check-valid-values
+		switch marketUnit {
+		case "baseCoin", "quoteCoin":
+			params["marketUnit"] = marketUnit
+
+		default:
+			return nil, fmt.Errorf("marketUnit value %v is invalid", marketUnit)
+
+		}
+		// END TEMPLATE check-valid-values
+
+		// assign parameter of marketUnit
+		params["marketUnit"] = marketUnit
+	} else {
+	}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (p *PlaceOrderRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := p.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if p.isVarSlice(_v) {
+			p.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (p *PlaceOrderRequest) GetParametersJSON() ([]byte, error) {
+	params, err := p.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (p *PlaceOrderRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (p *PlaceOrderRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (p *PlaceOrderRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (p *PlaceOrderRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (p *PlaceOrderRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := p.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+func (p *PlaceOrderRequest) Do(ctx context.Context) (*OrderResponse, error) {
+
+	params, err := p.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	apiURL := "/v5/order/create"
+
+	req, err := p.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := p.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+	var data OrderResponse
+	if err := json.Unmarshal(apiResponse.Result, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
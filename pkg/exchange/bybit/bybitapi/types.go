@@ -0,0 +1,65 @@
+package bybitapi
+
+// Category is the product type of the v5 API
+type Category string
+
+const (
+	CategorySpot Category = "spot"
+
+	// CategoryLinear is the USDT perpetual contract
+	CategoryLinear Category = "linear"
+)
+
+type Side string
+
+const (
+	SideBuy  Side = "Buy"
+	SideSell Side = "Sell"
+)
+
+type OrderType string
+
+const (
+	OrderTypeMarket OrderType = "Market"
+	OrderTypeLimit  OrderType = "Limit"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC      TimeInForce = "GTC"
+	TimeInForceIOC      TimeInForce = "IOC"
+	TimeInForceFOK      TimeInForce = "FOK"
+	TimeInForcePostOnly TimeInForce = "PostOnly"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusCreated                 OrderStatus = "Created"
+	OrderStatusNew                     OrderStatus = "New"
+	OrderStatusRejected                OrderStatus = "Rejected"
+	OrderStatusPartiallyFilled         OrderStatus = "PartiallyFilled"
+	OrderStatusPartiallyFilledCanceled OrderStatus = "PartiallyFilledCanceled"
+	OrderStatusFilled                  OrderStatus = "Filled"
+	OrderStatusCancelled               OrderStatus = "Cancelled"
+	OrderStatusUntriggered             OrderStatus = "Untriggered"
+	OrderStatusTriggered               OrderStatus = "Triggered"
+	OrderStatusDeactivated             OrderStatus = "Deactivated"
+)
+
+type AccountType string
+
+const (
+	AccountTypeSpot     AccountType = "SPOT"
+	AccountTypeContract AccountType = "CONTRACT"
+	AccountTypeUnified  AccountType = "UNIFIED"
+)
+
+// MarketUnit is the unit of the qty field of the spot market order
+type MarketUnit string
+
+const (
+	MarketUnitBaseCoin  MarketUnit = "baseCoin"
+	MarketUnitQuoteCoin MarketUnit = "quoteCoin"
+)
@@ -0,0 +1,71 @@
+package bybit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/exchange/conformance"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchange_Conformance(t *testing.T) {
+	server := conformance.NewMockServer(t, "testdata")
+
+	// the later pages overlap the previous pages, and the last page is empty
+	server.HandleFile("GET", "/v5/execution/list",
+		"conformance/execution-list-page1.json",
+		"conformance/execution-list-page2.json",
+		"conformance/execution-list-empty.json")
+	server.HandleFile("GET", "/v5/order/history",
+		"conformance/order-history-page1.json",
+		"conformance/order-history-page2.json",
+		"conformance/order-history-empty.json")
+
+	server.HandleWebSocket(`"op":"auth"`, "ws-auth.json")
+	server.HandleWebSocket(`"op":"subscribe"`,
+		"conformance/ws-order-new.json",
+		"conformance/ws-execution-1.json",
+		"conformance/ws-order-partially-filled.json",
+		"conformance/ws-execution-2.json",
+		"conformance/ws-order-filled.json")
+
+	ex := New("key", "secret")
+	ex.UseFutures()
+	ex.client = bybitapi.NewClient(server.URL)
+	ex.client.Auth("key", "secret")
+
+	suite := &conformance.Suite{
+		Exchange:             ex,
+		Server:               server,
+		Symbol:               "BTCUSDT",
+		Since:                time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:                time.Date(2023, time.January, 2, 0, 0, 0, 0, time.UTC),
+		ExpectedTrades:       5,
+		ExpectedClosedOrders: 3,
+		NewStream: func() types.Stream {
+			stream := ex.NewStream().(*Stream)
+			stream.privateEndpoint = server.WebSocketURL()
+			return stream
+		},
+		ExpectedOrderUpdates: 3,
+		ExpectedTradeUpdates: 2,
+	}
+	suite.Run(t)
+
+	// bybit queries the executions by the time range, the start time must be advanced for the next page
+	requests := server.Requests("GET", "/v5/execution/list")
+	if assert.Len(t, requests, 3) {
+		var last int64
+		for _, r := range requests {
+			startTime, err := strconv.ParseInt(r.Query.Get("startTime"), 10, 64)
+			if assert.NoError(t, err) {
+				assert.Greater(t, startTime, last)
+				last = startTime
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package bybit
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the symbols of bybit are the same as the global symbols, e.g. BTCUSDT
+func toGlobalSymbol(symbol string) string {
+	return symbol
+}
+
+func toLocalSymbol(symbol string) string {
+	return symbol
+}
+
+// parseID parses the numeric id of bybit, the string id (the UUID of the spot public trades) is hashed
+func parseID(s string) uint64 {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err == nil {
+		return id
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// precisionOf converts the step size to the precision, e.g. 0.0001 to 4
+func precisionOf(step fixedpoint.Value) int {
+	if step.Sign() <= 0 {
+		return 0
+	}
+
+	return int(math.Round(-math.Log10(step.Float64())))
+}
+
+func toGlobalMarket(inst bybitapi.Instrument) types.Market {
+	stepSize := inst.LotSizeFilter.QtyStep
+	if stepSize.IsZero() {
+		// the spot instruments use the base precision as the quantity step
+		stepSize = inst.LotSizeFilter.BasePrecision
+	}
+
+	return types.Market{
+		Symbol:          toGlobalSymbol(inst.Symbol),
+		LocalSymbol:     inst.Symbol,
+		PricePrecision:  precisionOf(inst.PriceFilter.TickSize),
+		VolumePrecision: precisionOf(stepSize),
+		QuoteCurrency:   inst.QuoteCoin,
+		BaseCurrency:    inst.BaseCoin,
+		MinNotional:     inst.LotSizeFilter.MinOrderAmt,
+		MinAmount:       inst.LotSizeFilter.MinOrderAmt,
+		MinQuantity:     inst.LotSizeFilter.MinOrderQty,
+		MaxQuantity:     inst.LotSizeFilter.MaxOrderQty,
+		StepSize:        stepSize,
+		MinPrice:        inst.PriceFilter.MinPrice,
+		MaxPrice:        inst.PriceFilter.MaxPrice,
+		TickSize:        inst.PriceFilter.TickSize,
+	}
+}
+
+func toGlobalTicker(t bybitapi.Ticker, now time.Time) types.Ticker {
+	return types.Ticker{
+		Time:   now,
+		Volume: t.Volume24H,
+		Last:   t.LastPrice,
+		Open:   t.PrevPrice24H,
+		High:   t.HighPrice24H,
+		Low:    t.LowPrice24H,
+		Buy:    t.Bid1Price,
+		Sell:   t.Ask1Price,
+	}
+}
+
+// From the doc
+// Kline interval. 1,3,5,15,30,60,120,240,360,720,D,M,W
+var toLocalInterval = map[types.Interval]string{
+	types.Interval1m:  "1",
+	types.Interval3m:  "3",
+	types.Interval5m:  "5",
+	types.Interval15m: "15",
+	types.Interval30m: "30",
+	types.Interval1h:  "60",
+	types.Interval2h:  "120",
+	types.Interval4h:  "240",
+	types.Interval6h:  "360",
+	types.Interval12h: "720",
+	types.Interval1d:  "D",
+	types.Interval1w:  "W",
+	types.Interval1mo: "M",
+}
+
+func toGlobalInterval(interval string) types.Interval {
+	for globalInterval, localInterval := range toLocalInterval {
+		if localInterval == interval {
+			return globalInterval
+		}
+	}
+
+	return types.Interval(interval)
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, k bybitapi.KLine) types.KLine {
+	return types.KLine{
+		Exchange:    types.ExchangeBybit,
+		Symbol:      toGlobalSymbol(symbol),
+		StartTime:   types.Time(k.StartTime),
+		EndTime:     types.Time(k.StartTime.Time().Add(interval.Duration() - time.Millisecond)),
+		Interval:    interval,
+		Open:        k.Open,
+		Close:       k.Close,
+		High:        k.High,
+		Low:         k.Low,
+		Volume:      k.Volume,
+		QuoteVolume: k.Turnover,
+		Closed:      true,
+	}
+}
+
+func toGlobalBalanceMap(walletBalances []bybitapi.WalletBalance) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, walletBalance := range walletBalances {
+		for _, c := range walletBalance.Coins {
+			// the spot account reports the free balance, the contract account reports the withdrawable balance,
+			// and the unified account only reports the locked balance of the spot orders
+			available := c.Free
+			if available.IsZero() {
+				available = c.AvailableToWithdraw
+			}
+			if available.IsZero() {
+				available = c.WalletBalance.Sub(c.Locked)
+			}
+
+			locked := c.WalletBalance.Sub(available)
+			if locked.Sign() < 0 {
+				locked = fixedpoint.Zero
+			}
+
+			balances[c.Coin] = types.Balance{
+				Currency:  c.Coin,
+				Available: available,
+				Locked:    locked,
+				Borrowed:  c.BorrowAmount,
+				Interest:  c.AccruedInterest,
+				NetAsset:  c.WalletBalance.Sub(c.BorrowAmount).Sub(c.AccruedInterest),
+			}
+		}
+	}
+
+	return balances
+}
+
+func toGlobalSide(side bybitapi.Side) types.SideType {
+	switch side {
+	case bybitapi.SideBuy:
+		return types.SideTypeBuy
+	case bybitapi.SideSell:
+		return types.SideTypeSell
+	}
+
+	return types.SideType(side)
+}
+
+func toLocalSide(side types.SideType) bybitapi.Side {
+	if side == types.SideTypeSell {
+		return bybitapi.SideSell
+	}
+
+	return bybitapi.SideBuy
+}
+
+func toGlobalOrderType(orderType bybitapi.OrderType, timeInForce bybitapi.TimeInForce) types.OrderType {
+	switch orderType {
+	case bybitapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case bybitapi.OrderTypeLimit:
+		if timeInForce == bybitapi.TimeInForcePostOnly {
+			return types.OrderTypeLimitMaker
+		}
+
+		return types.OrderTypeLimit
+	}
+
+	return types.OrderType(orderType)
+}
+
+func toGlobalTimeInForce(timeInForce bybitapi.TimeInForce) types.TimeInForce {
+	switch timeInForce {
+	case bybitapi.TimeInForceIOC:
+		return types.TimeInForceIOC
+	case bybitapi.TimeInForceFOK:
+		return types.TimeInForceFOK
+	}
+
+	return types.TimeInForceGTC
+}
+
+func toGlobalOrderStatus(status bybitapi.OrderStatus) types.OrderStatus {
+	switch status {
+	case bybitapi.OrderStatusCreated, bybitapi.OrderStatusNew, bybitapi.OrderStatusUntriggered, bybitapi.OrderStatusTriggered:
+		return types.OrderStatusNew
+
+	case bybitapi.OrderStatusPartiallyFilled:
+		return types.OrderStatusPartiallyFilled
+
+	case bybitapi.OrderStatusFilled:
+		return types.OrderStatusFilled
+
+	case bybitapi.OrderStatusCancelled, bybitapi.OrderStatusPartiallyFilledCanceled, bybitapi.OrderStatusDeactivated:
+		return types.OrderStatusCanceled
+
+	case bybitapi.OrderStatusRejected:
+		return types.OrderStatusRejected
+	}
+
+	return types.OrderStatus(status)
+}
+
+func isWorkingOrderStatus(status bybitapi.OrderStatus) bool {
+	switch status {
+	case bybitapi.OrderStatusCreated, bybitapi.OrderStatusNew, bybitapi.OrderStatusPartiallyFilled,
+		bybitapi.OrderStatusUntriggered, bybitapi.OrderStatusTriggered:
+		return true
+	}
+
+	return false
+}
+
+func toGlobalOrder(o bybitapi.Order, category bybitapi.Category) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.OrderLinkID,
+			Symbol:        toGlobalSymbol(o.Symbol),
+			Side:          toGlobalSide(o.Side),
+			Type:          toGlobalOrderType(o.OrderType, o.TimeInForce),
+			Quantity:      o.Qty,
+			Price:         o.Price,
+			TimeInForce:   toGlobalTimeInForce(o.TimeInForce),
+			ReduceOnly:    o.ReduceOnly,
+		},
+		Exchange:         types.ExchangeBybit,
+		OrderID:          parseID(o.OrderID),
+		UUID:             o.OrderID,
+		Status:           toGlobalOrderStatus(o.OrderStatus),
+		ExecutedQuantity: o.CumExecQty,
+		IsWorking:        isWorkingOrderStatus(o.OrderStatus),
+		CreationTime:     types.Time(o.CreatedTime),
+		UpdateTime:       types.Time(o.UpdatedTime),
+		IsFutures:        category == bybitapi.CategoryLinear,
+	}
+}
+
+func toGlobalTrade(e bybitapi.Execution, category bybitapi.Category) types.Trade {
+	feeCurrency := e.FeeCurrency
+	if len(feeCurrency) == 0 && category == bybitapi.CategoryLinear {
+		// the fee of the USDT perpetual contracts is settled in USDT
+		feeCurrency = "USDT"
+	}
+
+	return types.Trade{
+		ID:            parseID(e.ExecID),
+		OrderID:       parseID(e.OrderID),
+		Exchange:      types.ExchangeBybit,
+		Price:         e.ExecPrice,
+		Quantity:      e.ExecQty,
+		QuoteQuantity: e.ExecValue,
+		Symbol:        toGlobalSymbol(e.Symbol),
+		Side:          toGlobalSide(e.Side),
+		IsBuyer:       e.Side == bybitapi.SideBuy,
+		IsMaker:       e.IsMaker,
+		Time:          types.Time(e.ExecTime),
+		Fee:           e.ExecFee,
+		FeeCurrency:   feeCurrency,
+		IsFutures:     category == bybitapi.CategoryLinear,
+	}
+}
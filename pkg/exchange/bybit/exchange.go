@@ -0,0 +1,660 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The rate limits of the v5 API are counted per UID for each endpoint,
+// see https://bybit-exchange.github.io/docs/v5/rate-limit
+var marketDataLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var orderLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 10)
+var queryOrderLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var queryTradeLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var accountLimiter = rate.NewLimiter(rate.Every(200*time.Millisecond), 5)
+
+// BIT is the platform currency of Bybit, pre-allocate static string here
+const BIT = "BIT"
+
+const (
+	// pageLimit is the max page size of the order and the execution list
+	pageLimit = 50
+
+	// instrumentPageLimit is the max page size of the instrument list
+	instrumentPageLimit = 1000
+
+	// klineLimit is the max number of the klines in one query
+	klineLimit = 1000
+
+	// historyWindow is the max time range of the order and the execution history query
+	historyWindow = 7 * 24 * time.Hour
+)
+
+var log = logrus.WithFields(logrus.Fields{
+	"exchange": "bybit",
+})
+
+type Exchange struct {
+	types.FuturesSettings
+
+	key, secret string
+	client      *bybitapi.RestClient
+}
+
+func New(key, secret string) *Exchange {
+	client := bybitapi.NewClient("")
+
+	// for public access mode
+	if len(key) > 0 && len(secret) > 0 {
+		client.Auth(key, secret)
+	}
+
+	return &Exchange{
+		key: key,
+		// pragma: allowlist nextline secret
+		secret: secret,
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeBybit
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return BIT
+}
+
+// category returns the product category, the USDT perpetual contracts are used when the futures mode is enabled
+func (e *Exchange) category() bybitapi.Category {
+	if e.IsFutures {
+		return bybitapi.CategoryLinear
+	}
+
+	return bybitapi.CategorySpot
+}
+
+func (e *Exchange) accountType() bybitapi.AccountType {
+	if e.IsFutures {
+		return bybitapi.AccountTypeContract
+	}
+
+	return bybitapi.AccountTypeSpot
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.key, e.secret, e.category())
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	req := e.client.NewGetInstrumentsInfoRequest()
+	req.Category(e.category()).Limit(instrumentPageLimit)
+
+	marketMap := types.MarketMap{}
+	for {
+		if err := marketDataLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		info, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range info.List {
+			marketMap.Add(toGlobalMarket(inst))
+		}
+
+		if len(info.NextPageCursor) == 0 {
+			break
+		}
+
+		req.Cursor(info.NextPageCursor)
+	}
+
+	return marketMap, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	tickers, err := e.QueryTickers(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, ok := tickers[symbol]
+	if !ok {
+		return nil, fmt.Errorf("ticker %s not found", symbol)
+	}
+
+	return &ticker, nil
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewGetTickersRequest()
+	req.Category(e.category())
+	if len(symbols) == 1 {
+		req.Symbol(toLocalSymbol(symbols[0]))
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter = make(map[string]struct{}, len(symbols))
+	for _, s := range symbols {
+		filter[s] = struct{}{}
+	}
+
+	now := time.Now()
+	tickers := make(map[string]types.Ticker)
+	for _, t := range response.List {
+		symbol := toGlobalSymbol(t.Symbol)
+		if len(filter) > 0 {
+			if _, ok := filter[symbol]; !ok {
+				continue
+			}
+		}
+
+		tickers[symbol] = toGlobalTicker(t, now)
+	}
+
+	return tickers, nil
+}
+
+// QueryPremiumIndex queries the funding rate and the mark price of the USDT perpetual contract from the ticker
+func (e *Exchange) QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewGetTickersRequest()
+	req.Category(bybitapi.CategoryLinear).Symbol(toLocalSymbol(symbol))
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.List) == 0 {
+		return nil, fmt.Errorf("premium index %s not found", symbol)
+	}
+
+	ticker := response.List[0]
+	return &types.PremiumIndex{
+		Symbol:          symbol,
+		MarkPrice:       ticker.MarkPrice,
+		LastFundingRate: ticker.FundingRate,
+		NextFundingTime: ticker.NextFundingTime.Time(),
+		Time:            time.Now(),
+	}, nil
+}
+
+func (e *Exchange) SupportedInterval() map[types.Interval]int {
+	intervals := make(map[types.Interval]int, len(toLocalInterval))
+	for interval := range toLocalInterval {
+		intervals[interval] = interval.Minutes() * 60
+	}
+
+	return intervals
+}
+
+func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := toLocalInterval[interval]
+	return ok
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	localInterval, ok := toLocalInterval[interval]
+	if !ok {
+		return nil, fmt.Errorf("interval %s is not supported", interval)
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	limit := klineLimit
+	if options.Limit > 0 && options.Limit < klineLimit {
+		limit = options.Limit
+	}
+
+	req := e.client.NewGetKLinesRequest()
+	req.Category(e.category()).
+		Symbol(toLocalSymbol(symbol)).
+		Interval(localInterval).
+		Limit(uint64(limit))
+
+	if options.StartTime != nil {
+		req.StartTime(*options.StartTime)
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(*options.EndTime)
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var klines []types.KLine
+	for _, k := range response.List {
+		kline := toGlobalKLine(symbol, interval, k)
+
+		// the current kline is not closed yet
+		if kline.EndTime.After(now) {
+			continue
+		}
+
+		klines = append(klines, kline)
+	}
+
+	// the klines are returned in the reverse order
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime.Time())
+	})
+
+	return klines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+	if e.IsFutures {
+		account.AccountType = types.AccountTypeFutures
+	}
+
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if err := accountLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewGetWalletBalanceRequest()
+	req.AccountType(e.accountType())
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalanceMap(response.List), nil
+}
+
+func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	req := e.client.NewPlaceOrderRequest()
+	req.Category(e.category()).
+		Symbol(toLocalSymbol(order.Symbol)).
+		Side(toLocalSide(order.Side))
+
+	if len(order.ClientOrderID) > 0 {
+		req.OrderLinkID(order.ClientOrderID)
+	}
+
+	if order.Market.Symbol != "" {
+		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+	} else {
+		req.Quantity(order.Quantity.FormatString(8))
+	}
+
+	switch order.Type {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		req.OrderType(bybitapi.OrderTypeLimit)
+
+		if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price))
+		} else {
+			req.Price(order.Price.FormatString(8))
+		}
+
+		switch {
+		case order.Type == types.OrderTypeLimitMaker:
+			req.TimeInForce(bybitapi.TimeInForcePostOnly)
+		case order.TimeInForce == types.TimeInForceIOC:
+			req.TimeInForce(bybitapi.TimeInForceIOC)
+		case order.TimeInForce == types.TimeInForceFOK:
+			req.TimeInForce(bybitapi.TimeInForceFOK)
+		default:
+			req.TimeInForce(bybitapi.TimeInForceGTC)
+		}
+
+	case types.OrderTypeMarket:
+		req.OrderType(bybitapi.OrderTypeMarket)
+
+		// the quantity of the spot market buy order is in the quote currency by default
+		if !e.IsFutures {
+			req.MarketUnit(bybitapi.MarketUnitBaseCoin)
+		}
+
+	default:
+		return nil, fmt.Errorf("order type %s is not supported", order.Type)
+	}
+
+	if e.IsFutures && order.ReduceOnly {
+		req.ReduceOnly(true)
+	}
+
+	if err := orderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Order{
+		SubmitOrder:      order,
+		Exchange:         types.ExchangeBybit,
+		OrderID:          parseID(response.OrderID),
+		UUID:             response.OrderID,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(time.Now()),
+		UpdateTime:       types.Time(time.Now()),
+		IsFutures:        e.IsFutures,
+	}, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (errs error) {
+	for _, o := range orders {
+		req := e.client.NewCancelOrderRequest()
+		req.Category(e.category()).Symbol(toLocalSymbol(o.Symbol))
+
+		if len(o.UUID) > 0 {
+			req.OrderID(o.UUID)
+		} else if o.OrderID > 0 {
+			req.OrderID(fmt.Sprintf("%d", o.OrderID))
+		} else if len(o.ClientOrderID) > 0 {
+			req.OrderLinkID(o.ClientOrderID)
+		} else {
+			errs = multierr.Append(errs, fmt.Errorf("the order id and client order id are empty, order: %#v", o))
+			continue
+		}
+
+		if err := orderLimiter.Wait(ctx); err != nil {
+			return multierr.Append(errs, err)
+		}
+
+		if _, err := req.Do(ctx); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	req := e.client.NewGetOpenOrdersRequest()
+	req.Category(e.category()).
+		Symbol(toLocalSymbol(symbol)).
+		Limit(pageLimit)
+
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range response.List {
+			orders = append(orders, toGlobalOrder(o, response.Category))
+		}
+
+		if len(response.NextPageCursor) == 0 || len(response.List) < pageLimit {
+			break
+		}
+
+		req.Cursor(response.NextPageCursor)
+	}
+
+	return orders, nil
+}
+
+// QueryOrder queries the order by the order id or the client order id (the orderLinkId of bybit),
+// the open orders are queried first, and then the order history.
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if len(q.OrderID) == 0 && len(q.ClientOrderID) == 0 {
+		return nil, errors.New("order id or client order id is required for querying a bybit order")
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewGetOpenOrdersRequest()
+	req.Category(e.category())
+	if len(q.Symbol) > 0 {
+		req.Symbol(toLocalSymbol(q.Symbol))
+	}
+
+	if len(q.OrderID) > 0 {
+		req.OrderID(q.OrderID)
+	} else {
+		req.OrderLinkID(q.ClientOrderID)
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.List) == 0 {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		historyReq := e.client.NewGetOrderHistoryRequest()
+		historyReq.Category(e.category())
+		if len(q.Symbol) > 0 {
+			historyReq.Symbol(toLocalSymbol(q.Symbol))
+		}
+
+		if len(q.OrderID) > 0 {
+			historyReq.OrderID(q.OrderID)
+		} else {
+			historyReq.OrderLinkID(q.ClientOrderID)
+		}
+
+		response, err = historyReq.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(response.List) == 0 {
+		return nil, fmt.Errorf("order not found, query: %+v", q)
+	}
+
+	order := toGlobalOrder(response.List[0], response.Category)
+	return &order, nil
+}
+
+func (e *Exchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) (trades []types.Trade, err error) {
+	if len(q.OrderID) == 0 {
+		return nil, errors.New("order id is required for querying the trades of a bybit order")
+	}
+
+	req := e.client.NewGetExecutionsRequest()
+	req.Category(e.category()).
+		OrderID(q.OrderID).
+		Limit(pageLimit)
+
+	if len(q.Symbol) > 0 {
+		req.Symbol(toLocalSymbol(q.Symbol))
+	}
+
+	for {
+		if err := queryTradeLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		for _, execution := range response.List {
+			trades = append(trades, toGlobalTrade(execution, response.Category))
+		}
+
+		if len(response.NextPageCursor) == 0 || len(response.List) < pageLimit {
+			break
+		}
+
+		req.Cursor(response.NextPageCursor)
+	}
+
+	return trades, nil
+}
+
+// QueryClosedOrders queries the closed orders in the time range,
+// bybit limits the time range of the order history query to 7 days.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if until.Sub(since) > historyWindow {
+		until = since.Add(historyWindow)
+	}
+
+	req := e.client.NewGetOrderHistoryRequest()
+	req.Category(e.category()).
+		Symbol(toLocalSymbol(symbol)).
+		StartTime(since).
+		EndTime(until).
+		Limit(pageLimit)
+
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range response.List {
+			if isWorkingOrderStatus(o.OrderStatus) {
+				continue
+			}
+
+			order := toGlobalOrder(o, response.Category)
+			if lastOrderID > 0 && order.OrderID == lastOrderID {
+				continue
+			}
+
+			orders = append(orders, order)
+		}
+
+		if len(response.NextPageCursor) == 0 || len(response.List) < pageLimit {
+			break
+		}
+
+		req.Cursor(response.NextPageCursor)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime.Time())
+	})
+
+	return orders, nil
+}
+
+// QueryTrades queries the executions in the time range, the executions are returned in the ascending order.
+// bybit does not support querying the executions from a trade id, and the time range is limited to 7 days.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	req := e.client.NewGetExecutionsRequest()
+	req.Category(e.category()).
+		Symbol(toLocalSymbol(symbol)).
+		Limit(pageLimit)
+
+	if options.StartTime != nil {
+		req.StartTime(*options.StartTime)
+
+		endTime := options.StartTime.Add(historyWindow)
+		if options.EndTime != nil && options.EndTime.Before(endTime) {
+			endTime = *options.EndTime
+		}
+
+		req.EndTime(endTime)
+	} else if options.EndTime != nil {
+		req.EndTime(*options.EndTime)
+	}
+
+	for {
+		if err := queryTradeLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		for _, execution := range response.List {
+			trades = append(trades, toGlobalTrade(execution, response.Category))
+		}
+
+		if len(response.NextPageCursor) == 0 || len(response.List) < pageLimit {
+			break
+		}
+
+		if options.Limit > 0 && int64(len(trades)) >= options.Limit {
+			break
+		}
+
+		req.Cursor(response.NextPageCursor)
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+// DefaultFeeRates returns the fee rates of the VIP 0 level
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	if e.IsFutures {
+		return types.ExchangeFee{
+			MakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.020), // 0.02%
+			TakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.055), // 0.055%
+		}
+	}
+
+	return types.ExchangeFee{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.100), // 0.1%
+		TakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.100), // 0.1%
+	}
+}
@@ -0,0 +1,351 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// recordedRequest is the request received by the fixture server
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  map[string]string
+	Body   map[string]interface{}
+	Header http.Header
+}
+
+// newTestExchange creates an exchange connecting to a fixture server,
+// the fixtures are looked up by "{method} {path}" and served from the testdata directory
+func newTestExchange(t *testing.T, fixtures map[string]string) (*Exchange, *[]recordedRequest) {
+	var requests []recordedRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  map[string]string{},
+			Header: r.Header,
+		}
+
+		for k := range r.URL.Query() {
+			record.Query[k] = r.URL.Query().Get(k)
+		}
+
+		if body, _ := ioutil.ReadAll(r.Body); len(body) > 0 {
+			assert.NoError(t, json.Unmarshal(body, &record.Body))
+		}
+
+		requests = append(requests, record)
+
+		fixture, ok := fixtures[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	ex := New("key", "secret")
+	ex.client = bybitapi.NewClient(server.URL)
+	ex.client.Auth("key", "secret")
+	return ex, &requests
+}
+
+func TestExchange_QueryMarkets(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/market/instruments-info": "instruments-info-spot.json",
+	})
+
+	markets, err := ex.QueryMarkets(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "spot", (*requests)[0].Query["category"])
+
+	market, ok := markets["BTCUSDT"]
+	if assert.True(t, ok) {
+		assert.Equal(t, "BTC", market.BaseCurrency)
+		assert.Equal(t, "USDT", market.QuoteCurrency)
+		assert.Equal(t, 2, market.PricePrecision)
+		assert.Equal(t, 6, market.VolumePrecision)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.01"), market.TickSize)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.000001"), market.StepSize)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.000048"), market.MinQuantity)
+		assert.Equal(t, fixedpoint.One, market.MinNotional)
+	}
+}
+
+func TestExchange_QueryMarkets_Futures(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/market/instruments-info": "instruments-info-linear.json",
+	})
+	ex.UseFutures()
+
+	markets, err := ex.QueryMarkets(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "linear", (*requests)[0].Query["category"])
+
+	market, ok := markets["BTCUSDT"]
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, market.PricePrecision)
+		assert.Equal(t, 3, market.VolumePrecision)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.001"), market.StepSize)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.1"), market.MinPrice)
+		assert.Equal(t, fixedpoint.NewFromInt(100), market.MaxQuantity)
+	}
+}
+
+func TestExchange_QueryTickers(t *testing.T) {
+	ex, _ := newTestExchange(t, map[string]string{
+		"GET /v5/market/tickers": "tickers-spot.json",
+	})
+
+	tickers, err := ex.QueryTickers(context.Background())
+	if assert.NoError(t, err) {
+		assert.Len(t, tickers, 2)
+	}
+
+	ticker, err := ex.QueryTicker(context.Background(), "ETHUSDT")
+	if assert.NoError(t, err) {
+		assert.Equal(t, fixedpoint.MustNewFromString("1551.21"), ticker.Last)
+		assert.Equal(t, fixedpoint.MustNewFromString("1551.21"), ticker.Buy)
+		assert.Equal(t, fixedpoint.MustNewFromString("1551.22"), ticker.Sell)
+		assert.Equal(t, fixedpoint.MustNewFromString("1532.04"), ticker.Open)
+	}
+}
+
+func TestExchange_QueryPremiumIndex(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/market/tickers": "tickers-linear.json",
+	})
+
+	index, err := ex.QueryPremiumIndex(context.Background(), "BTCUSDT")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "linear", (*requests)[0].Query["category"])
+		assert.Equal(t, "BTCUSDT", (*requests)[0].Query["symbol"])
+		assert.Equal(t, fixedpoint.MustNewFromString("0.0001"), index.LastFundingRate)
+		assert.Equal(t, fixedpoint.MustNewFromString("20518.70"), index.MarkPrice)
+		assert.Equal(t, time.UnixMilli(1673884800000), index.NextFundingTime)
+	}
+}
+
+func TestExchange_QueryKLines(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/market/kline": "kline-spot.json",
+	})
+
+	startTime := time.UnixMilli(1670605200000)
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval1h, types.KLineQueryOptions{
+		StartTime: &startTime,
+		Limit:     2,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	query := (*requests)[0].Query
+	assert.Equal(t, "60", query["interval"])
+	assert.Equal(t, "1670605200000", query["start"])
+	assert.Equal(t, "2", query["limit"])
+
+	// the klines are sorted in the ascending order
+	if assert.Len(t, klines, 2) {
+		assert.Equal(t, int64(1670605200000), klines[0].StartTime.Time().UnixMilli())
+		assert.Equal(t, int64(1670608800000), klines[1].StartTime.Time().UnixMilli())
+		assert.Equal(t, int64(1670612399999), klines[1].EndTime.Time().UnixMilli())
+		assert.Equal(t, fixedpoint.MustNewFromString("17055.5"), klines[1].Close)
+		assert.Equal(t, fixedpoint.MustNewFromString("15.74462667"), klines[1].QuoteVolume)
+		assert.Equal(t, types.ExchangeBybit, klines[1].Exchange)
+	}
+
+	_, err = ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval1s, types.KLineQueryOptions{})
+	assert.Error(t, err)
+}
+
+func TestExchange_QueryAccountBalances(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/account/wallet-balance": "wallet-balance-spot.json",
+	})
+
+	balances, err := ex.QueryAccountBalances(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	request := (*requests)[0]
+	assert.Equal(t, "SPOT", request.Query["accountType"])
+	assert.Equal(t, "key", request.Header.Get("X-BAPI-API-KEY"))
+	assert.NotEmpty(t, request.Header.Get("X-BAPI-SIGN"))
+
+	assert.Equal(t, fixedpoint.MustNewFromString("900.5"), balances["USDT"].Available)
+	assert.Equal(t, fixedpoint.NewFromInt(100), balances["USDT"].Locked)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.1"), balances["BTC"].Available)
+	assert.Equal(t, fixedpoint.Zero, balances["BTC"].Locked)
+}
+
+func TestExchange_SubmitOrder(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"POST /v5/order/create": "order-create.json",
+	})
+
+	order, err := ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		ClientOrderID: "spot-test-postonly",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimitMaker,
+		Quantity:      fixedpoint.MustNewFromString("0.1"),
+		Price:         fixedpoint.NewFromInt(18900),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, uint64(1321003749386327552), order.OrderID)
+	assert.Equal(t, "1321003749386327552", order.UUID)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+
+	body := (*requests)[0].Body
+	assert.Equal(t, "spot", body["category"])
+	assert.Equal(t, "Buy", body["side"])
+	assert.Equal(t, "Limit", body["orderType"])
+	assert.Equal(t, "PostOnly", body["timeInForce"])
+	assert.Equal(t, "spot-test-postonly", body["orderLinkId"])
+	assert.Equal(t, "0.10000000", body["qty"])
+	assert.Equal(t, "18900.00000000", body["price"])
+
+	// the quantity of the spot market order is in the base currency
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.MustNewFromString("0.01"),
+	})
+	if assert.NoError(t, err) {
+		body = (*requests)[1].Body
+		assert.Equal(t, "Market", body["orderType"])
+		assert.Equal(t, "baseCoin", body["marketUnit"])
+		assert.NotContains(t, body, "price")
+	}
+
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeStopLimit,
+		Quantity: fixedpoint.MustNewFromString("0.01"),
+	})
+	assert.Error(t, err)
+}
+
+func TestExchange_CancelOrders(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"POST /v5/order/cancel": "order-cancel-error.json",
+	})
+
+	err := ex.CancelOrders(context.Background(), types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"},
+		UUID:        "1321003749386327552",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "170213")
+	}
+
+	assert.Equal(t, "1321003749386327552", (*requests)[0].Body["orderId"])
+}
+
+func TestExchange_QueryOrder(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/order/realtime": "order-realtime.json",
+	})
+
+	order, err := ex.QueryOrder(context.Background(), types.OrderQuery{
+		Symbol:        "BTCUSDT",
+		ClientOrderID: "spot-test-postonly",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "spot-test-postonly", (*requests)[0].Query["orderLinkId"])
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, types.OrderTypeLimitMaker, order.Type)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.04"), order.ExecutedQuantity)
+	assert.True(t, order.IsWorking)
+	assert.Equal(t, int64(1672211918467), order.CreationTime.Time().UnixMilli())
+}
+
+func TestExchange_QueryClosedOrders(t *testing.T) {
+	ex, requests := newTestExchange(t, map[string]string{
+		"GET /v5/order/history": "order-history.json",
+	})
+
+	since := time.UnixMilli(1672000000000)
+	orders, err := ex.QueryClosedOrders(context.Background(), "BTCUSDT", since, since.Add(30*24*time.Hour), 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the time range is limited to 7 days
+	query := (*requests)[0].Query
+	assert.Equal(t, "1672000000000", query["startTime"])
+	assert.Equal(t, "1672604800000", query["endTime"])
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, uint64(1321003749386327552), orders[0].OrderID)
+		assert.Equal(t, types.OrderStatusCanceled, orders[0].Status)
+		assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+		assert.Equal(t, types.OrderTypeMarket, orders[1].Type)
+		assert.False(t, orders[1].IsWorking)
+	}
+}
+
+func TestExchange_QueryTrades(t *testing.T) {
+	ex, _ := newTestExchange(t, map[string]string{
+		"GET /v5/execution/list": "execution-list.json",
+	})
+
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSDT", &types.TradeQueryOptions{})
+	if !assert.NoError(t, err) || !assert.Len(t, trades, 2) {
+		return
+	}
+
+	// the trades are sorted in the ascending order
+	buyTrade := trades[0]
+	assert.Equal(t, uint64(2100000000007764200), buyTrade.ID)
+	assert.Equal(t, uint64(1321003749386327552), buyTrade.OrderID)
+	assert.Equal(t, types.SideTypeBuy, buyTrade.Side)
+	assert.True(t, buyTrade.IsBuyer)
+	assert.True(t, buyTrade.IsMaker)
+	assert.Equal(t, "BTC", buyTrade.FeeCurrency)
+	assert.Equal(t, fixedpoint.NewFromInt(756), buyTrade.QuoteQuantity)
+
+	sellTrade := trades[1]
+	assert.Equal(t, types.SideTypeSell, sellTrade.Side)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.1691214"), sellTrade.Fee)
+	assert.Equal(t, "USDT", sellTrade.FeeCurrency)
+	assert.False(t, sellTrade.IsFutures)
+}
@@ -0,0 +1,83 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func parseWebSocketEvent(in []byte) (interface{}, error) {
+	var e WebSocketTopicEvent
+	if err := json.Unmarshal(in, &e); err != nil {
+		return nil, err
+	}
+
+	// the responses of the operations do not have the topic field
+	if len(e.Topic) == 0 {
+		var op WebSocketOpEvent
+		if err := json.Unmarshal(in, &op); err != nil {
+			return nil, err
+		}
+
+		return &op, nil
+	}
+
+	// the public topics are in the format of {channel}.{params}.{symbol}, e.g., orderbook.50.BTCUSDT, kline.5.BTCUSDT
+	topicParts := strings.Split(e.Topic, ".")
+	symbol := topicParts[len(topicParts)-1]
+
+	switch topicParts[0] {
+	case TopicOrderBook:
+		var o BookEvent
+		if err := json.Unmarshal(e.Data, &o); err != nil {
+			return nil, err
+		}
+
+		o.Type = e.Type
+		o.Ts = e.Ts
+		return &o, nil
+
+	case TopicKLine:
+		var o KLineEvent
+		if err := json.Unmarshal(e.Data, &o.KLines); err != nil {
+			return nil, err
+		}
+
+		o.Symbol = symbol
+		return &o, nil
+
+	case TopicPublicTrade:
+		var o MarketTradeEvent
+		if err := json.Unmarshal(e.Data, &o.Trades); err != nil {
+			return nil, err
+		}
+
+		return &o, nil
+
+	case TopicOrder:
+		var o OrderEvent
+		if err := json.Unmarshal(e.Data, &o.Orders); err != nil {
+			return nil, err
+		}
+
+		return &o, nil
+
+	case TopicExecution:
+		var o ExecutionEvent
+		if err := json.Unmarshal(e.Data, &o.Executions); err != nil {
+			return nil, err
+		}
+
+		return &o, nil
+
+	case TopicWallet:
+		var o WalletEvent
+		if err := json.Unmarshal(e.Data, &o.Wallets); err != nil {
+			return nil, err
+		}
+
+		return &o, nil
+	}
+
+	return nil, fmt.Errorf("unsupported topic: %s", e.Topic)
+}
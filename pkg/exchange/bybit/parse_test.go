@@ -0,0 +1,182 @@
+package bybit
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func parseFixture(t *testing.T, name string) interface{} {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	e, err := parseWebSocketEvent(data)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return e
+}
+
+func Test_parseWebSocketEvent(t *testing.T) {
+	op, ok := parseFixture(t, "ws-auth.json").(*WebSocketOpEvent)
+	if assert.True(t, ok) {
+		assert.True(t, op.Success)
+		assert.Equal(t, WebSocketOpAuth, op.Op)
+	}
+
+	book, ok := parseFixture(t, "ws-orderbook-snapshot.json").(*BookEvent)
+	if assert.True(t, ok) {
+		assert.Equal(t, EventTypeSnapshot, book.Type)
+		assert.Equal(t, "BTCUSDT", book.Symbol)
+		assert.Len(t, book.Bids, 2)
+		assert.Len(t, book.Asks, 2)
+		assert.Equal(t, fixedpoint.MustNewFromString("16493.50"), book.Bids[0].Price)
+		assert.Equal(t, int64(7961638724), book.Sequence)
+	}
+
+	kline, ok := parseFixture(t, "ws-kline.json").(*KLineEvent)
+	if assert.True(t, ok) && assert.Len(t, kline.KLines, 1) {
+		k := kline.KLine(kline.KLines[0])
+		assert.Equal(t, "BTCUSDT", k.Symbol)
+		assert.Equal(t, types.Interval5m, k.Interval)
+		assert.True(t, k.Closed)
+		assert.Equal(t, fixedpoint.NewFromInt(16677), k.Close)
+	}
+
+	trade, ok := parseFixture(t, "ws-public-trade.json").(*MarketTradeEvent)
+	if assert.True(t, ok) && assert.Len(t, trade.Trades, 1) {
+		assert.Equal(t, bybitapi.SideBuy, trade.Trades[0].Side)
+		assert.Equal(t, "20f43950-d8dd-5b31-9112-a178eb6023af", trade.Trades[0].TradeID)
+	}
+
+	order, ok := parseFixture(t, "ws-order.json").(*OrderEvent)
+	if assert.True(t, ok) && assert.Len(t, order.Orders, 1) {
+		assert.Equal(t, bybitapi.CategoryLinear, order.Orders[0].Category)
+		assert.Equal(t, bybitapi.OrderStatusFilled, order.Orders[0].OrderStatus)
+	}
+
+	execution, ok := parseFixture(t, "ws-execution.json").(*ExecutionEvent)
+	if assert.True(t, ok) && assert.Len(t, execution.Executions, 1) {
+		assert.Equal(t, "XRPUSDT", execution.Executions[0].Symbol)
+	}
+
+	wallet, ok := parseFixture(t, "ws-wallet.json").(*WalletEvent)
+	if assert.True(t, ok) && assert.Len(t, wallet.Wallets, 1) {
+		assert.Equal(t, bybitapi.AccountTypeUnified, wallet.Wallets[0].AccountType)
+	}
+
+	_, err := parseWebSocketEvent([]byte(`{"topic":"tickers.BTCUSDT","data":{}}`))
+	assert.Error(t, err)
+}
+
+func TestStream_dispatchEvent(t *testing.T) {
+	stream := NewStream("key", "secret", bybitapi.CategoryLinear)
+
+	var snapshots, updates []types.SliceOrderBook
+	stream.OnBookSnapshot(func(book types.SliceOrderBook) { snapshots = append(snapshots, book) })
+	stream.OnBookUpdate(func(book types.SliceOrderBook) { updates = append(updates, book) })
+
+	var closedKLines []types.KLine
+	stream.OnKLineClosed(func(kline types.KLine) { closedKLines = append(closedKLines, kline) })
+
+	var marketTrades, trades []types.Trade
+	stream.OnMarketTrade(func(trade types.Trade) { marketTrades = append(marketTrades, trade) })
+	stream.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+
+	var orders []types.Order
+	stream.OnOrderUpdate(func(order types.Order) { orders = append(orders, order) })
+
+	var balances types.BalanceMap
+	stream.OnBalanceUpdate(func(b types.BalanceMap) { balances = b })
+
+	for _, fixture := range []string{
+		"ws-orderbook-snapshot.json",
+		"ws-orderbook-delta.json",
+		"ws-kline.json",
+		"ws-public-trade.json",
+		"ws-order.json",
+		"ws-execution.json",
+		"ws-wallet.json",
+	} {
+		stream.dispatchEvent(parseFixture(t, fixture))
+	}
+
+	assert.Len(t, snapshots, 1)
+	if assert.Len(t, updates, 1) {
+		// the zero quantity removes the price level
+		assert.Equal(t, fixedpoint.Zero, updates[0].Bids[0].Volume)
+	}
+
+	assert.Len(t, closedKLines, 1)
+
+	if assert.Len(t, marketTrades, 1) {
+		assert.Equal(t, types.SideTypeBuy, marketTrades[0].Side)
+		assert.NotZero(t, marketTrades[0].ID)
+		assert.True(t, marketTrades[0].IsFutures)
+	}
+
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "ETHUSDT", orders[0].Symbol)
+		assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+		assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+		assert.Equal(t, "5cf98598-39a7-459e-97bf-76ca765ee020", orders[0].UUID)
+	}
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, types.SideTypeSell, trades[0].Side)
+		assert.Equal(t, "USDT", trades[0].FeeCurrency)
+		assert.Equal(t, fixedpoint.MustNewFromString("8.435"), trades[0].QuoteQuantity)
+	}
+
+	if assert.Contains(t, balances, "USDC") {
+		assert.Equal(t, fixedpoint.MustNewFromString("201.34882644"), balances["USDC"].Available)
+	}
+
+	// the events of the other categories are ignored
+	spotStream := NewStream("key", "secret", bybitapi.CategorySpot)
+	spotStream.OnOrderUpdate(func(order types.Order) {
+		t.Errorf("unexpected order update: %+v", order)
+	})
+	spotStream.dispatchEvent(parseFixture(t, "ws-order.json"))
+}
+
+func Test_convertSubscriptions(t *testing.T) {
+	cmd, err := convertSubscriptions([]types.Subscription{
+		{Channel: types.BookChannel, Symbol: "BTCUSDT"},
+		{Channel: types.BookChannel, Symbol: "ETHUSDT", Options: types.SubscribeOptions{Depth: types.DepthLevel1}},
+		{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: types.Interval1h}},
+		{Channel: types.MarketTradeChannel, Symbol: "BTCUSDT"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, WebSocketOpSubscribe, cmd.Op)
+		assert.Equal(t, []interface{}{
+			"orderbook.50.BTCUSDT",
+			"orderbook.1.ETHUSDT",
+			"kline.60.BTCUSDT",
+			"publicTrade.BTCUSDT",
+		}, cmd.Args)
+	}
+
+	_, err = convertSubscriptions([]types.Subscription{{Channel: types.BookTickerChannel, Symbol: "BTCUSDT"}})
+	assert.Error(t, err)
+}
+
+func Test_newAuthCommand(t *testing.T) {
+	cmd := newAuthCommand("key", "secret", time.UnixMilli(1662350400000))
+	assert.Equal(t, WebSocketOpAuth, cmd.Op)
+	assert.Equal(t, []interface{}{
+		"key",
+		"1662350410000",
+		bybitapi.Sign("secret", "GET/realtime1662350410000"),
+	}, cmd.Args)
+}
@@ -0,0 +1,302 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	publicWebSocketURL  = "wss://stream.bybit.com/v5/public"
+	privateWebSocketURL = "wss://stream.bybit.com/v5/private"
+
+	// bybit recommends sending the ping heartbeat packet every 20 seconds to maintain the websocket connection
+	pingInterval = 20 * time.Second
+
+	// authExpiration is the expiration of the private websocket authentication
+	authExpiration = 10 * time.Second
+)
+
+//go:generate callbackgen -type Stream -interface
+type Stream struct {
+	types.StandardStream
+
+	key, secret string
+	category    bybitapi.Category
+
+	// publicEndpoint and privateEndpoint are the websocket endpoints, the public endpoint is suffixed with the category
+	publicEndpoint, privateEndpoint string
+
+	// writeMutex is used for serializing the writes of the connection,
+	// the commands are written by the connect handler, the event dispatcher and the ping worker
+	writeMutex sync.Mutex
+
+	opEventCallbacks          []func(e *WebSocketOpEvent)
+	bookEventCallbacks        []func(e *BookEvent)
+	kLineEventCallbacks       []func(e *KLineEvent)
+	marketTradeEventCallbacks []func(e *MarketTradeEvent)
+	orderEventCallbacks       []func(e *OrderEvent)
+	executionEventCallbacks   []func(e *ExecutionEvent)
+	walletEventCallbacks      []func(e *WalletEvent)
+}
+
+func NewStream(key, secret string, category bybitapi.Category) *Stream {
+	stream := &Stream{
+		StandardStream: types.NewStandardStream(),
+		key:            key,
+		// pragma: allowlist nextline secret
+		secret:          secret,
+		category:        category,
+		publicEndpoint:  publicWebSocketURL,
+		privateEndpoint: privateWebSocketURL,
+	}
+
+	stream.SetParser(parseWebSocketEvent)
+	stream.SetDispatcher(stream.dispatchEvent)
+	stream.SetEndpointCreator(stream.getEndpoint)
+
+	stream.OnConnect(stream.handleConnect)
+	stream.OnOpEvent(stream.handleOpEvent)
+	stream.OnBookEvent(stream.handleBookEvent)
+	stream.OnKLineEvent(stream.handleKLineEvent)
+	stream.OnMarketTradeEvent(stream.handleMarketTradeEvent)
+	stream.OnOrderEvent(stream.handleOrderEvent)
+	stream.OnExecutionEvent(stream.handleExecutionEvent)
+	stream.OnWalletEvent(stream.handleWalletEvent)
+	return stream
+}
+
+func (s *Stream) getEndpoint(ctx context.Context) (string, error) {
+	if s.PublicOnly {
+		return s.publicEndpoint + "/" + string(s.category), nil
+	}
+
+	return s.privateEndpoint, nil
+}
+
+func (s *Stream) writeJSON(conn *websocket.Conn, v interface{}) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (s *Stream) handleConnect() {
+	s.ConnLock.Lock()
+	conn, connCtx := s.Conn, s.ConnCtx
+	s.ConnLock.Unlock()
+
+	go s.ping(connCtx, conn, pingInterval)
+
+	if s.PublicOnly {
+		cmd, err := convertSubscriptions(s.Subscriptions)
+		if err != nil {
+			log.WithError(err).Errorf("subscription convert error")
+			return
+		}
+
+		if err := s.writeJSON(conn, cmd); err != nil {
+			log.WithError(err).Errorf("subscribe write error, cmd: %+v", cmd)
+		}
+		return
+	}
+
+	// the private topics are subscribed after the authentication succeeded
+	if err := s.writeJSON(conn, newAuthCommand(s.key, s.secret, time.Now())); err != nil {
+		log.WithError(err).Errorf("auth write error")
+	}
+}
+
+// newAuthCommand creates the auth command, the signature is the hex encoded hmac-sha256 of "GET/realtime{expires}"
+func newAuthCommand(key, secret string, now time.Time) WebSocketCommand {
+	expires := strconv.FormatInt(now.Add(authExpiration).UnixMilli(), 10)
+	return WebSocketCommand{
+		Op:   WebSocketOpAuth,
+		Args: []interface{}{key, expires, bybitapi.Sign(secret, "GET/realtime"+expires)},
+	}
+}
+
+func (s *Stream) handleOpEvent(e *WebSocketOpEvent) {
+	if e.Op == WebSocketOpPing || e.Op == WebSocketOpPong {
+		return
+	}
+
+	if !e.Success {
+		log.Errorf("websocket %s operation failed: %s", e.Op, e.RetMsg)
+		return
+	}
+
+	if e.Op == WebSocketOpAuth {
+		s.ConnLock.Lock()
+		conn := s.Conn
+		s.ConnLock.Unlock()
+
+		cmd := WebSocketCommand{
+			Op:   WebSocketOpSubscribe,
+			Args: []interface{}{TopicOrder, TopicExecution, TopicWallet},
+		}
+		if err := s.writeJSON(conn, cmd); err != nil {
+			log.WithError(err).Errorf("private subscribe write error, cmd: %+v", cmd)
+		}
+	}
+}
+
+func (s *Stream) handleBookEvent(e *BookEvent) {
+	book := e.SliceOrderBook()
+	switch e.Type {
+	case EventTypeSnapshot:
+		s.EmitBookSnapshot(book)
+	case EventTypeDelta:
+		s.EmitBookUpdate(book)
+	}
+}
+
+func (s *Stream) handleKLineEvent(e *KLineEvent) {
+	for _, k := range e.KLines {
+		kline := e.KLine(k)
+		if kline.Closed {
+			s.EmitKLineClosed(kline)
+		} else {
+			s.EmitKLine(kline)
+		}
+	}
+}
+
+func (s *Stream) handleMarketTradeEvent(e *MarketTradeEvent) {
+	for _, t := range e.Trades {
+		s.EmitMarketTrade(types.Trade{
+			ID:            parseID(t.TradeID),
+			Exchange:      types.ExchangeBybit,
+			Price:         t.Price,
+			Quantity:      t.Quantity,
+			QuoteQuantity: t.Price.Mul(t.Quantity),
+			Symbol:        toGlobalSymbol(t.Symbol),
+			Side:          toGlobalSide(t.Side),
+			IsBuyer:       t.Side == bybitapi.SideBuy,
+			Time:          types.Time(t.Time),
+			IsFutures:     s.category == bybitapi.CategoryLinear,
+		})
+	}
+}
+
+func (s *Stream) handleOrderEvent(e *OrderEvent) {
+	for _, o := range e.Orders {
+		if o.Category != s.category {
+			continue
+		}
+
+		s.EmitOrderUpdate(toGlobalOrder(o.Order, o.Category))
+	}
+}
+
+func (s *Stream) handleExecutionEvent(e *ExecutionEvent) {
+	for _, execution := range e.Executions {
+		if execution.Category != s.category {
+			continue
+		}
+
+		s.EmitTradeUpdate(toGlobalTrade(execution.Execution, execution.Category))
+	}
+}
+
+func (s *Stream) handleWalletEvent(e *WalletEvent) {
+	s.EmitBalanceUpdate(toGlobalBalanceMap(e.Wallets))
+}
+
+func (s *Stream) dispatchEvent(event interface{}) {
+	switch e := event.(type) {
+	case *WebSocketOpEvent:
+		s.EmitOpEvent(e)
+
+	case *BookEvent:
+		s.EmitBookEvent(e)
+
+	case *KLineEvent:
+		s.EmitKLineEvent(e)
+
+	case *MarketTradeEvent:
+		s.EmitMarketTradeEvent(e)
+
+	case *OrderEvent:
+		s.EmitOrderEvent(e)
+
+	case *ExecutionEvent:
+		s.EmitExecutionEvent(e)
+
+	case *WalletEvent:
+		s.EmitWalletEvent(e)
+
+	default:
+		log.Warnf("unhandled event: %+v", e)
+	}
+}
+
+// ping sends the application level ping command, the websocket control frame is not recognized by bybit
+func (s *Stream) ping(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	pingTicker := time.NewTicker(interval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			log.Debug("ping worker stopped")
+			return
+
+		case <-pingTicker.C:
+			if err := s.writeJSON(conn, WebSocketCommand{Op: WebSocketOpPing}); err != nil {
+				log.WithError(err).Error("websocket ping error")
+				s.Reconnect()
+			}
+		}
+	}
+}
+
+func convertSubscriptions(subscriptions []types.Subscription) (WebSocketCommand, error) {
+	cmd := WebSocketCommand{Op: WebSocketOpSubscribe}
+	for _, sub := range subscriptions {
+		topic, err := convertSubscription(sub)
+		if err != nil {
+			return cmd, err
+		}
+
+		cmd.Args = append(cmd.Args, topic)
+	}
+
+	return cmd, nil
+}
+
+func convertSubscription(sub types.Subscription) (string, error) {
+	symbol := toLocalSymbol(sub.Symbol)
+	switch sub.Channel {
+	case types.BookChannel:
+		depth := 50
+		switch sub.Options.Depth {
+		case types.DepthLevel1:
+			depth = 1
+		case types.DepthLevelFull:
+			depth = 200
+		}
+
+		return fmt.Sprintf("%s.%d.%s", TopicOrderBook, depth, symbol), nil
+
+	case types.KLineChannel:
+		interval, ok := toLocalInterval[sub.Options.Interval]
+		if !ok {
+			return "", fmt.Errorf("interval %s is not supported", sub.Options.Interval)
+		}
+
+		return fmt.Sprintf("%s.%s.%s", TopicKLine, interval, symbol), nil
+
+	case types.MarketTradeChannel:
+		return fmt.Sprintf("%s.%s", TopicPublicTrade, symbol), nil
+	}
+
+	return "", fmt.Errorf("channel %s is not supported", sub.Channel)
+}
@@ -0,0 +1,151 @@
+package bybit
+
+import (
+	"encoding/json"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type WebSocketOp string
+
+const (
+	WebSocketOpAuth      WebSocketOp = "auth"
+	WebSocketOpSubscribe WebSocketOp = "subscribe"
+	WebSocketOpPing      WebSocketOp = "ping"
+	WebSocketOpPong      WebSocketOp = "pong"
+)
+
+// WebSocketCommand is the request message of the websocket operations
+type WebSocketCommand struct {
+	ReqID string        `json:"req_id,omitempty"`
+	Op    WebSocketOp   `json:"op"`
+	Args  []interface{} `json:"args,omitempty"`
+}
+
+// WebSocketOpEvent is the response of the websocket operations, e.g.,
+// {"success":true,"ret_msg":"","op":"auth","conn_id":"cejreaspqfh3sjdnldmg-p"}
+type WebSocketOpEvent struct {
+	Success bool        `json:"success"`
+	RetMsg  string      `json:"ret_msg"`
+	ReqID   string      `json:"req_id"`
+	ConnID  string      `json:"conn_id"`
+	Op      WebSocketOp `json:"op"`
+}
+
+const (
+	TopicOrderBook   = "orderbook"
+	TopicKLine       = "kline"
+	TopicPublicTrade = "publicTrade"
+	TopicOrder       = "order"
+	TopicExecution   = "execution"
+	TopicWallet      = "wallet"
+)
+
+type EventType string
+
+const (
+	EventTypeSnapshot EventType = "snapshot"
+	EventTypeDelta    EventType = "delta"
+)
+
+// WebSocketTopicEvent is the message pushed by the subscribed topics
+type WebSocketTopicEvent struct {
+	Topic string                     `json:"topic"`
+	Type  EventType                  `json:"type"`
+	Ts    types.MillisecondTimestamp `json:"ts"`
+	Data  json.RawMessage            `json:"data"`
+}
+
+type BookEvent struct {
+	Type     EventType
+	Ts       types.MillisecondTimestamp
+	Symbol   string                 `json:"s"`
+	Bids     types.PriceVolumeSlice `json:"b"`
+	Asks     types.PriceVolumeSlice `json:"a"`
+	UpdateID int64                  `json:"u"`
+	Sequence int64                  `json:"seq"`
+}
+
+func (e *BookEvent) SliceOrderBook() types.SliceOrderBook {
+	return types.SliceOrderBook{
+		Symbol: toGlobalSymbol(e.Symbol),
+		Bids:   e.Bids,
+		Asks:   e.Asks,
+	}
+}
+
+type KLineEventData struct {
+	StartTime types.MillisecondTimestamp `json:"start"`
+	EndTime   types.MillisecondTimestamp `json:"end"`
+	Interval  string                     `json:"interval"`
+	Open      fixedpoint.Value           `json:"open"`
+	Close     fixedpoint.Value           `json:"close"`
+	High      fixedpoint.Value           `json:"high"`
+	Low       fixedpoint.Value           `json:"low"`
+	Volume    fixedpoint.Value           `json:"volume"`
+	Turnover  fixedpoint.Value           `json:"turnover"`
+	Confirm   bool                       `json:"confirm"`
+	Timestamp types.MillisecondTimestamp `json:"timestamp"`
+}
+
+type KLineEvent struct {
+	Symbol string
+	KLines []KLineEventData
+}
+
+func (e *KLineEvent) KLine(k KLineEventData) types.KLine {
+	return types.KLine{
+		Exchange:    types.ExchangeBybit,
+		Symbol:      toGlobalSymbol(e.Symbol),
+		StartTime:   types.Time(k.StartTime),
+		EndTime:     types.Time(k.EndTime),
+		Interval:    toGlobalInterval(k.Interval),
+		Open:        k.Open,
+		Close:       k.Close,
+		High:        k.High,
+		Low:         k.Low,
+		Volume:      k.Volume,
+		QuoteVolume: k.Turnover,
+		Closed:      k.Confirm,
+	}
+}
+
+type MarketTradeEventData struct {
+	Time         types.MillisecondTimestamp `json:"T"`
+	Symbol       string                     `json:"s"`
+	Side         bybitapi.Side              `json:"S"`
+	Quantity     fixedpoint.Value           `json:"v"`
+	Price        fixedpoint.Value           `json:"p"`
+	TradeID      string                     `json:"i"`
+	IsBlockTrade bool                       `json:"BT"`
+}
+
+type MarketTradeEvent struct {
+	Trades []MarketTradeEventData
+}
+
+type OrderEventData struct {
+	bybitapi.Order
+
+	Category bybitapi.Category `json:"category"`
+}
+
+type OrderEvent struct {
+	Orders []OrderEventData
+}
+
+type ExecutionEventData struct {
+	bybitapi.Execution
+
+	Category bybitapi.Category `json:"category"`
+}
+
+type ExecutionEvent struct {
+	Executions []ExecutionEventData
+}
+
+type WalletEvent struct {
+	Wallets []bybitapi.WalletBalance
+}
@@ -0,0 +1,159 @@
+package conformance
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// quoteQuantityTolerance is the relative tolerance of the quote quantity against price * quantity
+var quoteQuantityTolerance = fixedpoint.NewFromFloat(0.0001)
+
+// CheckTrade checks the required fields of the converted trade
+func CheckTrade(trade types.Trade) error {
+	if len(trade.Exchange) == 0 {
+		return fmt.Errorf("trade %d: exchange name is empty", trade.ID)
+	}
+
+	if trade.ID == 0 {
+		return fmt.Errorf("trade: id is zero: %+v", trade)
+	}
+
+	if trade.OrderID == 0 {
+		return fmt.Errorf("trade %d: order id is zero", trade.ID)
+	}
+
+	if len(trade.Symbol) == 0 {
+		return fmt.Errorf("trade %d: symbol is empty", trade.ID)
+	}
+
+	if trade.Price.Sign() <= 0 {
+		return fmt.Errorf("trade %d: price %s is not positive", trade.ID, trade.Price.String())
+	}
+
+	if trade.Quantity.Sign() <= 0 {
+		return fmt.Errorf("trade %d: quantity %s is not positive", trade.ID, trade.Quantity.String())
+	}
+
+	expectedQuoteQuantity := trade.Price.Mul(trade.Quantity)
+	if trade.QuoteQuantity.Sub(expectedQuoteQuantity).Abs().Compare(expectedQuoteQuantity.Mul(quoteQuantityTolerance)) > 0 {
+		return fmt.Errorf("trade %d: quote quantity %s does not match price * quantity %s",
+			trade.ID, trade.QuoteQuantity.String(), expectedQuoteQuantity.String())
+	}
+
+	if trade.Side != types.SideTypeBuy && trade.Side != types.SideTypeSell {
+		return fmt.Errorf("trade %d: invalid side %q", trade.ID, trade.Side)
+	}
+
+	if trade.IsBuyer != (trade.Side == types.SideTypeBuy) {
+		return fmt.Errorf("trade %d: isBuyer %v is inconsistent with side %s", trade.ID, trade.IsBuyer, trade.Side)
+	}
+
+	if trade.Fee.Sign() < 0 {
+		return fmt.Errorf("trade %d: fee %s is negative", trade.ID, trade.Fee.String())
+	}
+
+	if len(trade.FeeCurrency) == 0 {
+		return fmt.Errorf("trade %d: fee currency is empty", trade.ID)
+	}
+
+	if trade.Time.Time().IsZero() {
+		return fmt.Errorf("trade %d: trade time is zero", trade.ID)
+	}
+
+	return nil
+}
+
+// CheckOrder checks the required fields of the converted order
+func CheckOrder(order types.Order) error {
+	if len(order.Exchange) == 0 {
+		return fmt.Errorf("order %d: exchange name is empty", order.OrderID)
+	}
+
+	if order.OrderID == 0 {
+		return fmt.Errorf("order: id is zero: %+v", order)
+	}
+
+	if len(order.Symbol) == 0 {
+		return fmt.Errorf("order %d: symbol is empty", order.OrderID)
+	}
+
+	if order.Side != types.SideTypeBuy && order.Side != types.SideTypeSell {
+		return fmt.Errorf("order %d: invalid side %q", order.OrderID, order.Side)
+	}
+
+	if len(order.Type) == 0 {
+		return fmt.Errorf("order %d: order type is empty", order.OrderID)
+	}
+
+	if _, ok := orderStatusRanks[order.Status]; !ok {
+		return fmt.Errorf("order %d: unknown order status %q", order.OrderID, order.Status)
+	}
+
+	if order.Quantity.Sign() <= 0 {
+		return fmt.Errorf("order %d: quantity %s is not positive", order.OrderID, order.Quantity.String())
+	}
+
+	if order.ExecutedQuantity.Sign() < 0 || order.ExecutedQuantity.Compare(order.Quantity) > 0 {
+		return fmt.Errorf("order %d: executed quantity %s is out of the range [0, %s]",
+			order.OrderID, order.ExecutedQuantity.String(), order.Quantity.String())
+	}
+
+	if order.Status == types.OrderStatusFilled && order.ExecutedQuantity.Compare(order.Quantity) != 0 {
+		return fmt.Errorf("order %d: filled order executed quantity %s does not equal to the quantity %s",
+			order.OrderID, order.ExecutedQuantity.String(), order.Quantity.String())
+	}
+
+	if order.CreationTime.Time().IsZero() {
+		return fmt.Errorf("order %d: creation time is zero", order.OrderID)
+	}
+
+	return nil
+}
+
+// orderStatusRanks defines the order of the status transitions, the status can only move to the same or a higher rank
+var orderStatusRanks = map[types.OrderStatus]int{
+	types.OrderStatusNew:             0,
+	types.OrderStatusPartiallyFilled: 1,
+	types.OrderStatusFilled:          2,
+	types.OrderStatusCanceled:        2,
+	types.OrderStatusRejected:        2,
+}
+
+func isFinalOrderStatus(status types.OrderStatus) bool {
+	return orderStatusRanks[status] == 2
+}
+
+// CheckOrderStatusTransitions checks the order updates in the received sequence:
+// the status never moves backward, no update is received after the final status,
+// and the executed quantity never decreases.
+func CheckOrderStatusTransitions(updates []types.Order) error {
+	last := make(map[uint64]types.Order)
+	for _, order := range updates {
+		if err := CheckOrder(order); err != nil {
+			return err
+		}
+
+		prev, ok := last[order.OrderID]
+		last[order.OrderID] = order
+		if !ok {
+			continue
+		}
+
+		if isFinalOrderStatus(prev.Status) {
+			return fmt.Errorf("order %d: received update %s after the final status %s", order.OrderID, order.Status, prev.Status)
+		}
+
+		if orderStatusRanks[order.Status] < orderStatusRanks[prev.Status] {
+			return fmt.Errorf("order %d: invalid status transition %s -> %s", order.OrderID, prev.Status, order.Status)
+		}
+
+		if order.ExecutedQuantity.Compare(prev.ExecutedQuantity) < 0 {
+			return fmt.Errorf("order %d: executed quantity decreased %s -> %s",
+				order.OrderID, prev.ExecutedQuantity.String(), order.ExecutedQuantity.String())
+		}
+	}
+
+	return nil
+}
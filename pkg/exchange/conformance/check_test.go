@@ -0,0 +1,93 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestTrade() types.Trade {
+	return types.Trade{
+		ID:            1,
+		OrderID:       2,
+		Exchange:      types.ExchangeBinance,
+		Price:         fixedpoint.NewFromInt(20000),
+		Quantity:      fixedpoint.NewFromFloat(0.5),
+		QuoteQuantity: fixedpoint.NewFromInt(10000),
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Time:          types.Time(time.Now()),
+		Fee:           fixedpoint.NewFromFloat(0.0005),
+		FeeCurrency:   "BTC",
+	}
+}
+
+func newTestOrder(status types.OrderStatus, executed float64) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeLimit,
+			Quantity: fixedpoint.One,
+			Price:    fixedpoint.NewFromInt(20000),
+		},
+		Exchange:         types.ExchangeBinance,
+		OrderID:          1,
+		Status:           status,
+		ExecutedQuantity: fixedpoint.NewFromFloat(executed),
+		CreationTime:     types.Time(time.Now()),
+	}
+}
+
+func TestCheckTrade(t *testing.T) {
+	assert.NoError(t, CheckTrade(newTestTrade()))
+
+	trade := newTestTrade()
+	trade.FeeCurrency = ""
+	assert.Error(t, CheckTrade(trade))
+
+	trade = newTestTrade()
+	trade.Fee = fixedpoint.NewFromFloat(-0.1)
+	assert.Error(t, CheckTrade(trade))
+
+	trade = newTestTrade()
+	trade.IsBuyer = false
+	assert.Error(t, CheckTrade(trade))
+
+	trade = newTestTrade()
+	trade.QuoteQuantity = fixedpoint.NewFromInt(20000)
+	assert.Error(t, CheckTrade(trade))
+}
+
+func TestCheckOrderStatusTransitions(t *testing.T) {
+	assert.NoError(t, CheckOrderStatusTransitions([]types.Order{
+		newTestOrder(types.OrderStatusNew, 0),
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.3),
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.6),
+		newTestOrder(types.OrderStatusFilled, 1.0),
+	}))
+
+	assert.Error(t, CheckOrderStatusTransitions([]types.Order{
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.3),
+		newTestOrder(types.OrderStatusNew, 0.3),
+	}), "status moves backward")
+
+	assert.Error(t, CheckOrderStatusTransitions([]types.Order{
+		newTestOrder(types.OrderStatusCanceled, 0),
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.3),
+	}), "update after the final status")
+
+	assert.Error(t, CheckOrderStatusTransitions([]types.Order{
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.6),
+		newTestOrder(types.OrderStatusPartiallyFilled, 0.3),
+	}), "executed quantity decreases")
+
+	assert.Error(t, CheckOrderStatusTransitions([]types.Order{
+		newTestOrder(types.OrderStatusFilled, 0.5),
+	}), "filled order is not fully executed")
+}
@@ -0,0 +1,239 @@
+package conformance
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// RecordedRequest is the http request received by the mock server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// route replays the responses in sequence, the last response is repeated when the responses are exhausted
+type route struct {
+	responses [][]byte
+	index     int
+}
+
+func (r *route) next() []byte {
+	response := r.responses[r.index]
+	if r.index < len(r.responses)-1 {
+		r.index++
+	}
+
+	return response
+}
+
+// webSocketRule replies the messages when the received message contains the given substring
+type webSocketRule struct {
+	contains string
+	messages [][]byte
+}
+
+// MockServer is a local http and websocket server replaying the recorded fixtures
+type MockServer struct {
+	*httptest.Server
+
+	t   testing.TB
+	dir string
+
+	mu       sync.Mutex
+	routes   map[string]*route
+	requests []RecordedRequest
+
+	wsRules    []webSocketRule
+	wsConns    []*websocket.Conn
+	wsMessages [][]string
+	wsUpgrader websocket.Upgrader
+}
+
+// NewMockServer creates and starts a mock server, the fixture files are loaded from the given directory.
+// The server is closed when the test finishes.
+func NewMockServer(t testing.TB, dir string) *MockServer {
+	s := &MockServer{
+		t:      t,
+		dir:    dir,
+		routes: make(map[string]*route),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(func() {
+		s.CloseWebSocketConnections()
+		s.Server.Close()
+	})
+	return s
+}
+
+// Handle registers the responses of the given method and path, the responses are replied in sequence,
+// and the last response is repeated for the subsequent requests.
+func (s *MockServer) Handle(method, path string, responses ...[]byte) {
+	if len(responses) == 0 {
+		s.t.Fatalf("conformance: no response is given for %s %s", method, path)
+	}
+
+	s.mu.Lock()
+	s.routes[method+" "+path] = &route{responses: responses}
+	s.mu.Unlock()
+}
+
+// HandleFile is the same as Handle, but the responses are loaded from the fixture files
+func (s *MockServer) HandleFile(method, path string, files ...string) {
+	s.Handle(method, path, s.readFiles(files)...)
+}
+
+// HandleWebSocket registers the fixture messages that are sent when a received websocket message contains the given substring.
+// An empty substring matches all the messages.
+func (s *MockServer) HandleWebSocket(contains string, files ...string) {
+	messages := s.readFiles(files)
+
+	s.mu.Lock()
+	s.wsRules = append(s.wsRules, webSocketRule{contains: contains, messages: messages})
+	s.mu.Unlock()
+}
+
+// WebSocketURL returns the websocket url of the server
+func (s *MockServer) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Requests returns the recorded requests of the given method and path
+func (s *MockServer) Requests(method, path string) (requests []RecordedRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.requests {
+		if r.Method == method && r.Path == path {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+// WebSocketConnections returns the number of the websocket connections accepted by the server
+func (s *MockServer) WebSocketConnections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.wsConns)
+}
+
+// WebSocketMessages returns the messages received from the i-th websocket connection
+func (s *MockServer) WebSocketMessages(i int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i >= len(s.wsMessages) {
+		return nil
+	}
+
+	return append([]string(nil), s.wsMessages[i]...)
+}
+
+// CloseWebSocketConnections closes all the accepted websocket connections without the close handshake,
+// so that the client sees an abnormal closure and re-connects.
+func (s *MockServer) CloseWebSocketConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.wsConns {
+		_ = conn.Close()
+	}
+}
+
+func (s *MockServer) readFiles(files []string) (data [][]byte) {
+	for _, file := range files {
+		content, err := ioutil.ReadFile(filepath.Join(s.dir, file))
+		if err != nil {
+			s.t.Fatalf("conformance: can not read fixture %s: %v", file, err)
+		}
+
+		data = append(data, content)
+	}
+
+	return data
+}
+
+func (s *MockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWebSocket(w, r)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	})
+
+	var response []byte
+	rt, ok := s.routes[r.Method+" "+r.URL.Path]
+	if ok {
+		response = rt.next()
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		s.t.Errorf("conformance: unexpected request %s %s", r.Method, r.URL.String())
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response)
+}
+
+func (s *MockServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.t.Errorf("conformance: websocket upgrade error: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	index := len(s.wsConns)
+	s.wsConns = append(s.wsConns, conn)
+	s.wsMessages = append(s.wsMessages, nil)
+	s.mu.Unlock()
+
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.wsMessages[index] = append(s.wsMessages[index], string(message))
+
+		var replies [][]byte
+		for _, rule := range s.wsRules {
+			if strings.Contains(string(message), rule.contains) {
+				replies = append(replies, rule.messages...)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, reply := range replies {
+			if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package conformance provides a generic test suite for the exchange connectors.
+//
+// The suite runs a types.Exchange implementation against a MockServer replaying the recorded fixtures,
+// so that a new connector can prove the correctness of the conversions, the batch query pagination,
+// the order status transitions and the stream reconnection without the live credentials.
+package conformance
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Suite is the conformance test suite of an exchange connector.
+// The http client and the stream endpoints of the exchange must be pointed to the mock server.
+type Suite struct {
+	Exchange types.Exchange
+	Server   *MockServer
+
+	Symbol string
+
+	// Since and Until is the time range of the batch queries
+	Since, Until time.Time
+
+	// ExpectedTrades is the number of the trades returned by the batch trade query, 0 skips the test
+	ExpectedTrades int
+
+	// ExpectedClosedOrders is the number of the orders returned by the batch closed order query, 0 skips the test
+	ExpectedClosedOrders int
+
+	// NewStream creates the user data stream connecting to the mock server, nil skips the stream tests
+	NewStream func() types.Stream
+
+	// ExpectedOrderUpdates and ExpectedTradeUpdates are the numbers of the updates replayed by the websocket fixtures
+	ExpectedOrderUpdates int
+	ExpectedTradeUpdates int
+
+	// Timeout is the waiting timeout of the stream tests, defaults to 5 seconds
+	Timeout time.Duration
+}
+
+// Run runs the conformance tests as the sub-tests of t
+func (s *Suite) Run(t *testing.T) {
+	if s.Timeout == 0 {
+		s.Timeout = defaultTimeout
+	}
+
+	t.Run("BatchTradeQuery", s.testBatchTradeQuery)
+	t.Run("BatchClosedOrderQuery", s.testBatchClosedOrderQuery)
+	t.Run("UserDataStream", s.testUserDataStream)
+	t.Run("StreamReconnect", s.testStreamReconnect)
+}
+
+func (s *Suite) tradeHistoryService(t *testing.T) types.ExchangeTradeHistoryService {
+	service, ok := s.Exchange.(types.ExchangeTradeHistoryService)
+	if !ok {
+		t.Skipf("%s does not implement types.ExchangeTradeHistoryService", s.Exchange.Name())
+	}
+
+	return service
+}
+
+func (s *Suite) testBatchTradeQuery(t *testing.T) {
+	if s.ExpectedTrades == 0 {
+		t.Skip("ExpectedTrades is not set")
+	}
+
+	query := &batch.TradeBatchQuery{ExchangeTradeHistoryService: s.tradeHistoryService(t)}
+
+	since, until := s.Since, s.Until
+	tradeC, errC := query.Query(context.Background(), s.Symbol, &types.TradeQueryOptions{
+		StartTime: &since,
+		EndTime:   &until,
+	})
+
+	var trades []types.Trade
+	for trade := range tradeC {
+		trades = append(trades, trade)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Len(t, trades, s.ExpectedTrades)
+
+	keys := make(map[types.TradeKey]struct{})
+	for i, trade := range trades {
+		assert.NoError(t, CheckTrade(trade))
+		assert.Equal(t, s.Exchange.Name(), trade.Exchange)
+
+		if _, exists := keys[trade.Key()]; exists {
+			t.Errorf("duplicated trade: %+v", trade)
+		}
+		keys[trade.Key()] = struct{}{}
+
+		if i > 0 && trade.Time.Before(trades[i-1].Time.Time()) {
+			t.Errorf("trades are not in the ascending order: %s before %s", trade.Time, trades[i-1].Time)
+		}
+	}
+}
+
+func (s *Suite) testBatchClosedOrderQuery(t *testing.T) {
+	if s.ExpectedClosedOrders == 0 {
+		t.Skip("ExpectedClosedOrders is not set")
+	}
+
+	query := &batch.ClosedOrderBatchQuery{ExchangeTradeHistoryService: s.tradeHistoryService(t)}
+	orderC, errC := query.Query(context.Background(), s.Symbol, s.Since, s.Until, 0)
+
+	var orders []types.Order
+	for order := range orderC {
+		orders = append(orders, order)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Len(t, orders, s.ExpectedClosedOrders)
+
+	ids := make(map[uint64]struct{})
+	for i, order := range orders {
+		assert.NoError(t, CheckOrder(order))
+		assert.Equal(t, s.Exchange.Name(), order.Exchange)
+
+		if _, exists := ids[order.OrderID]; exists {
+			t.Errorf("duplicated order: %+v", order)
+		}
+		ids[order.OrderID] = struct{}{}
+
+		if i > 0 && order.CreationTime.Before(orders[i-1].CreationTime.Time()) {
+			t.Errorf("orders are not in the ascending order: %s before %s", order.CreationTime, orders[i-1].CreationTime)
+		}
+	}
+}
+
+// streamRecorder records the updates emitted by the stream
+type streamRecorder struct {
+	mu       sync.Mutex
+	connects int
+	orders   []types.Order
+	trades   []types.Trade
+}
+
+func newStreamRecorder(stream types.Stream) *streamRecorder {
+	r := &streamRecorder{}
+	stream.OnConnect(func() {
+		r.mu.Lock()
+		r.connects++
+		r.mu.Unlock()
+	})
+	stream.OnOrderUpdate(func(order types.Order) {
+		r.mu.Lock()
+		r.orders = append(r.orders, order)
+		r.mu.Unlock()
+	})
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		r.mu.Lock()
+		r.trades = append(r.trades, trade)
+		r.mu.Unlock()
+	})
+	return r
+}
+
+func (r *streamRecorder) snapshot() (connects int, orders []types.Order, trades []types.Trade) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connects, append([]types.Order(nil), r.orders...), append([]types.Trade(nil), r.trades...)
+}
+
+func (s *Suite) newStream(t *testing.T) types.Stream {
+	if s.NewStream == nil {
+		t.Skip("NewStream is not set")
+	}
+
+	return s.NewStream()
+}
+
+// connectStream connects the stream and records the stream events, the stream should be configured before connecting,
+// the stream fields are read by the stream goroutines after connecting
+func (s *Suite) connectStream(t *testing.T, stream types.Stream) *streamRecorder {
+	recorder := newStreamRecorder(stream)
+
+	if !assert.NoError(t, stream.Connect(context.Background())) {
+		t.FailNow()
+	}
+
+	t.Cleanup(func() {
+		_ = stream.Close()
+	})
+
+	return recorder
+}
+
+func (s *Suite) testUserDataStream(t *testing.T) {
+	recorder := s.connectStream(t, s.newStream(t))
+
+	assert.Eventually(t, func() bool {
+		_, orders, trades := recorder.snapshot()
+		return len(orders) >= s.ExpectedOrderUpdates && len(trades) >= s.ExpectedTradeUpdates
+	}, s.Timeout, 10*time.Millisecond)
+
+	_, orders, trades := recorder.snapshot()
+	assert.Len(t, orders, s.ExpectedOrderUpdates)
+	assert.Len(t, trades, s.ExpectedTradeUpdates)
+	assert.NoError(t, CheckOrderStatusTransitions(orders))
+
+	for _, trade := range trades {
+		assert.NoError(t, CheckTrade(trade))
+	}
+}
+
+func (s *Suite) testStreamReconnect(t *testing.T) {
+	stream := s.newStream(t)
+	setter, ok := stream.(interface {
+		SetReconnectCoolDownPeriod(period time.Duration)
+	})
+	if !ok {
+		t.Skip("the stream does not support setting the reconnect cool down period")
+	}
+
+	setter.SetReconnectCoolDownPeriod(100 * time.Millisecond)
+
+	connections := s.Server.WebSocketConnections()
+	recorder := s.connectStream(t, stream)
+
+	// wait for the initial messages, e.g., auth and subscribe, before dropping the connection
+	assert.Eventually(t, func() bool {
+		return len(s.Server.WebSocketMessages(connections)) > 0
+	}, s.Timeout, 10*time.Millisecond)
+
+	s.Server.CloseWebSocketConnections()
+
+	if !assert.Eventually(t, func() bool {
+		connects, _, _ := recorder.snapshot()
+		return connects >= 2 && s.Server.WebSocketConnections() >= connections+2
+	}, s.Timeout, 10*time.Millisecond, "the stream is not re-connected") {
+		return
+	}
+
+	// the stream should send the same initial messages after re-connecting
+	initial := s.Server.WebSocketMessages(connections)
+	assert.Eventually(t, func() bool {
+		return len(s.Server.WebSocketMessages(connections+1)) >= len(initial)
+	}, s.Timeout, 10*time.Millisecond, "the stream does not re-subscribe after re-connecting")
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bybit"
 	"github.com/c9s/bbgo/pkg/exchange/kucoin"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/exchange/okex"
@@ -31,6 +32,9 @@ func NewStandard(n types.ExchangeName, key, secret, passphrase, subAccount strin
 	case types.ExchangeKucoin:
 		return kucoin.New(key, secret, passphrase), nil
 
+	case types.ExchangeBybit:
+		return bybit.New(key, secret), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported exchange: %v", n)
 
@@ -0,0 +1,72 @@
+package kucoin
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/conformance"
+)
+
+func TestExchange_Conformance(t *testing.T) {
+	// the history query limiters allow a request per 6 seconds, which is too slow for the replayed pages
+	tradeLimiter, orderLimiter := queryTradeLimiter, queryOrderLimiter
+	queryTradeLimiter, queryOrderLimiter = rate.NewLimiter(rate.Inf, 1), rate.NewLimiter(rate.Inf, 1)
+	t.Cleanup(func() {
+		queryTradeLimiter, queryOrderLimiter = tradeLimiter, orderLimiter
+	})
+
+	ex, server := newMockExchange(t)
+
+	// the later pages overlap the previous pages, and the last page is empty
+	server.HandleFile("GET", "/api/v1/fills",
+		"conformance/fills-page1.json",
+		"conformance/fills-page2.json",
+		"conformance/fills-empty.json")
+	server.HandleFile("GET", "/api/v1/orders",
+		"conformance/orders-page1.json",
+		"conformance/orders-page2.json",
+		"conformance/orders-empty.json")
+
+	// the private bullet points the stream to the websocket endpoint of the mock server
+	server.Handle("POST", "/api/v1/bullet-private", []byte(fmt.Sprintf(
+		`{"code":"200000","data":{"token":"token","instanceServers":[{"endpoint":%q,"protocol":"websocket","encrypt":false,"pingInterval":18000,"pingTimeout":10000}]}}`,
+		server.WebSocketURL())))
+
+	// the match events are not replayed, the trade updates of the kucoin stream do not carry the fee
+	server.HandleWebSocket(`"topic":"/spotMarket/tradeOrders"`,
+		"ack.json",
+		"conformance/ws-order-open.json",
+		"conformance/ws-order-filled.json")
+	server.HandleWebSocket(`"topic":"/account/balance"`, "ack.json")
+
+	suite := &conformance.Suite{
+		Exchange:             ex,
+		Server:               server,
+		Symbol:               "BTCUSDT",
+		Since:                time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:                time.Date(2023, time.January, 2, 0, 0, 0, 0, time.UTC),
+		ExpectedTrades:       5,
+		ExpectedClosedOrders: 3,
+		NewStream:            ex.NewStream,
+		ExpectedOrderUpdates: 2,
+	}
+	suite.Run(t)
+
+	// kucoin queries the fills by the time range, the start time must be advanced for the next page
+	requests := server.Requests("GET", "/api/v1/fills")
+	if assert.Len(t, requests, 3) {
+		var last int64
+		for _, r := range requests {
+			startAt, err := strconv.ParseInt(r.Query.Get("startAt"), 10, 64)
+			if assert.NoError(t, err) {
+				assert.Greater(t, startAt, last)
+				last = startAt
+			}
+		}
+	}
+}
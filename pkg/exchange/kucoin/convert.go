@@ -245,3 +245,74 @@ func toGlobalTrade(fill kucoinapi.Fill) types.Trade {
 	}
 	return trade
 }
+
+func toGlobalDepositStatus(status kucoinapi.DepositStatus) types.DepositStatus {
+	switch status {
+	case kucoinapi.DepositStatusProcessing:
+		return types.DepositPending
+	case kucoinapi.DepositStatusSuccess:
+		return types.DepositSuccess
+	case kucoinapi.DepositStatusFailure:
+		return types.DepositRejected
+	}
+
+	return types.DepositStatus(status)
+}
+
+func toGlobalDeposit(d kucoinapi.Deposit) types.Deposit {
+	return types.Deposit{
+		Exchange:      types.ExchangeKucoin,
+		Time:          types.Time(d.CreatedAt.Time()),
+		Amount:        d.Amount,
+		Asset:         toGlobalSymbol(d.Currency),
+		Address:       d.Address,
+		AddressTag:    d.Memo,
+		TransactionID: d.WalletTxID,
+		Status:        toGlobalDepositStatus(d.Status),
+	}
+}
+
+func toGlobalWithdrawStatus(status kucoinapi.WithdrawalStatus) string {
+	switch status {
+	case kucoinapi.WithdrawalStatusProcessing, kucoinapi.WithdrawalStatusWalletProcessing:
+		return "pending"
+	case kucoinapi.WithdrawalStatusSuccess:
+		return "completed" // make it compatible with binance
+	case kucoinapi.WithdrawalStatusFailure:
+		return "failed"
+	}
+
+	return string(status)
+}
+
+func toGlobalWithdraw(w kucoinapi.Withdrawal) types.Withdraw {
+	return types.Withdraw{
+		Exchange:               types.ExchangeKucoin,
+		ApplyTime:              types.Time(w.CreatedAt.Time()),
+		Asset:                  toGlobalSymbol(w.Currency),
+		Amount:                 w.Amount,
+		Address:                w.Address,
+		AddressTag:             w.Memo,
+		TransactionID:          w.WalletTxID,
+		TransactionFee:         w.Fee,
+		TransactionFeeCurrency: toGlobalSymbol(w.Currency),
+		WithdrawOrderID:        w.ID,
+		Status:                 toGlobalWithdrawStatus(w.Status),
+		Network:                w.Chain,
+	}
+}
+
+// toGlobalReward converts the bonus ledger entry to the reward, kucoin does not categorize the bonus
+// so the bonus is recorded as the airdrop with the business type as the note
+func toGlobalReward(l kucoinapi.Ledger) types.Reward {
+	return types.Reward{
+		UUID:      l.ID,
+		Exchange:  types.ExchangeKucoin,
+		Type:      types.RewardAirdrop,
+		Currency:  toGlobalSymbol(l.Currency),
+		Quantity:  l.Amount,
+		State:     "done",
+		Note:      l.BizType,
+		CreatedAt: types.Time(l.CreatedAt.Time()),
+	}
+}
@@ -0,0 +1,71 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_toGlobalDeposit(t *testing.T) {
+	var page kucoinapi.DepositListPage
+	err := json.Unmarshal([]byte(`{
+		"currentPage": 1,
+		"pageSize": 500,
+		"totalNum": 1,
+		"totalPage": 1,
+		"items": [{
+			"address": "0x5f047b29041bcfdbf0e4478cdfa753a336ba6989",
+			"memo": "5c247c8a03aa677cea2a251d",
+			"amount": "1",
+			"fee": "0.0001",
+			"currency": "KCS",
+			"chain": "",
+			"isInner": false,
+			"walletTxId": "5bbb57386d99522d9f954c5a@test004",
+			"status": "SUCCESS",
+			"remark": "test",
+			"createdAt": 1544178843000,
+			"updatedAt": 1544178891000
+		}]
+	}`), &page)
+	if !assert.NoError(t, err) || !assert.Len(t, page.Items, 1) {
+		return
+	}
+
+	deposit := toGlobalDeposit(page.Items[0])
+	assert.Equal(t, types.ExchangeKucoin, deposit.Exchange)
+	assert.Equal(t, "KCS", deposit.Asset)
+	assert.Equal(t, fixedpoint.One, deposit.Amount)
+	assert.Equal(t, "5c247c8a03aa677cea2a251d", deposit.AddressTag)
+	assert.Equal(t, "5bbb57386d99522d9f954c5a@test004", deposit.TransactionID)
+	assert.Equal(t, types.DepositSuccess, deposit.Status)
+	assert.Equal(t, int64(1544178843000), deposit.Time.Time().UnixMilli())
+
+	assert.Equal(t, types.DepositPending, toGlobalDepositStatus(kucoinapi.DepositStatusProcessing))
+	assert.Equal(t, types.DepositRejected, toGlobalDepositStatus(kucoinapi.DepositStatusFailure))
+}
+
+func Test_toGlobalWithdraw(t *testing.T) {
+	withdraw := toGlobalWithdraw(kucoinapi.Withdrawal{
+		ID:         "5c2dc64e03aa675aa263f1ac",
+		Address:    "0x5bedb060b8eb8d823e2414d82acce78d38be7fe9",
+		Currency:   "ETH",
+		Chain:      "ERC20",
+		Amount:     fixedpoint.One,
+		Fee:        fixedpoint.NewFromFloat(0.01),
+		WalletTxID: "3e2414d82acce78d38be7fe9",
+		Status:     kucoinapi.WithdrawalStatusWalletProcessing,
+	})
+	assert.Equal(t, "pending", withdraw.Status)
+	assert.Equal(t, "5c2dc64e03aa675aa263f1ac", withdraw.WithdrawOrderID)
+	assert.Equal(t, "ETH", withdraw.TransactionFeeCurrency)
+	assert.Equal(t, "ERC20", withdraw.Network)
+
+	assert.Equal(t, "completed", toGlobalWithdrawStatus(kucoinapi.WithdrawalStatusSuccess))
+	assert.Equal(t, "failed", toGlobalWithdrawStatus(kucoinapi.WithdrawalStatusFailure))
+}
@@ -20,6 +20,17 @@ import (
 var marketDataLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
 var queryTradeLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
 var queryOrderLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
+var queryTransferLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
+var marginLimiter = rate.NewLimiter(rate.Every(time.Second), 1)
+var queryLedgerLimiter = rate.NewLimiter(rate.Every(time.Second), 1)
+
+// transferPageSize is the max page size of the deposit and withdrawal list
+const transferPageSize = 500
+
+// ledgerQueryWindow is the max time range of a ledger query,
+// and the ledgers are only kept for a year
+const ledgerQueryWindow = 7 * 24 * time.Hour
+const ledgerRetention = 365 * 24 * time.Hour
 
 var ErrMissingSequence = errors.New("sequence is missing")
 
@@ -347,6 +358,69 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 	return orders, err
 }
 
+// orderUUID returns the kucoin order id of the query, the global order id is hashed from the kucoin order id,
+// so the OrderUUID is used, and the OrderID is only used when it's given as the kucoin order id.
+func orderUUID(q types.OrderQuery) string {
+	if len(q.OrderUUID) > 0 {
+		return q.OrderUUID
+	}
+
+	return q.OrderID
+}
+
+// QueryOrder queries the order by the order uuid or the client order id
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	req := e.client.TradeService.NewGetOrderRequest()
+	if uuid := orderUUID(q); len(uuid) > 0 {
+		req.OrderID(uuid)
+	} else if len(q.ClientOrderID) > 0 {
+		req.ClientOrderID(q.ClientOrderID)
+	} else {
+		return nil, errors.New("order uuid or client order id is required for querying a kucoin order")
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	o, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order := toGlobalOrder(*o)
+	return &order, nil
+}
+
+func (e *Exchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) (trades []types.Trade, err error) {
+	uuid := orderUUID(q)
+	if len(uuid) == 0 {
+		return nil, errors.New("order uuid is required for querying the trades of a kucoin order")
+	}
+
+	req := e.client.TradeService.NewGetFillsRequest()
+	req.OrderID(uuid)
+
+	if len(q.Symbol) > 0 {
+		req.Symbol(toLocalSymbol(q.Symbol))
+	}
+
+	if err := queryTradeLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fill := range response.Items {
+		trades = append(trades, toGlobalTrade(fill))
+	}
+
+	return trades, nil
+}
+
 var launchDate = time.Date(2017, 9, 0, 0, 0, 0, 0, time.UTC)
 
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
@@ -443,3 +517,196 @@ func (e *Exchange) QueryDepth(ctx context.Context, symbol string) (types.SliceOr
 		Asks:   orderBook.Asks,
 	}, sequence, nil
 }
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	req := e.client.TransferService.NewListDepositsRequest()
+	if len(asset) > 0 {
+		req.Currency(asset)
+	}
+
+	if !since.IsZero() {
+		req.StartAt(since)
+	}
+
+	if !until.IsZero() {
+		req.EndAt(until)
+	}
+
+	req.PageSize(transferPageSize)
+	for page := 1; ; page++ {
+		if err := queryTransferLimiter.Wait(ctx); err != nil {
+			return allDeposits, err
+		}
+
+		response, err := req.CurrentPage(page).Do(ctx)
+		if err != nil {
+			return allDeposits, err
+		}
+
+		for _, d := range response.Items {
+			allDeposits = append(allDeposits, toGlobalDeposit(d))
+		}
+
+		if page >= response.TotalPage {
+			break
+		}
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time.Time())
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	req := e.client.TransferService.NewListWithdrawalsRequest()
+	if len(asset) > 0 {
+		req.Currency(asset)
+	}
+
+	if !since.IsZero() {
+		req.StartAt(since)
+	}
+
+	if !until.IsZero() {
+		req.EndAt(until)
+	}
+
+	req.PageSize(transferPageSize)
+	for page := 1; ; page++ {
+		if err := queryTransferLimiter.Wait(ctx); err != nil {
+			return allWithdraws, err
+		}
+
+		response, err := req.CurrentPage(page).Do(ctx)
+		if err != nil {
+			return allWithdraws, err
+		}
+
+		for _, w := range response.Items {
+			allWithdraws = append(allWithdraws, toGlobalWithdraw(w))
+		}
+
+		if page >= response.TotalPage {
+			break
+		}
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime.Time())
+	})
+
+	return allWithdraws, nil
+}
+
+// QueryRewards queries the kucoin bonus from the account ledgers, the ledgers are scanned by the query window
+// from the start time, and the rewards of the first window that has any are returned in the ascending order
+func (e *Exchange) QueryRewards(ctx context.Context, startTime time.Time) ([]types.Reward, error) {
+	now := time.Now()
+	from := startTime
+	if since := now.Add(-ledgerRetention); from.Before(since) {
+		from = since
+	}
+
+	for ; from.Before(now); from = from.Add(ledgerQueryWindow) {
+		to := from.Add(ledgerQueryWindow)
+
+		req := e.client.AccountService.NewListLedgersRequest()
+		req.BizType(kucoinapi.LedgerBizTypeKucoinBonus).
+			Direction(kucoinapi.LedgerDirectionIn).
+			StartAt(from).
+			EndAt(to).
+			PageSize(transferPageSize)
+
+		var rewards []types.Reward
+		for page := 1; ; page++ {
+			if err := queryLedgerLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+
+			response, err := req.CurrentPage(page).Do(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, l := range response.Items {
+				rewards = append(rewards, toGlobalReward(l))
+			}
+
+			if page >= response.TotalPage {
+				break
+			}
+		}
+
+		if len(rewards) > 0 {
+			sort.Sort(types.RewardSliceByCreationTime(rewards))
+			return rewards, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// BorrowMarginAsset borrows the asset with the market interest rate, the borrowed asset is transferred into the margin account
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if err := marginLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req := e.client.MarginService.NewBorrowRequest()
+	req.Currency(asset).
+		OrderType(kucoinapi.BorrowOrderTypeFOK).
+		Size(amount.String())
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("margin borrowed %v %s, order id: %s", amount, asset, resp.OrderID)
+	return nil
+}
+
+// RepayMarginAsset repays the loans of the asset, the loans with the highest interest rate are repaid first
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if err := marginLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req := e.client.MarginService.NewRepayAllRequest()
+	req.Currency(asset).
+		Sequence(kucoinapi.RepaySequenceHighestRateFirst).
+		Size(amount.String())
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if resp.Code != kucoinapi.ResponseCodeSuccess {
+		return fmt.Errorf("margin repay error: [%s] %s", resp.Code, resp.Message)
+	}
+
+	log.Infof("margin repaid %v %s", amount, asset)
+	return nil
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (amount fixedpoint.Value, err error) {
+	if err := marginLimiter.Wait(ctx); err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	account, err := e.client.MarginService.NewGetMarginAccountRequest().Do(ctx)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	for _, a := range account.Accounts {
+		if a.Currency == asset {
+			return a.MaxBorrowSize, nil
+		}
+	}
+
+	return fixedpoint.Zero, fmt.Errorf("margin asset %s is not found", asset)
+}
@@ -0,0 +1,92 @@
+package kucoin
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/conformance"
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMockExchange(t *testing.T) (*Exchange, *conformance.MockServer) {
+	server := conformance.NewMockServer(t, "testdata")
+
+	ex := New("key", "secret", "passphrase")
+	baseURL, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ex.client.BaseURL = baseURL
+	return ex, server
+}
+
+func TestExchange_QueryOrder_ConvertedOrder(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v1/orders/5c35c02703aa673ceec2a168",
+		[]byte(`{"code":"200000","data":{"id":"5c35c02703aa673ceec2a168","symbol":"BTC-USDT","type":"limit","side":"buy","price":"20000","size":"0.01","dealSize":"0.01","isActive":false,"createdAt":1672531200000}}`))
+	server.Handle("GET", "/api/v1/fills",
+		[]byte(`{"code":"200000","data":{"currentPage":1,"pageSize":50,"totalNum":1,"totalPage":1,"items":[{"symbol":"BTC-USDT","tradeId":"5c35c02709e4f67d5266954e","orderId":"5c35c02703aa673ceec2a168","side":"buy","liquidity":"taker","price":"20000","size":"0.01","funds":"200","fee":"0.2","feeCurrency":"USDT","type":"limit","createdAt":1672531200000}]}}`))
+
+	// the order ID of the converted order is the hash of the kucoin order ID
+	converted := toGlobalOrder(kucoinapi.Order{ID: "5c35c02703aa673ceec2a168", Symbol: "BTC-USDT"})
+	q := types.OrderQuery{
+		Symbol:    converted.Symbol,
+		OrderID:   strconv.FormatUint(converted.OrderID, 10),
+		OrderUUID: converted.UUID,
+	}
+
+	order, err := ex.QueryOrder(context.Background(), q)
+	if assert.NoError(t, err) {
+		assert.Equal(t, converted.OrderID, order.OrderID)
+		assert.Equal(t, "5c35c02703aa673ceec2a168", order.UUID)
+	}
+
+	trades, err := ex.QueryOrderTrades(context.Background(), q)
+	if assert.NoError(t, err) && assert.Len(t, trades, 1) {
+		assert.Equal(t, converted.OrderID, trades[0].OrderID)
+	}
+
+	requests := server.Requests("GET", "/api/v1/fills")
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "5c35c02703aa673ceec2a168", requests[0].Query.Get("orderId"))
+	}
+}
+
+func TestExchange_QueryRewards(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v1/accounts/ledgers",
+		[]byte(`{"code":"200000","data":{"currentPage":1,"pageSize":500,"totalNum":0,"totalPage":0,"items":[]}}`),
+		[]byte(`{"code":"200000","data":{"currentPage":1,"pageSize":500,"totalNum":2,"totalPage":1,"items":[
+			{"id":"611a1e7c6a053300067a88d9","currency":"USDT","amount":"10","fee":"0","balance":"10","accountType":"MAIN","bizType":"KuCoin Bonus","direction":"in","createdAt":1629101692950},
+			{"id":"611a1e7c6a053300067a88d8","currency":"KCS","amount":"0.5","fee":"0","balance":"0.5","accountType":"MAIN","bizType":"KuCoin Bonus","direction":"in","createdAt":1629101592950}
+		]}}`))
+
+	startTime := time.Now().Add(-10 * 24 * time.Hour)
+	rewards, err := ex.QueryRewards(context.Background(), startTime)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the rewards of the first window that has any are returned in the ascending order
+	if assert.Len(t, rewards, 2) {
+		assert.Equal(t, "611a1e7c6a053300067a88d8", rewards[0].UUID)
+		assert.Equal(t, "KCS", rewards[0].Currency)
+		assert.Equal(t, types.RewardAirdrop, rewards[0].Type)
+		assert.Equal(t, "KuCoin Bonus", rewards[0].Note)
+		assert.Equal(t, "611a1e7c6a053300067a88d9", rewards[1].UUID)
+	}
+
+	requests := server.Requests("GET", "/api/v1/accounts/ledgers")
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "KUCOIN_BONUS", requests[0].Query.Get("bizType"))
+		assert.Equal(t, "in", requests[0].Query.Get("direction"))
+		assert.Equal(t, strconv.FormatInt(startTime.UnixMilli(), 10), requests[0].Query.Get("startAt"))
+		assert.Equal(t, requests[0].Query.Get("endAt"), requests[1].Query.Get("startAt"))
+	}
+}
@@ -4,9 +4,12 @@ package kucoinapi
 //go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Data
 
 import (
+	"time"
+
 	"github.com/c9s/requestgen"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 type AccountService struct {
@@ -25,6 +28,10 @@ func (s *AccountService) NewGetAccountRequest(accountID string) *GetAccountReque
 	return &GetAccountRequest{client: s.client, accountID: accountID}
 }
 
+func (s *AccountService) NewListLedgersRequest() *ListLedgersRequest {
+	return &ListLedgersRequest{client: s.client}
+}
+
 type SubAccount struct {
 	UserID string `json:"userId"`
 	Name   string `json:"subName"`
@@ -56,3 +63,65 @@ type GetAccountRequest struct {
 	client    requestgen.AuthenticatedAPIClient
 	accountID string `param:"accountID,slug"`
 }
+
+type LedgerDirection string
+
+const (
+	LedgerDirectionIn  LedgerDirection = "in"
+	LedgerDirectionOut LedgerDirection = "out"
+)
+
+// LedgerBizType is the business type filter of the ledger query,
+// the business type in the ledger entries is the display name, e.g., "KuCoin Bonus"
+type LedgerBizType string
+
+const (
+	LedgerBizTypeDeposit        LedgerBizType = "DEPOSIT"
+	LedgerBizTypeWithdraw       LedgerBizType = "WITHDRAW"
+	LedgerBizTypeTransfer       LedgerBizType = "TRANSFER"
+	LedgerBizTypeSubTransfer    LedgerBizType = "SUB_TRANSFER"
+	LedgerBizTypeTradeExchange  LedgerBizType = "TRADE_EXCHANGE"
+	LedgerBizTypeMarginExchange LedgerBizType = "MARGIN_EXCHANGE"
+	LedgerBizTypeKucoinBonus    LedgerBizType = "KUCOIN_BONUS"
+)
+
+type Ledger struct {
+	ID          string                     `json:"id"`
+	Currency    string                     `json:"currency"`
+	Amount      fixedpoint.Value           `json:"amount"`
+	Fee         fixedpoint.Value           `json:"fee"`
+	Balance     fixedpoint.Value           `json:"balance"`
+	AccountType AccountType                `json:"accountType"`
+	BizType     string                     `json:"bizType"`
+	Direction   LedgerDirection            `json:"direction"`
+	CreatedAt   types.MillisecondTimestamp `json:"createdAt"`
+	Context     string                     `json:"context"`
+}
+
+type LedgerListPage struct {
+	CurrentPage int      `json:"currentPage"`
+	PageSize    int      `json:"pageSize"`
+	TotalNumber int      `json:"totalNum"`
+	TotalPage   int      `json:"totalPage"`
+	Items       []Ledger `json:"items"`
+}
+
+//go:generate GetRequest -url "/api/v1/accounts/ledgers" -type ListLedgersRequest -responseDataType .LedgerListPage
+type ListLedgersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency"`
+
+	direction *LedgerDirection `param:"direction"`
+
+	bizType *LedgerBizType `param:"bizType"`
+
+	startAt *time.Time `param:"startAt,milliseconds"`
+
+	endAt *time.Time `param:"endAt,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	// pageSize is 10 ~ 500
+	pageSize *int `param:"pageSize"`
+}
@@ -15,12 +15,38 @@ import (
 
 	"github.com/c9s/requestgen"
 	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 )
 
 const defaultHTTPTimeout = time.Second * 15
 const RestBaseURL = "https://api.kucoin.com/api"
 const SandboxRestBaseURL = "https://openapi-sandbox.kucoin.com/api"
 
+// RequestBudget is the request weight budget of the kucoin spot api, the quota is reset every 30 seconds
+var RequestBudget = ratelimit.NewBudget("kucoin", 4000, 30*time.Second)
+
+var defaultTransport = &ratelimit.Transport{
+	Base:       http.DefaultTransport,
+	Budget:     RequestBudget,
+	UsedWeight: usedWeight,
+}
+
+// usedWeight parses the used quota from the gw-ratelimit-limit and gw-ratelimit-remaining headers
+func usedWeight(resp *http.Response) (int, bool) {
+	limit, err := strconv.Atoi(resp.Header.Get("gw-ratelimit-limit"))
+	if err != nil {
+		return 0, false
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("gw-ratelimit-remaining"))
+	if err != nil {
+		return 0, false
+	}
+
+	return limit - remaining, true
+}
+
 type RestClient struct {
 	requestgen.BaseAPIClient
 
@@ -31,6 +57,8 @@ type RestClient struct {
 	MarketDataService *MarketDataService
 	TradeService      *TradeService
 	BulletService     *BulletService
+	TransferService   *TransferService
+	MarginService     *MarginService
 }
 
 func NewClient() *RestClient {
@@ -43,7 +71,8 @@ func NewClient() *RestClient {
 		BaseAPIClient: requestgen.BaseAPIClient{
 			BaseURL: u,
 			HttpClient: &http.Client{
-				Timeout: defaultHTTPTimeout,
+				Timeout:   defaultHTTPTimeout,
+				Transport: defaultTransport,
 			},
 		},
 		KeyVersion: "2",
@@ -53,6 +82,8 @@ func NewClient() *RestClient {
 	client.MarketDataService = &MarketDataService{client: client}
 	client.TradeService = &TradeService{client: client}
 	client.BulletService = &BulletService{client: client}
+	client.TransferService = &TransferService{client: client}
+	client.MarginService = &MarginService{client: client}
 	return client
 }
 
@@ -148,6 +179,9 @@ func castPayload(payload interface{}) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+// ResponseCodeSuccess is the response code of the successful requests
+const ResponseCodeSuccess = "200000"
+
 type APIResponse struct {
 	Code    string          `json:"code"`
 	Message string          `json:"msg"`
@@ -0,0 +1,93 @@
+package kucoinapi
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Data
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Data
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarginService struct {
+	client *RestClient
+}
+
+func (s *MarginService) NewGetMarginAccountRequest() *GetMarginAccountRequest {
+	return &GetMarginAccountRequest{client: s.client}
+}
+
+func (s *MarginService) NewBorrowRequest() *BorrowRequest {
+	return &BorrowRequest{client: s.client}
+}
+
+func (s *MarginService) NewRepayAllRequest() *RepayAllRequest {
+	return &RepayAllRequest{client: s.client}
+}
+
+type MarginAccountAsset struct {
+	Currency         string           `json:"currency"`
+	TotalBalance     fixedpoint.Value `json:"totalBalance"`
+	AvailableBalance fixedpoint.Value `json:"availableBalance"`
+	HoldBalance      fixedpoint.Value `json:"holdBalance"`
+	Liability        fixedpoint.Value `json:"liability"`
+	MaxBorrowSize    fixedpoint.Value `json:"maxBorrowSize"`
+}
+
+type MarginAccount struct {
+	DebtRatio fixedpoint.Value     `json:"debtRatio"`
+	Accounts  []MarginAccountAsset `json:"accounts"`
+}
+
+//go:generate GetRequest -url /api/v1/margin/account -type GetMarginAccountRequest -responseDataType .MarginAccount
+type GetMarginAccountRequest struct {
+	client requestgen.AuthenticatedAPIClient
+}
+
+type BorrowOrderType string
+
+const (
+	BorrowOrderTypeFOK BorrowOrderType = "FOK"
+	BorrowOrderTypeIOC BorrowOrderType = "IOC"
+)
+
+type BorrowResponse struct {
+	OrderID  string `json:"orderId"`
+	Currency string `json:"currency"`
+}
+
+//go:generate PostRequest -url /api/v1/margin/borrow -type BorrowRequest -responseDataType .BorrowResponse
+type BorrowRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	// orderType is FOK or IOC
+	orderType BorrowOrderType `param:"type,required" validValues:"FOK,IOC"`
+
+	size string `param:"size,required"`
+
+	// maxRate is the max interest rate, the current market rate is used when it's empty
+	maxRate *string `param:"maxRate"`
+
+	// term is the term of the loan in days, multiple terms are separated by comma, like "7,14,28"
+	term *string `param:"term"`
+}
+
+type RepaySequence string
+
+const (
+	RepaySequenceRecentlyExpireFirst RepaySequence = "RECENTLY_EXPIRE_FIRST"
+	RepaySequenceHighestRateFirst    RepaySequence = "HIGHEST_RATE_FIRST"
+)
+
+//go:generate requestgen -method POST -url /api/v1/margin/repay/all -type RepayAllRequest -responseType .APIResponse
+type RepayAllRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	sequence RepaySequence `param:"sequence,required" validValues:"RECENTLY_EXPIRE_FIRST,HIGHEST_RATE_FIRST"`
+
+	size string `param:"size,required"`
+}
@@ -48,6 +48,10 @@ func (c *TradeService) NewGetFillsRequest() *GetFillsRequest {
 	return &GetFillsRequest{client: c.client}
 }
 
+func (c *TradeService) NewGetOrderRequest() *GetOrderRequest {
+	return &GetOrderRequest{client: c.client}
+}
+
 //go:generate GetRequest -url /api/v1/fills -type GetFillsRequest -responseDataType .FillListPage
 type GetFillsRequest struct {
 	client requestgen.AuthenticatedAPIClient
@@ -265,6 +269,53 @@ func (r *CancelOrderRequest) Do(ctx context.Context) (*CancelOrderResponse, erro
 	return apiResponse.Data, nil
 }
 
+//go:generate requestgen -type GetOrderRequest
+type GetOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	orderID       *string `param:"orderID"`
+	clientOrderID *string `param:"clientOrderID"`
+}
+
+func (r *GetOrderRequest) Do(ctx context.Context) (*Order, error) {
+	if r.orderID == nil && r.clientOrderID == nil {
+		return nil, errors.New("either orderID or clientOrderID is required for querying order")
+	}
+
+	var refURL string
+
+	if r.orderID != nil {
+		refURL = "/api/v1/orders/" + *r.orderID
+	} else if r.clientOrderID != nil {
+		refURL = "/api/v1/order/client-order/" + *r.clientOrderID
+	}
+
+	req, err := r.client.NewAuthenticatedRequest(ctx, "GET", refURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string `json:"code"`
+		Message string `json:"msg"`
+		Data    *Order `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Data == nil {
+		return nil, errors.New("api error: [" + apiResponse.Code + "] " + apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
+
 //go:generate DeleteRequest -url /api/v1/orders -type CancelAllOrderRequest -responseDataType .CancelOrderResponse
 type CancelAllOrderRequest struct {
 	client requestgen.AuthenticatedAPIClient
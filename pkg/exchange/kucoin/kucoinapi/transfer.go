@@ -0,0 +1,124 @@
+package kucoinapi
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Data
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type TransferService struct {
+	client *RestClient
+}
+
+func (s *TransferService) NewListDepositsRequest() *ListDepositsRequest {
+	return &ListDepositsRequest{client: s.client}
+}
+
+func (s *TransferService) NewListWithdrawalsRequest() *ListWithdrawalsRequest {
+	return &ListWithdrawalsRequest{client: s.client}
+}
+
+type DepositStatus string
+
+const (
+	DepositStatusProcessing DepositStatus = "PROCESSING"
+	DepositStatusSuccess    DepositStatus = "SUCCESS"
+	DepositStatusFailure    DepositStatus = "FAILURE"
+)
+
+type Deposit struct {
+	Address    string                     `json:"address"`
+	Memo       string                     `json:"memo"`
+	Amount     fixedpoint.Value           `json:"amount"`
+	Fee        fixedpoint.Value           `json:"fee"`
+	Currency   string                     `json:"currency"`
+	Chain      string                     `json:"chain"`
+	IsInner    bool                       `json:"isInner"`
+	WalletTxID string                     `json:"walletTxId"`
+	Status     DepositStatus              `json:"status"`
+	Remark     string                     `json:"remark"`
+	CreatedAt  types.MillisecondTimestamp `json:"createdAt"`
+	UpdatedAt  types.MillisecondTimestamp `json:"updatedAt"`
+}
+
+type DepositListPage struct {
+	CurrentPage int       `json:"currentPage"`
+	PageSize    int       `json:"pageSize"`
+	TotalNumber int       `json:"totalNum"`
+	TotalPage   int       `json:"totalPage"`
+	Items       []Deposit `json:"items"`
+}
+
+//go:generate GetRequest -url /api/v1/deposits -type ListDepositsRequest -responseDataType .DepositListPage
+type ListDepositsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency"`
+
+	startAt *time.Time `param:"startAt,milliseconds"`
+
+	endAt *time.Time `param:"endAt,milliseconds"`
+
+	status *DepositStatus `param:"status"`
+
+	currentPage *int `param:"currentPage"`
+
+	// pageSize is 10 ~ 500
+	pageSize *int `param:"pageSize"`
+}
+
+type WithdrawalStatus string
+
+const (
+	WithdrawalStatusProcessing       WithdrawalStatus = "PROCESSING"
+	WithdrawalStatusWalletProcessing WithdrawalStatus = "WALLET_PROCESSING"
+	WithdrawalStatusSuccess          WithdrawalStatus = "SUCCESS"
+	WithdrawalStatusFailure          WithdrawalStatus = "FAILURE"
+)
+
+type Withdrawal struct {
+	ID         string                     `json:"id"`
+	Address    string                     `json:"address"`
+	Memo       string                     `json:"memo"`
+	Currency   string                     `json:"currency"`
+	Chain      string                     `json:"chain"`
+	Amount     fixedpoint.Value           `json:"amount"`
+	Fee        fixedpoint.Value           `json:"fee"`
+	WalletTxID string                     `json:"walletTxId"`
+	IsInner    bool                       `json:"isInner"`
+	Status     WithdrawalStatus           `json:"status"`
+	Remark     string                     `json:"remark"`
+	CreatedAt  types.MillisecondTimestamp `json:"createdAt"`
+	UpdatedAt  types.MillisecondTimestamp `json:"updatedAt"`
+}
+
+type WithdrawalListPage struct {
+	CurrentPage int          `json:"currentPage"`
+	PageSize    int          `json:"pageSize"`
+	TotalNumber int          `json:"totalNum"`
+	TotalPage   int          `json:"totalPage"`
+	Items       []Withdrawal `json:"items"`
+}
+
+//go:generate GetRequest -url /api/v1/withdrawals -type ListWithdrawalsRequest -responseDataType .WithdrawalListPage
+type ListWithdrawalsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency"`
+
+	startAt *time.Time `param:"startAt,milliseconds"`
+
+	endAt *time.Time `param:"endAt,milliseconds"`
+
+	status *WithdrawalStatus `param:"status"`
+
+	currentPage *int `param:"currentPage"`
+
+	// pageSize is 10 ~ 500
+	pageSize *int `param:"pageSize"`
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/util"
 	"github.com/c9s/bbgo/pkg/version"
 )
@@ -95,9 +96,25 @@ var httpTransport = &http.Transport{
 	ExpectContinueTimeout: 1 * time.Second,
 }
 
+// RequestBudget is the request budget of the max api, every request is counted as 1
+var RequestBudget = ratelimit.NewBudget("max", 1200, time.Minute)
+
 var defaultHttpClient = &http.Client{
-	Timeout:   defaultHTTPTimeout,
-	Transport: httpTransport,
+	Timeout: defaultHTTPTimeout,
+	Transport: &ratelimit.Transport{
+		Base:     httpTransport,
+		Budget:   RequestBudget,
+		Priority: requestPriority,
+	},
+}
+
+// requestPriority detects the cancel requests of the v2 api, which are sent with the POST method
+func requestPriority(req *http.Request) ratelimit.Priority {
+	if strings.HasSuffix(req.URL.Path, "/order/delete") || strings.HasSuffix(req.URL.Path, "/orders/clear") {
+		return ratelimit.PriorityCancel
+	}
+
+	return ratelimit.DefaultPriority(req)
 }
 
 type RestClient struct {
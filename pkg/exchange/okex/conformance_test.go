@@ -0,0 +1,68 @@
+package okex
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/conformance"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchange_Conformance(t *testing.T) {
+	ex, server := newMockExchange(t)
+
+	// the later pages overlap the previous pages, and the last page is empty
+	server.HandleFile("GET", "/api/v5/trade/fills-history",
+		"conformance/fills-history-page1.json",
+		"conformance/fills-history-page2.json",
+		"conformance/fills-history-empty.json")
+	server.HandleFile("GET", "/api/v5/trade/orders-history-archive",
+		"conformance/orders-history-page1.json",
+		"conformance/orders-history-page2.json",
+		"conformance/orders-history-empty.json")
+
+	server.HandleWebSocket(`"op":"login"`, "conformance/ws-login.json")
+	server.HandleWebSocket(`"op":"subscribe"`,
+		"conformance/ws-order-live.json",
+		"conformance/ws-order-partially-filled.json",
+		"conformance/ws-order-filled.json")
+
+	suite := &conformance.Suite{
+		Exchange:             ex,
+		Server:               server,
+		Symbol:               "BTCUSDT",
+		Since:                time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:                time.Date(2023, time.January, 2, 0, 0, 0, 0, time.UTC),
+		ExpectedTrades:       5,
+		ExpectedClosedOrders: 3,
+		NewStream: func() types.Stream {
+			stream := ex.NewStream().(*Stream)
+			stream.SetEndpointCreator(func(ctx context.Context) (string, error) {
+				return server.WebSocketURL(), nil
+			})
+			return stream
+		},
+		ExpectedOrderUpdates: 3,
+		ExpectedTradeUpdates: 2,
+	}
+	suite.Run(t)
+
+	// the pages are shorter than the page limit, so the start time of the batch is advanced instead of the bill id cursor
+	requests := server.Requests("GET", "/api/v5/trade/fills-history")
+	if assert.Len(t, requests, 3) {
+		var last int64
+		for _, r := range requests {
+			assert.Empty(t, r.Query.Get("after"))
+
+			begin, err := strconv.ParseInt(r.Query.Get("begin"), 10, 64)
+			if assert.NoError(t, err) {
+				assert.Greater(t, begin, last)
+				last = begin
+			}
+		}
+	}
+}
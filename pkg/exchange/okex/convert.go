@@ -124,6 +124,8 @@ func segmentOrderDetails(orderDetails []okexapi.OrderDetails) (trades, orders []
 	return trades, orders
 }
 
+// toGlobalTrades converts the fills of the order details,
+// the fill fee is negative for the charged fee like the transaction history, so it's negated here
 func toGlobalTrades(orderDetails []okexapi.OrderDetails) ([]types.Trade, error) {
 	var trades []types.Trade
 	for _, orderDetail := range orderDetails {
@@ -151,7 +153,7 @@ func toGlobalTrades(orderDetails []okexapi.OrderDetails) ([]types.Trade, error)
 			IsBuyer:       side == types.SideTypeBuy,
 			IsMaker:       orderDetail.ExecutionType == "M",
 			Time:          types.Time(orderDetail.LastFilledTime),
-			Fee:           orderDetail.LastFilledFee,
+			Fee:           orderDetail.LastFilledFee.Neg(),
 			FeeCurrency:   orderDetail.LastFilledFeeCurrency,
 			IsMargin:      false,
 			IsIsolated:    false,
@@ -164,63 +166,165 @@ func toGlobalTrades(orderDetails []okexapi.OrderDetails) ([]types.Trade, error)
 func toGlobalOrders(orderDetails []okexapi.OrderDetails) ([]types.Order, error) {
 	var orders []types.Order
 	for _, orderDetail := range orderDetails {
-		orderID, err := strconv.ParseInt(orderDetail.OrderID, 10, 64)
+		order, err := toGlobalOrder(orderDetail)
 		if err != nil {
 			return orders, err
 		}
 
-		side := types.SideType(strings.ToUpper(string(orderDetail.Side)))
+		orders = append(orders, *order)
+	}
 
-		orderType, err := toGlobalOrderType(orderDetail.OrderType)
-		if err != nil {
-			return orders, err
-		}
+	return orders, nil
+}
 
-		timeInForce := types.TimeInForceGTC
-		switch orderDetail.OrderType {
-		case okexapi.OrderTypeFOK:
-			timeInForce = types.TimeInForceFOK
-		case okexapi.OrderTypeIOC:
-			timeInForce = types.TimeInForceIOC
+func toGlobalOrder(orderDetail okexapi.OrderDetails) (*types.Order, error) {
+	orderID, err := strconv.ParseInt(orderDetail.OrderID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
 
-		}
+	side := types.SideType(strings.ToUpper(string(orderDetail.Side)))
 
-		orderStatus, err := toGlobalOrderStatus(orderDetail.State)
-		if err != nil {
-			return orders, err
-		}
+	orderType, err := toGlobalOrderType(orderDetail.OrderType)
+	if err != nil {
+		return nil, err
+	}
 
-		isWorking := false
-		switch orderStatus {
-		case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
-			isWorking = true
+	timeInForce := types.TimeInForceGTC
+	switch orderDetail.OrderType {
+	case okexapi.OrderTypeFOK:
+		timeInForce = types.TimeInForceFOK
+	case okexapi.OrderTypeIOC:
+		timeInForce = types.TimeInForceIOC
 
-		}
+	}
 
-		orders = append(orders, types.Order{
-			SubmitOrder: types.SubmitOrder{
-				ClientOrderID: orderDetail.ClientOrderID,
-				Symbol:        toGlobalSymbol(orderDetail.InstrumentID),
-				Side:          side,
-				Type:          orderType,
-				Price:         orderDetail.Price,
-				Quantity:      orderDetail.Quantity,
-				StopPrice:     fixedpoint.Zero, // not supported yet
-				TimeInForce:   timeInForce,
-			},
-			Exchange:         types.ExchangeOKEx,
-			OrderID:          uint64(orderID),
-			Status:           orderStatus,
-			ExecutedQuantity: orderDetail.FilledQuantity,
-			IsWorking:        isWorking,
-			CreationTime:     types.Time(orderDetail.CreationTime),
-			UpdateTime:       types.Time(orderDetail.UpdateTime),
-			IsMargin:         false,
-			IsIsolated:       false,
-		})
+	orderStatus, err := toGlobalOrderStatus(orderDetail.State)
+	if err != nil {
+		return nil, err
 	}
 
-	return orders, nil
+	isWorking := false
+	switch orderStatus {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		isWorking = true
+
+	}
+
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: orderDetail.ClientOrderID,
+			Symbol:        toGlobalSymbol(orderDetail.InstrumentID),
+			Side:          side,
+			Type:          orderType,
+			Price:         orderDetail.Price,
+			Quantity:      orderDetail.Quantity,
+			StopPrice:     fixedpoint.Zero, // not supported yet
+			TimeInForce:   timeInForce,
+		},
+		Exchange:         types.ExchangeOKEx,
+		OrderID:          uint64(orderID),
+		Status:           orderStatus,
+		ExecutedQuantity: orderDetail.FilledQuantity,
+		IsWorking:        isWorking,
+		CreationTime:     types.Time(orderDetail.CreationTime),
+		UpdateTime:       types.Time(orderDetail.UpdateTime),
+		IsMargin:         false,
+		IsIsolated:       false,
+	}, nil
+}
+
+// toGlobalTrade converts the fill of the transaction history,
+// the fee of OKEx is negative for the charged fee, so it's negated here
+func toGlobalTrade(trade okexapi.Trade) (*types.Trade, error) {
+	tradeID, err := strconv.ParseInt(trade.TradeID, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing tradeId value: %s", trade.TradeID)
+	}
+
+	orderID, err := strconv.ParseInt(trade.OrderID, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing ordId value: %s", trade.OrderID)
+	}
+
+	side := types.SideType(strings.ToUpper(string(trade.Side)))
+
+	return &types.Trade{
+		ID:            uint64(tradeID),
+		OrderID:       uint64(orderID),
+		Exchange:      types.ExchangeOKEx,
+		Price:         trade.FillPrice,
+		Quantity:      trade.FillQuantity,
+		QuoteQuantity: trade.FillPrice.Mul(trade.FillQuantity),
+		Symbol:        toGlobalSymbol(trade.InstrumentID),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       trade.ExecutionType == "M",
+		Time:          types.Time(trade.Timestamp),
+		Fee:           trade.Fee.Neg(),
+		FeeCurrency:   trade.FeeCurrency,
+		IsMargin:      false,
+		IsIsolated:    false,
+	}, nil
+}
+
+func toGlobalDepositStatus(state okexapi.DepositState) types.DepositStatus {
+	switch state {
+	case okexapi.DepositStateWaitingForConfirmation:
+		return types.DepositPending
+	case okexapi.DepositStateCredited:
+		return types.DepositCredited
+	case okexapi.DepositStateSuccessful:
+		return types.DepositSuccess
+	}
+
+	return types.DepositStatus(state)
+}
+
+func toGlobalDeposit(deposit okexapi.Deposit) types.Deposit {
+	return types.Deposit{
+		Exchange:      types.ExchangeOKEx,
+		Time:          types.Time(deposit.Timestamp),
+		Amount:        deposit.Amount,
+		Asset:         deposit.Currency,
+		Address:       deposit.To,
+		TransactionID: deposit.TransactionID,
+		Status:        toGlobalDepositStatus(deposit.State),
+	}
+}
+
+func toGlobalWithdrawStatus(state okexapi.WithdrawalState) string {
+	switch state {
+	case okexapi.WithdrawalStatePendingCancel:
+		return "canceling"
+	case okexapi.WithdrawalStateCanceled:
+		return "canceled"
+	case okexapi.WithdrawalStateFailed:
+		return "failed"
+	case okexapi.WithdrawalStateSending:
+		return "sending"
+	case okexapi.WithdrawalStateSent:
+		return "completed"
+	}
+
+	return "pending"
+}
+
+func toGlobalWithdraw(withdrawal okexapi.Withdrawal) types.Withdraw {
+	return types.Withdraw{
+		Exchange:               types.ExchangeOKEx,
+		ApplyTime:              types.Time(withdrawal.Timestamp),
+		Asset:                  withdrawal.Currency,
+		Amount:                 withdrawal.Amount,
+		Address:                withdrawal.To,
+		AddressTag:             withdrawal.Tag,
+		TransactionID:          withdrawal.TransactionID,
+		TransactionFee:         withdrawal.Fee,
+		TransactionFeeCurrency: withdrawal.Currency,
+		WithdrawOrderID:        withdrawal.WithdrawalID,
+		Status:                 toGlobalWithdrawStatus(withdrawal.State),
+		Network:                withdrawal.Chain,
+	}
 }
 
 func toGlobalOrderStatus(state okexapi.OrderState) (types.OrderStatus, error) {
@@ -0,0 +1,84 @@
+package okex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/okex/okexapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_toGlobalTrade(t *testing.T) {
+	var trade okexapi.Trade
+	err := json.Unmarshal([]byte(`{
+		"instType": "SPOT",
+		"instId": "BTC-USDT",
+		"tradeId": "123",
+		"ordId": "312269865356374016",
+		"clOrdId": "b16",
+		"billId": "1111",
+		"tag": "",
+		"fillPx": "16500.1",
+		"fillSz": "0.02",
+		"side": "buy",
+		"execType": "M",
+		"feeCcy": "BTC",
+		"fee": "-0.000016",
+		"ts": "1597026383085"
+	}`), &trade)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	globalTrade, err := toGlobalTrade(trade)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(123), globalTrade.ID)
+		assert.Equal(t, uint64(312269865356374016), globalTrade.OrderID)
+		assert.Equal(t, "BTCUSDT", globalTrade.Symbol)
+		assert.Equal(t, types.SideTypeBuy, globalTrade.Side)
+		assert.True(t, globalTrade.IsBuyer)
+		assert.True(t, globalTrade.IsMaker)
+		assert.InDelta(t, 330.002, globalTrade.QuoteQuantity.Float64(), 1e-6)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.000016"), globalTrade.Fee)
+		assert.Equal(t, "BTC", globalTrade.FeeCurrency)
+		assert.Equal(t, int64(1597026383085), globalTrade.Time.Time().UnixMilli())
+	}
+}
+
+func Test_toGlobalDeposit(t *testing.T) {
+	deposit := toGlobalDeposit(okexapi.Deposit{
+		Currency:      "USDT",
+		Chain:         "USDT-TRC20",
+		Amount:        fixedpoint.NewFromInt(100),
+		To:            "TN8CKTQMnpWfT",
+		TransactionID: "7c4fd8a7",
+		State:         okexapi.DepositStateSuccessful,
+	})
+	assert.Equal(t, types.ExchangeOKEx, deposit.Exchange)
+	assert.Equal(t, types.DepositSuccess, deposit.Status)
+	assert.Equal(t, "TN8CKTQMnpWfT", deposit.Address)
+
+	assert.Equal(t, types.DepositPending, toGlobalDepositStatus(okexapi.DepositStateWaitingForConfirmation))
+	assert.Equal(t, types.DepositCredited, toGlobalDepositStatus(okexapi.DepositStateCredited))
+}
+
+func Test_toGlobalWithdraw(t *testing.T) {
+	withdraw := toGlobalWithdraw(okexapi.Withdrawal{
+		Currency:     "USDT",
+		Chain:        "USDT-TRC20",
+		Amount:       fixedpoint.NewFromInt(100),
+		Fee:          fixedpoint.NewFromInt(1),
+		WithdrawalID: "67485",
+		State:        okexapi.WithdrawalStateSent,
+	})
+	assert.Equal(t, "completed", withdraw.Status)
+	assert.Equal(t, "USDT-TRC20", withdraw.Network)
+	assert.Equal(t, "USDT", withdraw.TransactionFeeCurrency)
+	assert.Equal(t, "67485", withdraw.WithdrawOrderID)
+
+	assert.Equal(t, "canceled", toGlobalWithdrawStatus(okexapi.WithdrawalStateCanceled))
+	assert.Equal(t, "pending", toGlobalWithdrawStatus(okexapi.WithdrawalStateAwaitingManual))
+}
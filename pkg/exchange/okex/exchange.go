@@ -3,6 +3,7 @@ package okex
 import (
 	"context"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -17,6 +18,15 @@ import (
 
 var marketDataLimiter = rate.NewLimiter(rate.Every(time.Second/10), 1)
 
+// the history APIs are limited by 5 requests per 2 seconds (order history), 10 requests per 2 seconds (fills history)
+// and 6 requests per second (deposit and withdrawal history)
+var orderHistoryLimiter = rate.NewLimiter(rate.Every(time.Second*2/5), 1)
+var tradeHistoryLimiter = rate.NewLimiter(rate.Every(time.Second*2/10), 1)
+var assetHistoryLimiter = rate.NewLimiter(rate.Every(time.Second/6), 1)
+
+// historyPageLimit is the max number of the records of the history APIs
+const historyPageLimit = 100
+
 // OKB is the platform currency of OKEx, pre-allocate static string here
 const OKB = "OKB"
 
@@ -338,3 +348,290 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 	return klines, nil
 
 }
+
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if len(q.Symbol) == 0 {
+		return nil, errors.New("symbol is required for querying an okex order")
+	}
+
+	req := e.client.TradeService.NewGetOrderDetailsRequest().InstrumentID(toLocalSymbol(q.Symbol))
+	if len(q.OrderID) > 0 {
+		req.OrderID(q.OrderID)
+	} else if len(q.ClientOrderID) > 0 {
+		req.ClientOrderID(q.ClientOrderID)
+	} else {
+		return nil, errors.New("order id or client order id is required for querying an okex order")
+	}
+
+	orderDetail, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalOrder(*orderDetail)
+}
+
+func (e *Exchange) QueryOrderTrades(ctx context.Context, q types.OrderQuery) ([]types.Trade, error) {
+	if len(q.OrderID) == 0 {
+		return nil, errors.New("order id is required for querying the trades of an okex order")
+	}
+
+	req := e.client.TradeService.NewGetTransactionHistoryRequest().
+		InstrumentType(okexapi.InstrumentTypeSpot).
+		OrderID(q.OrderID)
+
+	if len(q.Symbol) > 0 {
+		req.InstrumentID(toLocalSymbol(q.Symbol))
+	}
+
+	return e.queryTradeHistory(ctx, req, 0)
+}
+
+// QueryClosedOrders queries the filled and canceled orders of the last 3 months, the orders are sorted by the creation
+// time in the ascending order.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	var orders []types.Order
+	var after string
+	for {
+		if err := orderHistoryLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		req := e.client.TradeService.NewGetOrderHistoryRequest().
+			InstrumentType(okexapi.InstrumentTypeSpot).
+			InstrumentID(toLocalSymbol(symbol)).
+			Limit(historyPageLimit)
+
+		if !since.IsZero() {
+			req.StartTime(since)
+		}
+
+		if !until.IsZero() {
+			req.EndTime(until)
+		}
+
+		if lastOrderID > 0 {
+			req.Before(strconv.FormatUint(lastOrderID, 10))
+		}
+
+		// the records are returned in the descending order, so we query the older records by the last record
+		if len(after) > 0 {
+			req.After(after)
+		}
+
+		orderDetails, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, orderDetail := range orderDetails {
+			order, err := toGlobalOrder(orderDetail)
+			if err != nil {
+				return orders, err
+			}
+
+			orders = append(orders, *order)
+		}
+
+		if len(orderDetails) < historyPageLimit {
+			break
+		}
+
+		after = orderDetails[len(orderDetails)-1].OrderID
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime.Time())
+	})
+
+	return orders, nil
+}
+
+// QueryTrades queries the fills of the last 3 months, the trades are sorted by the trade time in the ascending order.
+// The fills are paged from the newest one by the bill ID cursor, and the paging stops at the page reaching the last
+// trade ID, so only the fills newer than the last trade are fetched.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	req := e.client.TradeService.NewGetTransactionHistoryRequest().
+		InstrumentType(okexapi.InstrumentTypeSpot).
+		InstrumentID(toLocalSymbol(symbol))
+
+	if options.StartTime != nil && !options.StartTime.IsZero() {
+		req.StartTime(*options.StartTime)
+	}
+
+	if options.EndTime != nil && !options.EndTime.IsZero() {
+		req.EndTime(*options.EndTime)
+	}
+
+	trades, err := e.queryTradeHistory(ctx, req, options.LastTradeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+// queryTradeHistory queries the pages of the transaction history request, and returns the trades in the
+// ascending order of the trade time. The trade IDs of an instrument are increasing, so the trades not newer than
+// the last trade ID are dropped, and the older pages are not queried.
+func (e *Exchange) queryTradeHistory(ctx context.Context, req *okexapi.GetTransactionHistoryRequest, lastTradeID uint64) ([]types.Trade, error) {
+	var trades []types.Trade
+
+	req.Limit(historyPageLimit)
+	for {
+		if err := tradeHistoryLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		reachedLastTrade := false
+		for _, record := range records {
+			trade, err := toGlobalTrade(record)
+			if err != nil {
+				return trades, err
+			}
+
+			if lastTradeID > 0 && trade.ID <= lastTradeID {
+				reachedLastTrade = true
+				continue
+			}
+
+			trades = append(trades, *trade)
+		}
+
+		if reachedLastTrade || len(records) < historyPageLimit {
+			break
+		}
+
+		req.After(records[len(records)-1].BillID)
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		if trades[i].Time.Equal(trades[j].Time.Time()) {
+			return trades[i].ID < trades[j].ID
+		}
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	return trades, nil
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	req := e.client.AssetService.NewGetDepositHistoryRequest().Limit(historyPageLimit)
+	if len(asset) > 0 {
+		req.Currency(asset)
+	}
+
+	if !since.IsZero() {
+		req.Before(since)
+	}
+
+	// the after parameter is exclusive, the next page starts from the next millisecond of the last record,
+	// so that the records of the same millisecond are not skipped, and the seen records are dropped by the ID
+	seen := make(map[string]struct{})
+	for {
+		if err := assetHistoryLimiter.Wait(ctx); err != nil {
+			return allDeposits, err
+		}
+
+		if !until.IsZero() {
+			req.After(until)
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return allDeposits, err
+		}
+
+		numOfNewRecords := 0
+		for _, record := range records {
+			if _, ok := seen[record.DepositID]; ok {
+				continue
+			}
+
+			seen[record.DepositID] = struct{}{}
+			numOfNewRecords++
+			allDeposits = append(allDeposits, toGlobalDeposit(record))
+		}
+
+		if len(records) < historyPageLimit || numOfNewRecords == 0 {
+			break
+		}
+
+		until = records[len(records)-1].Timestamp.Time().Add(time.Millisecond)
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time.Time())
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	req := e.client.AssetService.NewGetWithdrawalHistoryRequest().Limit(historyPageLimit)
+	if len(asset) > 0 {
+		req.Currency(asset)
+	}
+
+	if !since.IsZero() {
+		req.Before(since)
+	}
+
+	// the after parameter is exclusive, the next page starts from the next millisecond of the last record,
+	// so that the records of the same millisecond are not skipped, and the seen records are dropped by the ID
+	seen := make(map[string]struct{})
+	for {
+		if err := assetHistoryLimiter.Wait(ctx); err != nil {
+			return allWithdraws, err
+		}
+
+		if !until.IsZero() {
+			req.After(until)
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return allWithdraws, err
+		}
+
+		numOfNewRecords := 0
+		for _, record := range records {
+			if _, ok := seen[record.WithdrawalID]; ok {
+				continue
+			}
+
+			seen[record.WithdrawalID] = struct{}{}
+			numOfNewRecords++
+			allWithdraws = append(allWithdraws, toGlobalWithdraw(record))
+		}
+
+		if len(records) < historyPageLimit || numOfNewRecords == 0 {
+			break
+		}
+
+		until = records[len(records)-1].Timestamp.Time().Add(time.Millisecond)
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime.Time())
+	})
+
+	return allWithdraws, nil
+}
+
+// DefaultFeeRates returns the fee rates of the regular user level 1 of the spot market
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	return types.ExchangeFee{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.080), // 0.08%
+		TakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.100), // 0.10%
+	}
+}
@@ -0,0 +1,164 @@
+package okex
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/conformance"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMockExchange(t *testing.T) (*Exchange, *conformance.MockServer) {
+	server := conformance.NewMockServer(t, "testdata")
+
+	ex := New("key", "secret", "passphrase")
+	baseURL, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ex.client.BaseURL = baseURL
+	return ex, server
+}
+
+// newFillsResponse returns a fills history page of the trade IDs in the descending order
+func newFillsResponse(t *testing.T, fromTradeID, toTradeID int) []byte {
+	startTime := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var fills []map[string]interface{}
+	for id := fromTradeID; id >= toTradeID; id-- {
+		fills = append(fills, map[string]interface{}{
+			"instType": "SPOT",
+			"instId":   "BTC-USDT",
+			"tradeId":  strconv.Itoa(id),
+			"ordId":    strconv.Itoa(id + 10000),
+			"billId":   strconv.Itoa(id + 90000),
+			"fillPx":   "20000",
+			"fillSz":   "0.01",
+			"side":     "buy",
+			"execType": "T",
+			"feeCcy":   "BTC",
+			"fee":      "-0.00001",
+			"ts":       strconv.FormatInt(startTime.Add(time.Duration(id)*time.Minute).UnixMilli(), 10),
+		})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"code": "0", "msg": "", "data": fills})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return data
+}
+
+func TestExchange_QueryTrades_LastTradeID(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v5/trade/fills-history",
+		newFillsResponse(t, 300, 201),
+		newFillsResponse(t, 200, 101),
+		newFillsResponse(t, 100, 1))
+
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSDT", &types.TradeQueryOptions{LastTradeID: 150, Limit: 100})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the trades after the last trade ID in the ascending order
+	if assert.Len(t, trades, 100) {
+		assert.Equal(t, uint64(151), trades[0].ID)
+		assert.Equal(t, uint64(250), trades[99].ID)
+	}
+
+	// the page containing the last trade ID stops the paging
+	requests := server.Requests("GET", "/api/v5/trade/fills-history")
+	if assert.Len(t, requests, 2) {
+		assert.Empty(t, requests[0].Query.Get("after"))
+		assert.Equal(t, "90201", requests[1].Query.Get("after"))
+		assert.Empty(t, requests[1].Query.Get("begin"))
+	}
+}
+
+func TestExchange_QueryClosedOrders_ZeroTime(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v5/trade/orders-history-archive", []byte(`{"code":"0","msg":"","data":[]}`))
+
+	orders, err := ex.QueryClosedOrders(context.Background(), "BTCUSDT", time.Time{}, time.Time{}, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+
+	// the epoch time is not sent as the time range
+	requests := server.Requests("GET", "/api/v5/trade/orders-history-archive")
+	if assert.Len(t, requests, 1) {
+		_, hasBegin := requests[0].Query["begin"]
+		_, hasEnd := requests[0].Query["end"]
+		assert.False(t, hasBegin)
+		assert.False(t, hasEnd)
+	}
+}
+
+func TestExchange_QueryOrder_NotFound(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v5/trade/order", []byte(`{"code":"0","msg":"","data":[]}`))
+
+	_, err := ex.QueryOrder(context.Background(), types.OrderQuery{Symbol: "BTCUSDT", OrderID: "312269865356374016"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "order not found")
+		assert.Contains(t, err.Error(), "instId: BTC-USDT")
+		assert.Contains(t, err.Error(), "ordId: 312269865356374016")
+	}
+}
+
+// newDepositsResponse returns a deposit history page of the deposit IDs in the descending order,
+// the deposits of the even and the next odd IDs are in the same millisecond
+func newDepositsResponse(t *testing.T, fromID, toID int) []byte {
+	startTime := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var deposits []map[string]interface{}
+	for id := fromID; id >= toID; id-- {
+		deposits = append(deposits, map[string]interface{}{
+			"ccy":   "USDT",
+			"chain": "USDT-TRC20",
+			"amt":   "100",
+			"txId":  "tx" + strconv.Itoa(id),
+			"state": "2",
+			"depId": strconv.Itoa(id),
+			"ts":    strconv.FormatInt(startTime.Add(time.Duration(id/2)*time.Minute).UnixMilli(), 10),
+		})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"code": "0", "msg": "", "data": deposits})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return data
+}
+
+func TestExchange_QueryDepositHistory_SameMillisecond(t *testing.T) {
+	ex, server := newMockExchange(t)
+	server.Handle("GET", "/api/v5/asset/deposit-history",
+		newDepositsResponse(t, 200, 101),
+		// the deposit 100 is in the same millisecond of the last deposit 101 of the first page
+		newDepositsResponse(t, 101, 51))
+
+	deposits, err := ex.QueryDepositHistory(context.Background(), "USDT", time.Time{}, time.Time{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, deposits, 150) {
+		assert.Equal(t, "tx51", deposits[0].TransactionID)
+		assert.Equal(t, "tx200", deposits[149].TransactionID)
+	}
+
+	// the second page includes the millisecond of the last deposit
+	startTime := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	requests := server.Requests("GET", "/api/v5/asset/deposit-history")
+	if assert.Len(t, requests, 2) {
+		assert.Empty(t, requests[0].Query.Get("after"))
+		assert.Equal(t, strconv.FormatInt(startTime.Add(50*time.Minute+time.Millisecond).UnixMilli(), 10), requests[1].Query.Get("after"))
+	}
+}
@@ -0,0 +1,244 @@
+package okexapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type AssetService struct {
+	client *RestClient
+}
+
+func (s *AssetService) NewGetDepositHistoryRequest() *GetDepositHistoryRequest {
+	return &GetDepositHistoryRequest{
+		client: s.client,
+	}
+}
+
+func (s *AssetService) NewGetWithdrawalHistoryRequest() *GetWithdrawalHistoryRequest {
+	return &GetWithdrawalHistoryRequest{
+		client: s.client,
+	}
+}
+
+type DepositState string
+
+const (
+	DepositStateWaitingForConfirmation DepositState = "0"
+	DepositStateCredited               DepositState = "1"
+	DepositStateSuccessful             DepositState = "2"
+)
+
+type Deposit struct {
+	Currency      string                     `json:"ccy"`
+	Chain         string                     `json:"chain"`
+	Amount        fixedpoint.Value           `json:"amt"`
+	From          string                     `json:"from"`
+	To            string                     `json:"to"`
+	TransactionID string                     `json:"txId"`
+	Timestamp     types.MillisecondTimestamp `json:"ts"`
+	State         DepositState               `json:"state"`
+	DepositID     string                     `json:"depId"`
+}
+
+type WithdrawalState string
+
+const (
+	WithdrawalStatePendingCancel    WithdrawalState = "-3"
+	WithdrawalStateCanceled         WithdrawalState = "-2"
+	WithdrawalStateFailed           WithdrawalState = "-1"
+	WithdrawalStatePending          WithdrawalState = "0"
+	WithdrawalStateSending          WithdrawalState = "1"
+	WithdrawalStateSent             WithdrawalState = "2"
+	WithdrawalStateAwaitingEmail    WithdrawalState = "3"
+	WithdrawalStateAwaitingManual   WithdrawalState = "4"
+	WithdrawalStateAwaitingIdentity WithdrawalState = "5"
+)
+
+type Withdrawal struct {
+	Currency      string                     `json:"ccy"`
+	Chain         string                     `json:"chain"`
+	Amount        fixedpoint.Value           `json:"amt"`
+	To            string                     `json:"to"`
+	Tag           string                     `json:"tag"`
+	TransactionID string                     `json:"txId"`
+	Fee           fixedpoint.Value           `json:"fee"`
+	Timestamp     types.MillisecondTimestamp `json:"ts"`
+	State         WithdrawalState            `json:"state"`
+	WithdrawalID  string                     `json:"wdId"`
+	ClientID      string                     `json:"clientId"`
+}
+
+// GetDepositHistoryRequest queries the deposit records of the last 3 months,
+// the records are returned in the descending order of the deposit time.
+type GetDepositHistoryRequest struct {
+	client *RestClient
+
+	ccy    *string
+	after  *time.Time
+	before *time.Time
+	limit  *int
+}
+
+func (r *GetDepositHistoryRequest) Currency(currency string) *GetDepositHistoryRequest {
+	r.ccy = &currency
+	return r
+}
+
+// After returns the records earlier than the given time
+func (r *GetDepositHistoryRequest) After(after time.Time) *GetDepositHistoryRequest {
+	r.after = &after
+	return r
+}
+
+// Before returns the records newer than the given time
+func (r *GetDepositHistoryRequest) Before(before time.Time) *GetDepositHistoryRequest {
+	r.before = &before
+	return r
+}
+
+// Limit sets the number of the records, the maximum and the default is 100
+func (r *GetDepositHistoryRequest) Limit(limit int) *GetDepositHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetDepositHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	if r.ccy != nil {
+		values.Add("ccy", *r.ccy)
+	}
+
+	if r.after != nil {
+		values.Add("after", strconv.FormatInt(r.after.UnixMilli(), 10))
+	}
+
+	if r.before != nil {
+		values.Add("before", strconv.FormatInt(r.before.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetDepositHistoryRequest) Do(ctx context.Context) ([]Deposit, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/asset/deposit-history", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var depositResponse struct {
+		Code    string    `json:"code"`
+		Message string    `json:"msg"`
+		Data    []Deposit `json:"data"`
+	}
+	if err := response.DecodeJSON(&depositResponse); err != nil {
+		return nil, err
+	}
+
+	if depositResponse.Code != "0" {
+		return nil, fmt.Errorf("deposit history query error: %s %s", depositResponse.Code, depositResponse.Message)
+	}
+
+	return depositResponse.Data, nil
+}
+
+// GetWithdrawalHistoryRequest queries the withdrawal records of the last 3 months,
+// the records are returned in the descending order of the withdrawal time.
+type GetWithdrawalHistoryRequest struct {
+	client *RestClient
+
+	ccy    *string
+	after  *time.Time
+	before *time.Time
+	limit  *int
+}
+
+func (r *GetWithdrawalHistoryRequest) Currency(currency string) *GetWithdrawalHistoryRequest {
+	r.ccy = &currency
+	return r
+}
+
+// After returns the records earlier than the given time
+func (r *GetWithdrawalHistoryRequest) After(after time.Time) *GetWithdrawalHistoryRequest {
+	r.after = &after
+	return r
+}
+
+// Before returns the records newer than the given time
+func (r *GetWithdrawalHistoryRequest) Before(before time.Time) *GetWithdrawalHistoryRequest {
+	r.before = &before
+	return r
+}
+
+// Limit sets the number of the records, the maximum and the default is 100
+func (r *GetWithdrawalHistoryRequest) Limit(limit int) *GetWithdrawalHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetWithdrawalHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	if r.ccy != nil {
+		values.Add("ccy", *r.ccy)
+	}
+
+	if r.after != nil {
+		values.Add("after", strconv.FormatInt(r.after.UnixMilli(), 10))
+	}
+
+	if r.before != nil {
+		values.Add("before", strconv.FormatInt(r.before.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetWithdrawalHistoryRequest) Do(ctx context.Context) ([]Withdrawal, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/asset/withdrawal-history", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var withdrawalResponse struct {
+		Code    string       `json:"code"`
+		Message string       `json:"msg"`
+		Data    []Withdrawal `json:"data"`
+	}
+	if err := response.DecodeJSON(&withdrawalResponse); err != nil {
+		return nil, err
+	}
+
+	if withdrawalResponse.Code != "0" {
+		return nil, fmt.Errorf("withdrawal history query error: %s %s", withdrawalResponse.Code, withdrawalResponse.Message)
+	}
+
+	return withdrawalResponse.Data, nil
+}
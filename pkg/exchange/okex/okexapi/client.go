@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -23,6 +24,10 @@ const RestBaseURL = "https://www.okex.com/"
 const PublicWebSocketURL = "wss://ws.okex.com:8443/ws/v5/public"
 const PrivateWebSocketURL = "wss://ws.okex.com:8443/ws/v5/private"
 
+// RequestBudget is the request budget shared by the okex clients.
+// okex limits the requests per endpoint, the shared budget avoids bursting the endpoints.
+var RequestBudget = ratelimit.NewBudget("okex", 100, 2*time.Second)
+
 type SideType string
 
 const (
@@ -68,6 +73,7 @@ type RestClient struct {
 	TradeService      *TradeService
 	PublicDataService *PublicDataService
 	MarketDataService *MarketDataService
+	AssetService      *AssetService
 }
 
 func NewClient() *RestClient {
@@ -79,13 +85,15 @@ func NewClient() *RestClient {
 	client := &RestClient{
 		BaseURL: u,
 		client: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(http.DefaultTransport, RequestBudget),
 		},
 	}
 
 	client.TradeService = &TradeService{client: client}
 	client.PublicDataService = &PublicDataService{client: client}
 	client.MarketDataService = &MarketDataService{client: client}
+	client.AssetService = &AssetService{client: client}
 	return client
 }
 
@@ -2,8 +2,11 @@ package okexapi
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -64,6 +67,18 @@ func (c *TradeService) NewGetTransactionDetailsRequest() *GetTransactionDetailsR
 	}
 }
 
+func (c *TradeService) NewGetOrderHistoryRequest() *GetOrderHistoryRequest {
+	return &GetOrderHistoryRequest{
+		client: c.client,
+	}
+}
+
+func (c *TradeService) NewGetTransactionHistoryRequest() *GetTransactionHistoryRequest {
+	return &GetTransactionHistoryRequest{
+		client: c.client,
+	}
+}
+
 //go:generate requestgen -type PlaceOrderRequest
 type PlaceOrderRequest struct {
 	client *RestClient
@@ -363,7 +378,8 @@ func (r *GetOrderDetailsRequest) Do(ctx context.Context) (*OrderDetails, error)
 	}
 
 	if len(orderResponse.Data) == 0 {
-		return nil, errors.New("order create error")
+		return nil, fmt.Errorf("order not found, instId: %s, ordId: %s, clOrdId: %s, code: %s, message: %s",
+			params.Get("instId"), params.Get("ordId"), params.Get("clOrdId"), orderResponse.Code, orderResponse.Message)
 	}
 
 	return &orderResponse.Data[0], nil
@@ -410,15 +426,15 @@ func (r *GetPendingOrderRequest) Parameters() map[string]interface{} {
 	var payload = map[string]interface{}{}
 
 	if r.instId != nil {
-		payload["instId"] = r.instId
+		payload["instId"] = *r.instId
 	}
 
 	if r.instType != nil {
-		payload["instType"] = r.instType
+		payload["instType"] = *r.instType
 	}
 
 	if r.state != nil {
-		payload["state"] = r.state
+		payload["state"] = *r.state
 	}
 
 	if len(r.orderTypes) > 0 {
@@ -429,8 +445,8 @@ func (r *GetPendingOrderRequest) Parameters() map[string]interface{} {
 }
 
 func (r *GetPendingOrderRequest) Do(ctx context.Context) ([]OrderDetails, error) {
-	payload := r.Parameters()
-	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/orders-pending", nil, payload)
+	params := toQueryParameters(r.Parameters())
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/orders-pending", params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -481,23 +497,150 @@ func (r *GetTransactionDetailsRequest) Parameters() map[string]interface{} {
 	var payload = map[string]interface{}{}
 
 	if r.instType != nil {
-		payload["instType"] = r.instType
+		payload["instType"] = *r.instType
 	}
 
 	if r.instId != nil {
-		payload["instId"] = r.instId
+		payload["instId"] = *r.instId
 	}
 
 	if r.ordId != nil {
-		payload["ordId"] = r.ordId
+		payload["ordId"] = *r.ordId
 	}
 
 	return payload
 }
 
 func (r *GetTransactionDetailsRequest) Do(ctx context.Context) ([]OrderDetails, error) {
-	payload := r.Parameters()
-	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/fills", nil, payload)
+	params := toQueryParameters(r.Parameters())
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/fills", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResponse struct {
+		Code    string         `json:"code"`
+		Message string         `json:"msg"`
+		Data    []OrderDetails `json:"data"`
+	}
+	if err := response.DecodeJSON(&orderResponse); err != nil {
+		return nil, err
+	}
+
+	return orderResponse.Data, nil
+}
+
+// toQueryParameters converts the parameters to the query string, the GET APIs of OKEx do not accept the parameters in the body
+func toQueryParameters(payload map[string]interface{}) url.Values {
+	var values = url.Values{}
+	for k, v := range payload {
+		values.Add(k, fmt.Sprintf("%v", v))
+	}
+	return values
+}
+
+// GetOrderHistoryRequest queries the completed orders (filled or canceled) of the last 3 months,
+// the orders are returned in the descending order of the creation time.
+type GetOrderHistoryRequest struct {
+	client *RestClient
+
+	instType  InstrumentType
+	instId    *string
+	state     *OrderState
+	after     *string
+	before    *string
+	startTime *time.Time
+	endTime   *time.Time
+	limit     *int
+}
+
+func (r *GetOrderHistoryRequest) InstrumentType(instType InstrumentType) *GetOrderHistoryRequest {
+	r.instType = instType
+	return r
+}
+
+func (r *GetOrderHistoryRequest) InstrumentID(instId string) *GetOrderHistoryRequest {
+	r.instId = &instId
+	return r
+}
+
+func (r *GetOrderHistoryRequest) State(state OrderState) *GetOrderHistoryRequest {
+	r.state = &state
+	return r
+}
+
+// After returns the orders older than the given order ID
+func (r *GetOrderHistoryRequest) After(orderID string) *GetOrderHistoryRequest {
+	r.after = &orderID
+	return r
+}
+
+// Before returns the orders newer than the given order ID
+func (r *GetOrderHistoryRequest) Before(orderID string) *GetOrderHistoryRequest {
+	r.before = &orderID
+	return r
+}
+
+func (r *GetOrderHistoryRequest) StartTime(startTime time.Time) *GetOrderHistoryRequest {
+	r.startTime = &startTime
+	return r
+}
+
+func (r *GetOrderHistoryRequest) EndTime(endTime time.Time) *GetOrderHistoryRequest {
+	r.endTime = &endTime
+	return r
+}
+
+// Limit sets the number of the orders, the maximum and the default is 100
+func (r *GetOrderHistoryRequest) Limit(limit int) *GetOrderHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetOrderHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	values.Add("instType", string(r.instType))
+
+	if r.instId != nil {
+		values.Add("instId", *r.instId)
+	}
+
+	if r.state != nil {
+		values.Add("state", string(*r.state))
+	}
+
+	if r.after != nil {
+		values.Add("after", *r.after)
+	}
+
+	if r.before != nil {
+		values.Add("before", *r.before)
+	}
+
+	if r.startTime != nil {
+		values.Add("begin", strconv.FormatInt(r.startTime.UnixMilli(), 10))
+	}
+
+	if r.endTime != nil {
+		values.Add("end", strconv.FormatInt(r.endTime.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetOrderHistoryRequest) Do(ctx context.Context) ([]OrderDetails, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/orders-history-archive", params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -516,5 +659,156 @@ func (r *GetTransactionDetailsRequest) Do(ctx context.Context) ([]OrderDetails,
 		return nil, err
 	}
 
+	if orderResponse.Code != "0" {
+		return nil, fmt.Errorf("order history query error: %s %s", orderResponse.Code, orderResponse.Message)
+	}
+
 	return orderResponse.Data, nil
 }
+
+type Trade struct {
+	InstrumentType InstrumentType `json:"instType"`
+	InstrumentID   string         `json:"instId"`
+	TradeID        string         `json:"tradeId"`
+	OrderID        string         `json:"ordId"`
+	ClientOrderID  string         `json:"clOrdId"`
+
+	// BillID is the pagination ID of the transaction history
+	BillID string `json:"billId"`
+	Tag    string `json:"tag"`
+
+	FillPrice    fixedpoint.Value `json:"fillPx"`
+	FillQuantity fixedpoint.Value `json:"fillSz"`
+	Side         SideType         `json:"side"`
+
+	// ExecutionType = liquidity (M = maker or T = taker)
+	ExecutionType string `json:"execType"`
+
+	// Fee is negative for the charged fee, and positive for the rebate
+	FeeCurrency string           `json:"feeCcy"`
+	Fee         fixedpoint.Value `json:"fee"`
+
+	Timestamp types.MillisecondTimestamp `json:"ts"`
+}
+
+// GetTransactionHistoryRequest queries the fills of the last 3 months,
+// the fills are returned in the descending order of the bill ID.
+type GetTransactionHistoryRequest struct {
+	client *RestClient
+
+	instType  InstrumentType
+	instId    *string
+	ordId     *string
+	after     *string
+	before    *string
+	startTime *time.Time
+	endTime   *time.Time
+	limit     *int
+}
+
+func (r *GetTransactionHistoryRequest) InstrumentType(instType InstrumentType) *GetTransactionHistoryRequest {
+	r.instType = instType
+	return r
+}
+
+func (r *GetTransactionHistoryRequest) InstrumentID(instId string) *GetTransactionHistoryRequest {
+	r.instId = &instId
+	return r
+}
+
+func (r *GetTransactionHistoryRequest) OrderID(orderID string) *GetTransactionHistoryRequest {
+	r.ordId = &orderID
+	return r
+}
+
+// After returns the fills older than the given bill ID
+func (r *GetTransactionHistoryRequest) After(billID string) *GetTransactionHistoryRequest {
+	r.after = &billID
+	return r
+}
+
+// Before returns the fills newer than the given bill ID
+func (r *GetTransactionHistoryRequest) Before(billID string) *GetTransactionHistoryRequest {
+	r.before = &billID
+	return r
+}
+
+func (r *GetTransactionHistoryRequest) StartTime(startTime time.Time) *GetTransactionHistoryRequest {
+	r.startTime = &startTime
+	return r
+}
+
+func (r *GetTransactionHistoryRequest) EndTime(endTime time.Time) *GetTransactionHistoryRequest {
+	r.endTime = &endTime
+	return r
+}
+
+// Limit sets the number of the fills, the maximum and the default is 100
+func (r *GetTransactionHistoryRequest) Limit(limit int) *GetTransactionHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetTransactionHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	values.Add("instType", string(r.instType))
+
+	if r.instId != nil {
+		values.Add("instId", *r.instId)
+	}
+
+	if r.ordId != nil {
+		values.Add("ordId", *r.ordId)
+	}
+
+	if r.after != nil {
+		values.Add("after", *r.after)
+	}
+
+	if r.before != nil {
+		values.Add("before", *r.before)
+	}
+
+	if r.startTime != nil {
+		values.Add("begin", strconv.FormatInt(r.startTime.UnixMilli(), 10))
+	}
+
+	if r.endTime != nil {
+		values.Add("end", strconv.FormatInt(r.endTime.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetTransactionHistoryRequest) Do(ctx context.Context) ([]Trade, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/fills-history", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var tradeResponse struct {
+		Code    string  `json:"code"`
+		Message string  `json:"msg"`
+		Data    []Trade `json:"data"`
+	}
+	if err := response.DecodeJSON(&tradeResponse); err != nil {
+		return nil, err
+	}
+
+	if tradeResponse.Code != "0" {
+		return nil, fmt.Errorf("transaction history query error: %s %s", tradeResponse.Code, tradeResponse.Message)
+	}
+
+	return tradeResponse.Data, nil
+}
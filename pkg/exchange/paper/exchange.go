@@ -0,0 +1,429 @@
+package paper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "paper")
+
+// defaultFeeRate is used when the source exchange does not provide the default fee rates
+var defaultFeeRate = fixedpoint.NewFromFloat(0.1 * 0.01)
+
+// Exchange is the paper trading exchange.
+// The market data is queried and streamed from the source exchange, while the orders are matched by the local
+// matching engine against the live prices of the market data stream, and settled with the simulated balances.
+type Exchange struct {
+	types.ExchangeMarketDataService
+
+	source   types.Exchange
+	feeRates types.ExchangeFee
+
+	mu           sync.Mutex
+	account      *types.Account
+	markets      types.MarketMap
+	lastPrices   map[string]fixedpoint.Value
+	openOrders   map[uint64]types.Order
+	closedOrders map[uint64]types.Order
+	orderID      uint64
+	tradeID      uint64
+
+	userDataStreams []types.StandardStreamEmitter
+}
+
+// New creates a paper trading exchange wrapping the source exchange with the initial balances
+func New(source types.Exchange, balances types.BalanceMap) *Exchange {
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+
+	feeRates := types.ExchangeFee{MakerFeeRate: defaultFeeRate, TakerFeeRate: defaultFeeRate}
+	if provider, ok := source.(types.ExchangeDefaultFeeRates); ok {
+		feeRates = provider.DefaultFeeRates()
+	}
+
+	return &Exchange{
+		ExchangeMarketDataService: source,
+		source:                    source,
+		feeRates:                  feeRates,
+		account:                   account,
+		lastPrices:                make(map[string]fixedpoint.Value),
+		openOrders:                make(map[uint64]types.Order),
+		closedOrders:              make(map[uint64]types.Order),
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return e.source.Name()
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return e.source.PlatformFeeCurrency()
+}
+
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	return e.feeRates
+}
+
+// SetFeeRates sets the fee rates of the simulated trades
+func (e *Exchange) SetFeeRates(feeRates types.ExchangeFee) {
+	e.feeRates = feeRates
+}
+
+// NewStream creates the stream, the market data is streamed from the source exchange when it's set to public only,
+// otherwise, the stream emits the order, trade and balance updates of the paper account.
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e, e.source.NewStream())
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.UpdateBalances(e.account.Balances())
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.account.Balances(), nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range e.openOrders {
+		if order.Symbol == symbol {
+			orders = append(orders, order)
+		}
+	}
+
+	return types.SortOrdersAscending(orders), nil
+}
+
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	orderID, err := strconv.ParseUint(q.OrderID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if order, ok := e.openOrders[orderID]; ok {
+		return &order, nil
+	}
+
+	if order, ok := e.closedOrders[orderID]; ok {
+		return &order, nil
+	}
+
+	return nil, fmt.Errorf("order %d not found", orderID)
+}
+
+func (e *Exchange) SubmitOrder(ctx context.Context, o types.SubmitOrder) (*types.Order, error) {
+	market, err := e.market(ctx, o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	lastPrice, err := e.lastPrice(ctx, o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("order quantity %s must be positive", o.Quantity.String())
+	}
+
+	var isTaker bool
+	switch o.Type {
+	case types.OrderTypeMarket:
+		isTaker = true
+		o.Price = lastPrice
+
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if o.Price.Sign() <= 0 {
+			return nil, fmt.Errorf("limit order price %s must be positive", o.Price.String())
+		}
+
+		isTaker = isCrossed(o.Side, o.Price, lastPrice)
+		if isTaker && o.Type == types.OrderTypeLimitMaker {
+			return nil, fmt.Errorf("limit maker order %s %s @ %s would immediately match the last price %s",
+				o.Side, o.Symbol, o.Price.String(), lastPrice.String())
+		}
+
+	default:
+		return nil, fmt.Errorf("order type %s is not supported by paper trading", o.Type)
+	}
+
+	if err := e.lockOrderBalance(market, o); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.orderID++
+	order := types.Order{
+		SubmitOrder:      o,
+		Exchange:         e.Name(),
+		OrderID:          e.orderID,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}
+
+	// emit the new order and the locked balances first
+	events := []interface{}{order, e.account.Balances()}
+
+	if isTaker {
+		// the taker order is filled at the last price, which is better than or equal to the limit price
+		events = append(events, e.fill(market, order, lastPrice, false)...)
+	} else {
+		e.openOrders[order.OrderID] = order
+	}
+	e.mu.Unlock()
+
+	e.emit(events)
+	return &order, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var events []interface{}
+
+	e.mu.Lock()
+	for _, o := range orders {
+		order, ok := e.openOrders[o.OrderID]
+		if !ok {
+			e.mu.Unlock()
+			e.emit(events)
+			return fmt.Errorf("order %d not found or already closed", o.OrderID)
+		}
+
+		market := e.markets[order.Symbol]
+		currency, amount := lockedAmount(market, order.SubmitOrder)
+		if err := e.account.UnlockBalance(currency, amount); err != nil {
+			log.WithError(err).Errorf("can not unlock the balance of the canceled order %d", order.OrderID)
+		}
+
+		delete(e.openOrders, order.OrderID)
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+		order.UpdateTime = types.Time(time.Now())
+		e.closedOrders[order.OrderID] = order
+		events = append(events, order, e.account.Balances())
+	}
+	e.mu.Unlock()
+
+	e.emit(events)
+	return nil
+}
+
+// bindUserDataStream registers the stream emitting the updates of the paper account
+func (e *Exchange) bindUserDataStream(stream types.StandardStreamEmitter) {
+	e.mu.Lock()
+	e.userDataStreams = append(e.userDataStreams, stream)
+	e.mu.Unlock()
+}
+
+// processPrice updates the last price of the symbol and matches the open orders against the price
+func (e *Exchange) processPrice(symbol string, price fixedpoint.Value) {
+	if price.Sign() <= 0 {
+		return
+	}
+
+	var events []interface{}
+
+	e.mu.Lock()
+	e.lastPrices[symbol] = price
+
+	var matched []types.Order
+	for _, order := range e.openOrders {
+		if order.Symbol == symbol && isCrossed(order.Side, order.Price, price) {
+			matched = append(matched, order)
+		}
+	}
+
+	for _, order := range types.SortOrdersAscending(matched) {
+		// the resting order is filled at its own price as a maker
+		delete(e.openOrders, order.OrderID)
+		events = append(events, e.fill(e.markets[symbol], order, order.Price, true)...)
+	}
+	e.mu.Unlock()
+
+	e.emit(events)
+}
+
+// fill executes the whole order at the given price, it must be called with the lock held
+func (e *Exchange) fill(market types.Market, order types.Order, price fixedpoint.Value, isMaker bool) (events []interface{}) {
+	feeRate := e.feeRates.TakerFeeRate
+	if isMaker {
+		feeRate = e.feeRates.MakerFeeRate
+	}
+
+	quantity := order.Quantity
+	quoteQuantity := quantity.Mul(price)
+
+	var fee fixedpoint.Value
+	var feeCurrency string
+	switch order.Side {
+	case types.SideTypeBuy:
+		// the fee is deducted from the received base asset
+		fee, feeCurrency = quantity.Mul(feeRate), market.BaseCurrency
+
+		_, locked := lockedAmount(market, order.SubmitOrder)
+		if err := e.account.UseLockedBalance(market.QuoteCurrency, locked); err != nil {
+			log.WithError(err).Errorf("can not use the locked balance of order %d", order.OrderID)
+		}
+
+		// return the unused quote amount when the order is filled at a better price
+		if refund := locked.Sub(quoteQuantity); refund.Sign() > 0 {
+			e.account.AddBalance(market.QuoteCurrency, refund)
+		}
+
+		e.account.AddBalance(market.BaseCurrency, quantity.Sub(fee))
+
+	case types.SideTypeSell:
+		// the fee is deducted from the received quote asset
+		fee, feeCurrency = quoteQuantity.Mul(feeRate), market.QuoteCurrency
+
+		if err := e.account.UseLockedBalance(market.BaseCurrency, quantity); err != nil {
+			log.WithError(err).Errorf("can not use the locked balance of order %d", order.OrderID)
+		}
+
+		e.account.AddBalance(market.QuoteCurrency, quoteQuantity.Sub(fee))
+	}
+
+	now := time.Now()
+	e.tradeID++
+	trade := types.Trade{
+		ID:            e.tradeID,
+		OrderID:       order.OrderID,
+		Exchange:      e.Name(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(now),
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = quantity
+	order.IsWorking = false
+	order.UpdateTime = types.Time(now)
+	e.closedOrders[order.OrderID] = order
+
+	return append(events, trade, order, e.account.Balances())
+}
+
+func (e *Exchange) lockOrderBalance(market types.Market, o types.SubmitOrder) error {
+	currency, amount := lockedAmount(market, o)
+	return e.account.LockBalance(currency, amount)
+}
+
+// lockedAmount returns the currency and the amount locked by the order,
+// the buy order locks the quote amount of the order price, and the sell order locks the base quantity
+func lockedAmount(market types.Market, o types.SubmitOrder) (string, fixedpoint.Value) {
+	if o.Side == types.SideTypeBuy {
+		return market.QuoteCurrency, o.Quantity.Mul(o.Price)
+	}
+
+	return market.BaseCurrency, o.Quantity
+}
+
+// isCrossed returns true if the order price crosses the given price, i.e., the buy price is higher than or equal to
+// the price, or the sell price is lower than or equal to the price.
+func isCrossed(side types.SideType, orderPrice, price fixedpoint.Value) bool {
+	if side == types.SideTypeBuy {
+		return orderPrice.Compare(price) >= 0
+	}
+
+	return orderPrice.Compare(price) <= 0
+}
+
+func (e *Exchange) market(ctx context.Context, symbol string) (types.Market, error) {
+	e.mu.Lock()
+	markets := e.markets
+	e.mu.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.source.QueryMarkets(ctx)
+		if err != nil {
+			return types.Market{}, err
+		}
+
+		e.mu.Lock()
+		e.markets = markets
+		e.mu.Unlock()
+	}
+
+	market, ok := markets[symbol]
+	if !ok {
+		return market, fmt.Errorf("market %s not found", symbol)
+	}
+
+	return market, nil
+}
+
+// lastPrice returns the last price from the market data stream, the ticker is queried if no price is received yet
+func (e *Exchange) lastPrice(ctx context.Context, symbol string) (fixedpoint.Value, error) {
+	e.mu.Lock()
+	price, ok := e.lastPrices[symbol]
+	e.mu.Unlock()
+
+	if ok {
+		return price, nil
+	}
+
+	ticker, err := e.source.QueryTicker(ctx, symbol)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	if ticker.Last.Sign() <= 0 {
+		return fixedpoint.Zero, fmt.Errorf("can not get the last price of %s", symbol)
+	}
+
+	e.mu.Lock()
+	if _, ok := e.lastPrices[symbol]; !ok {
+		e.lastPrices[symbol] = ticker.Last
+	}
+	e.mu.Unlock()
+
+	return ticker.Last, nil
+}
+
+// emit emits the events to the user data streams, it must be called without the lock held,
+// since the callbacks might submit or cancel orders.
+func (e *Exchange) emit(events []interface{}) {
+	e.mu.Lock()
+	streams := e.userDataStreams
+	e.mu.Unlock()
+
+	for _, event := range events {
+		for _, stream := range streams {
+			switch ev := event.(type) {
+			case types.Order:
+				stream.EmitOrderUpdate(ev)
+			case types.Trade:
+				stream.EmitTradeUpdate(ev)
+			case types.BalanceMap:
+				stream.EmitBalanceUpdate(ev)
+			}
+		}
+	}
+}
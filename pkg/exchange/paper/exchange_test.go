@@ -0,0 +1,149 @@
+package paper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+var number = fixedpoint.MustNewFromString
+
+func TestExchange(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+
+	source := mocks.NewMockExchange(mockCtrl)
+	source.EXPECT().Name().Return(types.ExchangeBinance).AnyTimes()
+	source.EXPECT().NewStream().Return(&types.StandardStream{})
+	source.EXPECT().QueryMarkets(ctx).Return(types.MarketMap{
+		"BTCUSDT": {
+			Symbol:        "BTCUSDT",
+			BaseCurrency:  "BTC",
+			QuoteCurrency: "USDT",
+		},
+	}, nil)
+	// the ticker is only queried before receiving any price from the market data stream
+	source.EXPECT().QueryTicker(ctx, "BTCUSDT").Return(&types.Ticker{Last: number("20000")}, nil)
+
+	ex := New(source, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: number("10000")},
+	})
+
+	stream := ex.NewStream()
+	var orders []types.Order
+	var trades []types.Trade
+	var balances types.BalanceMap
+	stream.OnOrderUpdate(func(order types.Order) { orders = append(orders, order) })
+	stream.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+	stream.OnBalanceUpdate(func(b types.BalanceMap) { balances = b })
+	assert.NoError(t, stream.Connect(ctx))
+
+	// the market order is filled at the last price as a taker
+	order, err := ex.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: number("0.1"),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusNew, order.Status)
+	}
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, number("20000"), trades[0].Price)
+		assert.False(t, trades[0].IsMaker)
+		assert.Equal(t, number("0.0001"), trades[0].Fee)
+		assert.Equal(t, "BTC", trades[0].FeeCurrency)
+	}
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+	}
+
+	assert.Equal(t, number("8000"), balances["USDT"].Available)
+	assert.Equal(t, number("0.0999"), balances["BTC"].Available)
+
+	// the limit orders rest on the book and lock the balances
+	sellOrder, err := ex.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Quantity: number("0.05"),
+		Price:    number("21000"),
+	})
+	assert.NoError(t, err)
+
+	buyOrder, err := ex.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: number("0.1"),
+		Price:    number("19000"),
+	})
+	assert.NoError(t, err)
+
+	openOrders, err := ex.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, openOrders, 2)
+	assert.Equal(t, number("0.05"), balances["BTC"].Locked)
+	assert.Equal(t, number("1900"), balances["USDT"].Locked)
+
+	ex.processPrice("BTCUSDT", number("20500"))
+	assert.Len(t, trades, 1)
+
+	// the sell order is filled at its price as a maker
+	ex.processPrice("BTCUSDT", number("21000"))
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, sellOrder.OrderID, trades[1].OrderID)
+		assert.True(t, trades[1].IsMaker)
+		assert.Equal(t, number("1.05"), trades[1].Fee)
+		assert.Equal(t, "USDT", trades[1].FeeCurrency)
+	}
+
+	assert.Equal(t, number("7148.95"), balances["USDT"].Available)
+	assert.Equal(t, number("0.0499"), balances["BTC"].Available)
+	assert.Equal(t, fixedpoint.Zero, balances["BTC"].Locked)
+
+	// cancel the buy order and unlock the balance
+	assert.NoError(t, ex.CancelOrders(ctx, *buyOrder))
+	assert.Equal(t, types.OrderStatusCanceled, orders[len(orders)-1].Status)
+	assert.Equal(t, number("9048.95"), balances["USDT"].Available)
+	assert.Equal(t, fixedpoint.Zero, balances["USDT"].Locked)
+	assert.Error(t, ex.CancelOrders(ctx, *buyOrder))
+
+	openOrders, err = ex.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Empty(t, openOrders)
+
+	// the limit maker order crossing the last price is rejected
+	_, err = ex.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimitMaker,
+		Quantity: number("0.01"),
+		Price:    number("21500"),
+	})
+	assert.Error(t, err)
+
+	// insufficient balance
+	_, err = ex.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: number("1"),
+	})
+	assert.Error(t, err)
+
+	account, err := ex.QueryAccount(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, balances, account.Balances())
+	}
+}
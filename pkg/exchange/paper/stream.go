@@ -0,0 +1,76 @@
+package paper
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Stream is the stream of the paper trading exchange.
+// The public stream forwards the market data of the source stream and feeds the prices to the matching engine,
+// the user data stream emits the updates of the paper account without connecting to the exchange.
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+	source   types.Stream
+}
+
+func NewStream(exchange *Exchange, source types.Stream) *Stream {
+	return &Stream{
+		StandardStream: types.NewStandardStream(),
+		exchange:       exchange,
+		source:         source,
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.StandardStream.SetPublicOnly()
+	s.source.SetPublicOnly()
+}
+
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.StandardStream.Subscribe(channel, symbol, options)
+	s.source.Subscribe(channel, symbol, options)
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if !s.PublicOnly {
+		s.exchange.bindUserDataStream(s)
+		s.EmitConnect()
+		s.EmitStart()
+		return nil
+	}
+
+	// feed the prices to the matching engine before forwarding the market data,
+	// so that the strategies see the fills of the price first
+	s.source.OnKLine(func(kline types.KLine) {
+		s.exchange.processPrice(kline.Symbol, kline.Close)
+	})
+	s.source.OnKLineClosed(func(kline types.KLine) {
+		s.exchange.processPrice(kline.Symbol, kline.Close)
+	})
+	s.source.OnMarketTrade(func(trade types.Trade) {
+		s.exchange.processPrice(trade.Symbol, trade.Price)
+	})
+
+	s.source.OnStart(s.EmitStart)
+	s.source.OnConnect(s.EmitConnect)
+	s.source.OnDisconnect(s.EmitDisconnect)
+	s.source.OnKLine(s.EmitKLine)
+	s.source.OnKLineClosed(s.EmitKLineClosed)
+	s.source.OnBookSnapshot(s.EmitBookSnapshot)
+	s.source.OnBookUpdate(s.EmitBookUpdate)
+	s.source.OnBookTickerUpdate(s.EmitBookTickerUpdate)
+	s.source.OnMarketTrade(s.EmitMarketTrade)
+	s.source.OnAggTrade(s.EmitAggTrade)
+	return s.source.Connect(ctx)
+}
+
+func (s *Stream) Close() error {
+	if s.PublicOnly {
+		return s.source.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+// Package ratelimit provides the exchange-agnostic request weight accounting.
+//
+// A Budget tracks the request weight used in the current time window of an exchange api,
+// the api clients plug into it through the Transport, which acquires the weight before sending the request,
+// and syncs the used weight and the bans from the exchange response.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Priority is the queueing priority of the request, the higher priority requests are sent first when the budget is exhausted
+type Priority int
+
+const (
+	PriorityQuery Priority = iota
+	PrioritySubmit
+	PriorityCancel
+
+	numPriorities
+)
+
+// Priorities is the list of the priorities in the ascending order
+var Priorities = []Priority{PriorityQuery, PrioritySubmit, PriorityCancel}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityQuery:
+		return "query"
+	case PrioritySubmit:
+		return "submit"
+	case PriorityCancel:
+		return "cancel"
+	}
+
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// priorityUsageRatios is the ratio of the budget limit that each priority can use,
+// the rest of the budget is reserved for the higher priority requests,
+// so that the orders can still be canceled when the queries exhaust the budget.
+var priorityUsageRatios = [numPriorities]float64{
+	PriorityQuery:  0.8,
+	PrioritySubmit: 0.9,
+	PriorityCancel: 1.0,
+}
+
+// BannedError is returned when the api is banned by the exchange, e.g., http status 429 or 418 of binance
+type BannedError struct {
+	Name  string
+	Until time.Time
+}
+
+func (e *BannedError) Error() string {
+	return fmt.Sprintf("rate limit: %s api is banned until %s", e.Name, e.Until.Format(time.RFC3339))
+}
+
+// Stats is the snapshot of the budget usage
+type Stats struct {
+	Name        string
+	Limit       int
+	Used        int
+	BannedUntil time.Time
+	Bans        uint64
+
+	Waiting   [numPriorities]int
+	Requests  [numPriorities]uint64
+	Throttled [numPriorities]uint64
+}
+
+// Budget is the request weight budget of an exchange api in a fixed time window
+type Budget struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+	bannedUntil time.Time
+	bans        uint64
+	waiting     [numPriorities]int
+	requests    [numPriorities]uint64
+	throttled   [numPriorities]uint64
+
+	// changed is closed and replaced when the waiting requests need to re-check the budget
+	changed chan struct{}
+}
+
+var budgetsMutex sync.Mutex
+var budgets []*Budget
+
+// NewBudget creates a budget of the weight limit per window, the budget is registered for the metrics
+func NewBudget(name string, limit int, window time.Duration) *Budget {
+	b := &Budget{
+		Name:    name,
+		Limit:   limit,
+		Window:  window,
+		changed: make(chan struct{}),
+	}
+
+	budgetsMutex.Lock()
+	budgets = append(budgets, b)
+	budgetsMutex.Unlock()
+	return b
+}
+
+// Budgets returns the registered budgets
+func Budgets() []*Budget {
+	budgetsMutex.Lock()
+	defer budgetsMutex.Unlock()
+	return append([]*Budget(nil), budgets...)
+}
+
+// Acquire acquires the weight from the budget, it blocks until the weight is available for the priority
+// and no higher priority request is waiting. BannedError is returned immediately when the api is banned.
+func (b *Budget) Acquire(ctx context.Context, priority Priority, weight int) error {
+	b.mu.Lock()
+	b.requests[priority]++
+
+	queued := false
+	for {
+		now := time.Now()
+		b.resetWindow(now)
+
+		if now.Before(b.bannedUntil) {
+			if queued {
+				b.dequeue(priority)
+			}
+
+			err := &BannedError{Name: b.Name, Until: b.bannedUntil}
+			b.mu.Unlock()
+			return err
+		}
+
+		if b.isAvailable(priority, weight) && !b.hasHigherPriorityWaiting(priority) {
+			if queued {
+				b.dequeue(priority)
+			}
+
+			b.used += weight
+			b.mu.Unlock()
+			return nil
+		}
+
+		if !queued {
+			queued = true
+			b.waiting[priority]++
+			b.throttled[priority]++
+		}
+
+		changed := b.changed
+		timer := time.NewTimer(b.windowStart.Add(b.Window).Sub(now))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			b.mu.Lock()
+			b.dequeue(priority)
+			b.mu.Unlock()
+			return ctx.Err()
+
+		case <-changed:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+		b.mu.Lock()
+	}
+}
+
+// SetUsed syncs the used weight of the current window reported by the exchange,
+// the weight used by the other processes of the same ip is also counted by the exchange.
+func (b *Budget) SetUsed(used int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetWindow(time.Now())
+	if used > b.used {
+		b.used = used
+	}
+}
+
+// Ban bans the requests until the given time
+func (b *Budget) Ban(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until.After(b.bannedUntil) {
+		b.bannedUntil = until
+		b.bans++
+	}
+}
+
+// Stats returns the snapshot of the budget usage
+func (b *Budget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetWindow(time.Now())
+	return Stats{
+		Name:        b.Name,
+		Limit:       b.Limit,
+		Used:        b.used,
+		BannedUntil: b.bannedUntil,
+		Bans:        b.bans,
+		Waiting:     b.waiting,
+		Requests:    b.requests,
+		Throttled:   b.throttled,
+	}
+}
+
+// windowEnd returns the end time of the current window
+func (b *Budget) windowEnd() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetWindow(time.Now())
+	return b.windowStart.Add(b.Window)
+}
+
+// resetWindow resets the used weight when the time moves to the next window, it must be called with the lock held
+func (b *Budget) resetWindow(now time.Time) {
+	start := now.Truncate(b.Window)
+	if start.After(b.windowStart) {
+		b.windowStart = start
+		b.used = 0
+	}
+}
+
+func (b *Budget) isAvailable(priority Priority, weight int) bool {
+	// a single request heavier than the usable limit is allowed when nothing is used in the window
+	if b.used == 0 {
+		return true
+	}
+
+	return float64(b.used+weight) <= float64(b.Limit)*priorityUsageRatios[priority]
+}
+
+func (b *Budget) hasHigherPriorityWaiting(priority Priority) bool {
+	for p := priority + 1; p < numPriorities; p++ {
+		if b.waiting[p] > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dequeue removes the waiting request and wakes up the other waiting requests, it must be called with the lock held
+func (b *Budget) dequeue(priority Priority) {
+	b.waiting[priority]--
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitNextWindow sleeps until the beginning of the next window, so that the test is not affected by the window reset
+func waitNextWindow(window time.Duration) {
+	now := time.Now()
+	time.Sleep(now.Truncate(window).Add(window).Sub(now))
+}
+
+func TestBudget_Acquire(t *testing.T) {
+	window := time.Hour
+	b := NewBudget("test", 10, window)
+	ctx := context.Background()
+
+	// the query requests can use 80% of the limit
+	assert.NoError(t, b.Acquire(ctx, PriorityQuery, 8))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Acquire(timeoutCtx, PriorityQuery, 1), context.DeadlineExceeded)
+
+	// the rest is reserved for the submit and cancel requests
+	assert.NoError(t, b.Acquire(ctx, PrioritySubmit, 1))
+	assert.NoError(t, b.Acquire(ctx, PriorityCancel, 1))
+
+	stats := b.Stats()
+	assert.Equal(t, 10, stats.Used)
+	assert.Equal(t, uint64(2), stats.Requests[PriorityQuery])
+	assert.Equal(t, uint64(1), stats.Throttled[PriorityQuery])
+	assert.Equal(t, 0, stats.Waiting[PriorityQuery])
+}
+
+func TestBudget_Priority(t *testing.T) {
+	window := 200 * time.Millisecond
+	b := NewBudget("test-priority", 10, window)
+	ctx := context.Background()
+
+	waitNextWindow(window)
+	b.SetUsed(10)
+
+	done := make(chan Priority, 2)
+	acquire := func(priority Priority) {
+		if err := b.Acquire(ctx, priority, 5); err == nil {
+			done <- priority
+		}
+	}
+
+	go acquire(PriorityQuery)
+	go acquire(PriorityCancel)
+
+	// the cancel request is sent first when the window is reset
+	assert.Equal(t, PriorityCancel, <-done)
+	assert.Equal(t, PriorityQuery, <-done)
+}
+
+func TestBudget_SetUsed(t *testing.T) {
+	b := NewBudget("test-set-used", 10, time.Hour)
+	b.SetUsed(5)
+	assert.Equal(t, 5, b.Stats().Used)
+
+	// the local usage is kept when the exchange reports a lower usage
+	assert.NoError(t, b.Acquire(context.Background(), PriorityQuery, 2))
+	b.SetUsed(6)
+	assert.Equal(t, 7, b.Stats().Used)
+}
+
+func TestBudget_Ban(t *testing.T) {
+	b := NewBudget("test-ban", 10, time.Hour)
+	until := time.Now().Add(time.Minute)
+	b.Ban(until)
+
+	err := b.Acquire(context.Background(), PriorityCancel, 1)
+	var bannedErr *BannedError
+	if assert.True(t, errors.As(err, &bannedErr)) {
+		assert.Equal(t, until, bannedErr.Until)
+	}
+
+	assert.Equal(t, uint64(1), b.Stats().Bans)
+	assert.Contains(t, Budgets(), b)
+}
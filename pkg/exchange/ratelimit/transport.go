@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type priorityContextKey struct{}
+
+// WithPriority returns a context that overrides the request priority detected by the transport
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, priority)
+}
+
+// PriorityFromContext returns the priority set by WithPriority
+func PriorityFromContext(ctx context.Context) (Priority, bool) {
+	priority, ok := ctx.Value(priorityContextKey{}).(Priority)
+	return priority, ok
+}
+
+// DefaultPriority detects the priority from the request:
+// the DELETE requests and the paths containing "cancel" are the cancel requests,
+// the other POST and PUT requests are the submit requests, and the rest are the query requests.
+func DefaultPriority(req *http.Request) Priority {
+	switch {
+	case req.Method == http.MethodDelete || strings.Contains(strings.ToLower(req.URL.Path), "cancel"):
+		return PriorityCancel
+	case req.Method == http.MethodPost || req.Method == http.MethodPut:
+		return PrioritySubmit
+	}
+
+	return PriorityQuery
+}
+
+// HeaderUsedWeight returns a function parsing the used weight from the response header, e.g., X-MBX-USED-WEIGHT-1M
+func HeaderUsedWeight(header string) func(resp *http.Response) (int, bool) {
+	return func(resp *http.Response) (int, bool) {
+		v := resp.Header.Get(header)
+		if len(v) == 0 {
+			return 0, false
+		}
+
+		used, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+
+		return used, true
+	}
+}
+
+// Transport is a http.RoundTripper acquiring the request weight from the budget before sending the request
+type Transport struct {
+	Base   http.RoundTripper
+	Budget *Budget
+
+	// Weight returns the weight of the request, defaults to 1
+	Weight func(req *http.Request) int
+
+	// Priority returns the priority of the request, defaults to DefaultPriority.
+	// The priority set by WithPriority in the request context takes precedence.
+	Priority func(req *http.Request) Priority
+
+	// UsedWeight returns the used weight of the current window reported in the response, optional
+	UsedWeight func(resp *http.Response) (int, bool)
+}
+
+func NewTransport(base http.RoundTripper, budget *Budget) *Transport {
+	return &Transport{
+		Base:   base,
+		Budget: budget,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	weight := 1
+	if t.Weight != nil {
+		weight = t.Weight(req)
+	}
+
+	priority, ok := PriorityFromContext(req.Context())
+	if !ok {
+		if t.Priority != nil {
+			priority = t.Priority(req)
+		} else {
+			priority = DefaultPriority(req)
+		}
+	}
+
+	if err := t.Budget.Acquire(req.Context(), priority, weight); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if t.UsedWeight != nil {
+		if used, ok := t.UsedWeight(resp); ok {
+			t.Budget.SetUsed(used)
+		}
+	}
+
+	// 429 is returned when the limit is exceeded, and 418 is returned when the ip is auto-banned for continuing to send requests after 429
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		t.Budget.Ban(t.retryAfter(resp))
+	}
+
+	return resp, nil
+}
+
+// retryAfter returns the time from the Retry-After header in seconds, or the end of the current window
+func (t *Transport) retryAfter(resp *http.Response) time.Time {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+
+	return t.Budget.windowEnd()
+}
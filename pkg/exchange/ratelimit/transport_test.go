@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPriority(t *testing.T) {
+	newRequest := func(method, path string) *http.Request {
+		req, _ := http.NewRequest(method, "https://api.example.com"+path, nil)
+		return req
+	}
+
+	assert.Equal(t, PriorityCancel, DefaultPriority(newRequest(http.MethodDelete, "/api/v3/order")))
+	assert.Equal(t, PriorityCancel, DefaultPriority(newRequest(http.MethodPost, "/v5/order/cancel")))
+	assert.Equal(t, PrioritySubmit, DefaultPriority(newRequest(http.MethodPost, "/api/v3/order")))
+	assert.Equal(t, PriorityQuery, DefaultPriority(newRequest(http.MethodGet, "/api/v3/openOrders")))
+
+	ctx := WithPriority(context.Background(), PriorityCancel)
+	priority, ok := PriorityFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, PriorityCancel, priority)
+}
+
+func TestTransport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "100")
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "30")
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	budget := NewBudget("test-transport", 1200, time.Hour)
+	client := &http.Client{
+		Transport: &Transport{
+			Budget:     budget,
+			Weight:     func(req *http.Request) int { return 10 },
+			UsedWeight: HeaderUsedWeight("X-MBX-USED-WEIGHT-1M"),
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	// the used weight reported by the exchange is synced
+	stats := budget.Stats()
+	assert.Equal(t, 100, stats.Used)
+	assert.Equal(t, uint64(1), stats.Requests[PriorityQuery])
+
+	status = http.StatusTooManyRequests
+	resp, err = client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	}
+
+	// the requests are rejected until the retry-after time
+	_, err = client.Get(server.URL)
+	var bannedErr *BannedError
+	if assert.True(t, errors.As(err, &bannedErr)) {
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), bannedErr.Until, 5*time.Second)
+	}
+}
@@ -103,6 +103,11 @@ func (inc *ATR) Length() int {
 	return inc.RMA.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *ATR) Lookback() int {
+	return exponentialLookback(inc.Window) + 1
+}
+
 func (inc *ATR) PushK(k types.KLine) {
 	if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
 		return
@@ -94,6 +94,11 @@ func (inc *ATRP) Length() int {
 	return inc.RMA.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *ATRP) Lookback() int {
+	return exponentialLookback(inc.Window) + 1
+}
+
 var _ types.SeriesExtend = &ATRP{}
 
 func (inc *ATRP) PushK(k types.KLine) {
@@ -73,6 +73,11 @@ func (inc *DEMA) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *DEMA) Lookback() int {
+	return 2 * exponentialLookback(inc.Window)
+}
+
 var _ types.SeriesExtend = &DEMA{}
 
 func (inc *DEMA) PushK(k types.KLine) {
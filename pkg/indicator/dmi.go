@@ -98,6 +98,11 @@ func (inc *DMI) Length() int {
 	return inc.ADX.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *DMI) Lookback() int {
+	return exponentialLookback(inc.Window) + exponentialLookback(inc.ADXSmoothing) + 1
+}
+
 func (inc *DMI) PushK(k types.KLine) {
 	inc.Update(k.High.Float64(), k.Low.Float64(), k.Close.Float64())
 }
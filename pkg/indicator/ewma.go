@@ -74,6 +74,11 @@ func (inc *EWMA) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *EWMA) Lookback() int {
+	return exponentialLookback(inc.Window)
+}
+
 func (inc *EWMA) PushK(k types.KLine) {
 	if inc.EndTime != zeroTime && k.EndTime.Before(inc.EndTime) {
 		return
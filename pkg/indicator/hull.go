@@ -60,6 +60,11 @@ func (inc *HULL) Length() int {
 	return inc.result.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *HULL) Lookback() int {
+	return exponentialLookback(inc.Window + int(math.Sqrt(float64(inc.Window))))
+}
+
 func (inc *HULL) PushK(k types.KLine) {
 	if inc.ma1 != nil && inc.ma1.Length() > 0 && k.EndTime.Before(inc.ma1.EndTime) {
 		return
@@ -32,3 +32,23 @@ type Simple interface {
 type KLineCalculateUpdater interface {
 	CalculateAndUpdate(allKLines []types.KLine)
 }
+
+// LookbackProvider is implemented by the indicators that declare the number of the klines required to warm up,
+// the indicators embedding types.IntervalWindow require the window size by default.
+type LookbackProvider interface {
+	Lookback() int
+}
+
+// Lookback returns the number of the klines required to warm up the indicator, 0 if the indicator doesn't declare it
+func Lookback(inc interface{}) int {
+	if p, ok := inc.(LookbackProvider); ok {
+		return p.Lookback()
+	}
+
+	return 0
+}
+
+// exponentialLookback is the lookback of the exponential smoothing, the weight of the initial value is less than 5% after 3 windows
+func exponentialLookback(window int) int {
+	return 3 * window
+}
@@ -30,6 +30,11 @@ func (inc *KlingerOscillator) Length() int {
 	return inc.Fast.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *KlingerOscillator) Lookback() int {
+	return exponentialLookback(55) + 1
+}
+
 func (inc *KlingerOscillator) Last() float64 {
 	if inc.Fast == nil || inc.Slow == nil {
 		return 0
@@ -88,6 +88,11 @@ func (inc *MACD) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *MACD) Lookback() int {
+	return exponentialLookback(inc.LongPeriod) + exponentialLookback(inc.Window)
+}
+
 func (inc *MACD) PushK(k types.KLine) {
 	inc.Update(k.Close.Float64())
 }
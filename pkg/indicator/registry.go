@@ -0,0 +1,236 @@
+package indicator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Config is the declarative config of an indicator, e.g.,
+//
+//	{"type": "boll", "interval": "1h", "window": 21, "k": 2}
+//
+// The keys other than type, interval, window and rightWindow are the indicator specific numeric parameters.
+type Config struct {
+	Type string `json:"type"`
+
+	types.IntervalWindow
+
+	Params map[string]float64 `json:"-"`
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var base struct {
+		Type string `json:"type"`
+		types.IntervalWindow
+	}
+
+	if err := json.Unmarshal(data, &base); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	c.Type = base.Type
+	c.IntervalWindow = base.IntervalWindow
+	c.Params = nil
+
+	for key, raw := range fields {
+		switch key {
+		case "type", "interval", "window", "rightWindow":
+			continue
+		}
+
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("indicator %s parameter %s should be a number, %s given", c.Type, key, raw)
+		}
+
+		if c.Params == nil {
+			c.Params = make(map[string]float64)
+		}
+		c.Params[key] = v
+	}
+
+	return nil
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{
+		"type":     c.Type,
+		"interval": c.Interval,
+		"window":   c.Window,
+	}
+
+	if c.RightWindow > 0 {
+		fields["rightWindow"] = c.RightWindow
+	}
+
+	for key, v := range c.Params {
+		fields[key] = v
+	}
+
+	return json.Marshal(fields)
+}
+
+// Float returns the parameter of the key, or the default value if it's not set
+func (c Config) Float(key string, defaultValue float64) float64 {
+	if v, ok := c.Params[key]; ok {
+		return v
+	}
+
+	return defaultValue
+}
+
+// Int returns the parameter of the key as an integer, or the default value if it's not set
+func (c Config) Int(key string, defaultValue int) int {
+	if v, ok := c.Params[key]; ok {
+		return int(v)
+	}
+
+	return defaultValue
+}
+
+// Factory creates the indicator from the config
+type Factory func(config Config) (KLinePusher, error)
+
+var factories = map[string]Factory{}
+
+// Register registers the indicator factory by the type name, the name is case-insensitive.
+func Register(name string, factory Factory) {
+	factories[strings.ToLower(name)] = factory
+}
+
+// RegisteredTypes returns the sorted names of the registered indicator types
+func RegisteredTypes() []string {
+	var names []string
+	for name := range factories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// New creates the indicator of the config type
+func New(config Config) (KLinePusher, error) {
+	factory, ok := factories[strings.ToLower(config.Type)]
+	if !ok {
+		return nil, fmt.Errorf("indicator type %q is not registered", config.Type)
+	}
+
+	if config.Interval == "" {
+		return nil, fmt.Errorf("indicator %s: interval is required", config.Type)
+	}
+
+	return factory(config)
+}
+
+// windowed wraps the factory of the indicator that requires a positive window
+func windowed(f func(config Config) KLinePusher) Factory {
+	return func(config Config) (KLinePusher, error) {
+		if config.Window <= 0 {
+			return nil, fmt.Errorf("indicator %s: window must be greater than 0", config.Type)
+		}
+
+		return f(config), nil
+	}
+}
+
+func init() {
+	Register("sma", windowed(func(c Config) KLinePusher { return &SMA{IntervalWindow: c.IntervalWindow} }))
+	Register("ewma", windowed(func(c Config) KLinePusher { return &EWMA{IntervalWindow: c.IntervalWindow} }))
+	Register("ema", windowed(func(c Config) KLinePusher { return &EWMA{IntervalWindow: c.IntervalWindow} }))
+	Register("vwma", windowed(func(c Config) KLinePusher { return &VWMA{IntervalWindow: c.IntervalWindow} }))
+	Register("dema", windowed(func(c Config) KLinePusher { return &DEMA{IntervalWindow: c.IntervalWindow} }))
+	Register("tema", windowed(func(c Config) KLinePusher { return &TEMA{IntervalWindow: c.IntervalWindow} }))
+	Register("zlema", windowed(func(c Config) KLinePusher { return &ZLEMA{IntervalWindow: c.IntervalWindow} }))
+	Register("hull", windowed(func(c Config) KLinePusher { return &HULL{IntervalWindow: c.IntervalWindow} }))
+	Register("wwma", windowed(func(c Config) KLinePusher { return &WWMA{IntervalWindow: c.IntervalWindow} }))
+	Register("tma", windowed(func(c Config) KLinePusher { return &TMA{IntervalWindow: c.IntervalWindow} }))
+	Register("gma", windowed(func(c Config) KLinePusher { return &GMA{IntervalWindow: c.IntervalWindow} }))
+	Register("rma", windowed(func(c Config) KLinePusher {
+		return &RMA{IntervalWindow: c.IntervalWindow, Adjust: c.Float("adjust", 0) != 0}
+	}))
+	Register("vidya", windowed(func(c Config) KLinePusher { return &VIDYA{IntervalWindow: c.IntervalWindow} }))
+	Register("till", windowed(func(c Config) KLinePusher {
+		return &TILL{IntervalWindow: c.IntervalWindow, VolumeFactor: c.Float("volumeFactor", defaultVolumeFactor)}
+	}))
+	Register("ssf", windowed(func(c Config) KLinePusher {
+		return &SSF{IntervalWindow: c.IntervalWindow, Poles: c.Int("poles", 2)}
+	}))
+	Register("ca", func(c Config) (KLinePusher, error) { return &CA{Interval: c.Interval}, nil })
+
+	Register("atr", windowed(func(c Config) KLinePusher { return &ATR{IntervalWindow: c.IntervalWindow} }))
+	Register("atrp", windowed(func(c Config) KLinePusher { return &ATRP{IntervalWindow: c.IntervalWindow} }))
+	Register("stddev", windowed(func(c Config) KLinePusher { return &StdDev{IntervalWindow: c.IntervalWindow} }))
+	Register("boll", windowed(func(c Config) KLinePusher {
+		return &BOLL{IntervalWindow: c.IntervalWindow, K: c.Float("k", 2.0), SMA: &SMA{IntervalWindow: c.IntervalWindow}}
+	}))
+	Register("macd", windowed(func(c Config) KLinePusher {
+		return &MACD{MACDConfig: MACDConfig{
+			IntervalWindow: c.IntervalWindow,
+			ShortPeriod:    c.Int("short", 12),
+			LongPeriod:     c.Int("long", 26),
+		}}
+	}))
+	Register("rsi", windowed(func(c Config) KLinePusher { return &RSI{IntervalWindow: c.IntervalWindow} }))
+	Register("stoch", windowed(func(c Config) KLinePusher { return &STOCH{IntervalWindow: c.IntervalWindow} }))
+	Register("cci", windowed(func(c Config) KLinePusher { return &CCI{IntervalWindow: c.IntervalWindow} }))
+	Register("emv", windowed(func(c Config) KLinePusher {
+		return &EMV{IntervalWindow: c.IntervalWindow, EMVScale: c.Float("scale", DefaultEMVScale)}
+	}))
+	Register("obv", windowed(func(c Config) KLinePusher { return &OBV{IntervalWindow: c.IntervalWindow} }))
+	Register("vwap", func(c Config) (KLinePusher, error) { return &VWAP{IntervalWindow: c.IntervalWindow}, nil })
+	Register("psar", windowed(func(c Config) KLinePusher { return &PSAR{IntervalWindow: c.IntervalWindow} }))
+	Register("dmi", windowed(func(c Config) KLinePusher {
+		return &DMI{IntervalWindow: c.IntervalWindow, ADXSmoothing: c.Int("adxSmoothing", c.Window)}
+	}))
+	Register("tsi", func(c Config) (KLinePusher, error) {
+		return &TSI{Interval: c.Interval, FastWindow: c.Int("fastWindow", 13), SlowWindow: c.Int("slowWindow", 25)}, nil
+	})
+	Register("klinger", func(c Config) (KLinePusher, error) {
+		return &KlingerOscillator{IntervalWindow: c.IntervalWindow}, nil
+	})
+	Register("drift", windowed(func(c Config) KLinePusher { return &Drift{IntervalWindow: c.IntervalWindow} }))
+	Register("wdrift", windowed(func(c Config) KLinePusher { return &WeightedDrift{IntervalWindow: c.IntervalWindow} }))
+	Register("linreg", windowed(func(c Config) KLinePusher { return &LinReg{IntervalWindow: c.IntervalWindow} }))
+	Register("pivothigh", windowed(func(c Config) KLinePusher { return &PivotHigh{IntervalWindow: c.IntervalWindow} }))
+	Register("pivotlow", windowed(func(c Config) KLinePusher { return &PivotLow{IntervalWindow: c.IntervalWindow} }))
+	Register("ghfilter", windowed(func(c Config) KLinePusher { return &GHFilter{IntervalWindow: c.IntervalWindow} }))
+	Register("kalmanfilter", windowed(func(c Config) KLinePusher {
+		return &KalmanFilter{IntervalWindow: c.IntervalWindow, AdditionalSmoothWindow: uint(c.Int("smoothWindow", 0))}
+	}))
+
+	Register("supertrend", windowed(func(c Config) KLinePusher {
+		return &Supertrend{
+			IntervalWindow:   c.IntervalWindow,
+			ATRMultiplier:    c.Float("atrMultiplier", 3.0),
+			AverageTrueRange: &ATR{IntervalWindow: c.IntervalWindow},
+		}
+	}))
+	Register("pivotsupertrend", windowed(func(c Config) KLinePusher {
+		pivotWindow := types.IntervalWindow{Interval: c.Interval, Window: c.Int("pivotWindow", 10)}
+		return &PivotSupertrend{
+			IntervalWindow:   c.IntervalWindow,
+			ATRMultiplier:    c.Float("atrMultiplier", 3.0),
+			PivotWindow:      pivotWindow.Window,
+			AverageTrueRange: &ATR{IntervalWindow: c.IntervalWindow},
+			PivotLow:         &PivotLow{IntervalWindow: pivotWindow},
+			PivotHigh:        &PivotHigh{IntervalWindow: pivotWindow},
+		}
+	}))
+	Register("utbotalert", windowed(func(c Config) KLinePusher {
+		return &UtBotAlert{
+			IntervalWindow:   c.IntervalWindow,
+			KeyValue:         c.Float("keyValue", 1.0),
+			AverageTrueRange: &ATR{IntervalWindow: c.IntervalWindow},
+		}
+	}))
+}
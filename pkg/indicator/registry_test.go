@@ -0,0 +1,93 @@
+package indicator
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestConfig_UnmarshalJSON(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{"type": "boll", "interval": "1h", "window": 21, "k": 2.5}`), &config)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "boll", config.Type)
+		assert.Equal(t, types.Interval1h, config.Interval)
+		assert.Equal(t, 21, config.Window)
+		assert.Equal(t, 2.5, config.Float("k", 2.0))
+		assert.Equal(t, 12, config.Int("short", 12))
+	}
+
+	data, err := json.Marshal(config)
+	if assert.NoError(t, err) {
+		var config2 Config
+		assert.NoError(t, json.Unmarshal(data, &config2))
+		assert.Equal(t, config, config2)
+	}
+
+	err = json.Unmarshal([]byte(`{"type": "pivotlow", "interval": "1h", "window": 10, "rightWindow": 5}`), &config)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, config.RightWindow)
+		assert.Empty(t, config.Params)
+	}
+
+	err = json.Unmarshal([]byte(`{"type": "boll", "interval": "1h", "window": 21, "k": "x"}`), &config)
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	inc, err := New(Config{Type: "EMA", IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 20}})
+	if assert.NoError(t, err) {
+		assert.IsType(t, &EWMA{}, inc)
+	}
+
+	inc, err = New(Config{Type: "boll", IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 20}, Params: map[string]float64{"k": 3}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3.0, inc.(*BOLL).K)
+	}
+
+	_, err = New(Config{Type: "sma", IntervalWindow: types.IntervalWindow{Interval: types.Interval5m}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Type: "sma", IntervalWindow: types.IntervalWindow{Window: 5}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Type: "unknown", IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 5}})
+	assert.Error(t, err)
+}
+
+func TestNew_RegisteredTypes(t *testing.T) {
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var kLines []types.KLine
+	for i := 0; i < 100; i++ {
+		price := 100 + 10*math.Sin(float64(i)/5)
+		kLines = append(kLines, types.KLine{
+			Interval:  types.Interval1m,
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+			EndTime:   types.Time(startTime.Add(time.Duration(i+1)*time.Minute - time.Millisecond)),
+			Open:      fixedpoint.NewFromFloat(price - 1),
+			High:      fixedpoint.NewFromFloat(price + 2),
+			Low:       fixedpoint.NewFromFloat(price - 2),
+			Close:     fixedpoint.NewFromFloat(price),
+			Volume:    fixedpoint.NewFromFloat(10 + float64(i%7)),
+		})
+	}
+
+	for _, name := range RegisteredTypes() {
+		inc, err := New(Config{Type: name, IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 14}})
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+
+		assert.NotPanics(t, func() {
+			for _, k := range kLines {
+				inc.PushK(k)
+			}
+		}, name)
+	}
+}
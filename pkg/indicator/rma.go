@@ -94,6 +94,11 @@ func (inc *RMA) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *RMA) Lookback() int {
+	return exponentialLookback(inc.Window)
+}
+
 var _ types.SeriesExtend = &RMA{}
 
 func (inc *RMA) PushK(k types.KLine) {
@@ -66,6 +66,11 @@ func (inc *Supertrend) Length() int {
 	return len(inc.trendPrices)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *Supertrend) Lookback() int {
+	return exponentialLookback(inc.Window) + 1
+}
+
 func (inc *Supertrend) Update(highPrice, lowPrice, closePrice float64) {
 	if inc.Window <= 0 {
 		panic("window must be greater than 0")
@@ -62,6 +62,11 @@ func (inc *TEMA) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *TEMA) Lookback() int {
+	return 3 * exponentialLookback(inc.Window)
+}
+
 var _ types.SeriesExtend = &TEMA{}
 
 func (inc *TEMA) PushK(k types.KLine) {
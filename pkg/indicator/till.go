@@ -93,6 +93,11 @@ func (inc *TILL) Length() int {
 	return inc.e1.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *TILL) Lookback() int {
+	return 6 * exponentialLookback(inc.Window)
+}
+
 var _ types.Series = &TILL{}
 
 func (inc *TILL) PushK(k types.KLine) {
@@ -80,6 +80,11 @@ func (inc *TSI) Length() int {
 	return inc.Values.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *TSI) Lookback() int {
+	return exponentialLookback(inc.SlowWindow) + exponentialLookback(inc.FastWindow) + 1
+}
+
 func (inc *TSI) Last() float64 {
 	return inc.Values.Last()
 }
@@ -62,6 +62,11 @@ func (inc *UtBotAlert) Length() int {
 	return len(inc.Values)
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *UtBotAlert) Lookback() int {
+	return exponentialLookback(inc.Window) + 1
+}
+
 func (inc *UtBotAlert) Update(highPrice, lowPrice, closePrice float64) {
 	if inc.Window <= 0 {
 		panic("window must be greater than 0")
@@ -47,6 +47,11 @@ func (inc *ZLEMA) Length() int {
 	return inc.zlema.Length()
 }
 
+// Lookback returns the number of the klines required to warm up the indicator
+func (inc *ZLEMA) Lookback() int {
+	return exponentialLookback(inc.Window) + (inc.Window-1)/2
+}
+
 func (inc *ZLEMA) Update(value float64) {
 	if inc.lag == 0 || inc.zlema == nil {
 		inc.SeriesBase.Series = inc
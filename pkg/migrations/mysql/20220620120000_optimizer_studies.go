@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upOptimizerStudies, downOptimizerStudies)
+
+}
+
+func upOptimizerStudies(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `optimizer_studies`\n(\n    `gid`        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `name`       VARCHAR(128)    NOT NULL,\n    -- config is the optimizer config in JSON\n    `config`     TEXT            NOT NULL,\n    `created_at` DATETIME        NOT NULL,\n    `updated_at` DATETIME        NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `name` (`name`)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `optimizer_trials`\n(\n    `gid`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `study_id`        BIGINT UNSIGNED NOT NULL,\n    -- number is the trial number in the study\n    `number`          INT UNSIGNED    NOT NULL,\n    `state`           VARCHAR(16)     NOT NULL,\n    `value`           DOUBLE          NOT NULL DEFAULT 0,\n    -- the params, internal_params, distributions and user_attrs fields are the trial fields in JSON\n    `params`          TEXT            NOT NULL,\n    `internal_params` TEXT            NOT NULL,\n    `distributions`   TEXT            NOT NULL,\n    `user_attrs`      TEXT            NOT NULL,\n    `started_at`      DATETIME        NOT NULL,\n    `completed_at`    DATETIME        NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `study_number` (`study_id`, `number`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downOptimizerStudies(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `optimizer_trials`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `optimizer_studies`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
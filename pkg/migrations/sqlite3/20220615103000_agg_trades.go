@@ -0,0 +1,44 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAggTrades, downAggTrades)
+
+}
+
+func upAggTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `agg_trades`\n(\n    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,\n    -- id is the aggregate trade id\n    `id`             INTEGER        NOT NULL,\n    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',\n    `symbol`         VARCHAR(20)    NOT NULL,\n    `price`          DECIMAL(16, 8) NOT NULL,\n    `quantity`       DECIMAL(16, 8) NOT NULL,\n    `quote_quantity` DECIMAL(16, 8) NOT NULL,\n    -- side is the taker side of the trade\n    `side`           VARCHAR(4)     NOT NULL DEFAULT '',\n    `is_buyer`       BOOLEAN        NOT NULL DEFAULT FALSE,\n    `is_maker`       BOOLEAN        NOT NULL DEFAULT FALSE,\n    `traded_at`      DATETIME(3)    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX `agg_trades_unique_id` ON `agg_trades` (`exchange`, `symbol`, `id`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `agg_trades_traded_at` ON `agg_trades` (`traded_at`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAggTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `agg_trades`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,54 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upOptimizerStudies, downOptimizerStudies)
+
+}
+
+func upOptimizerStudies(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `optimizer_studies`\n(\n    `gid`        INTEGER PRIMARY KEY AUTOINCREMENT,\n    `name`       VARCHAR(128) NOT NULL,\n    -- config is the optimizer config in JSON\n    `config`     TEXT         NOT NULL,\n    `created_at` DATETIME     NOT NULL,\n    `updated_at` DATETIME     NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX `optimizer_studies_name` ON `optimizer_studies` (`name`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `optimizer_trials`\n(\n    `gid`             INTEGER PRIMARY KEY AUTOINCREMENT,\n    `study_id`        INTEGER     NOT NULL,\n    -- number is the trial number in the study\n    `number`          INTEGER     NOT NULL,\n    `state`           VARCHAR(16) NOT NULL,\n    `value`           REAL        NOT NULL DEFAULT 0,\n    -- the params, internal_params, distributions and user_attrs fields are the trial fields in JSON\n    `params`          TEXT        NOT NULL,\n    `internal_params` TEXT        NOT NULL,\n    `distributions`   TEXT        NOT NULL,\n    `user_attrs`      TEXT        NOT NULL,\n    `started_at`      DATETIME    NOT NULL,\n    `completed_at`    DATETIME    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX `optimizer_trials_study_number` ON `optimizer_trials` (`study_id`, `number`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downOptimizerStudies(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `optimizer_trials`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `optimizer_studies`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
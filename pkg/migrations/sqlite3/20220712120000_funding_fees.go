@@ -0,0 +1,34 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upFundingFees, downFundingFees)
+
+}
+
+func upFundingFees(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `funding_fees`\n(\n    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,\n    `transaction_id` INTEGER        NOT NULL,\n    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',\n    `symbol`         VARCHAR(32)    NOT NULL DEFAULT '',\n    `asset`          VARCHAR(24)    NOT NULL DEFAULT '',\n    -- amount is positive for the received funding fee and negative for the paid funding fee\n    `amount`         DECIMAL(20, 8) NOT NULL,\n    `time`           DATETIME(3)    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downFundingFees(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `funding_fees`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
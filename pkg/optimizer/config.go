@@ -1,13 +1,16 @@
 package optimizer
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 const (
@@ -29,13 +32,66 @@ type SelectorConfig struct {
 	Step   fixedpoint.Value `json:"step,omitempty" yaml:"step,omitempty"`
 }
 
+const (
+	objectiveDirectionMaximize = "maximize"
+	objectiveDirectionMinimize = "minimize"
+)
+
+// ObjectiveConfig is one of the weighted objectives, the trial value is the weighted sum of the objective metrics,
+// and the metrics of the minimized objectives are subtracted.
+type ObjectiveConfig struct {
+	By        string           `json:"by" yaml:"by"`
+	Weight    fixedpoint.Value `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Direction string           `json:"direction,omitempty" yaml:"direction,omitempty"`
+}
+
+// ConstraintConfig limits the metric value of the trials, the trials violating the constraint are pruned
+type ConstraintConfig struct {
+	By  string            `json:"by" yaml:"by"`
+	Min *fixedpoint.Value `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *fixedpoint.Value `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
 type LocalExecutorConfig struct {
 	MaxNumberOfProcesses int `json:"maxNumberOfProcesses" yaml:"maxNumberOfProcesses"`
 }
 
+// RemoteExecutorConfig is the config of the coordinator, the trials are pulled and executed by the remote workers
+// started with the optimize-worker command.
+type RemoteExecutorConfig struct {
+	// Listen is the bind address of the coordinator http server
+	Listen string `json:"listen" yaml:"listen"`
+
+	// Token is the optional shared token, the workers send it in the Authorization header
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+
+	// MaxNumberOfTrials is the max number of the concurrent trials of the hyperparameter optimizer
+	MaxNumberOfTrials int `json:"maxNumberOfTrials" yaml:"maxNumberOfTrials"`
+
+	// MaxRetries is the max number of retries of the failed or timed out trials
+	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+
+	// TaskTimeout re-queues the trial when the worker doesn't return the result in time
+	TaskTimeout types.Duration `json:"taskTimeout" yaml:"taskTimeout"`
+
+	// StateFile records the completed trial results, so that the results of the same trial configs are reused
+	// when the study is restarted
+	StateFile string `json:"stateFile,omitempty" yaml:"stateFile,omitempty"`
+}
+
 type ExecutorConfig struct {
-	Type                string               `json:"type" yaml:"type"`
-	LocalExecutorConfig *LocalExecutorConfig `json:"local" yaml:"local"`
+	Type                 string                `json:"type" yaml:"type"`
+	LocalExecutorConfig  *LocalExecutorConfig  `json:"local" yaml:"local"`
+	RemoteExecutorConfig *RemoteExecutorConfig `json:"remote,omitempty" yaml:"remote,omitempty"`
+}
+
+// NumOfProcesses returns the max number of the concurrent backtests of the executor
+func (c *ExecutorConfig) NumOfProcesses() int {
+	if c.Type == ExecutorTypeRemote && c.RemoteExecutorConfig != nil {
+		return c.RemoteExecutorConfig.MaxNumberOfTrials
+	}
+
+	return c.LocalExecutorConfig.MaxNumberOfProcesses
 }
 
 type Config struct {
@@ -45,10 +101,20 @@ type Config struct {
 	Algorithm     string           `yaml:"algorithm,omitempty"`
 	Objective     string           `yaml:"objectiveBy,omitempty"`
 	MaxEvaluation int              `yaml:"maxEvaluation"`
+
+	// Objectives optimizes the weighted sum of multiple objectives instead of the single objectiveBy metric,
+	// the Pareto-optimal trials of the objectives are reported.
+	Objectives []ObjectiveConfig `yaml:"objectives,omitempty"`
+
+	// Constraints limits the metric values of the trials, e.g. the max drawdown ratio
+	Constraints []ConstraintConfig `yaml:"constraints,omitempty"`
+
+	// WalkForward enables the walk-forward mode, the backtest time range is split into the in-sample and out-of-sample windows
+	WalkForward *WalkForwardConfig `yaml:"walkForward,omitempty"`
 }
 
 var defaultExecutorConfig = &ExecutorConfig{
-	Type:                "local",
+	Type:                ExecutorTypeLocal,
 	LocalExecutorConfig: defaultLocalExecutorConfig,
 }
 
@@ -56,6 +122,11 @@ var defaultLocalExecutorConfig = &LocalExecutorConfig{
 	MaxNumberOfProcesses: 10,
 }
 
+const (
+	ExecutorTypeLocal  = "local"
+	ExecutorTypeRemote = "remote"
+)
+
 func LoadConfig(yamlConfigFileName string) (*Config, error) {
 	configYaml, err := ioutil.ReadFile(yamlConfigFileName)
 	if err != nil {
@@ -76,29 +147,80 @@ func LoadConfig(yamlConfigFileName string) (*Config, error) {
 		return nil, fmt.Errorf(`unknown algorithm "%s"`, optConfig.Algorithm)
 	}
 
-	switch objective := strings.ToLower(optConfig.Objective); objective {
-	case "", "default":
-		optConfig.Objective = HpOptimizerObjectiveEquity
-	case HpOptimizerObjectiveEquity, HpOptimizerObjectiveProfit, HpOptimizerObjectiveVolume, HpOptimizerObjectiveProfitFactor:
-		optConfig.Objective = objective
-	default:
-		return nil, fmt.Errorf(`unknown objective "%s"`, optConfig.Objective)
+	if len(optConfig.Objectives) > 0 {
+		if len(optConfig.Objective) > 0 {
+			return nil, errors.New("objectiveBy and objectives can not be used together")
+		}
+
+		for i := range optConfig.Objectives {
+			if err := optConfig.Objectives[i].normalize(); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		switch objective := strings.ToLower(optConfig.Objective); objective {
+		case "", "default":
+			optConfig.Objective = HpOptimizerObjectiveEquity
+		default:
+			if _, ok := metricValueFuncs[objective]; !ok {
+				return nil, fmt.Errorf(`unknown objective "%s"`, optConfig.Objective)
+			}
+			optConfig.Objective = objective
+		}
+	}
+
+	for i := range optConfig.Constraints {
+		if err := optConfig.Constraints[i].normalize(); err != nil {
+			return nil, err
+		}
 	}
 
 	if optConfig.MaxEvaluation <= 0 {
 		optConfig.MaxEvaluation = 100
 	}
 
+	if wf := optConfig.WalkForward; wf != nil && (wf.InSample.Duration() <= 0 || wf.OutOfSample.Duration() <= 0) {
+		return nil, errors.New("walkForward.inSample and walkForward.outOfSample are required for the walk-forward mode")
+	}
+
 	if optConfig.Executor == nil {
 		optConfig.Executor = defaultExecutorConfig
 	}
 
 	if optConfig.Executor.Type == "" {
-		optConfig.Executor.Type = "local"
+		optConfig.Executor.Type = ExecutorTypeLocal
 	}
 
-	if optConfig.Executor.Type == "local" && optConfig.Executor.LocalExecutorConfig == nil {
-		optConfig.Executor.LocalExecutorConfig = defaultLocalExecutorConfig
+	switch optConfig.Executor.Type {
+	case ExecutorTypeLocal:
+		if optConfig.Executor.LocalExecutorConfig == nil {
+			optConfig.Executor.LocalExecutorConfig = defaultLocalExecutorConfig
+		}
+
+	case ExecutorTypeRemote:
+		if optConfig.Executor.RemoteExecutorConfig == nil {
+			optConfig.Executor.RemoteExecutorConfig = &RemoteExecutorConfig{}
+		}
+
+		remoteConfig := optConfig.Executor.RemoteExecutorConfig
+		if remoteConfig.Listen == "" {
+			remoteConfig.Listen = ":9091"
+		}
+
+		if remoteConfig.MaxNumberOfTrials <= 0 {
+			remoteConfig.MaxNumberOfTrials = 10
+		}
+
+		if remoteConfig.MaxRetries < 0 {
+			remoteConfig.MaxRetries = 0
+		}
+
+		if remoteConfig.TaskTimeout <= 0 {
+			remoteConfig.TaskTimeout = types.Duration(time.Hour)
+		}
+
+	default:
+		return nil, fmt.Errorf(`unknown executor type "%s"`, optConfig.Executor.Type)
 	}
 
 	return &optConfig, nil
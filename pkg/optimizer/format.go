@@ -2,27 +2,85 @@ package optimizer
 
 import (
 	"fmt"
-	"github.com/c9s/bbgo/pkg/data/tsv"
-	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"io"
+	"sort"
 	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/data/tsv"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
+// FormatResultsTsv writes the trial results in the tsv format, the parameter columns are sorted by the label.
+// For the walk-forward results, each row is a walk-forward window with the in-sample value and the out-of-sample result,
+// so that the parameter stability among the windows can be inspected.
 func FormatResultsTsv(writer io.WriteCloser, labelPaths map[string]string, results []*HyperparameterOptimizeTrialResult) error {
-	headerLen := len(labelPaths)
-	headers := make([]string, 0, headerLen)
+	labels := make([]string, 0, len(labelPaths))
 	for label := range labelPaths {
-		headers = append(headers, label)
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	isWalkForward := false
+	metricKeySet := make(map[string]struct{})
+	for _, result := range results {
+		if result.WalkForward != nil {
+			isWalkForward = true
+		}
+
+		for key := range result.Metrics {
+			metricKeySet[key] = struct{}{}
+		}
+	}
+
+	metricKeys := make([]string, 0, len(metricKeySet))
+	for key := range metricKeySet {
+		metricKeys = append(metricKeys, key)
 	}
+	sort.Strings(metricKeys)
 
-	rows := make([][]interface{}, len(labelPaths))
+	var headers []string
+	if isWalkForward {
+		headers = append(headers, "inSampleStartTime", "outOfSampleStartTime", "outOfSampleEndTime")
+	}
+	headers = append(headers, labels...)
+	headers = append(headers, "value")
+	headers = append(headers, metricKeys...)
+	if isWalkForward {
+		headers = append(headers, "outOfSampleValue", "outOfSampleProfit")
+	}
+
+	rows := make([][]interface{}, len(results))
 	for ri, result := range results {
-		row := make([]interface{}, headerLen)
-		for ci, columnKey := range headers {
-			var ok bool
-			if row[ci], ok = result.Parameters[columnKey]; !ok {
+		var row []interface{}
+		if isWalkForward {
+			wf := result.WalkForward
+			if wf == nil {
+				return fmt.Errorf("missing walk-forward result from trial result (%v)", result.Parameters)
+			}
+			row = append(row, wf.InSampleStartTime, wf.OutOfSampleStartTime, wf.OutOfSampleEndTime)
+		}
+
+		for _, columnKey := range labels {
+			param, ok := result.Parameters[columnKey]
+			if !ok {
 				return fmt.Errorf(`missing parameter "%s" from trial result (%v)`, columnKey, result.Parameters)
 			}
+			row = append(row, param)
+		}
+
+		row = append(row, result.Value)
+		for _, key := range metricKeys {
+			// the failed trials have no metric values
+			if metric, ok := result.Metrics[key]; ok {
+				row = append(row, metric)
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		if isWalkForward {
+			row = append(row, result.WalkForward.OutOfSampleValue, result.WalkForward.OutOfSampleProfit)
 		}
 		rows[ri] = row
 	}
@@ -136,6 +194,8 @@ func castCellValue(a interface{}) (string, error) {
 		return tv, nil
 	case []byte:
 		return string(tv), nil
+	case time.Time:
+		return tv.Format(time.RFC3339), nil
 	default:
 		return "", fmt.Errorf("unsupported object type: %T value: %v", tv, tv)
 	}
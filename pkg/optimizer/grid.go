@@ -53,6 +53,20 @@ var ProfitFactorMetricValueFunc = func(summaryReport *backtest.SummaryReport) fl
 	return pf*0.9 + win*0.1
 }
 
+var MaxDrawdownMetricValueFunc = func(summaryReport *backtest.SummaryReport) float64 {
+	return summaryReport.MaxDrawdown.Float64()
+}
+
+var NumOfTradesMetricValueFunc = func(summaryReport *backtest.SummaryReport) float64 {
+	var numOfTrades int
+	for _, report := range summaryReport.SymbolReports {
+		if report.PnL != nil {
+			numOfTrades += report.PnL.NumTrades
+		}
+	}
+	return float64(numOfTrades)
+}
+
 type Metric struct {
 	// Labels is the labels of the given parameters
 	Labels []string `json:"labels,omitempty"`
@@ -213,6 +227,8 @@ func (o *GridOptimizer) Run(executor Executor, configJson []byte) (map[string][]
 		"totalVolume":     TotalVolume,
 		"totalEquityDiff": TotalEquityDiff,
 		"profitFactor":    ProfitFactorMetricValueFunc,
+		"maxDrawdown":     MaxDrawdownMetricValueFunc,
+		"numOfTrades":     NumOfTradesMetricValueFunc,
 	}
 	var metrics = map[string][]Metric{}
 
@@ -10,7 +10,9 @@ import (
 	goptunaCMAES "github.com/c-bata/goptuna/cmaes"
 	goptunaSOBOL "github.com/c-bata/goptuna/sobol"
 	goptunaTPE "github.com/c-bata/goptuna/tpe"
+	"github.com/c9s/bbgo/pkg/backtest"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
 	"github.com/cheggaaa/pb/v3"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -26,8 +28,21 @@ const (
 	HpOptimizerObjectiveVolume = "volume"
 	// HpOptimizerObjectiveProfitFactor optimize the parameters to maximize profit factor
 	HpOptimizerObjectiveProfitFactor = "profitfactor"
+	// HpOptimizerObjectiveMaxDrawdown optimize the parameters to minimize the max drawdown ratio
+	HpOptimizerObjectiveMaxDrawdown = "maxdrawdown"
+	// HpOptimizerObjectiveTrades optimize the parameters by the number of trades
+	HpOptimizerObjectiveTrades = "trades"
 )
 
+var metricValueFuncs = map[string]MetricValueFunc{
+	HpOptimizerObjectiveEquity:       TotalEquityDiff,
+	HpOptimizerObjectiveProfit:       TotalProfitMetricValueFunc,
+	HpOptimizerObjectiveVolume:       TotalVolume,
+	HpOptimizerObjectiveProfitFactor: ProfitFactorMetricValueFunc,
+	HpOptimizerObjectiveMaxDrawdown:  MaxDrawdownMetricValueFunc,
+	HpOptimizerObjectiveTrades:       NumOfTradesMetricValueFunc,
+}
+
 const (
 	// HpOptimizerAlgorithmTPE is the implementation of Tree-structured Parzen Estimators
 	HpOptimizerAlgorithmTPE = "tpe"
@@ -44,22 +59,41 @@ type HyperparameterOptimizeTrialResult struct {
 	Parameters map[string]interface{} `json:"parameters"`
 	ID         *int                   `json:"id,omitempty"`
 	State      string                 `json:"state,omitempty"`
+
+	// Metrics are the objective and constraint metric values of the trial
+	Metrics map[string]fixedpoint.Value `json:"metrics,omitempty"`
+
+	// WalkForward is the out-of-sample result of the walk-forward window, the value is the best in-sample objective value
+	WalkForward *WalkForwardResult `json:"walkForward,omitempty"`
 }
 
 type HyperparameterOptimizeReport struct {
 	Name       string                               `json:"studyName"`
 	Objective  string                               `json:"objective"`
+	Objectives []ObjectiveConfig                    `json:"objectives,omitempty"`
 	Parameters map[string]string                    `json:"domains"`
 	Best       *HyperparameterOptimizeTrialResult   `json:"best"`
 	Trials     []*HyperparameterOptimizeTrialResult `json:"trials,omitempty"`
+
+	// ParetoFront is the Pareto-optimal trial set of the multiple objectives
+	ParetoFront []*HyperparameterOptimizeTrialResult `json:"paretoFront,omitempty"`
+
+	// the walk-forward analysis fields, the trials are the best trials of the walk-forward windows
+	OutOfSampleProfit  *fixedpoint.Value           `json:"outOfSampleProfit,omitempty"`
+	ParameterStability map[string]fixedpoint.Value `json:"parameterStability,omitempty"`
 }
 
 func buildBestHyperparameterOptimizeResult(study *goptuna.Study) *HyperparameterOptimizeTrialResult {
-	val, _ := study.GetBestValue()
-	params, _ := study.GetBestParams()
+	trial, err := study.Storage.GetBestTrial(study.ID)
+	if err != nil {
+		log.WithError(err).Warn("no completed trial, all the trials are failed or violating the constraints")
+		return &HyperparameterOptimizeTrialResult{}
+	}
+
 	return &HyperparameterOptimizeTrialResult{
-		Value:      fixedpoint.NewFromFloat(val),
-		Parameters: params,
+		Value:      fixedpoint.NewFromFloat(trial.Value),
+		Parameters: trial.Params,
+		Metrics:    parseMetrics(trial.UserAttrs),
 	}
 }
 
@@ -72,6 +106,8 @@ func buildHyperparameterOptimizeTrialResults(study *goptuna.Study) []*Hyperparam
 			ID:         &trialId,
 			Value:      fixedpoint.NewFromFloat(trial.Value),
 			Parameters: trial.Params,
+			State:      trial.State.String(),
+			Metrics:    parseMetrics(trial.UserAttrs),
 		}
 		results[i] = trialResult
 	}
@@ -82,6 +118,12 @@ type HyperparameterOptimizer struct {
 	SessionName string
 	Config      *Config
 
+	// StudyService persists the study and the finished trials when it's set, the study is named by the session name
+	StudyService *service.OptimizerStudyService
+
+	// Resume continues the persisted study, the finished trials are counted in the max evaluation
+	Resume bool
+
 	// Workaround for goptuna/tpe parameter suggestion. Remove this after fixed.
 	// ref: https://github.com/c-bata/goptuna/issues/236
 	paramSuggestionLock sync.Mutex
@@ -193,19 +235,14 @@ func (o *HyperparameterOptimizer) buildParamDomains() (map[string]string, []para
 	return labelPaths, domains
 }
 
-func (o *HyperparameterOptimizer) buildObjective(executor Executor, configJson []byte, paramDomains []paramDomain) goptuna.FuncObjective {
-	var metricValueFunc MetricValueFunc
-	switch o.Config.Objective {
-	case HpOptimizerObjectiveProfit:
-		metricValueFunc = TotalProfitMetricValueFunc
-	case HpOptimizerObjectiveVolume:
-		metricValueFunc = TotalVolume
-	case HpOptimizerObjectiveEquity:
-		metricValueFunc = TotalEquityDiff
-	case HpOptimizerObjectiveProfitFactor:
-		metricValueFunc = ProfitFactorMetricValueFunc
+// metricValueFunc returns the weighted objective score of the summary report
+func (o *HyperparameterOptimizer) metricValueFunc() MetricValueFunc {
+	return func(summaryReport *backtest.SummaryReport) float64 {
+		return o.Config.score(o.Config.evaluateMetrics(summaryReport))
 	}
+}
 
+func (o *HyperparameterOptimizer) buildObjective(executor Executor, configJson []byte, paramDomains []paramDomain) goptuna.FuncObjective {
 	return func(trial goptuna.Trial) (float64, error) {
 		trialConfig, err := func(trialConfig []byte) ([]byte, error) {
 			o.paramSuggestionLock.Lock()
@@ -230,8 +267,22 @@ func (o *HyperparameterOptimizer) buildObjective(executor Executor, configJson [
 		if err != nil {
 			return 0.0, err
 		}
+
+		metrics := o.Config.evaluateMetrics(summary)
+		for key, value := range metrics {
+			if err := trial.SetUserAttr(key, formatMetricValue(value)); err != nil {
+				return 0.0, err
+			}
+		}
+
+		// the trials violating the constraints are pruned, so they are excluded from the best trial and the Pareto front
+		if err := o.Config.checkConstraints(metrics); err != nil {
+			log.Debugf("trial #%d is pruned: %v", trial.ID, err)
+			return 0.0, goptuna.ErrTrialPruned
+		}
+
 		// By config, the Goptuna optimize the parameters by maximize the objective output.
-		return metricValueFunc(summary), nil
+		return o.Config.score(metrics), nil
 	}
 }
 
@@ -239,8 +290,28 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 	labelPaths, paramDomains := o.buildParamDomains()
 	objective := o.buildObjective(executor, configJson, paramDomains)
 
-	maxEvaluation := o.Config.MaxEvaluation
-	numOfProcesses := o.Config.Executor.LocalExecutorConfig.MaxNumberOfProcesses
+	trialFinishChan := make(chan goptuna.FrozenTrial, 128)
+	study, err := o.buildStudy(trialFinishChan)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorder *studyRecorder
+	var numOfFinishedTrials int
+	if o.StudyService != nil {
+		recorder, numOfFinishedTrials, err = o.openStudy(ctx, study)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxEvaluation := o.Config.MaxEvaluation - numOfFinishedTrials
+	if maxEvaluation <= 0 {
+		log.Infof("study %s has finished %d trials, no more trial to evaluate", o.SessionName, numOfFinishedTrials)
+		return o.buildReport(study, labelPaths), nil
+	}
+
+	numOfProcesses := o.Config.Executor.NumOfProcesses()
 	if numOfProcesses > maxEvaluation {
 		numOfProcesses = maxEvaluation
 	}
@@ -249,7 +320,6 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 		maxEvaluationPerProcess++
 	}
 
-	trialFinishChan := make(chan goptuna.FrozenTrial, 128)
 	allTrailFinishChan := make(chan struct{})
 	bar := pb.Full.Start(maxEvaluation)
 	bar.SetTemplateString(`{{ string . "log" | green}} | {{counters . }} {{bar . }} {{percent . }} {{etime . }} {{rtime . "ETA %s"}}`)
@@ -257,23 +327,29 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 	go func() {
 		defer close(allTrailFinishChan)
 		var bestVal = math.Inf(-1)
+		if best, err := study.Storage.GetBestTrial(study.ID); err == nil {
+			bestVal = best.Value
+		}
+
 		for result := range trialFinishChan {
 			log.WithFields(logrus.Fields{"ID": result.ID, "evaluation": result.Value, "state": result.State}).Debug("trial finished")
 			if result.State == goptuna.TrialStateFail {
 				log.WithFields(result.Params).Errorf("failed at trial #%d", result.ID)
 			}
-			if result.Value > bestVal {
+			if result.State == goptuna.TrialStateComplete && result.Value > bestVal {
 				bestVal = result.Value
 			}
+			// the trials interrupted by the cancellation are evaluated again when resuming
+			if recorder != nil && !(result.State == goptuna.TrialStateFail && ctx.Err() != nil) {
+				if err := recorder.record(result); err != nil {
+					log.WithError(err).Errorf("unable to persist trial #%d", result.ID)
+				}
+			}
 			bar.Set("log", fmt.Sprintf("best value: %v", bestVal))
 			bar.Increment()
 		}
 	}()
 
-	study, err := o.buildStudy(trialFinishChan)
-	if err != nil {
-		return nil, err
-	}
 	eg, studyCtx := errgroup.WithContext(ctx)
 	study.WithContext(studyCtx)
 	for i := 0; i < numOfProcesses; i++ {
@@ -293,11 +369,22 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 	<-allTrailFinishChan
 	bar.Finish()
 
-	return &HyperparameterOptimizeReport{
+	return o.buildReport(study, labelPaths), nil
+}
+
+func (o *HyperparameterOptimizer) buildReport(study *goptuna.Study, labelPaths map[string]string) *HyperparameterOptimizeReport {
+	report := &HyperparameterOptimizeReport{
 		Name:       o.SessionName,
 		Objective:  o.Config.Objective,
+		Objectives: o.Config.Objectives,
 		Parameters: labelPaths,
 		Best:       buildBestHyperparameterOptimizeResult(study),
 		Trials:     buildHyperparameterOptimizeTrialResults(study),
-	}, nil
+	}
+
+	if len(o.Config.Objectives) > 1 {
+		report.ParetoFront = buildParetoFront(o.Config.Objectives, report.Trials)
+	}
+
+	return report
 }
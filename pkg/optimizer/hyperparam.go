@@ -8,6 +8,11 @@ import (
 
 type paramDomain interface {
 	buildPatch(trail *goptuna.Trial) (jsonpatch.Patch, error)
+
+	// buildValuePatch builds the patch with the given parameter value, it's used for applying the best parameters
+	buildValuePatch(val interface{}) (jsonpatch.Patch, error)
+
+	getLabel() string
 }
 
 type paramDomainBase struct {
@@ -15,6 +20,15 @@ type paramDomainBase struct {
 	path  string
 }
 
+func (d *paramDomainBase) getLabel() string {
+	return d.label
+}
+
+func (d *paramDomainBase) buildValuePatch(val interface{}) (jsonpatch.Patch, error) {
+	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %v }]`, d.path, val)))
+	return jsonpatch.DecodePatch(jsonOp)
+}
+
 type intRangeDomain struct {
 	paramDomainBase
 	min int
@@ -26,8 +40,7 @@ func (d *intRangeDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.Patch, erro
 	if err != nil {
 		return nil, err
 	}
-	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %v }]`, d.path, val)))
-	return jsonpatch.DecodePatch(jsonOp)
+	return d.buildValuePatch(val)
 }
 
 type intStepRangeDomain struct {
@@ -42,8 +55,7 @@ func (d *intStepRangeDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.Patch,
 	if err != nil {
 		return nil, err
 	}
-	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %v }]`, d.path, val)))
-	return jsonpatch.DecodePatch(jsonOp)
+	return d.buildValuePatch(val)
 }
 
 type floatRangeDomain struct {
@@ -57,8 +69,7 @@ func (d *floatRangeDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.Patch, er
 	if err != nil {
 		return nil, err
 	}
-	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %v }]`, d.path, val)))
-	return jsonpatch.DecodePatch(jsonOp)
+	return d.buildValuePatch(val)
 }
 
 type floatDiscreteRangeDomain struct {
@@ -73,8 +84,7 @@ func (d *floatDiscreteRangeDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.P
 	if err != nil {
 		return nil, err
 	}
-	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %v }]`, d.path, val)))
-	return jsonpatch.DecodePatch(jsonOp)
+	return d.buildValuePatch(val)
 }
 
 type stringDomain struct {
@@ -87,6 +97,10 @@ func (d *stringDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.Patch, error)
 	if err != nil {
 		return nil, err
 	}
+	return d.buildValuePatch(val)
+}
+
+func (d *stringDomain) buildValuePatch(val interface{}) (jsonpatch.Patch, error) {
 	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": "%v" }]`, d.path, val)))
 	return jsonpatch.DecodePatch(jsonOp)
 }
@@ -100,6 +114,5 @@ func (d *boolDomain) buildPatch(trial *goptuna.Trial) (jsonpatch.Patch, error) {
 	if err != nil {
 		return nil, err
 	}
-	jsonOp := []byte(reformatJson(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": %s }]`, d.path, valStr)))
-	return jsonpatch.DecodePatch(jsonOp)
+	return d.buildValuePatch(valStr)
 }
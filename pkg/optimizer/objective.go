@@ -0,0 +1,200 @@
+package optimizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/goptuna"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+var trialStateComplete = goptuna.TrialStateComplete.String()
+
+// defaultObjectiveDirection returns the default optimize direction of the metric, only the drawdown is minimized by default
+func defaultObjectiveDirection(by string) string {
+	if by == HpOptimizerObjectiveMaxDrawdown {
+		return objectiveDirectionMinimize
+	}
+	return objectiveDirectionMaximize
+}
+
+func (c *ObjectiveConfig) normalize() error {
+	c.By = strings.ToLower(c.By)
+	if _, ok := metricValueFuncs[c.By]; !ok {
+		return fmt.Errorf(`unknown objective "%s"`, c.By)
+	}
+
+	if c.Weight.Sign() < 0 {
+		return fmt.Errorf("the weight of the objective %s can not be negative, use direction: minimize instead", c.By)
+	} else if c.Weight.IsZero() {
+		c.Weight = fixedpoint.One
+	}
+
+	switch direction := strings.ToLower(c.Direction); direction {
+	case "":
+		c.Direction = defaultObjectiveDirection(c.By)
+	case objectiveDirectionMaximize, objectiveDirectionMinimize:
+		c.Direction = direction
+	default:
+		return fmt.Errorf(`unknown direction "%s" of the objective %s`, c.Direction, c.By)
+	}
+
+	return nil
+}
+
+// sign returns 1 for the maximized objective, and -1 for the minimized objective
+func (c *ObjectiveConfig) sign() float64 {
+	if c.Direction == objectiveDirectionMinimize {
+		return -1.0
+	}
+	return 1.0
+}
+
+func (c *ConstraintConfig) normalize() error {
+	c.By = strings.ToLower(c.By)
+	if _, ok := metricValueFuncs[c.By]; !ok {
+		return fmt.Errorf(`unknown constraint metric "%s"`, c.By)
+	}
+
+	if c.Min == nil && c.Max == nil {
+		return fmt.Errorf("constraint %s requires min or max", c.By)
+	}
+
+	return nil
+}
+
+// check returns an error if the metric value violates the constraint
+func (c *ConstraintConfig) check(value float64) error {
+	if c.Min != nil && value < c.Min.Float64() {
+		return fmt.Errorf("%s %v is less than %v", c.By, value, c.Min.Float64())
+	}
+
+	if c.Max != nil && value > c.Max.Float64() {
+		return fmt.Errorf("%s %v is greater than %v", c.By, value, c.Max.Float64())
+	}
+
+	return nil
+}
+
+// objectives returns the configured objectives, the objectiveBy metric is used when the objectives are not defined
+func (c *Config) objectives() []ObjectiveConfig {
+	if len(c.Objectives) > 0 {
+		return c.Objectives
+	}
+
+	return []ObjectiveConfig{{
+		By:        c.Objective,
+		Weight:    fixedpoint.One,
+		Direction: defaultObjectiveDirection(c.Objective),
+	}}
+}
+
+// evaluateMetrics evaluates the metrics used by the objectives and the constraints
+func (c *Config) evaluateMetrics(summary *backtest.SummaryReport) map[string]float64 {
+	metrics := make(map[string]float64)
+	for _, objective := range c.objectives() {
+		if f, ok := metricValueFuncs[objective.By]; ok {
+			metrics[objective.By] = f(summary)
+		}
+	}
+
+	for _, constraint := range c.Constraints {
+		if f, ok := metricValueFuncs[constraint.By]; ok {
+			metrics[constraint.By] = f(summary)
+		}
+	}
+
+	return metrics
+}
+
+// checkConstraints returns the first constraint violation of the metrics
+func (c *Config) checkConstraints(metrics map[string]float64) error {
+	for _, constraint := range c.Constraints {
+		if err := constraint.check(metrics[constraint.By]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// score returns the weighted sum of the objective metrics, the study maximizes the score
+func (c *Config) score(metrics map[string]float64) float64 {
+	var score float64
+	for _, objective := range c.objectives() {
+		score += objective.sign() * objective.Weight.Float64() * metrics[objective.By]
+	}
+	return score
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func parseMetrics(attrs map[string]string) map[string]fixedpoint.Value {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	metrics := make(map[string]fixedpoint.Value, len(attrs))
+	for key, attr := range attrs {
+		if _, ok := metricValueFuncs[key]; !ok {
+			continue
+		}
+
+		value, err := fixedpoint.NewFromString(attr)
+		if err != nil {
+			continue
+		}
+
+		metrics[key] = value
+	}
+	return metrics
+}
+
+// dominates returns true if the trial a is not worse than the trial b in all the objectives,
+// and is better than b in at least one objective
+func dominates(objectives []ObjectiveConfig, a, b *HyperparameterOptimizeTrialResult) bool {
+	better := false
+	for _, objective := range objectives {
+		va := objective.sign() * a.Metrics[objective.By].Float64()
+		vb := objective.sign() * b.Metrics[objective.By].Float64()
+		if va < vb {
+			return false
+		} else if va > vb {
+			better = true
+		}
+	}
+	return better
+}
+
+// buildParetoFront returns the non-dominated trials among the completed trials, the pruned trials violating the
+// constraints and the failed trials are excluded.
+func buildParetoFront(objectives []ObjectiveConfig, trials []*HyperparameterOptimizeTrialResult) []*HyperparameterOptimizeTrialResult {
+	var candidates []*HyperparameterOptimizeTrialResult
+	for _, trial := range trials {
+		if trial.State == trialStateComplete && trial.Metrics != nil {
+			candidates = append(candidates, trial)
+		}
+	}
+
+	var front []*HyperparameterOptimizeTrialResult
+	for _, trial := range candidates {
+		dominated := false
+		for _, other := range candidates {
+			if other != trial && dominates(objectives, other, trial) {
+				dominated = true
+				break
+			}
+		}
+
+		if !dominated {
+			front = append(front, trial)
+		}
+	}
+
+	return front
+}
@@ -0,0 +1,188 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// multiObjectiveTestExecutor returns the profit x and the max drawdown x / 100, so that all the trials are Pareto-optimal
+type multiObjectiveTestExecutor struct{}
+
+func (e *multiObjectiveTestExecutor) Execute(configJson []byte) (*backtest.SummaryReport, error) {
+	var config struct {
+		Param struct {
+			X int `json:"x"`
+		} `json:"param"`
+	}
+
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return nil, err
+	}
+
+	return &backtest.SummaryReport{
+		TotalProfit: fixedpoint.NewFromInt(int64(config.Param.X)),
+		MaxDrawdown: fixedpoint.NewFromInt(int64(config.Param.X)).Div(fixedpoint.NewFromInt(100)),
+	}, nil
+}
+
+func (e *multiObjectiveTestExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	return nil, nil
+}
+
+func TestLoadConfig_Objectives(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "optimizer.yaml")
+	err := ioutil.WriteFile(configFile, []byte(`
+objectives:
+  - by: Profit
+  - by: maxdrawdown
+    weight: 100
+  - by: trades
+    direction: minimize
+constraints:
+  - by: maxdrawdown
+    max: 0.2
+`), 0644)
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", config.Objective)
+		assert.Equal(t, []ObjectiveConfig{
+			{By: HpOptimizerObjectiveProfit, Weight: fixedpoint.One, Direction: objectiveDirectionMaximize},
+			{By: HpOptimizerObjectiveMaxDrawdown, Weight: fixedpoint.NewFromInt(100), Direction: objectiveDirectionMinimize},
+			{By: HpOptimizerObjectiveTrades, Weight: fixedpoint.One, Direction: objectiveDirectionMinimize},
+		}, config.Objectives)
+		if assert.Len(t, config.Constraints, 1) && assert.NotNil(t, config.Constraints[0].Max) {
+			assert.Equal(t, "0.2", config.Constraints[0].Max.String())
+		}
+	}
+
+	for _, invalid := range []string{
+		"objectiveBy: profit\nobjectives:\n  - by: maxdrawdown\n",
+		"objectives:\n  - by: sharpe\n",
+		"objectives:\n  - by: profit\n    direction: up\n",
+		"constraints:\n  - by: maxdrawdown\n",
+	} {
+		err = ioutil.WriteFile(configFile, []byte(invalid), 0644)
+		assert.NoError(t, err)
+
+		_, err = LoadConfig(configFile)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestConfig_Score(t *testing.T) {
+	maxDrawdown := fixedpoint.NewFromFloat(0.2)
+	config := &Config{Objective: HpOptimizerObjectiveProfit}
+	assert.Equal(t, 10.0, config.score(map[string]float64{"profit": 10}))
+
+	config = &Config{
+		Objectives: []ObjectiveConfig{
+			{By: HpOptimizerObjectiveProfit, Weight: fixedpoint.One, Direction: objectiveDirectionMaximize},
+			{By: HpOptimizerObjectiveMaxDrawdown, Weight: fixedpoint.NewFromInt(100), Direction: objectiveDirectionMinimize},
+		},
+		Constraints: []ConstraintConfig{
+			{By: HpOptimizerObjectiveMaxDrawdown, Max: &maxDrawdown},
+		},
+	}
+
+	metrics := config.evaluateMetrics(&backtest.SummaryReport{
+		TotalProfit: fixedpoint.NewFromInt(100),
+		MaxDrawdown: fixedpoint.NewFromFloat(0.1),
+	})
+	if assert.Len(t, metrics, 2) {
+		assert.InDelta(t, 100.0, metrics["profit"], 1e-8)
+		assert.InDelta(t, 0.1, metrics["maxdrawdown"], 1e-8)
+	}
+	assert.InDelta(t, 90.0, config.score(metrics), 1e-8)
+	assert.NoError(t, config.checkConstraints(metrics))
+
+	metrics["maxdrawdown"] = 0.25
+	assert.Error(t, config.checkConstraints(metrics))
+}
+
+func TestBuildParetoFront(t *testing.T) {
+	objectives := []ObjectiveConfig{
+		{By: HpOptimizerObjectiveProfit, Weight: fixedpoint.One, Direction: objectiveDirectionMaximize},
+		{By: HpOptimizerObjectiveMaxDrawdown, Weight: fixedpoint.One, Direction: objectiveDirectionMinimize},
+	}
+
+	newTrial := func(id int, state string, profit, drawdown float64) *HyperparameterOptimizeTrialResult {
+		return &HyperparameterOptimizeTrialResult{
+			ID:    &id,
+			State: state,
+			Metrics: map[string]fixedpoint.Value{
+				HpOptimizerObjectiveProfit:      fixedpoint.NewFromFloat(profit),
+				HpOptimizerObjectiveMaxDrawdown: fixedpoint.NewFromFloat(drawdown),
+			},
+		}
+	}
+
+	trials := []*HyperparameterOptimizeTrialResult{
+		newTrial(0, "Complete", 100, 0.1),
+		newTrial(1, "Complete", 200, 0.3),
+		newTrial(2, "Complete", 90, 0.2),  // dominated by #0
+		newTrial(3, "Complete", 200, 0.4), // dominated by #1
+		newTrial(4, "Pruned", 300, 0.05),
+		{ID: new(int), State: "Fail"},
+	}
+
+	front := buildParetoFront(objectives, trials)
+	assert.Equal(t, []*HyperparameterOptimizeTrialResult{trials[0], trials[1]}, front)
+}
+
+func TestHyperparameterOptimizer_RunMultiObjective(t *testing.T) {
+	maxDrawdown := fixedpoint.NewFromFloat(0.2)
+	optimizer := &HyperparameterOptimizer{
+		SessionName: "test",
+		Config: &Config{
+			Executor:      &ExecutorConfig{Type: "local", LocalExecutorConfig: &LocalExecutorConfig{MaxNumberOfProcesses: 1}},
+			Algorithm:     HpOptimizerAlgorithmSOBOL,
+			MaxEvaluation: 30,
+			Matrix: []SelectorConfig{
+				{Type: selectorTypeRangeInt, Label: "x", Path: "/param/x", Min: fixedpoint.NewFromInt(0), Max: fixedpoint.NewFromInt(40)},
+			},
+			Objectives: []ObjectiveConfig{
+				{By: HpOptimizerObjectiveProfit, Weight: fixedpoint.One, Direction: objectiveDirectionMaximize},
+				{By: HpOptimizerObjectiveMaxDrawdown, Weight: fixedpoint.NewFromInt(50), Direction: objectiveDirectionMinimize},
+			},
+			Constraints: []ConstraintConfig{
+				{By: HpOptimizerObjectiveMaxDrawdown, Max: &maxDrawdown},
+			},
+		},
+	}
+
+	report, err := optimizer.Run(context.Background(), &multiObjectiveTestExecutor{}, []byte(`{"param":{"x":0}}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the score is x - 50 * x / 100 = x / 2, and the drawdown constraint limits x <= 20
+	assert.LessOrEqual(t, report.Best.Metrics[HpOptimizerObjectiveMaxDrawdown].Float64(), 0.2)
+	assert.InDelta(t, report.Best.Metrics[HpOptimizerObjectiveProfit].Float64()/2, report.Best.Value.Float64(), 1e-8)
+
+	assert.NotEmpty(t, report.ParetoFront)
+	for _, trial := range report.ParetoFront {
+		assert.Equal(t, trialStateComplete, trial.State)
+		assert.LessOrEqual(t, trial.Metrics[HpOptimizerObjectiveMaxDrawdown].Float64(), 0.2)
+	}
+
+	var pruned int
+	for _, trial := range report.Trials {
+		if trial.Metrics[HpOptimizerObjectiveMaxDrawdown].Float64() > 0.2 {
+			assert.NotEqual(t, trialStateComplete, trial.State)
+			pruned++
+		}
+	}
+	assert.Greater(t, pruned, 0)
+}
@@ -0,0 +1,527 @@
+package optimizer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+)
+
+// RemoteTask is the trial task leased to the remote worker
+type RemoteTask struct {
+	ID         string          `json:"id"`
+	ConfigJson json.RawMessage `json:"config"`
+}
+
+// RemoteTaskResult is the trial result returned by the remote worker
+type RemoteTaskResult struct {
+	ID     string                  `json:"id"`
+	Worker string                  `json:"worker"`
+	Report *backtest.SummaryReport `json:"report,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+// RemoteExecutorProgress is the progress of the trials dispatched by the coordinator
+type RemoteExecutorProgress struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Resumed   int `json:"resumed"`
+	Retried   int `json:"retried"`
+	Failed    int `json:"failed"`
+
+	// Workers is the last seen time of the workers
+	Workers map[string]time.Time `json:"workers"`
+}
+
+type remoteTaskOutcome struct {
+	report *backtest.SummaryReport
+	err    error
+}
+
+type remoteTask struct {
+	RemoteTask
+
+	hash     string
+	attempts int
+	worker   string
+	leasedAt time.Time
+	done     chan remoteTaskOutcome
+}
+
+type remoteStateRecord struct {
+	Hash   string                  `json:"hash"`
+	Report *backtest.SummaryReport `json:"report"`
+}
+
+// RemoteExecutor is the coordinator of the remote workers, the trial configs are queued and pulled by the workers over HTTP,
+// the failed or timed out trials are re-queued until the max retries are reached.
+type RemoteExecutor struct {
+	Config *RemoteExecutorConfig
+
+	mu sync.Mutex
+
+	// configJson is the config template for the workers to sync the backtest data
+	configJson []byte
+
+	seq     int
+	queue   []string
+	tasks   map[string]*remoteTask
+	workers map[string]time.Time
+
+	// results are the completed trial results keyed by the config hash
+	results   map[string]*backtest.SummaryReport
+	stateFile *os.File
+
+	completed, resumed, retried, failed int
+
+	// abortErr is set when the coordinator is shut down
+	abortErr error
+}
+
+func NewRemoteExecutor(config *RemoteExecutorConfig) (*RemoteExecutor, error) {
+	e := &RemoteExecutor{
+		Config:  config,
+		tasks:   make(map[string]*remoteTask),
+		workers: make(map[string]time.Time),
+		results: make(map[string]*backtest.SummaryReport),
+	}
+
+	if len(config.StateFile) > 0 {
+		if err := e.loadState(config.StateFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// loadState loads the completed trial results from the state file, and opens the file for appending the new results
+func (e *RemoteExecutor) loadState(stateFile string) error {
+	f, err := os.OpenFile(stateFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record remoteStateRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// the last line could be truncated when the coordinator is killed
+			log.WithError(err).Warnf("skip the invalid record of the state file %s", stateFile)
+			continue
+		}
+
+		e.results[record.Hash] = record.Report
+	}
+
+	if err := scanner.Err(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	log.Infof("loaded %d completed trial results from the state file %s", len(e.results), stateFile)
+	e.stateFile = f
+	return nil
+}
+
+// Prepare sets the config template for the workers, the workers sync the backtest data with the template before pulling the trials
+func (e *RemoteExecutor) Prepare(configJson []byte) error {
+	e.mu.Lock()
+	e.configJson = configJson
+	e.mu.Unlock()
+	return nil
+}
+
+// Start starts the coordinator http server, the server is shut down and the pending trials are failed when the context is canceled.
+// The listen error is returned, e.g., the address is already in use.
+func (e *RemoteExecutor) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", e.Config.Listen)
+	if err != nil {
+		return errors.Wrapf(err, "unable to listen on %s", e.Config.Listen)
+	}
+
+	srv := &http.Server{
+		Addr:    e.Config.Listen,
+		Handler: e.Handler(),
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Error("coordinator http server shutdown error")
+		}
+
+		e.abort(ctx.Err())
+	}()
+
+	go func() {
+		log.Infof("optimizer coordinator is listening on %s", ln.Addr())
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("coordinator http server error")
+		}
+	}()
+
+	go e.requeueWorker(ctx)
+	return nil
+}
+
+// requeueWorker re-queues the timed out tasks periodically, so that the tasks leased to the dead workers are retried or failed
+// even if there is no worker polling the coordinator.
+func (e *RemoteExecutor) requeueWorker(ctx context.Context) {
+	timeout := e.Config.TaskTimeout.Duration()
+	if timeout <= 0 {
+		return
+	}
+
+	interval := timeout / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			e.mu.Lock()
+			e.requeueTimeoutTasks(now)
+			e.mu.Unlock()
+		}
+	}
+}
+
+// Close closes the state file
+func (e *RemoteExecutor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stateFile == nil {
+		return nil
+	}
+
+	err := e.stateFile.Close()
+	e.stateFile = nil
+	return err
+}
+
+// Handler returns the http handler of the coordinator API
+func (e *RemoteExecutor) Handler() http.Handler {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	api := r.Group("/api/optimizer", e.authenticate)
+	api.GET("/config", func(c *gin.Context) {
+		e.mu.Lock()
+		configJson := e.configJson
+		e.mu.Unlock()
+
+		if configJson == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		c.Data(http.StatusOK, "application/json", configJson)
+	})
+
+	api.POST("/tasks/next", func(c *gin.Context) {
+		var req struct {
+			Worker string `json:"worker"`
+		}
+
+		if err := c.BindJSON(&req); err != nil {
+			return
+		}
+
+		task := e.next(req.Worker)
+		if task == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	})
+
+	api.POST("/tasks/result", func(c *gin.Context) {
+		var result RemoteTaskResult
+		if err := c.BindJSON(&result); err != nil {
+			return
+		}
+
+		if !e.complete(result) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		c.Status(http.StatusOK)
+	})
+
+	api.GET("/progress", func(c *gin.Context) {
+		c.JSON(http.StatusOK, e.Progress())
+	})
+
+	return r
+}
+
+func (e *RemoteExecutor) authenticate(c *gin.Context) {
+	if len(e.Config.Token) > 0 && c.GetHeader("Authorization") != "Bearer "+e.Config.Token {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	c.Next()
+}
+
+// Progress returns the progress of the trials
+func (e *RemoteExecutor) Progress() RemoteExecutorProgress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	progress := RemoteExecutorProgress{
+		Completed: e.completed,
+		Resumed:   e.resumed,
+		Retried:   e.retried,
+		Failed:    e.failed,
+		Workers:   make(map[string]time.Time, len(e.workers)),
+	}
+
+	for _, task := range e.tasks {
+		if task.worker == "" {
+			progress.Pending++
+		} else {
+			progress.Running++
+		}
+	}
+
+	for worker, lastSeen := range e.workers {
+		progress.Workers[worker] = lastSeen
+	}
+
+	return progress
+}
+
+// Execute queues the config json and waits for the summary report returned by the workers. This is a blocking operation.
+func (e *RemoteExecutor) Execute(configJson []byte) (*backtest.SummaryReport, error) {
+	hash := hashConfigJson(configJson)
+
+	e.mu.Lock()
+	if e.abortErr != nil {
+		e.mu.Unlock()
+		return nil, e.abortErr
+	}
+
+	if report, ok := e.results[hash]; ok {
+		e.resumed++
+		e.mu.Unlock()
+		return report, nil
+	}
+
+	e.seq++
+	task := &remoteTask{
+		RemoteTask: RemoteTask{
+			ID:         strconv.Itoa(e.seq),
+			ConfigJson: configJson,
+		},
+		hash: hash,
+		done: make(chan remoteTaskOutcome, 1),
+	}
+	e.tasks[task.ID] = task
+	e.queue = append(e.queue, task.ID)
+	e.mu.Unlock()
+
+	outcome := <-task.done
+	return outcome.report, outcome.err
+}
+
+func (e *RemoteExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	var resultsC = make(chan BacktestTask, e.Config.MaxNumberOfTrials*2)
+
+	wg := sync.WaitGroup{}
+	go func() {
+		defer func() {
+			wg.Wait()
+			close(resultsC)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case task, ok := <-taskC:
+				if !ok {
+					return
+				}
+
+				wg.Add(1)
+				go func(task BacktestTask) {
+					defer wg.Done()
+
+					report, err := e.Execute(task.ConfigJson)
+					if err != nil {
+						log.WithError(err).Errorf("remote execute error")
+					}
+
+					progress := e.Progress()
+					bar.Set("log", fmt.Sprintf("%d workers, %d running, %d pending", len(progress.Workers), progress.Running, progress.Pending))
+					bar.Write()
+
+					task.Error = err
+					task.Report = report
+					resultsC <- task
+				}(task)
+			}
+		}
+	}()
+
+	return resultsC, nil
+}
+
+// next leases the next pending task to the worker, the timed out tasks are re-queued first.
+// nil is returned when there is no pending task.
+func (e *RemoteExecutor) next(worker string) *RemoteTask {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.workers[worker] = now
+	e.requeueTimeoutTasks(now)
+
+	for len(e.queue) > 0 {
+		id := e.queue[0]
+		e.queue = e.queue[1:]
+
+		task, ok := e.tasks[id]
+		if !ok || task.worker != "" {
+			// the task is completed or leased
+			continue
+		}
+
+		task.attempts++
+		task.worker = worker
+		task.leasedAt = now
+		log.Infof("trial #%s is leased to the worker %s (attempt %d)", task.ID, worker, task.attempts)
+		return &task.RemoteTask
+	}
+
+	return nil
+}
+
+func (e *RemoteExecutor) requeueTimeoutTasks(now time.Time) {
+	timeout := e.Config.TaskTimeout.Duration()
+	if timeout <= 0 {
+		return
+	}
+
+	for _, task := range e.tasks {
+		if task.worker == "" || now.Sub(task.leasedAt) < timeout {
+			continue
+		}
+
+		e.retryOrFail(task, fmt.Errorf("trial #%s timed out on the worker %s", task.ID, task.worker))
+	}
+}
+
+// retryOrFail re-queues the task if the max retries are not reached, otherwise the task is failed with the error
+func (e *RemoteExecutor) retryOrFail(task *remoteTask, err error) {
+	if task.attempts > e.Config.MaxRetries {
+		log.WithError(err).Errorf("trial #%s failed after %d attempts", task.ID, task.attempts)
+		e.failed++
+		delete(e.tasks, task.ID)
+		task.done <- remoteTaskOutcome{err: err}
+		return
+	}
+
+	log.WithError(err).Warnf("retrying trial #%s", task.ID)
+	e.retried++
+	task.worker = ""
+	e.queue = append(e.queue, task.ID)
+}
+
+// complete handles the trial result from the worker, false is returned if the task is not found
+func (e *RemoteExecutor) complete(result RemoteTaskResult) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.workers[result.Worker] = time.Now()
+
+	task, ok := e.tasks[result.ID]
+	if !ok {
+		return false
+	}
+
+	if len(result.Error) > 0 || result.Report == nil {
+		err := errors.New(result.Error)
+		if result.Report == nil && len(result.Error) == 0 {
+			err = errors.New("empty summary report")
+		}
+
+		e.retryOrFail(task, fmt.Errorf("trial #%s failed on the worker %s: %w", task.ID, result.Worker, err))
+		return true
+	}
+
+	e.completed++
+	e.results[task.hash] = result.Report
+	delete(e.tasks, task.ID)
+	e.saveResult(task.hash, result.Report)
+
+	log.Infof("trial #%s is completed by the worker %s, %d completed, %d remaining", task.ID, result.Worker, e.completed, len(e.tasks))
+	task.done <- remoteTaskOutcome{report: result.Report}
+	return true
+}
+
+func (e *RemoteExecutor) saveResult(hash string, report *backtest.SummaryReport) {
+	if e.stateFile == nil {
+		return
+	}
+
+	out, err := json.Marshal(remoteStateRecord{Hash: hash, Report: report})
+	if err != nil {
+		log.WithError(err).Error("can not encode the trial result")
+		return
+	}
+
+	if _, err := e.stateFile.Write(append(out, '\n')); err != nil {
+		log.WithError(err).Errorf("can not write the trial result to the state file")
+	}
+}
+
+// abort fails all the outstanding tasks
+func (e *RemoteExecutor) abort(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.abortErr = err
+	for id, task := range e.tasks {
+		delete(e.tasks, id)
+		task.done <- remoteTaskOutcome{err: err}
+	}
+	e.queue = nil
+}
+
+func hashConfigJson(configJson []byte) string {
+	sum := sha256.Sum256(configJson)
+	return hex.EncodeToString(sum[:])
+}
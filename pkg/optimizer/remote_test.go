@@ -0,0 +1,236 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// flakyTestExecutor fails the first execution of each config, and returns the profit x
+type flakyTestExecutor struct {
+	mu       sync.Mutex
+	executed map[int]int
+}
+
+func (e *flakyTestExecutor) Execute(configJson []byte) (*backtest.SummaryReport, error) {
+	var config struct {
+		Param struct {
+			X int `json:"x"`
+		} `json:"param"`
+	}
+
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.executed[config.Param.X]++
+	if e.executed[config.Param.X] == 1 {
+		return nil, errors.New("backtest process crashed")
+	}
+
+	return &backtest.SummaryReport{TotalProfit: fixedpoint.NewFromInt(int64(config.Param.X))}, nil
+}
+
+func (e *flakyTestExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	return nil, nil
+}
+
+func newRemoteTestExecutor(t *testing.T, config *RemoteExecutorConfig) (*RemoteExecutor, *httptest.Server) {
+	executor, err := NewRemoteExecutor(config)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	server := httptest.NewServer(executor.Handler())
+	t.Cleanup(func() {
+		server.Close()
+		_ = executor.Close()
+	})
+	return executor, server
+}
+
+func TestRemoteExecutor(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.jsonl")
+	config := &RemoteExecutorConfig{
+		Token:             "secret",
+		MaxNumberOfTrials: 2,
+		MaxRetries:        1,
+		TaskTimeout:       types.Duration(time.Minute),
+		StateFile:         stateFile,
+	}
+
+	executor, server := newRemoteTestExecutor(t, config)
+	assert.NoError(t, executor.Prepare([]byte(`{"param":{"x":0}}`)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workerExecutor := &flakyTestExecutor{executed: make(map[int]int)}
+	worker := &RemoteWorker{
+		CoordinatorURL: server.URL,
+		Name:           "test-worker",
+		Token:          "secret",
+		Executor:       workerExecutor,
+		NumOfProcesses: 2,
+		PollInterval:   10 * time.Millisecond,
+	}
+
+	configJson, err := worker.FetchConfig(ctx)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"param":{"x":0}}`, string(configJson))
+
+	go func() {
+		_ = worker.Run(ctx)
+	}()
+
+	var wg sync.WaitGroup
+	for x := 1; x <= 3; x++ {
+		wg.Add(1)
+		go func(x int) {
+			defer wg.Done()
+			report, err := executor.Execute([]byte(fmt.Sprintf(`{"param":{"x":%d}}`, x)))
+			if assert.NoError(t, err) {
+				assert.Equal(t, int64(x), report.TotalProfit.Int64())
+			}
+		}(x)
+	}
+	wg.Wait()
+
+	progress := executor.Progress()
+	assert.Equal(t, 3, progress.Completed)
+	assert.Equal(t, 3, progress.Retried, "the first execution of each trial should be retried")
+	assert.Equal(t, 0, progress.Pending+progress.Running)
+	assert.Contains(t, progress.Workers, "test-worker")
+
+	cancel()
+	assert.NoError(t, executor.Close())
+
+	// the completed trials are resumed from the state file
+	resumedExecutor, _ := newRemoteTestExecutor(t, config)
+	report, err := resumedExecutor.Execute([]byte(`{"param":{"x":2}}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(2), report.TotalProfit.Int64())
+	}
+	assert.Equal(t, 1, resumedExecutor.Progress().Resumed)
+}
+
+func TestRemoteExecutor_RetryOrFail(t *testing.T) {
+	executor, err := NewRemoteExecutor(&RemoteExecutorConfig{
+		MaxRetries:  1,
+		TaskTimeout: types.Duration(time.Minute),
+	})
+	assert.NoError(t, err)
+
+	outcomeC := make(chan error, 1)
+	go func() {
+		_, err := executor.Execute([]byte(`{"param":{"x":1}}`))
+		outcomeC <- err
+	}()
+
+	var task *RemoteTask
+	assert.Eventually(t, func() bool {
+		task = executor.next("worker-1")
+		return task != nil
+	}, time.Second, time.Millisecond)
+
+	// the timed out task is re-queued and leased to another worker
+	executor.mu.Lock()
+	executor.tasks[task.ID].leasedAt = time.Now().Add(-2 * time.Minute)
+	executor.mu.Unlock()
+
+	retriedTask := executor.next("worker-2")
+	if assert.NotNil(t, retriedTask) {
+		assert.Equal(t, task.ID, retriedTask.ID)
+	}
+
+	// the max retries are reached
+	assert.True(t, executor.complete(RemoteTaskResult{ID: task.ID, Worker: "worker-2", Error: "out of memory"}))
+	assert.Error(t, <-outcomeC)
+	assert.False(t, executor.complete(RemoteTaskResult{ID: task.ID, Worker: "worker-1"}), "the failed task should be removed")
+
+	progress := executor.Progress()
+	assert.Equal(t, 1, progress.Failed)
+	assert.Equal(t, 1, progress.Retried)
+}
+
+func TestRemoteExecutor_Start(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the address is already in use
+	executor, err := NewRemoteExecutor(&RemoteExecutorConfig{Listen: ln.Addr().String()})
+	assert.NoError(t, err)
+	assert.Error(t, executor.Start(ctx))
+
+	executor, err = NewRemoteExecutor(&RemoteExecutorConfig{
+		Listen:      "127.0.0.1:0",
+		TaskTimeout: types.Duration(50 * time.Millisecond),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, executor.Start(ctx))
+
+	outcomeC := make(chan error, 1)
+	go func() {
+		_, err := executor.Execute([]byte(`{"param":{"x":1}}`))
+		outcomeC <- err
+	}()
+
+	assert.Eventually(t, func() bool {
+		return executor.next("worker-1") != nil
+	}, time.Second, time.Millisecond)
+
+	// the worker is dead, the timed out task is failed by the coordinator without the worker polling
+	select {
+	case err := <-outcomeC:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Error("the timed out task is not failed")
+	}
+}
+
+func TestRemoteExecutor_Unauthorized(t *testing.T) {
+	_, server := newRemoteTestExecutor(t, &RemoteExecutorConfig{Token: "secret"})
+	worker := &RemoteWorker{CoordinatorURL: server.URL, Name: "test-worker", Token: "wrong"}
+
+	_, err := worker.next(context.Background())
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_RemoteExecutor(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "optimizer.yaml")
+	err := ioutil.WriteFile(configFile, []byte("executor:\n  type: remote\n  remote:\n    maxRetries: 3\n"), 0644)
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	if assert.NoError(t, err) && assert.NotNil(t, config.Executor.RemoteExecutorConfig) {
+		remoteConfig := config.Executor.RemoteExecutorConfig
+		assert.Equal(t, ":9091", remoteConfig.Listen)
+		assert.Equal(t, 3, remoteConfig.MaxRetries)
+		assert.Equal(t, time.Hour, remoteConfig.TaskTimeout.Duration())
+		assert.Equal(t, 10, config.Executor.NumOfProcesses())
+	}
+}
@@ -0,0 +1,262 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/c-bata/goptuna"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// studyRecorder persists the finished trials of the study
+type studyRecorder struct {
+	service *service.OptimizerStudyService
+	study   *service.OptimizerStudy
+
+	// nextNumber is the next trial number of the persisted study, the running trials are not persisted,
+	// so the number could be different from the trial number of the resumed goptuna study
+	nextNumber int
+}
+
+func (r *studyRecorder) record(trial goptuna.FrozenTrial) error {
+	if !trial.State.IsFinished() {
+		return nil
+	}
+
+	record, err := newOptimizerTrialRecord(r.study.GID, r.nextNumber, trial)
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.InsertTrial(context.Background(), record); err != nil {
+		return err
+	}
+
+	r.nextNumber++
+	return nil
+}
+
+// openStudy creates the persisted study, or loads the finished trials of the persisted study into the goptuna study
+// when resuming. The persisted study of the same name is replaced when it's not resumed. The number of the loaded trials is returned.
+func (o *HyperparameterOptimizer) openStudy(ctx context.Context, study *goptuna.Study) (*studyRecorder, int, error) {
+	record, err := o.StudyService.FindStudy(ctx, o.SessionName)
+	if errors.Is(err, service.ErrOptimizerStudyNotFound) {
+		if o.Resume {
+			log.Warnf("study %s is not found, starting a new study", o.SessionName)
+		}
+
+		return o.createStudy(ctx)
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	if !o.Resume {
+		log.Warnf("study %s already exists, replacing it with a new study", o.SessionName)
+		if err := o.StudyService.DeleteStudy(ctx, record.GID); err != nil {
+			return nil, 0, err
+		}
+
+		return o.createStudy(ctx)
+	}
+
+	var persistedConfig Config
+	if err := json.Unmarshal([]byte(record.Config), &persistedConfig); err != nil {
+		return nil, 0, err
+	}
+
+	// the sampler can not use the trials from different parameter domains
+	if !reflect.DeepEqual(persistedConfig.Matrix, o.Config.Matrix) {
+		return nil, 0, fmt.Errorf("the parameter matrix of study %s is changed, please start a new study", o.SessionName)
+	}
+
+	trials, err := o.StudyService.QueryTrials(ctx, record.GID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := cloneTrials(study, trials); err != nil {
+		return nil, 0, err
+	}
+
+	log.Infof("resuming study %s with %d finished trials", o.SessionName, len(trials))
+
+	recorder := &studyRecorder{service: o.StudyService, study: record}
+	if len(trials) > 0 {
+		recorder.nextNumber = trials[len(trials)-1].Number + 1
+	}
+
+	return recorder, len(trials), nil
+}
+
+func (o *HyperparameterOptimizer) createStudy(ctx context.Context) (*studyRecorder, int, error) {
+	configJson, err := json.Marshal(o.Config)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	record := &service.OptimizerStudy{Name: o.SessionName, Config: string(configJson)}
+	if err := o.StudyService.InsertStudy(ctx, record); err != nil {
+		return nil, 0, err
+	}
+
+	return &studyRecorder{service: o.StudyService, study: record}, 0, nil
+}
+
+// cloneTrials adds the persisted trials to the goptuna study
+func cloneTrials(study *goptuna.Study, trials []service.OptimizerTrial) error {
+	for _, trial := range trials {
+		frozenTrial, err := newFrozenTrial(trial)
+		if err != nil {
+			return err
+		}
+
+		if _, err := study.Storage.CloneTrial(study.ID, frozenTrial); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newOptimizerTrialRecord(studyID int64, number int, trial goptuna.FrozenTrial) (*service.OptimizerTrial, error) {
+	params, err := json.Marshal(trial.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	internalParams, err := json.Marshal(trial.InternalParams)
+	if err != nil {
+		return nil, err
+	}
+
+	distributions := make(map[string]json.RawMessage, len(trial.Distributions))
+	for name, distribution := range trial.Distributions {
+		distributionJson, err := goptuna.DistributionToJSON(distribution)
+		if err != nil {
+			return nil, err
+		}
+		distributions[name] = distributionJson
+	}
+
+	distributionsJson, err := json.Marshal(distributions)
+	if err != nil {
+		return nil, err
+	}
+
+	userAttrs, err := json.Marshal(trial.UserAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.OptimizerTrial{
+		StudyID:        studyID,
+		Number:         number,
+		State:          trial.State.String(),
+		Value:          trial.Value,
+		Params:         string(params),
+		InternalParams: string(internalParams),
+		Distributions:  string(distributionsJson),
+		UserAttrs:      string(userAttrs),
+		StartedAt:      trial.DatetimeStart,
+		CompletedAt:    trial.DatetimeComplete,
+	}, nil
+}
+
+func parseTrialState(state string) (goptuna.TrialState, error) {
+	for _, s := range []goptuna.TrialState{goptuna.TrialStateComplete, goptuna.TrialStatePruned, goptuna.TrialStateFail} {
+		if s.String() == state {
+			return s, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unexpected trial state %q", state)
+}
+
+// newFrozenTrial converts the persisted trial to the goptuna trial, the parameters are restored from the internal
+// representation, so that the parameter types are kept.
+func newFrozenTrial(record service.OptimizerTrial) (goptuna.FrozenTrial, error) {
+	state, err := parseTrialState(record.State)
+	if err != nil {
+		return goptuna.FrozenTrial{}, err
+	}
+
+	trial := goptuna.FrozenTrial{
+		Number:             record.Number,
+		State:              state,
+		Value:              record.Value,
+		IntermediateValues: make(map[int]float64),
+		DatetimeStart:      record.StartedAt,
+		DatetimeComplete:   record.CompletedAt,
+		Params:             make(map[string]interface{}),
+		Distributions:      make(map[string]interface{}),
+	}
+
+	if err := json.Unmarshal([]byte(record.InternalParams), &trial.InternalParams); err != nil {
+		return trial, err
+	}
+
+	if err := json.Unmarshal([]byte(record.UserAttrs), &trial.UserAttrs); err != nil {
+		return trial, err
+	}
+
+	var distributions map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(record.Distributions), &distributions); err != nil {
+		return trial, err
+	}
+
+	for name, distributionJson := range distributions {
+		distribution, err := goptuna.JSONToDistribution(distributionJson)
+		if err != nil {
+			return trial, err
+		}
+
+		param, err := goptuna.ToExternalRepresentation(distribution, trial.InternalParams[name])
+		if err != nil {
+			return trial, err
+		}
+
+		trial.Distributions[name] = distribution
+		trial.Params[name] = param
+	}
+
+	if trial.UserAttrs == nil {
+		trial.UserAttrs = make(map[string]string)
+	}
+	trial.SystemAttrs = make(map[string]string)
+	return trial, nil
+}
+
+// LoadStudyReport builds the report of the persisted study
+func LoadStudyReport(ctx context.Context, studyService *service.OptimizerStudyService, name string) (*HyperparameterOptimizeReport, error) {
+	record, err := studyService.FindStudy(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal([]byte(record.Config), &config); err != nil {
+		return nil, err
+	}
+
+	trials, err := studyService.QueryTrials(ctx, record.GID)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &HyperparameterOptimizer{SessionName: name, Config: &config}
+	study, err := o.buildStudy(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloneTrials(study, trials); err != nil {
+		return nil, err
+	}
+
+	labelPaths, _ := o.buildParamDomains()
+	return o.buildReport(study, labelPaths), nil
+}
@@ -0,0 +1,113 @@
+package optimizer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func newStudyTestService(t *testing.T) *service.OptimizerStudyService {
+	databaseService := service.NewDatabaseService("sqlite3", filepath.Join(t.TempDir(), "hoptimize.sqlite3"))
+	if !assert.NoError(t, databaseService.Connect()) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		_ = databaseService.Close()
+	})
+
+	if !assert.NoError(t, databaseService.Upgrade(context.Background())) {
+		t.FailNow()
+	}
+
+	return service.NewOptimizerStudyService(databaseService.DB)
+}
+
+func newStudyTestOptimizer(studyService *service.OptimizerStudyService, maxEvaluation int) *HyperparameterOptimizer {
+	return &HyperparameterOptimizer{
+		SessionName:  "test",
+		StudyService: studyService,
+		Config: &Config{
+			Executor:      &ExecutorConfig{Type: "local", LocalExecutorConfig: &LocalExecutorConfig{MaxNumberOfProcesses: 1}},
+			Algorithm:     HpOptimizerAlgorithmTPE,
+			Objective:     HpOptimizerObjectiveProfit,
+			MaxEvaluation: maxEvaluation,
+			Matrix: []SelectorConfig{
+				{Type: selectorTypeRangeInt, Label: "x", Path: "/param/x", Min: fixedpoint.NewFromInt(0), Max: fixedpoint.NewFromInt(40)},
+			},
+		},
+	}
+}
+
+func TestHyperparameterOptimizer_ResumeStudy(t *testing.T) {
+	ctx := context.Background()
+	studyService := newStudyTestService(t)
+	configJson := []byte(`{"param":{"x":0}}`)
+
+	report, err := newStudyTestOptimizer(studyService, 5).Run(ctx, &multiObjectiveTestExecutor{}, configJson)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, report.Trials, 5)
+
+	optimizer := newStudyTestOptimizer(studyService, 8)
+	optimizer.Resume = true
+	resumedReport, err := optimizer.Run(ctx, &multiObjectiveTestExecutor{}, configJson)
+	if !assert.NoError(t, err) || !assert.Len(t, resumedReport.Trials, 8) {
+		return
+	}
+
+	// the loaded trials keep the parameter types and the metrics
+	for i, trial := range report.Trials {
+		assert.Equal(t, trial.Parameters, resumedReport.Trials[i].Parameters)
+		assert.Equal(t, trial.Metrics, resumedReport.Trials[i].Metrics)
+		assert.IsType(t, 0, resumedReport.Trials[i].Parameters["x"])
+	}
+	assert.GreaterOrEqual(t, resumedReport.Best.Value.Float64(), report.Best.Value.Float64())
+
+	exportedReport, err := LoadStudyReport(ctx, studyService, "test")
+	if assert.NoError(t, err) {
+		assert.Equal(t, resumedReport.Best.Value, exportedReport.Best.Value)
+		assert.Equal(t, resumedReport.Best.Parameters, exportedReport.Best.Parameters)
+		assert.Len(t, exportedReport.Trials, 8)
+		assert.Equal(t, map[string]string{"x": "/param/x"}, exportedReport.Parameters)
+	}
+
+	// the finished study is not evaluated again
+	optimizer = newStudyTestOptimizer(studyService, 8)
+	optimizer.Resume = true
+	finishedReport, err := optimizer.Run(ctx, &multiObjectiveTestExecutor{}, configJson)
+	if assert.NoError(t, err) {
+		assert.Len(t, finishedReport.Trials, 8)
+	}
+
+	// the parameter domains of the resumed study can not be changed
+	optimizer = newStudyTestOptimizer(studyService, 10)
+	optimizer.Resume = true
+	optimizer.Config.Matrix[0].Max = fixedpoint.NewFromInt(80)
+	_, err = optimizer.Run(ctx, &multiObjectiveTestExecutor{}, configJson)
+	assert.Error(t, err)
+
+	// the persisted study is replaced by a new study without resuming
+	report, err = newStudyTestOptimizer(studyService, 3).Run(ctx, &multiObjectiveTestExecutor{}, configJson)
+	if assert.NoError(t, err) {
+		assert.Len(t, report.Trials, 3)
+	}
+
+	trials, err := studyService.QueryTrials(ctx, mustFindStudy(t, studyService, "test").GID)
+	if assert.NoError(t, err) {
+		assert.Len(t, trials, 3)
+	}
+}
+
+func mustFindStudy(t *testing.T, studyService *service.OptimizerStudyService, name string) *service.OptimizerStudy {
+	study, err := studyService.FindStudy(context.Background(), name)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return study
+}
@@ -0,0 +1,263 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// WalkForwardConfig splits the backtest time range into the rolling in-sample and out-of-sample windows,
+// the parameters are optimized on each in-sample window and evaluated on the following out-of-sample window.
+type WalkForwardConfig struct {
+	InSample    types.Duration `json:"inSample" yaml:"inSample"`
+	OutOfSample types.Duration `json:"outOfSample" yaml:"outOfSample"`
+
+	// Anchored keeps all the in-sample windows starting from the backtest start time
+	Anchored bool `json:"anchored,omitempty" yaml:"anchored,omitempty"`
+}
+
+type WalkForwardWindow struct {
+	InSampleStartTime    time.Time `json:"inSampleStartTime"`
+	OutOfSampleStartTime time.Time `json:"outOfSampleStartTime"`
+	OutOfSampleEndTime   time.Time `json:"outOfSampleEndTime"`
+}
+
+type WalkForwardResult struct {
+	WalkForwardWindow
+
+	// OutOfSampleValue is the objective value of the best in-sample parameters on the out-of-sample window
+	OutOfSampleValue  fixedpoint.Value `json:"outOfSampleValue"`
+	OutOfSampleProfit fixedpoint.Value `json:"outOfSampleProfit"`
+}
+
+// Windows returns the walk-forward windows in the time range, the out-of-sample windows are contiguous,
+// and the last out-of-sample window is truncated by the end time.
+func (c *WalkForwardConfig) Windows(startTime, endTime time.Time) ([]WalkForwardWindow, error) {
+	inSample := c.InSample.Duration()
+	outOfSample := c.OutOfSample.Duration()
+	if inSample <= 0 || outOfSample <= 0 {
+		return nil, errors.New("walk-forward inSample and outOfSample durations must be positive")
+	}
+
+	var windows []WalkForwardWindow
+	for oosStartTime := startTime.Add(inSample); oosStartTime.Before(endTime); oosStartTime = oosStartTime.Add(outOfSample) {
+		window := WalkForwardWindow{
+			InSampleStartTime:    oosStartTime.Add(-inSample),
+			OutOfSampleStartTime: oosStartTime,
+			OutOfSampleEndTime:   oosStartTime.Add(outOfSample),
+		}
+
+		if c.Anchored {
+			window.InSampleStartTime = startTime
+		}
+
+		if window.OutOfSampleEndTime.After(endTime) {
+			window.OutOfSampleEndTime = endTime
+		}
+
+		windows = append(windows, window)
+	}
+
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("backtest time range %s ~ %s is shorter than the walk-forward in-sample window %s", startTime, endTime, inSample)
+	}
+
+	return windows, nil
+}
+
+// RunWalkForward optimizes the parameters on the in-sample windows, and evaluates the best parameters on the out-of-sample windows.
+// The report trials are the best trials of the windows.
+func (o *HyperparameterOptimizer) RunWalkForward(ctx context.Context, executor Executor, configJson []byte) (*HyperparameterOptimizeReport, error) {
+	if o.Config.WalkForward == nil {
+		return nil, errors.New("walk-forward config is not defined")
+	}
+
+	startTime, endTime, err := parseBacktestTimeRange(configJson)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := o.Config.WalkForward.Windows(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	labelPaths, paramDomains := o.buildParamDomains()
+	metricValueFunc := o.metricValueFunc()
+
+	report := &HyperparameterOptimizeReport{
+		Name:       o.SessionName,
+		Objective:  o.Config.Objective,
+		Objectives: o.Config.Objectives,
+		Parameters: labelPaths,
+	}
+
+	outOfSampleProfit := fixedpoint.Zero
+	for i, window := range windows {
+		log.Infof("walk-forward window #%d: in-sample %s ~ %s, out-of-sample %s ~ %s", i+1,
+			window.InSampleStartTime, window.OutOfSampleStartTime, window.OutOfSampleStartTime, window.OutOfSampleEndTime)
+
+		inSampleConfig, err := patchBacktestTimeRange(configJson, window.InSampleStartTime, window.OutOfSampleStartTime)
+		if err != nil {
+			return nil, err
+		}
+
+		windowOptimizer := &HyperparameterOptimizer{
+			SessionName:  fmt.Sprintf("%s-wf%d", o.SessionName, i+1),
+			Config:       o.Config,
+			StudyService: o.StudyService,
+			Resume:       o.Resume,
+		}
+
+		inSampleReport, err := windowOptimizer.Run(ctx, executor, inSampleConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		outOfSampleConfig, err := patchBacktestTimeRange(configJson, window.OutOfSampleStartTime, window.OutOfSampleEndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		outOfSampleConfig, err = applyParams(outOfSampleConfig, paramDomains, inSampleReport.Best.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		summary, err := executor.Execute(outOfSampleConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &WalkForwardResult{
+			WalkForwardWindow: window,
+			OutOfSampleValue:  fixedpoint.NewFromFloat(metricValueFunc(summary)),
+			OutOfSampleProfit: fixedpoint.NewFromFloat(TotalProfitMetricValueFunc(summary)),
+		}
+		outOfSampleProfit = outOfSampleProfit.Add(result.OutOfSampleProfit)
+
+		windowID := i + 1
+		report.Trials = append(report.Trials, &HyperparameterOptimizeTrialResult{
+			ID:          &windowID,
+			Value:       inSampleReport.Best.Value,
+			Parameters:  inSampleReport.Best.Parameters,
+			WalkForward: result,
+		})
+	}
+
+	// the parameters of the last window are the best parameters for the next period
+	report.Best = report.Trials[len(report.Trials)-1]
+	report.OutOfSampleProfit = &outOfSampleProfit
+	report.ParameterStability = parameterStability(report.Trials)
+	return report, nil
+}
+
+// parameterStability returns the coefficient of variation of the numeric parameters among the trials,
+// the lower value means the parameter is more stable. The non-numeric parameters are skipped.
+func parameterStability(trials []*HyperparameterOptimizeTrialResult) map[string]fixedpoint.Value {
+	values := make(map[string][]float64)
+	for _, trial := range trials {
+		for label, param := range trial.Parameters {
+			switch v := param.(type) {
+			case float64:
+				values[label] = append(values[label], v)
+			case int:
+				values[label] = append(values[label], float64(v))
+			}
+		}
+	}
+
+	stability := make(map[string]fixedpoint.Value)
+	for label, vs := range values {
+		var sum float64
+		for _, v := range vs {
+			sum += v
+		}
+		mean := sum / float64(len(vs))
+
+		var variance float64
+		for _, v := range vs {
+			variance += (v - mean) * (v - mean)
+		}
+		variance /= float64(len(vs))
+
+		if mean == 0 {
+			stability[label] = fixedpoint.Zero
+			continue
+		}
+
+		stability[label] = fixedpoint.NewFromFloat(math.Sqrt(variance) / math.Abs(mean))
+	}
+
+	return stability
+}
+
+func parseBacktestTimeRange(configJson []byte) (startTime, endTime time.Time, err error) {
+	var config struct {
+		Backtest *struct {
+			StartTime types.LooseFormatTime  `json:"startTime"`
+			EndTime   *types.LooseFormatTime `json:"endTime,omitempty"`
+		} `json:"backtest"`
+	}
+
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return startTime, endTime, err
+	}
+
+	if config.Backtest == nil {
+		return startTime, endTime, errors.New("backtest config is not defined")
+	}
+
+	startTime = config.Backtest.StartTime.Time()
+	endTime = time.Now()
+	if config.Backtest.EndTime != nil {
+		endTime = config.Backtest.EndTime.Time()
+	}
+
+	return startTime, endTime, nil
+}
+
+func patchBacktestTimeRange(configJson []byte, startTime, endTime time.Time) ([]byte, error) {
+	jsonOp := []byte(fmt.Sprintf(`[{"op": "add", "path": "/backtest/startTime", "value": "%s" }, {"op": "add", "path": "/backtest/endTime", "value": "%s" }]`,
+		startTime.Format(time.RFC3339), endTime.Format(time.RFC3339)))
+
+	patch, err := jsonpatch.DecodePatch(jsonOp)
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.ApplyIndent(configJson, "  ")
+}
+
+func applyParams(configJson []byte, paramDomains []paramDomain, params map[string]interface{}) ([]byte, error) {
+	for _, domain := range paramDomains {
+		val, ok := params[domain.getLabel()]
+		if !ok {
+			return nil, fmt.Errorf("missing parameter %q from the trial result (%v)", domain.getLabel(), params)
+		}
+
+		patch, err := domain.buildValuePatch(val)
+		if err != nil {
+			return nil, err
+		}
+
+		configJson, err = patch.ApplyIndent(configJson, "  ")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return configJson, nil
+}
@@ -0,0 +1,140 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// walkForwardTestExecutor returns the profit -(x - 5)^2 in the in-sample windows, and the constant profit 10 in the out-of-sample windows
+type walkForwardTestExecutor struct {
+	outOfSampleStartTimes []string
+}
+
+func (e *walkForwardTestExecutor) Execute(configJson []byte) (*backtest.SummaryReport, error) {
+	var config struct {
+		Backtest struct {
+			StartTime string `json:"startTime"`
+			EndTime   string `json:"endTime"`
+		} `json:"backtest"`
+		Param struct {
+			X int `json:"x"`
+		} `json:"param"`
+	}
+
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(config.Backtest.StartTime, "2022-01-01") {
+		x := config.Param.X - 5
+		return &backtest.SummaryReport{TotalProfit: fixedpoint.NewFromInt(int64(-x * x))}, nil
+	}
+
+	e.outOfSampleStartTimes = append(e.outOfSampleStartTimes, config.Backtest.StartTime)
+	return &backtest.SummaryReport{TotalProfit: fixedpoint.NewFromInt(10)}, nil
+}
+
+func (e *walkForwardTestExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	return nil, nil
+}
+
+func TestWalkForwardConfig_Windows(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2022, 1, 26, 0, 0, 0, 0, time.UTC)
+
+	config := &WalkForwardConfig{
+		InSample:    types.Duration(10 * 24 * time.Hour),
+		OutOfSample: types.Duration(7 * 24 * time.Hour),
+	}
+
+	windows, err := config.Windows(startTime, endTime)
+	assert.NoError(t, err)
+	if assert.Len(t, windows, 3) {
+		assert.Equal(t, startTime, windows[0].InSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 10), windows[0].OutOfSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 7), windows[1].InSampleStartTime)
+		assert.Equal(t, windows[0].OutOfSampleEndTime, windows[1].OutOfSampleStartTime)
+		assert.Equal(t, endTime, windows[2].OutOfSampleEndTime, "the last window should be truncated")
+	}
+
+	config.Anchored = true
+	windows, err = config.Windows(startTime, endTime)
+	assert.NoError(t, err)
+	for _, window := range windows {
+		assert.Equal(t, startTime, window.InSampleStartTime)
+	}
+
+	_, err = config.Windows(startTime, startTime.AddDate(0, 0, 5))
+	assert.Error(t, err)
+}
+
+func TestHyperparameterOptimizer_RunWalkForward(t *testing.T) {
+	optimizer := &HyperparameterOptimizer{
+		SessionName: "test",
+		Config: &Config{
+			Executor:      &ExecutorConfig{Type: "local", LocalExecutorConfig: &LocalExecutorConfig{MaxNumberOfProcesses: 1}},
+			Algorithm:     HpOptimizerAlgorithmSOBOL,
+			Objective:     HpOptimizerObjectiveProfit,
+			MaxEvaluation: 20,
+			Matrix: []SelectorConfig{
+				{Type: selectorTypeRangeInt, Label: "x", Path: "/param/x", Min: fixedpoint.NewFromInt(0), Max: fixedpoint.NewFromInt(10)},
+			},
+			WalkForward: &WalkForwardConfig{
+				InSample:    types.Duration(10 * 24 * time.Hour),
+				OutOfSample: types.Duration(10 * 24 * time.Hour),
+				Anchored:    true,
+			},
+		},
+	}
+
+	configJson := []byte(`{"backtest": {"startTime": "2022-01-01", "endTime": "2022-01-31"}, "param": {"x": 0}}`)
+	executor := &walkForwardTestExecutor{}
+
+	report, err := optimizer.RunWalkForward(context.Background(), executor, configJson)
+	assert.NoError(t, err)
+	assert.Len(t, report.Trials, 2)
+	assert.Equal(t, []string{"2022-01-11T00:00:00Z", "2022-01-21T00:00:00Z"}, executor.outOfSampleStartTimes)
+	assert.Equal(t, "20", report.OutOfSampleProfit.String())
+
+	for _, trial := range report.Trials {
+		assert.Equal(t, "10", trial.WalkForward.OutOfSampleValue.String())
+	}
+
+	var buf closableBuffer
+	assert.NoError(t, FormatResultsTsv(&buf, report.Parameters, report.Trials))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "inSampleStartTime\toutOfSampleStartTime\toutOfSampleEndTime\tx\tvalue\toutOfSampleValue\toutOfSampleProfit", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "2022-01-01T00:00:00Z\t2022-01-11T00:00:00Z\t2022-01-21T00:00:00Z\t"))
+	}
+}
+
+func TestParameterStability(t *testing.T) {
+	stability := parameterStability([]*HyperparameterOptimizeTrialResult{
+		{Parameters: map[string]interface{}{"x": 10, "y": 1.0, "s": "a"}},
+		{Parameters: map[string]interface{}{"x": 10, "y": 3.0, "s": "b"}},
+	})
+
+	assert.Equal(t, "0", stability["x"].String())
+	assert.Equal(t, "0.5", stability["y"].String())
+	assert.NotContains(t, stability, "s")
+}
+
+type closableBuffer struct {
+	strings.Builder
+}
+
+func (b *closableBuffer) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package optimizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteWorker pulls the trial configs from the coordinator, executes them with the local executor
+// and returns the summary reports to the coordinator.
+type RemoteWorker struct {
+	CoordinatorURL string
+	Name           string
+	Token          string
+
+	// Executor executes the trial config, usually the LocalProcessExecutor
+	Executor Executor
+
+	// NumOfProcesses is the number of the concurrent trials of the worker
+	NumOfProcesses int
+
+	// PollInterval is the interval of polling the coordinator when there is no pending trial
+	PollInterval time.Duration
+
+	Client *http.Client
+}
+
+func (w *RemoteWorker) client() *http.Client {
+	if w.Client != nil {
+		return w.Client
+	}
+	return http.DefaultClient
+}
+
+func (w *RemoteWorker) pollInterval() time.Duration {
+	if w.PollInterval > 0 {
+		return w.PollInterval
+	}
+	return 3 * time.Second
+}
+
+// do sends the request to the coordinator, the response body is returned with the status code
+func (w *RemoteWorker) do(ctx context.Context, method, path string, payload interface{}) (int, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		out, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewReader(out)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(w.CoordinatorURL, "/")+"/api/optimizer"+path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.Token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	resp, err := w.client().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return resp.StatusCode, respBody, nil
+	default:
+		return resp.StatusCode, respBody, fmt.Errorf("coordinator response error: %s %s", resp.Status, respBody)
+	}
+}
+
+// FetchConfig waits until the coordinator is ready, and returns the config template for syncing the backtest data
+func (w *RemoteWorker) FetchConfig(ctx context.Context) ([]byte, error) {
+	for {
+		status, body, err := w.do(ctx, http.MethodGet, "/config", nil)
+		if err != nil {
+			log.WithError(err).Warn("can not fetch the config from the coordinator, retrying...")
+		} else if status == http.StatusOK {
+			return body, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(w.pollInterval()):
+		}
+	}
+}
+
+// Run pulls and executes the trials until the context is canceled
+func (w *RemoteWorker) Run(ctx context.Context) error {
+	numOfProcesses := w.NumOfProcesses
+	if numOfProcesses <= 0 {
+		numOfProcesses = 1
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(numOfProcesses)
+	for i := 0; i < numOfProcesses; i++ {
+		go func(id int) {
+			defer wg.Done()
+			w.runLoop(ctx, id)
+		}(i + 1)
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (w *RemoteWorker) runLoop(ctx context.Context, id int) {
+	for ctx.Err() == nil {
+		task, err := w.next(ctx)
+		if err != nil {
+			log.WithError(err).Warnf("worker %s #%d can not pull the trial from the coordinator", w.Name, id)
+		}
+
+		if task == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.pollInterval()):
+			}
+			continue
+		}
+
+		log.Infof("worker %s #%d is executing trial #%s", w.Name, id, task.ID)
+		result := RemoteTaskResult{ID: task.ID, Worker: w.Name}
+		report, err := w.Executor.Execute(task.ConfigJson)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Report = report
+		}
+
+		if err := w.submit(ctx, result); err != nil {
+			// the coordinator re-queues the trial after the task timeout
+			log.WithError(err).Errorf("worker %s #%d can not submit the result of trial #%s", w.Name, id, task.ID)
+		}
+	}
+}
+
+func (w *RemoteWorker) next(ctx context.Context) (*RemoteTask, error) {
+	status, body, err := w.do(ctx, http.MethodPost, "/tasks/next", map[string]string{"worker": w.Name})
+	if err != nil || status == http.StatusNoContent {
+		return nil, err
+	}
+
+	var task RemoteTask
+	if err := json.Unmarshal(body, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (w *RemoteWorker) submit(ctx context.Context, result RemoteTaskResult) error {
+	_, _, err := w.do(ctx, http.MethodPost, "/tasks/result", result)
+	return err
+}
@@ -63,3 +63,33 @@ func (s *AccountService) InsertAsset(time time.Time, session string, name types.
 	}
 	return err
 }
+
+// QueryNavHistory queries the asset records of the session in the time range, ordered by time
+func (s *AccountService) QueryNavHistory(session string, since, until time.Time) ([]types.NavHistoryDetail, error) {
+	// the gid column of sqlite is not auto-incremented, so it's not selected
+	sql := "SELECT `session`, `exchange`, `subaccount`, `time`, `currency`, `net_asset_in_usd`, `net_asset_in_btc`, " +
+		"`balance`, `available`, `locked`, `borrowed`, `interest`, `net_asset`, `price_in_usd`, `is_margin`, `is_isolated`, `isolated_symbol` " +
+		"FROM `nav_history_details` WHERE `session` = :session AND `time` >= :since AND `time` <= :until ORDER BY `time` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"session": session,
+		"since":   since,
+		"until":   until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var details []types.NavHistoryDetail
+	for rows.Next() {
+		var detail types.NavHistoryDetail
+		if err := rows.StructScan(&detail); err != nil {
+			return details, err
+		}
+
+		details = append(details, detail)
+	}
+
+	return details, rows.Err()
+}
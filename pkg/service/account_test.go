@@ -38,4 +38,15 @@ func TestAccountService(t *testing.T) {
 		},
 	})
 	assert.NoError(t, err)
+
+	details, err := service.QueryNavHistory("binance", t1.Add(-time.Minute), t1.Add(time.Minute))
+	if assert.NoError(t, err) && assert.Len(t, details, 1) {
+		assert.Equal(t, "BTC", details[0].Currency)
+		assert.Equal(t, "1", details[0].NetAsset.String())
+		assert.Equal(t, "44870", details[0].PriceInUSD.String())
+	}
+
+	details, err = service.QueryNavHistory("ALL", t1.Add(-time.Minute), t1.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, details)
 }
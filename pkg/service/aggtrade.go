@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// QueryAggTradeIDs returns the imported aggregate trade ids of the symbol in the id range [fromID, toID]
+func (s *BacktestService) QueryAggTradeIDs(ctx context.Context, ex types.ExchangeName, symbol string, fromID, toID uint64) (map[uint64]struct{}, error) {
+	sel := sq.Select("id").
+		From("agg_trades").
+		Where(sq.Eq{
+			"exchange": ex.String(),
+			"symbol":   symbol,
+		}).
+		Where(sq.GtOrEq{"id": fromID}).
+		Where(sq.LtOrEq{"id": toID})
+
+	query, args, err := sel.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := make(map[uint64]struct{})
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	return ids, rows.Err()
+}
+
+// InsertNewAggTrades inserts the aggregate trades of one symbol that are not imported yet,
+// the imported trades are looked up by the id range of the given trades, so the files can be imported in any order.
+func (s *BacktestService) InsertNewAggTrades(ctx context.Context, trades []types.Trade) (int, error) {
+	if len(trades) == 0 {
+		return 0, nil
+	}
+
+	fromID, toID := trades[0].ID, trades[0].ID
+	for _, trade := range trades {
+		if trade.ID < fromID {
+			fromID = trade.ID
+		}
+		if trade.ID > toID {
+			toID = trade.ID
+		}
+	}
+
+	imported, err := s.QueryAggTradeIDs(ctx, trades[0].Exchange, trades[0].Symbol, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+
+	var newTrades []types.Trade
+	for _, trade := range trades {
+		if _, ok := imported[trade.ID]; ok {
+			continue
+		}
+
+		// mark it as imported in case the batch contains the duplicated trades
+		imported[trade.ID] = struct{}{}
+		newTrades = append(newTrades, trade)
+	}
+
+	if err := s.BatchInsertAggTrades(newTrades); err != nil {
+		return 0, err
+	}
+
+	return len(newTrades), nil
+}
+
+// BatchInsertAggTrades inserts the aggregate trades in one transaction
+func (s *BacktestService) BatchInsertAggTrades(trades []types.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	sql := "INSERT INTO `agg_trades` (`id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `side`, `is_buyer`, `is_maker`, `traded_at`)" +
+		" VALUES (:id, :exchange, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :traded_at)"
+
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.NamedExec(sql, trades); err != nil {
+		if e := tx.Rollback(); e != nil {
+			log.WithError(e).Errorf("can not rollback the aggregate trade insertion")
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// QueryAggTradesCh queries the aggregate trades of the symbols in the time range, the trades are sorted by the trade time
+func (s *BacktestService) QueryAggTradesCh(since, until time.Time, ex types.ExchangeName, symbols []string) (chan types.Trade, chan error) {
+	if len(symbols) == 0 {
+		return returnAggTradeError(errors.New("symbols is empty when querying aggregate trades"))
+	}
+
+	query, args, err := sqlx.In("SELECT `id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `side`, `is_buyer`, `is_maker`, `traded_at`"+
+		" FROM `agg_trades` WHERE `exchange` = ? AND `symbol` IN (?) AND `traded_at` BETWEEN ? AND ? ORDER BY `traded_at` ASC, `id` ASC",
+		ex.String(), symbols, since, until)
+	if err != nil {
+		return returnAggTradeError(err)
+	}
+
+	rows, err := s.DB.Queryx(s.DB.Rebind(query), args...)
+	if err != nil {
+		return returnAggTradeError(err)
+	}
+
+	ch := make(chan types.Trade, 1000)
+	errC := make(chan error, 1)
+	go func() {
+		defer close(errC)
+		defer close(ch)
+		defer rows.Close()
+
+		for rows.Next() {
+			var trade types.Trade
+			if err := rows.StructScan(&trade); err != nil {
+				errC <- err
+				return
+			}
+
+			ch <- trade
+		}
+
+		if err := rows.Err(); err != nil {
+			errC <- err
+		}
+	}()
+
+	return ch, errC
+}
+
+func returnAggTradeError(err error) (chan types.Trade, chan error) {
+	ch := make(chan types.Trade)
+	close(ch)
+	log.WithError(err).Error("aggregate trade query error")
+
+	errC := make(chan error, 1)
+	errC <- err
+	close(errC)
+	return ch, errC
+}
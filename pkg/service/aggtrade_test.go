@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBacktestService_AggTrades(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &BacktestService{DB: xdb}
+
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	newTrade := func(i int) types.Trade {
+		return types.Trade{
+			ID:            uint64(100 + i),
+			Exchange:      types.ExchangeBinance,
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			Price:         fixedpoint.NewFromInt(20000 + int64(i)),
+			Quantity:      fixedpoint.NewFromFloat(0.1),
+			QuoteQuantity: fixedpoint.NewFromFloat(2000.0),
+			IsBuyer:       true,
+			Time:          types.Time(t1.Add(time.Duration(i) * time.Second)),
+		}
+	}
+
+	// the newer trades are imported first
+	n, err := service.InsertNewAggTrades(ctx, []types.Trade{newTrade(1), newTrade(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// the older file overlaps the imported trades, only the older trade is inserted
+	n, err = service.InsertNewAggTrades(ctx, []types.Trade{newTrade(0), newTrade(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	ids, err := service.QueryAggTradeIDs(ctx, types.ExchangeBinance, "BTCUSDT", 0, 1000)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+
+	tradeC, errC := service.QueryAggTradesCh(t1.Add(time.Second), t1.Add(time.Minute), types.ExchangeBinance, []string{"BTCUSDT"})
+
+	var results []types.Trade
+	for trade := range tradeC {
+		results = append(results, trade)
+	}
+	assert.NoError(t, <-errC)
+
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, uint64(101), results[0].ID)
+		assert.Equal(t, "20001", results[0].Price.String())
+		assert.Equal(t, types.SideTypeBuy, results[0].Side)
+		assert.True(t, results[0].IsBuyer)
+	}
+}
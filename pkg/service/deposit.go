@@ -100,3 +100,19 @@ func SelectLastDeposits(ex types.ExchangeName, limit uint64) sq.SelectBuilder {
 		OrderBy("time DESC").
 		Limit(limit)
 }
+
+// QueryByTimeRange queries the deposits of all the exchanges in the time range
+func (s *DepositService) QueryByTimeRange(since, until time.Time) ([]types.Deposit, error) {
+	sql := "SELECT * FROM `deposits` WHERE `time` >= :since AND `time` <= :until ORDER BY `time` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
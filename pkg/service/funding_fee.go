@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type FundingFeeService struct {
+	DB *sqlx.DB
+}
+
+// Sync syncs the funding fees of the futures symbol, the exchange must be in the futures mode
+func (s *FundingFeeService) Sync(ctx context.Context, ex types.Exchange, symbol string, startTime time.Time) error {
+	api, ok := ex.(types.FundingFeeHistory)
+	if !ok {
+		return nil
+	}
+
+	futuresExchange, ok := ex.(types.FuturesExchange)
+	if !ok {
+		return nil
+	}
+
+	futuresSettings := futuresExchange.GetFuturesSettings()
+	if !futuresSettings.IsFutures {
+		return nil
+	}
+
+	tasks := []SyncTask{
+		{
+			Select: SelectLastFundingFees(ex.Name(), symbol, 100),
+			Type:   types.FundingFee{},
+			BatchQuery: func(ctx context.Context, startTime, endTime time.Time) (interface{}, chan error) {
+				query := &batch.FundingFeeBatchQuery{
+					FundingFeeHistory: api,
+				}
+				return query.Query(ctx, symbol, startTime, endTime)
+			},
+			Time: func(obj interface{}) time.Time {
+				return obj.(types.FundingFee).Time.Time()
+			},
+			ID: func(obj interface{}) string {
+				return strconv.FormatUint(obj.(types.FundingFee).TransactionID, 10)
+			},
+			LogInsert: true,
+		},
+	}
+
+	for _, sel := range tasks {
+		if err := sel.execute(ctx, s.DB, startTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func SelectLastFundingFees(ex types.ExchangeName, symbol string, limit uint64) sq.SelectBuilder {
+	return sq.Select("*").
+		From("funding_fees").
+		Where(sq.Eq{"exchange": ex, "symbol": symbol}).
+		OrderBy("time DESC").
+		Limit(limit)
+}
+
+// QueryByTimeRange queries the funding fees of all the exchanges in the time range
+func (s *FundingFeeService) QueryByTimeRange(since, until time.Time) ([]types.FundingFee, error) {
+	sql := "SELECT * FROM `funding_fees` WHERE `time` >= :since AND `time` <= :until ORDER BY `time` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var fees []types.FundingFee
+	for rows.Next() {
+		var fee types.FundingFee
+		if err := rows.StructScan(&fee); err != nil {
+			return fees, err
+		}
+
+		fees = append(fees, fee)
+	}
+
+	return fees, rows.Err()
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type fakeFundingFeeExchange struct {
+	types.Exchange
+	types.FuturesSettings
+
+	fees []types.FundingFee
+}
+
+func (e *fakeFundingFeeExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *fakeFundingFeeExchange) QueryFundingFeeHistory(ctx context.Context, symbol string, startTime, endTime *time.Time) ([]types.FundingFee, error) {
+	var fees []types.FundingFee
+	for _, fee := range e.fees {
+		if fee.Symbol == symbol && !fee.Time.Before(*startTime) && !fee.Time.After(*endTime) {
+			fees = append(fees, fee)
+		}
+	}
+
+	return fees, nil
+}
+
+func TestFundingFeeService(t *testing.T) {
+	db, err := prepareDB(t)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer db.Close()
+
+	now := time.Now().Truncate(time.Second)
+	ex := &fakeFundingFeeExchange{}
+	for i, amount := range []float64{-1.5, 0.5, -2.0} {
+		ex.fees = append(ex.fees, types.FundingFee{
+			Exchange:      types.ExchangeBinance,
+			Symbol:        "BTCUSDT",
+			Asset:         "USDT",
+			Amount:        fixedpoint.NewFromFloat(amount),
+			TransactionID: uint64(1000 + i),
+			Time:          types.Time(now.Add(time.Duration(i-3) * 8 * time.Hour)),
+		})
+	}
+
+	ctx := context.Background()
+	service := &FundingFeeService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	// the exchange is not in the futures mode
+	assert.NoError(t, service.Sync(ctx, ex, "BTCUSDT", now.Add(-2*24*time.Hour)))
+	fees, err := service.QueryByTimeRange(now.Add(-2*24*time.Hour), now)
+	assert.NoError(t, err)
+	assert.Empty(t, fees)
+
+	// sync twice, the synced records are not inserted again
+	ex.UseFutures()
+	assert.NoError(t, service.Sync(ctx, ex, "BTCUSDT", now.Add(-2*24*time.Hour)))
+	assert.NoError(t, service.Sync(ctx, ex, "BTCUSDT", now.Add(-2*24*time.Hour)))
+
+	fees, err = service.QueryByTimeRange(now.Add(-2*24*time.Hour), now)
+	if assert.NoError(t, err) && assert.Len(t, fees, 3) {
+		assert.Equal(t, uint64(1000), fees[0].TransactionID)
+		assert.InDelta(t, -1.5, fees[0].Amount.Float64(), 1e-8)
+		assert.Equal(t, "USDT", fees[2].Asset)
+	}
+}
@@ -145,3 +145,29 @@ func SelectLastMarginLiquidations(ex types.ExchangeName, limit uint64) sq.Select
 		OrderBy("time DESC").
 		Limit(limit)
 }
+
+// QueryInterestsByTimeRange queries the margin interests of all the exchanges in the time range
+func (s *MarginService) QueryInterestsByTimeRange(since, until time.Time) ([]types.MarginInterest, error) {
+	sql := "SELECT * FROM `margin_interests` WHERE `time` >= :since AND `time` <= :until ORDER BY `time` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var interests []types.MarginInterest
+	for rows.Next() {
+		var interest types.MarginInterest
+		if err := rows.StructScan(&interest); err != nil {
+			return interests, err
+		}
+
+		interests = append(interests, interest)
+	}
+
+	return interests, rows.Err()
+}
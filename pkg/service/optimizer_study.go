@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+var ErrOptimizerStudyNotFound = errors.New("optimizer study not found")
+
+// OptimizerStudy is the persisted hyperparameter optimization study
+type OptimizerStudy struct {
+	GID  int64  `json:"gid" db:"gid"`
+	Name string `json:"name" db:"name"`
+
+	// Config is the optimizer config in JSON
+	Config string `json:"config" db:"config"`
+
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// OptimizerStudySummary is the study with the trial statistics
+type OptimizerStudySummary struct {
+	OptimizerStudy
+
+	NumOfTrials         int             `json:"numOfTrials" db:"num_of_trials"`
+	NumOfCompleteTrials int             `json:"numOfCompleteTrials" db:"num_of_complete_trials"`
+	BestValue           sql.NullFloat64 `json:"bestValue" db:"best_value"`
+}
+
+// OptimizerTrial is the finished trial of the study, the JSON fields are encoded by the optimizer
+type OptimizerTrial struct {
+	GID     int64   `json:"gid" db:"gid"`
+	StudyID int64   `json:"studyID" db:"study_id"`
+	Number  int     `json:"number" db:"number"`
+	State   string  `json:"state" db:"state"`
+	Value   float64 `json:"value" db:"value"`
+
+	Params         string `json:"params" db:"params"`
+	InternalParams string `json:"internalParams" db:"internal_params"`
+	Distributions  string `json:"distributions" db:"distributions"`
+	UserAttrs      string `json:"userAttrs" db:"user_attrs"`
+
+	StartedAt   time.Time `json:"startedAt" db:"started_at"`
+	CompletedAt time.Time `json:"completedAt" db:"completed_at"`
+}
+
+type OptimizerStudyService struct {
+	DB *sqlx.DB
+}
+
+func NewOptimizerStudyService(db *sqlx.DB) *OptimizerStudyService {
+	return &OptimizerStudyService{DB: db}
+}
+
+// FindStudy finds the study by the name, ErrOptimizerStudyNotFound is returned if the study does not exist
+func (s *OptimizerStudyService) FindStudy(ctx context.Context, name string) (*OptimizerStudy, error) {
+	var study OptimizerStudy
+	err := s.DB.GetContext(ctx, &study, s.DB.Rebind("SELECT * FROM `optimizer_studies` WHERE `name` = ?"), name)
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(ErrOptimizerStudyNotFound, "study %s", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &study, nil
+}
+
+// InsertStudy inserts the study, the GID of the study is updated
+func (s *OptimizerStudyService) InsertStudy(ctx context.Context, study *OptimizerStudy) error {
+	now := time.Now()
+	study.CreatedAt = now
+	study.UpdatedAt = now
+
+	result, err := s.DB.NamedExecContext(ctx,
+		"INSERT INTO `optimizer_studies` (`name`, `config`, `created_at`, `updated_at`) VALUES (:name, :config, :created_at, :updated_at)",
+		study)
+	if err != nil {
+		return err
+	}
+
+	study.GID, err = result.LastInsertId()
+	return err
+}
+
+// DeleteStudy deletes the study and its trials
+func (s *OptimizerStudyService) DeleteStudy(ctx context.Context, studyID int64) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM `optimizer_trials` WHERE `study_id` = ?"), studyID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM `optimizer_studies` WHERE `gid` = ?"), studyID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// QueryStudySummaries returns all the studies with the trial statistics, the latest updated study comes first
+func (s *OptimizerStudyService) QueryStudySummaries(ctx context.Context) ([]OptimizerStudySummary, error) {
+	sel := sq.Select(
+		"s.*",
+		"COUNT(t.gid) AS num_of_trials",
+		"SUM(CASE WHEN t.state = 'Complete' THEN 1 ELSE 0 END) AS num_of_complete_trials",
+		"MAX(CASE WHEN t.state = 'Complete' THEN t.value ELSE NULL END) AS best_value",
+	).
+		From("optimizer_studies s").
+		LeftJoin("optimizer_trials t ON t.study_id = s.gid").
+		GroupBy("s.gid", "s.name", "s.config", "s.created_at", "s.updated_at").
+		OrderBy("s.updated_at DESC")
+
+	query, args, err := sel.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var summaries []OptimizerStudySummary
+	for rows.Next() {
+		var summary OptimizerStudySummary
+		var numOfCompleteTrials sql.NullInt64
+		if err := rows.Scan(
+			&summary.GID, &summary.Name, &summary.Config, &summary.CreatedAt, &summary.UpdatedAt,
+			&summary.NumOfTrials, &numOfCompleteTrials, &summary.BestValue); err != nil {
+			return nil, err
+		}
+
+		summary.NumOfCompleteTrials = int(numOfCompleteTrials.Int64)
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// InsertTrial inserts the finished trial, and touches the updated time of the study
+func (s *OptimizerStudyService) InsertTrial(ctx context.Context, trial *OptimizerTrial) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.NamedExecContext(ctx,
+		"INSERT INTO `optimizer_trials` (`study_id`, `number`, `state`, `value`, `params`, `internal_params`, `distributions`, `user_attrs`, `started_at`, `completed_at`)"+
+			" VALUES (:study_id, :number, :state, :value, :params, :internal_params, :distributions, :user_attrs, :started_at, :completed_at)",
+		trial)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind("UPDATE `optimizer_studies` SET `updated_at` = ? WHERE `gid` = ?"), time.Now(), trial.StudyID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	trial.GID, err = result.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// QueryTrials returns the finished trials of the study ordered by the trial number
+func (s *OptimizerStudyService) QueryTrials(ctx context.Context, studyID int64) ([]OptimizerTrial, error) {
+	var trials []OptimizerTrial
+	err := s.DB.SelectContext(ctx, &trials,
+		s.DB.Rebind("SELECT * FROM `optimizer_trials` WHERE `study_id` = ? ORDER BY `number` ASC"), studyID)
+	return trials, err
+}
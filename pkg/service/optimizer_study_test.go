@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizerStudyService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := NewOptimizerStudyService(xdb)
+
+	_, err = service.FindStudy(ctx, "test")
+	assert.True(t, errors.Is(err, ErrOptimizerStudyNotFound))
+
+	study := &OptimizerStudy{Name: "test", Config: "{}"}
+	if !assert.NoError(t, service.InsertStudy(ctx, study)) {
+		return
+	}
+	assert.NotZero(t, study.GID)
+	assert.Error(t, service.InsertStudy(ctx, &OptimizerStudy{Name: "test", Config: "{}"}), "the study name should be unique")
+
+	startedAt := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, state := range []string{"Complete", "Pruned", "Complete"} {
+		err := service.InsertTrial(ctx, &OptimizerTrial{
+			StudyID:        study.GID,
+			Number:         i,
+			State:          state,
+			Value:          float64(i * 10),
+			Params:         `{"x":1}`,
+			InternalParams: `{"x":1}`,
+			Distributions:  `{}`,
+			UserAttrs:      `{}`,
+			StartedAt:      startedAt,
+			CompletedAt:    startedAt.Add(time.Minute),
+		})
+		assert.NoError(t, err)
+	}
+
+	found, err := service.FindStudy(ctx, "test")
+	if assert.NoError(t, err) {
+		assert.Equal(t, study.GID, found.GID)
+	}
+
+	trials, err := service.QueryTrials(ctx, study.GID)
+	if assert.NoError(t, err) && assert.Len(t, trials, 3) {
+		assert.Equal(t, "Pruned", trials[1].State)
+		assert.Equal(t, 20.0, trials[2].Value)
+		assert.Equal(t, startedAt, trials[0].StartedAt.UTC())
+	}
+
+	summaries, err := service.QueryStudySummaries(ctx)
+	if assert.NoError(t, err) && assert.Len(t, summaries, 1) {
+		assert.Equal(t, "test", summaries[0].Name)
+		assert.Equal(t, 3, summaries[0].NumOfTrials)
+		assert.Equal(t, 2, summaries[0].NumOfCompleteTrials)
+		assert.Equal(t, 20.0, summaries[0].BestValue.Float64)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -104,3 +105,38 @@ func (s *ProfitService) Insert(profit types.Profit) error {
 		profit)
 	return err
 }
+
+// QueryByTimeRange queries the profits of all the strategies in the time range
+func (s *ProfitService) QueryByTimeRange(since, until time.Time) ([]types.Profit, error) {
+	sql := "SELECT `strategy`, `strategy_instance_id`, `symbol`, `quote_currency`, `base_currency`, `average_cost`, " +
+		"`profit`, `net_profit`, `profit_margin`, `net_profit_margin`, `exchange`, `is_futures`, `is_margin`, `is_isolated`, " +
+		"`trade_id`, `side`, `is_buyer`, `is_maker`, `price`, `quantity`, `quote_quantity`, `traded_at`, COALESCE(`fee_in_usd`, 0) AS `fee_in_usd`, `fee`, `fee_currency` " +
+		"FROM `profits` WHERE `traded_at` >= :since AND `traded_at` <= :until ORDER BY `traded_at` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var profits []types.Profit
+	for rows.Next() {
+		// the traded_at column of sqlite is scanned as string, which is supported by types.Time
+		var row struct {
+			types.Profit
+			TradedAt types.Time `db:"traded_at"`
+		}
+
+		if err := rows.StructScan(&row); err != nil {
+			return profits, err
+		}
+
+		row.Profit.TradedAt = row.TradedAt.Time()
+		profits = append(profits, row.Profit)
+	}
+
+	return profits, rows.Err()
+}
@@ -38,4 +38,10 @@ func TestProfitService(t *testing.T) {
 		TradedAt:      time.Now(),
 	})
 	assert.NoError(t, err)
+
+	profits, err := service.QueryByTimeRange(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, profits, 1) {
+		assert.Equal(t, "BTCUSDT", profits[0].Symbol)
+		assert.Equal(t, "0.98", profits[0].NetProfit.String())
+	}
 }
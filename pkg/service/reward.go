@@ -197,3 +197,18 @@ func SelectLastRewards(ex types.ExchangeName, limit uint64) sq.SelectBuilder {
 		OrderBy("created_at DESC").
 		Limit(limit)
 }
+
+// QueryByTimeRange queries the rewards of all the exchanges in the time range, including the spent rewards
+func (s *RewardService) QueryByTimeRange(ctx context.Context, since, until time.Time) ([]types.Reward, error) {
+	sql := "SELECT * FROM rewards WHERE created_at >= :since AND created_at <= :until ORDER BY created_at ASC"
+	rows, err := s.DB.NamedQueryContext(ctx, sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	return s.scanRows(rows)
+}
@@ -22,6 +22,8 @@ type SyncService struct {
 	WithdrawService *WithdrawService
 	DepositService  *DepositService
 	MarginService   *MarginService
+
+	FundingFeeService *FundingFeeService
 }
 
 // SyncSessionSymbols syncs the trades from the given exchange session
@@ -75,6 +77,30 @@ func (s *SyncService) SyncMarginHistory(ctx context.Context, exchange types.Exch
 	return nil
 }
 
+func (s *SyncService) SyncFundingFeeHistory(ctx context.Context, exchange types.Exchange, startTime time.Time, symbols ...string) error {
+	if _, implemented := exchange.(types.FundingFeeHistory); !implemented {
+		log.Debugf("exchange %T does not support types.FundingFeeHistory", exchange)
+		return nil
+	}
+
+	if futuresExchange, implemented := exchange.(types.FuturesExchange); !implemented {
+		log.Debugf("exchange %T does not implement types.FuturesExchange", exchange)
+		return nil
+	} else if !futuresExchange.GetFuturesSettings().IsFutures {
+		log.Debugf("exchange %T is not using futures", exchange)
+		return nil
+	}
+
+	log.Infof("syncing %s funding fee history: %v...", exchange.Name(), symbols)
+	for _, symbol := range symbols {
+		if err := s.FundingFeeService.Sync(ctx, exchange, symbol, startTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *SyncService) SyncRewardHistory(ctx context.Context, exchange types.Exchange, startTime time.Time) error {
 	if _, implemented := exchange.(types.ExchangeRewardService); !implemented {
 		return nil
@@ -413,3 +413,18 @@ func SelectLastTrades(ex types.ExchangeName, symbol string, isMargin, isFutures,
 		Limit(limit)
 }
 
+// QueryByTimeRange queries the trades of all the exchanges and the symbols in the time range
+func (s *TradeService) QueryByTimeRange(since, until time.Time) ([]types.Trade, error) {
+	sql := "SELECT * FROM `trades` WHERE `traded_at` >= :since AND `traded_at` <= :until ORDER BY `traded_at` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
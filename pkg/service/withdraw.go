@@ -129,3 +129,19 @@ func (s *WithdrawService) Insert(withdrawal types.Withdraw) error {
 	_, err := s.DB.NamedExec(sql, withdrawal)
 	return err
 }
+
+// QueryByTimeRange queries the withdrawals of all the exchanges in the time range
+func (s *WithdrawService) QueryByTimeRange(since, until time.Time) ([]types.Withdraw, error) {
+	sql := "SELECT * FROM `withdraws` WHERE `time` >= :since AND `time` <= :until ORDER BY `time` ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
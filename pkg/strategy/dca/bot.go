@@ -0,0 +1,561 @@
+package dca
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BotConfig is the config of the DCA bot mode
+type BotConfig struct {
+	// BaseOrderAmount is the quote amount of the market order starting the cycle
+	BaseOrderAmount fixedpoint.Value `json:"baseOrderAmount"`
+
+	// SafetyOrderAmount is the quote amount of the first safety order
+	SafetyOrderAmount fixedpoint.Value `json:"safetyOrderAmount"`
+
+	// MaxSafetyOrders is the number of the safety orders of each cycle
+	MaxSafetyOrders int `json:"maxSafetyOrders"`
+
+	// PriceDeviation is the price deviation of the first safety order from the entry price, e.g., 1%
+	PriceDeviation fixedpoint.Value `json:"priceDeviation"`
+
+	// SafetyOrderVolumeScale multiplies the amount of each safety order from the previous one, defaults to 1
+	SafetyOrderVolumeScale fixedpoint.Value `json:"safetyOrderVolumeScale"`
+
+	// SafetyOrderStepScale multiplies the price deviation step of each safety order from the previous one, defaults to 1
+	SafetyOrderStepScale fixedpoint.Value `json:"safetyOrderStepScale"`
+
+	// TakeProfitRatio is the profit ratio of the take-profit order to the average cost, e.g., 1.5%
+	TakeProfitRatio fixedpoint.Value `json:"takeProfitRatio"`
+
+	// MaxActiveCycles is the max number of the cycles running at the same time, defaults to 1
+	MaxActiveCycles int `json:"maxActiveCycles"`
+
+	// MaxCycles stops starting new cycles after the number of the completed cycles, 0 means unlimited
+	MaxCycles int `json:"maxCycles"`
+
+	// CycleCoolDown is the min period between starting two cycles
+	CycleCoolDown types.Duration `json:"cycleCoolDown"`
+
+	// Interval is the interval of the k-lines used as the price of starting the cycles, defaults to 1m
+	Interval types.Interval `json:"interval"`
+}
+
+func (c *BotConfig) Defaults() {
+	if c.SafetyOrderVolumeScale.IsZero() {
+		c.SafetyOrderVolumeScale = fixedpoint.One
+	}
+
+	if c.SafetyOrderStepScale.IsZero() {
+		c.SafetyOrderStepScale = fixedpoint.One
+	}
+
+	if c.MaxActiveCycles == 0 {
+		c.MaxActiveCycles = 1
+	}
+
+	if c.Interval == "" {
+		c.Interval = types.Interval1m
+	}
+}
+
+func (c *BotConfig) Validate() error {
+	if c.BaseOrderAmount.Sign() <= 0 {
+		return fmt.Errorf("bot.baseOrderAmount should be positive")
+	}
+
+	if c.TakeProfitRatio.Sign() <= 0 {
+		return fmt.Errorf("bot.takeProfitRatio should be positive")
+	}
+
+	if c.MaxSafetyOrders > 0 {
+		if c.SafetyOrderAmount.Sign() <= 0 {
+			return fmt.Errorf("bot.safetyOrderAmount should be positive")
+		}
+
+		if c.PriceDeviation.Sign() <= 0 {
+			return fmt.Errorf("bot.priceDeviation should be positive")
+		}
+	}
+
+	return nil
+}
+
+// runBot binds the bot mode handlers, the cycles are updated in a single goroutine since the graceful cancel
+// waits for the order updates from the user data stream.
+func (s *Strategy) runBot(ctx context.Context, session *bbgo.ExchangeSession) error {
+	if s.BotState == nil {
+		s.BotState = &BotState{NextCycleID: 1}
+	}
+
+	s.botUpdateC = make(chan struct{}, 1)
+	s.pendingTrades = make(map[uint64][]types.Trade)
+
+	s.orderExecutor.TradeCollector().OnTrade(func(trade types.Trade, _, _ fixedpoint.Value) {
+		s.handleBotTrade(ctx, trade)
+	})
+
+	session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+		if order.Symbol != s.Symbol {
+			return
+		}
+
+		switch order.Status {
+		case types.OrderStatusCanceled, types.OrderStatusRejected:
+			s.botMu.Lock()
+			if _, o := s.BotState.FindOrder(order.OrderID); o != nil {
+				o.Closed = true
+			}
+			s.botMu.Unlock()
+		}
+	})
+
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != s.Bot.Interval {
+			return
+		}
+
+		s.botMu.Lock()
+		s.lastPrice = kline.Close
+		s.lastKLineTime = kline.EndTime.Time()
+		s.botMu.Unlock()
+		s.triggerBotUpdate(ctx)
+	})
+
+	if bbgo.IsBackTesting {
+		s.botRecovered = true
+	} else {
+		session.UserDataStream.OnStart(func() {
+			if err := s.recoverCycles(ctx); err != nil {
+				log.WithError(err).Errorf("unable to recover the dca cycles")
+			}
+
+			s.botMu.Lock()
+			s.botRecovered = true
+			s.botMu.Unlock()
+			s.triggerBotUpdate(ctx)
+		})
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-s.botUpdateC:
+					s.updateCycles(ctx)
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *Strategy) triggerBotUpdate(ctx context.Context) {
+	if bbgo.IsBackTesting {
+		s.updateCycles(ctx)
+		return
+	}
+
+	select {
+	case s.botUpdateC <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Strategy) handleBotTrade(ctx context.Context, trade types.Trade) {
+	s.botMu.Lock()
+	cycle, order := s.BotState.FindOrder(trade.OrderID)
+	if cycle == nil {
+		// the trade could be received before the submitted order is added to the cycle
+		s.pendingTrades[trade.OrderID] = append(s.pendingTrades[trade.OrderID], trade)
+		s.botMu.Unlock()
+		return
+	}
+
+	cycle.AddTrade(order, trade, s.Market)
+	s.botMu.Unlock()
+
+	bbgo.Sync(ctx, s)
+	s.triggerBotUpdate(ctx)
+}
+
+// updateCycles drives the cycles by their states, the re-entrant calls from the trades of the submitted orders
+// (in back-testing) are deferred to the next round of the loop.
+func (s *Strategy) updateCycles(ctx context.Context) {
+	s.botMu.Lock()
+	if s.botUpdating {
+		s.botDirty = true
+		s.botMu.Unlock()
+		return
+	}
+	s.botUpdating = true
+	s.botMu.Unlock()
+
+	for {
+		s.processCycles(ctx)
+
+		s.botMu.Lock()
+		if !s.botDirty {
+			s.botUpdating = false
+			s.botMu.Unlock()
+			return
+		}
+
+		s.botDirty = false
+		s.botMu.Unlock()
+	}
+}
+
+func (s *Strategy) processCycles(ctx context.Context) {
+	s.botMu.Lock()
+	// the persisted cycles are not processed before their orders are recovered
+	if !s.botRecovered {
+		s.botMu.Unlock()
+		return
+	}
+
+	cycles := append([]*Cycle(nil), s.BotState.Cycles...)
+	s.botMu.Unlock()
+
+	for _, cycle := range cycles {
+		s.botMu.Lock()
+		takeProfitFilled := cycle.IsTakeProfitFilled()
+		placeSafetyOrders := cycle.IsBaseOrderFilled() && !cycle.SafetyOrdersPlaced
+		takeProfitOutdated := cycle.TakeProfitOutdated
+		s.botMu.Unlock()
+
+		switch {
+		case takeProfitFilled:
+			s.completeCycle(ctx, cycle)
+
+		case placeSafetyOrders:
+			s.placeSafetyOrders(ctx, cycle)
+			s.placeTakeProfitOrder(ctx, cycle)
+
+		case takeProfitOutdated:
+			s.placeTakeProfitOrder(ctx, cycle)
+		}
+	}
+
+	if price, ok := s.shouldStartCycle(); ok {
+		s.startCycle(ctx, price)
+	}
+}
+
+func (s *Strategy) shouldStartCycle() (fixedpoint.Value, bool) {
+	s.botMu.Lock()
+	defer s.botMu.Unlock()
+
+	if s.lastPrice.IsZero() || s.Status == types.StrategyStatusStopped {
+		return fixedpoint.Zero, false
+	}
+
+	if len(s.BotState.Cycles) >= s.Bot.MaxActiveCycles {
+		return fixedpoint.Zero, false
+	}
+
+	if s.Bot.MaxCycles > 0 && s.BotState.CompletedCycles+len(s.BotState.Cycles) >= s.Bot.MaxCycles {
+		return fixedpoint.Zero, false
+	}
+
+	if s.bbgoTime().Sub(s.BotState.LastCycleStartTime) < s.Bot.CycleCoolDown.Duration() {
+		return fixedpoint.Zero, false
+	}
+
+	if balance, ok := s.session.GetAccount().Balance(s.Market.QuoteCurrency); !ok || balance.Available.Compare(s.Bot.RequiredAmount()) < 0 {
+		if !s.insufficientBalanceLogged {
+			log.Warnf("insufficient %s balance to start a new cycle, required %s", s.Market.QuoteCurrency, s.Bot.RequiredAmount().String())
+			s.insufficientBalanceLogged = true
+		}
+
+		return fixedpoint.Zero, false
+	}
+
+	s.insufficientBalanceLogged = false
+	return s.lastPrice, true
+}
+
+// bbgoTime returns the time of the last k-line in back-testing, so that the cool down works with the simulated time
+func (s *Strategy) bbgoTime() time.Time {
+	if bbgo.IsBackTesting {
+		return s.lastKLineTime
+	}
+
+	return time.Now()
+}
+
+func (s *Strategy) startCycle(ctx context.Context, price fixedpoint.Value) {
+	s.botMu.Lock()
+	cycle := &Cycle{
+		ID:        s.BotState.NextCycleID,
+		StartedAt: s.bbgoTime(),
+	}
+	s.BotState.NextCycleID++
+	s.BotState.LastCycleStartTime = cycle.StartedAt
+	s.BotState.Cycles = append(s.BotState.Cycles, cycle)
+	s.botMu.Unlock()
+
+	bbgo.Notify("%s: starting %s %s at price %s", ID, s.Symbol, cycle, price.String())
+
+	quantity := s.Market.TruncateQuantity(s.Bot.BaseOrderAmount.Div(price))
+	if !s.submitCycleOrder(ctx, cycle, CycleOrderTypeBase, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Tag:      "dcaBase",
+	}) {
+		s.botMu.Lock()
+		s.BotState.RemoveCycle(cycle)
+		s.botMu.Unlock()
+	}
+
+	bbgo.Sync(ctx, s)
+}
+
+func (s *Strategy) placeSafetyOrders(ctx context.Context, cycle *Cycle) {
+	s.botMu.Lock()
+	cycle.SafetyOrdersPlaced = true
+	entryPrice := cycle.EntryPrice
+	s.botMu.Unlock()
+
+	for i, level := range s.Bot.SafetyOrderLevels(entryPrice) {
+		s.submitCycleOrder(ctx, cycle, CycleOrderTypeSafety, types.SubmitOrder{
+			Symbol:   s.Symbol,
+			Market:   s.Market,
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Price:    level.Price,
+			Quantity: s.Market.TruncateQuantity(level.Amount.Div(level.Price)),
+			Tag:      "dcaSafety" + strconv.Itoa(i+1),
+		})
+	}
+
+	bbgo.Sync(ctx, s)
+}
+
+// placeTakeProfitOrder places the take-profit order of the cycle position, the previous take-profit order is canceled
+func (s *Strategy) placeTakeProfitOrder(ctx context.Context, cycle *Cycle) {
+	if err := s.cancelCycleOrders(ctx, cycle, CycleOrderTypeTakeProfit); err != nil {
+		log.WithError(err).Errorf("unable to cancel the take-profit order of %s", cycle)
+		return
+	}
+
+	s.botMu.Lock()
+	cycle.TakeProfitOutdated = false
+	price := cycle.AverageCost().Mul(fixedpoint.One.Add(s.Bot.TakeProfitRatio))
+	quantity := s.Market.TruncateQuantity(cycle.Quantity)
+	s.botMu.Unlock()
+
+	if s.Market.IsDustQuantity(quantity, price) {
+		return
+	}
+
+	s.submitCycleOrder(ctx, cycle, CycleOrderTypeTakeProfit, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Price:    price,
+		Quantity: quantity,
+		Tag:      "dcaTakeProfit",
+	})
+	bbgo.Sync(ctx, s)
+}
+
+func (s *Strategy) completeCycle(ctx context.Context, cycle *Cycle) {
+	if err := s.cancelCycleOrders(ctx, cycle, CycleOrderTypeSafety); err != nil {
+		log.WithError(err).Errorf("unable to cancel the safety orders of %s", cycle)
+	}
+
+	s.botMu.Lock()
+	profit := cycle.Profit()
+	s.BotState.RemoveCycle(cycle)
+	s.BotState.CompletedCycles++
+	s.BotState.AccumulatedProfit = s.BotState.AccumulatedProfit.Add(profit)
+	completed := s.BotState.CompletedCycles
+	accumulatedProfit := s.BotState.AccumulatedProfit
+	s.botMu.Unlock()
+
+	bbgo.Notify("%s: %s %s completed, profit %s %s, %d cycles completed, accumulated profit %s %s",
+		ID, s.Symbol, cycle, profit.String(), s.Market.QuoteCurrency, completed, accumulatedProfit.String(), s.Market.QuoteCurrency)
+	bbgo.Sync(ctx, s)
+}
+
+// submitCycleOrder submits the order and adds it to the cycle, false is returned if the order is not created
+func (s *Strategy) submitCycleOrder(ctx context.Context, cycle *Cycle, orderType CycleOrderType, submitOrder types.SubmitOrder) bool {
+	createdOrders, err := s.orderExecutor.SubmitOrders(ctx, submitOrder)
+	if err != nil || len(createdOrders) == 0 {
+		log.WithError(err).Errorf("unable to submit the %s order of %s", orderType, cycle)
+		return false
+	}
+
+	created := createdOrders[0]
+
+	s.botMu.Lock()
+	order := &CycleOrder{
+		OrderID:   created.OrderID,
+		OrderUUID: created.UUID,
+		Type:      orderType,
+		Side:      created.Side,
+		Price:     created.Price,
+		Quantity:  created.Quantity,
+	}
+	cycle.Orders = append(cycle.Orders, order)
+
+	trades := s.pendingTrades[created.OrderID]
+	delete(s.pendingTrades, created.OrderID)
+	for _, trade := range trades {
+		cycle.AddTrade(order, trade, s.Market)
+	}
+	s.botMu.Unlock()
+
+	if len(trades) > 0 {
+		s.triggerBotUpdate(ctx)
+	}
+
+	return true
+}
+
+func (s *Strategy) cancelCycleOrders(ctx context.Context, cycle *Cycle, orderType CycleOrderType) error {
+	s.botMu.Lock()
+	var orders []types.Order
+	for _, o := range cycle.OpenOrders(orderType) {
+		if order, ok := s.orderExecutor.OrderStore().Get(o.OrderID); ok {
+			orders = append(orders, order)
+		}
+	}
+	s.botMu.Unlock()
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	if err := s.orderExecutor.GracefulCancel(ctx, orders...); err != nil {
+		return err
+	}
+
+	s.botMu.Lock()
+	for _, o := range cycle.OpenOrders(orderType) {
+		o.Closed = true
+	}
+	s.botMu.Unlock()
+	return nil
+}
+
+// recoverCycles resumes the cycles from the persisted state: the open orders are added back to the order executor,
+// and the fills during the downtime are accounted at the order prices.
+func (s *Strategy) recoverCycles(ctx context.Context) error {
+	s.botMu.Lock()
+	numCycles := len(s.BotState.Cycles)
+	s.botMu.Unlock()
+
+	if numCycles == 0 {
+		return nil
+	}
+
+	openOrders, err := s.session.Exchange.QueryOpenOrders(ctx, s.Symbol)
+	if err != nil {
+		return err
+	}
+
+	openOrderMap := make(map[uint64]types.Order, len(openOrders))
+	for _, o := range openOrders {
+		openOrderMap[o.OrderID] = o
+	}
+
+	queryService, _ := s.session.Exchange.(types.ExchangeOrderQueryService)
+
+	s.botMu.Lock()
+	defer s.botMu.Unlock()
+
+	for _, cycle := range s.BotState.Cycles {
+		for _, o := range cycle.Orders {
+			if o.Closed {
+				continue
+			}
+
+			order, ok := openOrderMap[o.OrderID]
+			if ok {
+				s.orderExecutor.OrderStore().Add(order)
+				s.orderExecutor.ActiveMakerOrders().Add(order)
+			} else if queryService != nil {
+				queried, err := queryService.QueryOrder(ctx, types.OrderQuery{
+					Symbol:    s.Symbol,
+					OrderID:   strconv.FormatUint(o.OrderID, 10),
+					OrderUUID: o.OrderUUID,
+				})
+				if err != nil {
+					log.WithError(err).Errorf("unable to query the order %d of %s", o.OrderID, cycle)
+					continue
+				}
+
+				order = *queried
+				o.Closed = true
+			} else {
+				continue
+			}
+
+			delta := order.ExecutedQuantity.Sub(o.ExecutedQuantity)
+			if delta.Sign() <= 0 {
+				continue
+			}
+
+			price := o.Price
+			if price.IsZero() && queryService != nil {
+				// the market order price is averaged from the order trades
+				price = s.queryAveragePrice(ctx, queryService, o)
+			}
+
+			if price.IsZero() {
+				log.Errorf("unable to recover %s order %d fill %s, unknown price", cycle, o.OrderID, delta.String())
+				continue
+			}
+
+			log.Infof("recovering %s order %d fill %s at %s", cycle, o.OrderID, delta.String(), price.String())
+			cycle.AddTrade(o, types.Trade{
+				OrderID:  o.OrderID,
+				Side:     o.Side,
+				Price:    price,
+				Quantity: delta,
+			}, s.Market)
+		}
+
+		log.Infof("recovered %s: quantity %s, average cost %s", cycle, cycle.Quantity.String(), cycle.AverageCost().String())
+	}
+
+	return nil
+}
+
+func (s *Strategy) queryAveragePrice(ctx context.Context, queryService types.ExchangeOrderQueryService, o *CycleOrder) fixedpoint.Value {
+	trades, err := queryService.QueryOrderTrades(ctx, types.OrderQuery{
+		Symbol:    s.Symbol,
+		OrderID:   strconv.FormatUint(o.OrderID, 10),
+		OrderUUID: o.OrderUUID,
+	})
+	if err != nil {
+		log.WithError(err).Errorf("unable to query the trades of the order %d", o.OrderID)
+		return fixedpoint.Zero
+	}
+
+	quantity := fixedpoint.Zero
+	quoteQuantity := fixedpoint.Zero
+	for _, trade := range trades {
+		quantity = quantity.Add(trade.Quantity)
+		quoteQuantity = quoteQuantity.Add(trade.Price.Mul(trade.Quantity))
+	}
+
+	if quantity.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	return quoteQuantity.Div(quantity)
+}
@@ -0,0 +1,239 @@
+package dca
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type CycleOrderType string
+
+const (
+	CycleOrderTypeBase       CycleOrderType = "base"
+	CycleOrderTypeSafety     CycleOrderType = "safety"
+	CycleOrderTypeTakeProfit CycleOrderType = "takeProfit"
+)
+
+// CycleOrder is the order submitted by the cycle, the executed quantity is accounted from the trades
+type CycleOrder struct {
+	OrderID uint64 `json:"orderID"`
+
+	// OrderUUID is set when the exchange identifies the orders by the UUID, it's used for querying the order
+	OrderUUID string `json:"orderUUID,omitempty"`
+
+	Type             CycleOrderType   `json:"type"`
+	Side             types.SideType   `json:"side"`
+	Price            fixedpoint.Value `json:"price"`
+	Quantity         fixedpoint.Value `json:"quantity"`
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity"`
+
+	// Closed is set when the order is filled, canceled or rejected
+	Closed bool `json:"closed"`
+}
+
+func (o *CycleOrder) IsFilled() bool {
+	return o.ExecutedQuantity.Compare(o.Quantity) >= 0
+}
+
+// Cycle is one round of the DCA bot: the base order, the safety orders averaging down the cost,
+// and the take-profit order selling the position at the take-profit ratio of the average cost.
+type Cycle struct {
+	ID        int       `json:"id"`
+	StartedAt time.Time `json:"startedAt"`
+
+	Orders []*CycleOrder `json:"orders"`
+
+	// EntryPrice is the average price of the base order, the safety order prices are calculated from it
+	EntryPrice fixedpoint.Value `json:"entryPrice"`
+
+	// BoughtQuantity and Cost are the base quantity bought and the quote cost of the cycle
+	BoughtQuantity fixedpoint.Value `json:"boughtQuantity"`
+	Cost           fixedpoint.Value `json:"cost"`
+
+	// Quantity is the base quantity held by the cycle
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Proceeds is the quote amount received from selling
+	Proceeds fixedpoint.Value `json:"proceeds"`
+
+	SafetyOrdersPlaced bool `json:"safetyOrdersPlaced"`
+
+	// TakeProfitOutdated is set when the average cost is changed by the safety orders
+	TakeProfitOutdated bool `json:"takeProfitOutdated"`
+}
+
+func (c *Cycle) String() string {
+	return fmt.Sprintf("cycle #%d", c.ID)
+}
+
+func (c *Cycle) FindOrder(orderID uint64) *CycleOrder {
+	for _, o := range c.Orders {
+		if o.OrderID == orderID {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// Order returns the last order of the given type
+func (c *Cycle) Order(orderType CycleOrderType) *CycleOrder {
+	for i := len(c.Orders) - 1; i >= 0; i-- {
+		if c.Orders[i].Type == orderType {
+			return c.Orders[i]
+		}
+	}
+
+	return nil
+}
+
+// OpenOrders returns the orders that are not closed
+func (c *Cycle) OpenOrders(orderType CycleOrderType) (orders []*CycleOrder) {
+	for _, o := range c.Orders {
+		if o.Type == orderType && !o.Closed {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders
+}
+
+func (c *Cycle) AverageCost() fixedpoint.Value {
+	if c.BoughtQuantity.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	return c.Cost.Div(c.BoughtQuantity)
+}
+
+func (c *Cycle) Profit() fixedpoint.Value {
+	return c.Proceeds.Sub(c.Cost)
+}
+
+func (c *Cycle) IsBaseOrderFilled() bool {
+	o := c.Order(CycleOrderTypeBase)
+	return o != nil && o.IsFilled()
+}
+
+func (c *Cycle) IsTakeProfitFilled() bool {
+	o := c.Order(CycleOrderTypeTakeProfit)
+	return o != nil && o.IsFilled()
+}
+
+// AddTrade accounts the trade of the cycle order, the fee paid in the base or the quote currency is deducted
+func (c *Cycle) AddTrade(order *CycleOrder, trade types.Trade, market types.Market) {
+	order.ExecutedQuantity = order.ExecutedQuantity.Add(trade.Quantity)
+	if order.IsFilled() {
+		order.Closed = true
+	}
+
+	quantity := trade.Quantity
+	quoteQuantity := trade.QuoteQuantity
+	if quoteQuantity.IsZero() {
+		quoteQuantity = trade.Price.Mul(trade.Quantity)
+	}
+
+	switch trade.Side {
+	case types.SideTypeBuy:
+		switch trade.FeeCurrency {
+		case market.BaseCurrency:
+			quantity = quantity.Sub(trade.Fee)
+		case market.QuoteCurrency:
+			quoteQuantity = quoteQuantity.Add(trade.Fee)
+		}
+
+		c.BoughtQuantity = c.BoughtQuantity.Add(quantity)
+		c.Quantity = c.Quantity.Add(quantity)
+		c.Cost = c.Cost.Add(quoteQuantity)
+
+		switch order.Type {
+		case CycleOrderTypeBase:
+			if order.IsFilled() {
+				c.EntryPrice = c.AverageCost()
+			}
+
+		case CycleOrderTypeSafety:
+			c.TakeProfitOutdated = true
+		}
+
+	case types.SideTypeSell:
+		if trade.FeeCurrency == market.QuoteCurrency {
+			quoteQuantity = quoteQuantity.Sub(trade.Fee)
+		}
+
+		c.Quantity = c.Quantity.Sub(quantity)
+		c.Proceeds = c.Proceeds.Add(quoteQuantity)
+	}
+}
+
+// SafetyOrderLevel is the price and the quote amount of the safety order
+type SafetyOrderLevel struct {
+	Price  fixedpoint.Value
+	Amount fixedpoint.Value
+}
+
+// SafetyOrderLevels calculates the safety orders from the entry price:
+// the price deviation of the n-th safety order is priceDeviation * (1 + stepScale + ... + stepScale^(n-1)),
+// and the amount of the n-th safety order is safetyOrderAmount * volumeScale^(n-1).
+func (c *BotConfig) SafetyOrderLevels(entryPrice fixedpoint.Value) []SafetyOrderLevel {
+	var levels []SafetyOrderLevel
+	deviation := fixedpoint.Zero
+	step := c.PriceDeviation
+	amount := c.SafetyOrderAmount
+	for i := 0; i < c.MaxSafetyOrders; i++ {
+		deviation = deviation.Add(step)
+		if deviation.Compare(fixedpoint.One) >= 0 {
+			break
+		}
+
+		levels = append(levels, SafetyOrderLevel{
+			Price:  entryPrice.Mul(fixedpoint.One.Sub(deviation)),
+			Amount: amount,
+		})
+
+		step = step.Mul(c.SafetyOrderStepScale)
+		amount = amount.Mul(c.SafetyOrderVolumeScale)
+	}
+
+	return levels
+}
+
+// RequiredAmount returns the quote amount required by the base order and all the safety orders of a cycle
+func (c *BotConfig) RequiredAmount() fixedpoint.Value {
+	amount := c.BaseOrderAmount
+	for _, level := range c.SafetyOrderLevels(fixedpoint.One) {
+		amount = amount.Add(level.Amount)
+	}
+
+	return amount
+}
+
+// BotState is the persisted state of the DCA bot, the active cycles are resumed on restart
+type BotState struct {
+	Cycles             []*Cycle         `json:"cycles"`
+	NextCycleID        int              `json:"nextCycleID"`
+	CompletedCycles    int              `json:"completedCycles"`
+	LastCycleStartTime time.Time        `json:"lastCycleStartTime"`
+	AccumulatedProfit  fixedpoint.Value `json:"accumulatedProfit"`
+}
+
+func (s *BotState) FindOrder(orderID uint64) (*Cycle, *CycleOrder) {
+	for _, c := range s.Cycles {
+		if o := c.FindOrder(orderID); o != nil {
+			return c, o
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *BotState) RemoveCycle(cycle *Cycle) {
+	for i, c := range s.Cycles {
+		if c == cycle {
+			s.Cycles = append(s.Cycles[:i], s.Cycles[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package dca
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+}
+
+func TestBotConfig_SafetyOrderLevels(t *testing.T) {
+	config := &BotConfig{
+		BaseOrderAmount:        fixedpoint.NewFromInt(100),
+		SafetyOrderAmount:      fixedpoint.NewFromInt(100),
+		MaxSafetyOrders:        3,
+		PriceDeviation:         fixedpoint.NewFromFloat(0.01),
+		SafetyOrderVolumeScale: fixedpoint.NewFromInt(2),
+		SafetyOrderStepScale:   fixedpoint.NewFromInt(2),
+	}
+
+	levels := config.SafetyOrderLevels(fixedpoint.NewFromInt(1000))
+	if assert.Len(t, levels, 3) {
+		// deviations 1%, 3% and 7%
+		assert.InDelta(t, 990.0, levels[0].Price.Float64(), 1e-6)
+		assert.InDelta(t, 970.0, levels[1].Price.Float64(), 1e-6)
+		assert.InDelta(t, 930.0, levels[2].Price.Float64(), 1e-6)
+
+		assert.Equal(t, "100", levels[0].Amount.String())
+		assert.Equal(t, "200", levels[1].Amount.String())
+		assert.Equal(t, "400", levels[2].Amount.String())
+	}
+
+	assert.Equal(t, "800", config.RequiredAmount().String())
+}
+
+func TestBotConfig_SafetyOrderLevels_MaxDeviation(t *testing.T) {
+	config := &BotConfig{
+		SafetyOrderAmount:      fixedpoint.NewFromInt(100),
+		MaxSafetyOrders:        5,
+		PriceDeviation:         fixedpoint.NewFromFloat(0.3),
+		SafetyOrderVolumeScale: fixedpoint.One,
+		SafetyOrderStepScale:   fixedpoint.One,
+	}
+
+	// the deviation of the 4th safety order reaches 120%
+	levels := config.SafetyOrderLevels(fixedpoint.NewFromInt(1000))
+	assert.Len(t, levels, 3)
+}
+
+func TestCycle_AddTrade(t *testing.T) {
+	cycle := &Cycle{ID: 1}
+	base := &CycleOrder{OrderID: 1, Type: CycleOrderTypeBase, Side: types.SideTypeBuy, Quantity: fixedpoint.NewFromFloat(0.1)}
+	safety := &CycleOrder{OrderID: 2, Type: CycleOrderTypeSafety, Side: types.SideTypeBuy, Price: fixedpoint.NewFromInt(900), Quantity: fixedpoint.NewFromFloat(0.1)}
+	takeProfit := &CycleOrder{OrderID: 3, Type: CycleOrderTypeTakeProfit, Side: types.SideTypeSell, Price: fixedpoint.NewFromInt(1000), Quantity: fixedpoint.NewFromFloat(0.2)}
+	cycle.Orders = []*CycleOrder{base, safety, takeProfit}
+
+	cycle.AddTrade(base, types.Trade{
+		OrderID:     1,
+		Side:        types.SideTypeBuy,
+		Price:       fixedpoint.NewFromInt(1000),
+		Quantity:    fixedpoint.NewFromFloat(0.1),
+		Fee:         fixedpoint.NewFromFloat(0.1),
+		FeeCurrency: "USDT",
+	}, testMarket)
+
+	assert.True(t, cycle.IsBaseOrderFilled())
+	assert.True(t, base.Closed)
+	assert.InDelta(t, 1001.0, cycle.EntryPrice.Float64(), 1e-6)
+	assert.False(t, cycle.TakeProfitOutdated)
+
+	cycle.AddTrade(safety, types.Trade{
+		OrderID:  2,
+		Side:     types.SideTypeBuy,
+		Price:    fixedpoint.NewFromInt(900),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	}, testMarket)
+
+	assert.True(t, cycle.TakeProfitOutdated)
+	assert.InDelta(t, 0.2, cycle.Quantity.Float64(), 1e-6)
+	assert.InDelta(t, 950.5, cycle.AverageCost().Float64(), 1e-6)
+
+	cycle.AddTrade(takeProfit, types.Trade{
+		OrderID:  3,
+		Side:     types.SideTypeSell,
+		Price:    fixedpoint.NewFromInt(1000),
+		Quantity: fixedpoint.NewFromFloat(0.2),
+	}, testMarket)
+
+	assert.True(t, cycle.IsTakeProfitFilled())
+	assert.InDelta(t, 0.0, cycle.Quantity.Float64(), 1e-6)
+	assert.InDelta(t, 9.9, cycle.Profit().Float64(), 1e-6)
+}
+
+func TestCycle_AddTrade_BaseFee(t *testing.T) {
+	cycle := &Cycle{ID: 1}
+	base := &CycleOrder{OrderID: 1, Type: CycleOrderTypeBase, Side: types.SideTypeBuy, Quantity: fixedpoint.NewFromFloat(0.1)}
+	cycle.Orders = []*CycleOrder{base}
+
+	cycle.AddTrade(base, types.Trade{
+		OrderID:     1,
+		Side:        types.SideTypeBuy,
+		Price:       fixedpoint.NewFromInt(1000),
+		Quantity:    fixedpoint.NewFromFloat(0.1),
+		Fee:         fixedpoint.NewFromFloat(0.001),
+		FeeCurrency: "BTC",
+	}, testMarket)
+
+	assert.InDelta(t, 0.099, cycle.Quantity.Float64(), 1e-6)
+	assert.InDelta(t, 100.0, cycle.Cost.Float64(), 1e-6)
+}
+
+func TestBotState_RemoveCycle(t *testing.T) {
+	c1 := &Cycle{ID: 1, Orders: []*CycleOrder{{OrderID: 10}}}
+	c2 := &Cycle{ID: 2, Orders: []*CycleOrder{{OrderID: 20}}}
+	state := &BotState{Cycles: []*Cycle{c1, c2}}
+
+	cycle, order := state.FindOrder(20)
+	assert.Equal(t, c2, cycle)
+	assert.Equal(t, uint64(20), order.OrderID)
+
+	state.RemoveCycle(c1)
+	assert.Equal(t, []*Cycle{c2}, state.Cycles)
+
+	cycle, order = state.FindOrder(10)
+	assert.Nil(t, cycle)
+	assert.Nil(t, order)
+}
@@ -3,6 +3,7 @@ package dca
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -45,7 +46,9 @@ func (b BudgetPeriod) Duration() time.Duration {
 	return period
 }
 
-// Strategy is the Dollar-Cost-Average strategy
+// Strategy is the Dollar-Cost-Average strategy.
+// It invests the budget periodically by default, the DCA bot mode with the safety orders and the take-profit cycles
+// is enabled when the bot config is set.
 type Strategy struct {
 	Environment *bbgo.Environment
 	Symbol      string `json:"symbol"`
@@ -61,6 +64,9 @@ type Strategy struct {
 	// InvestmentInterval is the interval of each investment
 	InvestmentInterval types.Interval `json:"investmentInterval"`
 
+	// Bot enables the DCA bot mode
+	Bot *BotConfig `json:"bot,omitempty"`
+
 	budgetPerInvestment fixedpoint.Value
 
 	Position              *types.Position    `persistence:"position"`
@@ -68,9 +74,21 @@ type Strategy struct {
 	BudgetQuota           fixedpoint.Value   `persistence:"budget_quota"`
 	BudgetPeriodStartTime time.Time          `persistence:"budget_period_start_time"`
 
+	// BotState is the cycle state of the bot mode
+	BotState *BotState `persistence:"bot_state"`
+
 	session       *bbgo.ExchangeSession
 	orderExecutor *bbgo.GeneralOrderExecutor
 
+	botMu                     sync.Mutex
+	botUpdateC                chan struct{}
+	botUpdating, botDirty     bool
+	botRecovered              bool
+	pendingTrades             map[uint64][]types.Trade
+	lastPrice                 fixedpoint.Value
+	lastKLineTime             time.Time
+	insufficientBalanceLogged bool
+
 	bbgo.StrategyController
 }
 
@@ -78,7 +96,32 @@ func (s *Strategy) ID() string {
 	return ID
 }
 
+func (s *Strategy) Defaults() error {
+	if s.Bot != nil {
+		s.Bot.Defaults()
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if s.Bot != nil {
+		return s.Bot.Validate()
+	}
+
+	return nil
+}
+
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if s.Bot != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Bot.Interval})
+		return
+	}
+
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.InvestmentInterval})
 }
 
@@ -113,6 +156,10 @@ func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.
 	})
 	s.orderExecutor.Bind()
 
+	if s.Bot != nil {
+		return s.runBot(ctx, session)
+	}
+
 	numOfInvestmentPerPeriod := fixedpoint.NewFromFloat(float64(s.BudgetPeriod.Duration()) / float64(s.InvestmentInterval.Duration()))
 	s.budgetPerInvestment = s.Budget.Div(numOfInvestmentPerPeriod)
 
@@ -255,6 +255,7 @@ func (s *Strategy) initIndicators(store *bbgo.SerialMarketDataStore) error {
 		return errors.New("klines not exists")
 	}
 	log.Infof("loaded %d klines", klinesLength)
+	// the indicators are updated by the kline source and the derived values, which can't be preloaded by PushK
 	for _, kline := range *klines {
 		source := s.GetSource(&kline).Float64()
 		high := kline.High.Float64()
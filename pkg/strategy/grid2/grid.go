@@ -10,7 +10,19 @@ import (
 
 type PinCalculator func() []Pin
 
+type GridType string
+
+const (
+	// GridTypeArithmetic places the pins with the same price spread
+	GridTypeArithmetic GridType = "arithmetic"
+
+	// GridTypeGeometric places the pins with the same price ratio
+	GridTypeGeometric GridType = "geometric"
+)
+
 type Grid struct {
+	Type GridType `json:"type"`
+
 	UpperPrice fixedpoint.Value `json:"upperPrice"`
 	LowerPrice fixedpoint.Value `json:"lowerPrice"`
 
@@ -23,6 +35,12 @@ type Grid struct {
 	// Spread is a immutable number
 	Spread fixedpoint.Value `json:"spread"`
 
+	// Ratio is the immutable price ratio of the adjacent pins, it's only used by the geometric grid
+	Ratio fixedpoint.Value `json:"ratio,omitempty"`
+
+	// ratio is the float ratio for calculating the pins, the fixedpoint ratio is not precise enough for the power
+	ratio float64
+
 	// Pins are the pinned grid prices, from low to high
 	Pins []Pin `json:"pins"`
 
@@ -33,23 +51,53 @@ type Grid struct {
 
 type Pin fixedpoint.Value
 
-func calculateArithmeticPins(lower, upper, spread, tickSize fixedpoint.Value) []Pin {
-	var pins []Pin
-
-	// tickSize number is like 0.01, 0.1, 0.001
+// truncatePinPrice truncates the price by the tick size, the tick size number is like 0.01, 0.1, 0.001
+func truncatePinPrice(price float64, tickSize fixedpoint.Value) Pin {
 	var ts = tickSize.Float64()
 	var prec = int(math.Round(math.Log10(ts) * -1.0))
 	var pow10 = math.Pow10(prec)
+	pp := math.Round(price*pow10*10.0) / 10.0
+	pp = math.Trunc(pp) / pow10
+	return Pin(fixedpoint.NewFromFloat(pp))
+}
+
+func calculateArithmeticPins(lower, upper, spread, tickSize fixedpoint.Value) []Pin {
+	var pins []Pin
 	for p := lower; p.Compare(upper) <= 0; p = p.Add(spread) {
-		pp := math.Round(p.Float64()*pow10*10.0) / 10.0
-		pp = math.Trunc(pp) / pow10
-		pin := Pin(fixedpoint.NewFromFloat(pp))
-		pins = append(pins, pin)
+		pins = append(pins, truncatePinPrice(p.Float64(), tickSize))
 	}
 
 	return pins
 }
 
+// calculateGeometricRatio calculates the price ratio of the adjacent pins,
+// upper = lower * ratio ^ (size - 1)
+// =>
+// ratio = (upper / lower) ^ (1 / (size - 1))
+func calculateGeometricRatio(lower, upper, size fixedpoint.Value) float64 {
+	return math.Pow(upper.Float64()/lower.Float64(), 1.0/(size.Float64()-1.0))
+}
+
+// calculateGeometricPins calculates the pins from the lower price to the upper price with the given ratio,
+// the pins are calculated by the power of the ratio instead of the accumulated multiplication to avoid the rounding error.
+func calculateGeometricPins(lower, upper fixedpoint.Value, ratio float64, tickSize fixedpoint.Value) []Pin {
+	var pins []Pin
+
+	var l = lower.Float64()
+	var n = numOfGeometricSteps(l, upper.Float64(), ratio)
+	for i := 0; i <= n; i++ {
+		pins = append(pins, truncatePinPrice(l*math.Pow(ratio, float64(i)), tickSize))
+	}
+
+	return pins
+}
+
+// numOfGeometricSteps returns the max n that lower * ratio ^ n <= upper,
+// a small epsilon is added, so that the upper price is included when it's on the grid
+func numOfGeometricSteps(lower, upper, ratio float64) int {
+	return int(math.Floor(math.Log(upper/lower)/math.Log(ratio) + 1e-9))
+}
+
 func buildPinCache(pins []Pin) map[Pin]struct{} {
 	cache := make(map[Pin]struct{}, len(pins))
 	for _, pin := range pins {
@@ -65,6 +113,7 @@ func NewGrid(lower, upper, size, tickSize fixedpoint.Value) *Grid {
 	spread := height.Div(size.Sub(one))
 
 	grid := &Grid{
+		Type:       GridTypeArithmetic,
 		UpperPrice: upper,
 		LowerPrice: lower,
 		Size:       size,
@@ -76,16 +125,18 @@ func NewGrid(lower, upper, size, tickSize fixedpoint.Value) *Grid {
 }
 
 func (g *Grid) CalculateGeometricPins() {
+	g.Type = GridTypeGeometric
+	g.ratio = calculateGeometricRatio(g.LowerPrice, g.UpperPrice, g.Size)
+	g.Ratio = fixedpoint.NewFromFloat(g.ratio)
 	g.calculator = func() []Pin {
-		// TODO: implement geometric calculator
-		// return calculateArithmeticPins(g.LowerPrice, g.UpperPrice, g.Spread, g.TickSize)
-		return nil
+		return calculateGeometricPins(g.LowerPrice, g.UpperPrice, g.ratio, g.TickSize)
 	}
 
 	g.addPins(g.calculator())
 }
 
 func (g *Grid) CalculateArithmeticPins() {
+	g.Type = GridTypeArithmetic
 	g.calculator = func() []Pin {
 		one := fixedpoint.NewFromInt(1)
 		height := g.UpperPrice.Sub(g.LowerPrice)
@@ -150,7 +201,19 @@ func (g *Grid) ExtendUpperPrice(upper fixedpoint.Value) (newPins []Pin) {
 		return nil
 	}
 
-	newPins = calculateArithmeticPins(g.UpperPrice.Add(g.Spread), upper, g.Spread, g.TickSize)
+	if g.Type == GridTypeGeometric {
+		// upper = g.UpperPrice * ratio ^ n
+		n := numOfGeometricSteps(g.UpperPrice.Float64(), upper.Float64(), g.ratio)
+		if n == 0 {
+			return nil
+		}
+
+		newPins = calculateGeometricPins(fixedpoint.NewFromFloat(g.UpperPrice.Float64()*g.ratio), upper, g.ratio, g.TickSize)
+		upper = fixedpoint.NewFromFloat(g.UpperPrice.Float64() * math.Pow(g.ratio, float64(n)))
+	} else {
+		newPins = calculateArithmeticPins(g.UpperPrice.Add(g.Spread), upper, g.Spread, g.TickSize)
+	}
+
 	g.UpperPrice = upper
 	g.addPins(newPins)
 	return newPins
@@ -161,9 +224,20 @@ func (g *Grid) ExtendLowerPrice(lower fixedpoint.Value) (newPins []Pin) {
 		return nil
 	}
 
-	n := g.LowerPrice.Sub(lower).Div(g.Spread).Floor()
-	lower = g.LowerPrice.Sub(g.Spread.Mul(n))
-	newPins = calculateArithmeticPins(lower, g.LowerPrice.Sub(g.Spread), g.Spread, g.TickSize)
+	if g.Type == GridTypeGeometric {
+		// lower = g.LowerPrice / ratio ^ n
+		n := numOfGeometricSteps(lower.Float64(), g.LowerPrice.Float64(), g.ratio)
+		if n == 0 {
+			return nil
+		}
+
+		lower = fixedpoint.NewFromFloat(g.LowerPrice.Float64() / math.Pow(g.ratio, float64(n)))
+		newPins = calculateGeometricPins(lower, fixedpoint.NewFromFloat(g.LowerPrice.Float64()/g.ratio), g.ratio, g.TickSize)
+	} else {
+		n := g.LowerPrice.Sub(lower).Div(g.Spread).Floor()
+		lower = g.LowerPrice.Sub(g.Spread.Mul(n))
+		newPins = calculateArithmeticPins(lower, g.LowerPrice.Sub(g.Spread), g.Spread, g.TickSize)
+	}
 
 	g.LowerPrice = lower
 	g.addPins(newPins)
@@ -195,5 +269,9 @@ func (g *Grid) updatePinsCache() {
 }
 
 func (g *Grid) String() string {
+	if g.Type == GridTypeGeometric {
+		return fmt.Sprintf("GRID: priceRange: %f <=> %f size: %f ratio: %f", g.LowerPrice.Float64(), g.UpperPrice.Float64(), g.Size.Float64(), g.Ratio.Float64())
+	}
+
 	return fmt.Sprintf("GRID: priceRange: %f <=> %f size: %f spread: %f", g.LowerPrice.Float64(), g.UpperPrice.Float64(), g.Size.Float64(), g.Spread.Float64())
 }
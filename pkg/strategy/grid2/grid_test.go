@@ -147,6 +147,56 @@ func TestGrid_NextHigherPin(t *testing.T) {
 	assert.Equal(t, Pin(fixedpoint.Zero), next)
 }
 
+func TestGrid_CalculateGeometricPins(t *testing.T) {
+	// ratio = (400 / 100) ^ (1 / 4) = sqrt(2)
+	grid := NewGrid(number(100.0), number(400.0), number(5.0), number(0.01))
+	grid.CalculateGeometricPins()
+
+	assert.Equal(t, GridTypeGeometric, grid.Type)
+	assert.InDelta(t, 1.41421356, grid.Ratio.Float64(), 1e-8)
+	assert.Equal(t, []Pin{
+		Pin(number(100.0)),
+		Pin(number(141.42)),
+		Pin(number(200.0)),
+		Pin(number(282.84)),
+		Pin(number(400.0)),
+	}, grid.Pins)
+
+	next, ok := grid.NextHigherPin(number(141.42))
+	assert.True(t, ok)
+	assert.Equal(t, Pin(number(200.0)), next)
+
+	next, ok = grid.NextLowerPin(number(282.84))
+	assert.True(t, ok)
+	assert.Equal(t, Pin(number(200.0)), next)
+
+	_, ok = grid.NextHigherPin(number(400.0))
+	assert.False(t, ok)
+}
+
+func TestGrid_ExtendGeometricPrice(t *testing.T) {
+	grid := NewGrid(number(100.0), number(400.0), number(5.0), number(0.01))
+	grid.CalculateGeometricPins()
+
+	// 400 * sqrt(2) ^ 2 = 800 <= 1000
+	newPins := grid.ExtendUpperPrice(number(1000.0))
+	assert.Equal(t, []Pin{Pin(number(565.68)), Pin(number(800.0))}, newPins)
+	assert.InDelta(t, 800.0, grid.UpperPrice.Float64(), 1e-6)
+
+	// 100 / sqrt(2) ^ 3 = 35.355 >= 30
+	newPins = grid.ExtendLowerPrice(number(30.0))
+	assert.Equal(t, []Pin{Pin(number(35.35)), Pin(number(50.0)), Pin(number(70.71))}, newPins)
+	assert.InDelta(t, 35.355339, grid.LowerPrice.Float64(), 1e-6)
+
+	assert.Len(t, grid.Pins, 10)
+	assert.Equal(t, Pin(number(35.35)), grid.BottomPin())
+	assert.Equal(t, Pin(number(800.0)), grid.TopPin())
+
+	// the price range is not extended when it's less than 1 grid
+	assert.Nil(t, grid.ExtendUpperPrice(number(1000.0)))
+	assert.Len(t, grid.Pins, 10)
+}
+
 func Test_calculateArithmeticPins(t *testing.T) {
 	type args struct {
 		lower    fixedpoint.Value
@@ -66,6 +66,10 @@ type Strategy struct {
 	// GridNum is the grid number, how many orders you want to post on the orderbook.
 	GridNum int64 `json:"gridNumber"`
 
+	// Type is the pin spacing of the grid, "arithmetic" (default) places the pins with the same price spread,
+	// "geometric" places the pins with the same price ratio, which is suitable for the wide price range.
+	Type GridType `json:"type"`
+
 	AutoRange *types.SimpleDuration `json:"autoRange"`
 
 	UpperPrice fixedpoint.Value `json:"upperPrice"`
@@ -165,6 +169,12 @@ func (s *Strategy) Validate() error {
 		return fmt.Errorf("gridNum can not be zero")
 	}
 
+	switch s.Type {
+	case "", GridTypeArithmetic, GridTypeGeometric:
+	default:
+		return fmt.Errorf("unsupported grid type %q, valid types are %q and %q", s.Type, GridTypeArithmetic, GridTypeGeometric)
+	}
+
 	if !s.SkipSpreadCheck {
 		if err := s.checkSpread(); err != nil {
 			return errors.Wrapf(err, "spread is too small, please try to reduce your gridNum or increase the price range (upperPrice and lowerPrice)")
@@ -201,15 +211,15 @@ func (s *Strategy) InstanceID() string {
 		id += "-" + s.UpperPrice.String() + "-" + s.LowerPrice.String()
 	}
 
+	if s.Type == GridTypeGeometric {
+		id += "-" + string(s.Type)
+	}
+
 	return id
 }
 
 func (s *Strategy) checkSpread() error {
 	gridNum := fixedpoint.NewFromInt(s.GridNum)
-	spread := s.ProfitSpread
-	if spread.IsZero() {
-		spread = s.UpperPrice.Sub(s.LowerPrice).Div(gridNum)
-	}
 
 	feeRate := s.FeeRate
 	if feeRate.IsZero() {
@@ -219,6 +229,21 @@ func (s *Strategy) checkSpread() error {
 	// the min fee rate from 2 maker/taker orders (with 0.1 rate for profit)
 	gridFeeRate := feeRate.Mul(fixedpoint.NewFromFloat(2.01))
 
+	spread := s.ProfitSpread
+	if spread.IsZero() && s.Type == GridTypeGeometric {
+		// the profit rate of the geometric grid is the same for all the pins, which is ratio - 1
+		ratio := fixedpoint.NewFromFloat(calculateGeometricRatio(s.LowerPrice, s.UpperPrice, gridNum))
+		if profitRate := ratio.Sub(fixedpoint.One); profitRate.Compare(gridFeeRate) < 0 {
+			return fmt.Errorf("grid profit rate %s is too small, less than the grid fee rate: %s", profitRate.Percentage(), gridFeeRate.Percentage())
+		}
+
+		return nil
+	}
+
+	if spread.IsZero() {
+		spread = s.UpperPrice.Sub(s.LowerPrice).Div(gridNum)
+	}
+
 	if spread.Div(s.LowerPrice).Compare(gridFeeRate) < 0 {
 		return fmt.Errorf("profitSpread %f %s is too small for lower price, less than the grid fee rate: %s", spread.Float64(), spread.Div(s.LowerPrice).Percentage(), gridFeeRate.Percentage())
 	}
@@ -309,8 +334,9 @@ func (s *Strategy) aggregateOrderBaseFee(o types.Order) fixedpoint.Value {
 
 		// if orderQueryService is supported, use it to query the trades of the filled order
 		apiOrderTrades, err := s.orderQueryService.QueryOrderTrades(context.Background(), types.OrderQuery{
-			Symbol:  o.Symbol,
-			OrderID: strconv.FormatUint(o.OrderID, 10),
+			Symbol:    o.Symbol,
+			OrderID:   strconv.FormatUint(o.OrderID, 10),
+			OrderUUID: o.UUID,
 		})
 		if err != nil {
 			s.logger.WithError(err).Errorf("query order trades error")
@@ -488,6 +514,12 @@ func (s *Strategy) checkRequiredInvestmentByAmount(baseBalance, quoteBalance, am
 			// for orders that sell
 			// if we still have the base balance
 			quantity := amount.Div(lastPrice)
+			if s.Type == GridTypeGeometric {
+				// the pins of the geometric grid could be far from the last price in a wide price range,
+				// so the quantity is calculated by the pin price like the grid orders
+				quantity = amount.Div(price)
+			}
+
 			if requiredBase.Add(quantity).Compare(baseBalance) <= 0 {
 				requiredBase = requiredBase.Add(quantity)
 			} else if i > 0 { // we do not want to sell at i == 0
@@ -754,7 +786,11 @@ func (s *Strategy) CloseGrid(ctx context.Context) error {
 
 func (s *Strategy) newGrid() *Grid {
 	grid := NewGrid(s.LowerPrice, s.UpperPrice, fixedpoint.NewFromInt(s.GridNum), s.Market.TickSize)
-	grid.CalculateArithmeticPins()
+	if s.Type == GridTypeGeometric {
+		grid.CalculateGeometricPins()
+	} else {
+		grid.CalculateArithmeticPins()
+	}
 	return grid
 }
 
@@ -793,12 +829,12 @@ func (s *Strategy) openGrid(ctx context.Context, session *bbgo.ExchangeSession)
 	// if the buy order is filled, then we will submit another sell order at the higher grid.
 	if s.QuantityOrAmount.IsSet() {
 		if quantity := s.QuantityOrAmount.Quantity; !quantity.IsZero() {
-			if _, _, err2 := s.checkRequiredInvestmentByQuantity(totalBase, totalQuote, lastPrice, s.QuantityOrAmount.Quantity, s.grid.Pins); err != nil {
+			if _, _, err2 := s.checkRequiredInvestmentByQuantity(totalBase, totalQuote, quantity, lastPrice, s.grid.Pins); err2 != nil {
 				return err2
 			}
 		}
 		if amount := s.QuantityOrAmount.Amount; !amount.IsZero() {
-			if _, _, err2 := s.checkRequiredInvestmentByAmount(totalBase, totalQuote, lastPrice, amount, s.grid.Pins); err != nil {
+			if _, _, err2 := s.checkRequiredInvestmentByAmount(totalBase, totalQuote, amount, lastPrice, s.grid.Pins); err2 != nil {
 				return err2
 			}
 		}
@@ -215,6 +215,27 @@ func TestStrategy_checkRequiredInvestmentByAmount(t *testing.T) {
 		assert.EqualError(t, err, "quote balance (3000.000000 USDT) is not enough, required = quote 4999.999890")
 		assert.InDelta(t, 4999.999890, requiredQuote.Float64(), number(0.001).Float64())
 	})
+
+	t.Run("geometric grid quantity by the pin price", func(t *testing.T) {
+		s.Type = GridTypeGeometric
+		defer func() { s.Type = "" }()
+
+		_, requiredQuote, err := s.checkRequiredInvestmentByAmount(
+			number(0.0), number(3_000.0),
+			number(1000.0),
+			number(25_000.0), []Pin{
+				Pin(number(10_000.0)),
+				Pin(number(14_142.13)),
+				Pin(number(20_000.0)),
+				Pin(number(28_284.27)),
+				Pin(number(40_000.0)),
+			})
+		// the sell orders @ 40_000 and 28_284.27 are converted to the buy orders @ 28_284.27 and 20_000,
+		// the grid buy order @ 20_000 is not placed again, and the buy orders @ 14_142.13 and 10_000 require 1000 each
+		// requiredQuote = 1000 / 40_000 * 28_284.27 + 1000 / 28_284.27 * 20_000 + 1000 * 2
+		assert.Error(t, err)
+		assert.InDelta(t, 3414.213, requiredQuote.Float64(), number(0.001).Float64())
+	})
 }
 
 func TestStrategy_calculateQuoteInvestmentQuantity(t *testing.T) {
@@ -259,6 +280,43 @@ func TestStrategy_calculateQuoteInvestmentQuantity(t *testing.T) {
 
 }
 
+func TestStrategy_checkSpread_Geometric(t *testing.T) {
+	s := newTestStrategy()
+	s.Type = GridTypeGeometric
+	s.LowerPrice = number(10_000.0)
+	s.UpperPrice = number(50_000.0)
+
+	// ratio = 5 ^ (1 / 10) = 1.1746, the profit rate of every grid is 17.46%
+	assert.NoError(t, s.checkSpread())
+
+	// ratio = 5 ^ (1 / 1999) = 1.0008, which is less than the grid fee rate 0.15%
+	s.GridNum = 2000
+	assert.Error(t, s.checkSpread())
+}
+
+func TestStrategy_calculateQuoteInvestmentQuantity_Geometric(t *testing.T) {
+	s := newTestStrategy()
+	s.Type = GridTypeGeometric
+	s.UpperPrice = number(40_000)
+	s.GridNum = 5
+	s.grid = s.newGrid()
+	assert.Equal(t, []Pin{
+		Pin(number(10_000.0)),
+		Pin(number(14_142.13)),
+		Pin(number(20_000.0)),
+		Pin(number(28_284.27)),
+		Pin(number(40_000.0)),
+	}, s.grid.Pins)
+
+	// the sell orders @ 40_000 and 28_284.27 are converted to the buy orders @ 28_284.27 and 20_000,
+	// the grid buy order @ 20_000 right below the last price is not placed again since the converted buy order takes the pin,
+	// and the buy orders @ 14_142.13 and 10_000 are placed as usual
+	// quoteInvestment = (28_284.27 + 20_000 + 14_142.13 + 10_000) * q
+	quantity, err := s.calculateQuoteInvestmentQuantity(number(7_242.64), number(25_000.0), s.grid.Pins)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.1, quantity.Float64(), 1e-8)
+}
+
 func newTestStrategy() *Strategy {
 	market := types.Market{
 		BaseCurrency:    "BTC",
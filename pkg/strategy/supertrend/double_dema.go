@@ -1,6 +1,8 @@
 package supertrend
 
 import (
+	"context"
+
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/types"
@@ -31,21 +33,17 @@ func (dd *DoubleDema) getDemaSignal(openPrice float64, closePrice float64) types
 }
 
 // preloadDema preloads DEMA indicators
-func (dd *DoubleDema) preloadDema(kLineStore *bbgo.MarketDataStore) {
-	if klines, ok := kLineStore.KLinesOfInterval(dd.fastDEMA.Interval); ok {
-		for i := 0; i < len(*klines); i++ {
-			dd.fastDEMA.Update((*klines)[i].GetClose().Float64())
-		}
+func (dd *DoubleDema) preloadDema(ctx context.Context, session *bbgo.ExchangeSession, symbol string) {
+	if err := session.PreloadIndicator(ctx, symbol, dd.fastDEMA.Interval, dd.fastDEMA); err != nil {
+		log.WithError(err).Warnf("unable to preload the fast DEMA")
 	}
-	if klines, ok := kLineStore.KLinesOfInterval(dd.slowDEMA.Interval); ok {
-		for i := 0; i < len(*klines); i++ {
-			dd.slowDEMA.Update((*klines)[i].GetClose().Float64())
-		}
+	if err := session.PreloadIndicator(ctx, symbol, dd.slowDEMA.Interval, dd.slowDEMA); err != nil {
+		log.WithError(err).Warnf("unable to preload the slow DEMA")
 	}
 }
 
 // newDoubleDema initializes double DEMA indicators
-func newDoubleDema(kLineStore *bbgo.MarketDataStore, interval types.Interval, fastDEMAWindow int, slowDEMAWindow int) *DoubleDema {
+func newDoubleDema(ctx context.Context, session *bbgo.ExchangeSession, symbol string, interval types.Interval, fastDEMAWindow int, slowDEMAWindow int) *DoubleDema {
 	dd := DoubleDema{Interval: interval, FastDEMAWindow: fastDEMAWindow, SlowDEMAWindow: slowDEMAWindow}
 
 	// DEMA
@@ -53,15 +51,17 @@ func newDoubleDema(kLineStore *bbgo.MarketDataStore, interval types.Interval, fa
 		dd.FastDEMAWindow = 144
 	}
 	dd.fastDEMA = &indicator.DEMA{IntervalWindow: types.IntervalWindow{Interval: dd.Interval, Window: dd.FastDEMAWindow}}
-	dd.fastDEMA.Bind(kLineStore)
 
 	if dd.SlowDEMAWindow == 0 {
 		dd.SlowDEMAWindow = 169
 	}
 	dd.slowDEMA = &indicator.DEMA{IntervalWindow: types.IntervalWindow{Interval: dd.Interval, Window: dd.SlowDEMAWindow}}
-	dd.slowDEMA.Bind(kLineStore)
 
-	dd.preloadDema(kLineStore)
+	dd.preloadDema(ctx, session, symbol)
+
+	kLineStore, _ := session.MarketDataStore(symbol)
+	dd.fastDEMA.Bind(kLineStore)
+	dd.slowDEMA.Bind(kLineStore)
 
 	return &dd
 }
@@ -162,6 +162,7 @@ type Strategy struct {
 	Position    *types.Position    `persistence:"position"`
 	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
 	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+	EquityCurve *types.EquityCurve `persistence:"equity_curve"`
 
 	// Symbol is the market symbol you want to trade
 	Symbol string `json:"symbol"`
@@ -297,12 +298,9 @@ func (s *Strategy) ClosePosition(ctx context.Context, percentage fixedpoint.Valu
 }
 
 // setupIndicators initializes indicators
-func (s *Strategy) setupIndicators() {
-	// K-line store for indicators
-	kLineStore, _ := s.session.MarketDataStore(s.Symbol)
-
+func (s *Strategy) setupIndicators(ctx context.Context) {
 	// Double DEMA
-	s.doubleDema = newDoubleDema(kLineStore, s.Interval, s.FastDEMAWindow, s.SlowDEMAWindow)
+	s.doubleDema = newDoubleDema(ctx, s.session, s.Symbol, s.Interval, s.FastDEMAWindow, s.SlowDEMAWindow)
 
 	// Supertrend
 	if s.Window == 0 {
@@ -313,10 +311,10 @@ func (s *Strategy) setupIndicators() {
 	}
 	s.Supertrend = &indicator.Supertrend{IntervalWindow: types.IntervalWindow{Window: s.Window, Interval: s.Interval}, ATRMultiplier: s.SupertrendMultiplier}
 	s.Supertrend.AverageTrueRange = &indicator.ATR{IntervalWindow: types.IntervalWindow{Window: s.Window, Interval: s.Interval}}
-	s.Supertrend.BindK(s.session.MarketDataStream, s.Symbol, s.Supertrend.Interval)
-	if klines, ok := kLineStore.KLinesOfInterval(s.Supertrend.Interval); ok {
-		s.Supertrend.LoadK((*klines)[0:])
+	if err := s.session.PreloadIndicator(ctx, s.Symbol, s.Supertrend.Interval, s.Supertrend); err != nil {
+		log.WithError(err).Warnf("unable to preload the supertrend indicator")
 	}
+	s.Supertrend.BindK(s.session.MarketDataStream, s.Symbol, s.Supertrend.Interval)
 
 	// Linear Regression
 	if s.LinearRegression != nil {
@@ -325,10 +323,10 @@ func (s *Strategy) setupIndicators() {
 		} else if s.LinearRegression.Interval == "" {
 			s.LinearRegression = nil
 		} else {
-			s.LinearRegression.BindK(s.session.MarketDataStream, s.Symbol, s.LinearRegression.Interval)
-			if klines, ok := kLineStore.KLinesOfInterval(s.LinearRegression.Interval); ok {
-				s.LinearRegression.LoadK((*klines)[0:])
+			if err := s.session.PreloadIndicator(ctx, s.Symbol, s.LinearRegression.Interval, s.LinearRegression); err != nil {
+				log.WithError(err).Warnf("unable to preload the linear regression indicator")
 			}
+			s.LinearRegression.BindK(s.session.MarketDataStream, s.Symbol, s.LinearRegression.Interval)
 		}
 	}
 }
@@ -455,6 +453,16 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.TradeStats = types.NewTradeStats(s.Symbol)
 	}
 
+	if s.EquityCurve == nil {
+		// the drawdown ratio is computed against the equity, so the curve starts from the quote balance of the account
+		initialCapital := fixedpoint.Zero
+		if balance, ok := session.GetAccount().Balance(s.Market.QuoteCurrency); ok {
+			initialCapital = balance.Total()
+		}
+
+		s.EquityCurve = types.NewEquityCurve(s.Market, initialCapital)
+	}
+
 	// Interval profit report
 	if bbgo.IsBackTesting {
 		startTime := s.Environment.StartTime()
@@ -476,6 +484,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	s.orderExecutor.BindEnvironment(s.Environment)
 	s.orderExecutor.BindProfitStats(s.ProfitStats)
 	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.BindEquityCurve(s.EquityCurve, s.Interval)
 	s.orderExecutor.Bind()
 
 	// AccountValueCalculator
@@ -560,7 +569,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	})
 
 	// Setup indicators
-	s.setupIndicators()
+	s.setupIndicators(ctx)
 
 	// Exit methods
 	for _, method := range s.ExitMethods {
@@ -0,0 +1,232 @@
+package tri
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Leg is one conversion of the cycle, it converts the From currency to the To currency by trading on the market
+type Leg struct {
+	Market types.Market
+	Side   types.SideType
+	From   string
+	To     string
+}
+
+func (l Leg) String() string {
+	return fmt.Sprintf("%s %s (%s->%s)", l.Side, l.Market.Symbol, l.From, l.To)
+}
+
+// Cycle is the three legs converting the start currency back to itself
+type Cycle [3]Leg
+
+func (c Cycle) String() string {
+	var legs []string
+	for _, leg := range c {
+		legs = append(legs, leg.String())
+	}
+
+	return strings.Join(legs, " -> ")
+}
+
+// newLeg creates the leg converting the from currency through the market,
+// false is returned if the market does not contain the from currency
+func newLeg(market types.Market, from string) (Leg, bool) {
+	switch from {
+	case market.QuoteCurrency:
+		return Leg{Market: market, Side: types.SideTypeBuy, From: from, To: market.BaseCurrency}, true
+	case market.BaseCurrency:
+		return Leg{Market: market, Side: types.SideTypeSell, From: from, To: market.QuoteCurrency}, true
+	}
+
+	return Leg{}, false
+}
+
+// FindCycles finds the cycles of the three markets starting from and ending to the start currency,
+// there are two cycles in the opposite directions if the markets form a triangle.
+func FindCycles(markets [3]types.Market, startCurrency string) ([]Cycle, error) {
+	var cycles []Cycle
+	for i := range markets {
+		first, ok := newLeg(markets[i], startCurrency)
+		if !ok {
+			continue
+		}
+
+		for j := range markets {
+			if j == i {
+				continue
+			}
+
+			second, ok := newLeg(markets[j], first.To)
+			if !ok {
+				continue
+			}
+
+			third, ok := newLeg(markets[3-i-j], second.To)
+			if !ok || third.To != startCurrency {
+				continue
+			}
+
+			cycles = append(cycles, Cycle{first, second, third})
+		}
+	}
+
+	if len(cycles) == 0 {
+		return nil, fmt.Errorf("markets %s, %s and %s do not form a triangle of %s",
+			markets[0].Symbol, markets[1].Symbol, markets[2].Symbol, startCurrency)
+	}
+
+	return cycles, nil
+}
+
+// LegOrder is the executable order of the leg computed from the order book
+type LegOrder struct {
+	Leg
+
+	// Quantity is the base quantity of the order, truncated by the step size
+	Quantity fixedpoint.Value
+
+	// Price is the worst price level consumed, it's used as the price of the IOC order
+	Price fixedpoint.Value
+
+	// AmountIn is the amount of the from currency spent
+	AmountIn fixedpoint.Value
+
+	// AmountOut is the amount of the to currency received after the taker fee
+	AmountOut fixedpoint.Value
+}
+
+func (o LegOrder) SubmitOrder() types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:      o.Market.Symbol,
+		Market:      o.Market,
+		Side:        o.Side,
+		Type:        types.OrderTypeLimit,
+		Quantity:    o.Quantity,
+		Price:       o.Price,
+		TimeInForce: types.TimeInForceIOC,
+	}
+}
+
+// Opportunity is the result of simulating the cycle against the order books
+type Opportunity struct {
+	Cycle  Cycle
+	Orders [3]LegOrder
+
+	AmountIn  fixedpoint.Value
+	AmountOut fixedpoint.Value
+}
+
+// Edge returns the profit ratio of the cycle after the taker fees
+func (o Opportunity) Edge() fixedpoint.Value {
+	return o.AmountOut.Div(o.AmountIn).Sub(fixedpoint.One)
+}
+
+func (o Opportunity) Profit() fixedpoint.Value {
+	return o.AmountOut.Sub(o.AmountIn)
+}
+
+// Simulate walks the order books of the legs with the amount of the start currency,
+// the orders consume the asks for buying and the bids for selling, the taker fee is deducted from the received currency.
+func Simulate(cycle Cycle, books map[string]types.OrderBook, amount, takerFeeRate fixedpoint.Value) (*Opportunity, error) {
+	opportunity := &Opportunity{Cycle: cycle}
+
+	amountIn := amount
+	for i, leg := range cycle {
+		book, ok := books[leg.Market.Symbol]
+		if !ok {
+			return nil, fmt.Errorf("order book of %s is not found", leg.Market.Symbol)
+		}
+
+		order, err := simulateLeg(leg, book, amountIn, takerFeeRate)
+		if err != nil {
+			return nil, err
+		}
+
+		opportunity.Orders[i] = *order
+		amountIn = order.AmountOut
+	}
+
+	// the spent amount of the first leg can be less than the given amount because of the step size
+	opportunity.AmountIn = opportunity.Orders[0].AmountIn
+	opportunity.AmountOut = amountIn
+	return opportunity, nil
+}
+
+func simulateLeg(leg Leg, book types.OrderBook, amountIn, takerFeeRate fixedpoint.Value) (*LegOrder, error) {
+	var quantity fixedpoint.Value
+	var levels types.PriceVolumeSlice
+	switch leg.Side {
+	case types.SideTypeBuy:
+		levels = book.SideBook(types.SideTypeSell)
+		baseQuantity, ok := baseQuantityOfQuote(levels, amountIn)
+		if !ok {
+			return nil, fmt.Errorf("insufficient depth of %s for %s %s", leg.Market.Symbol, amountIn.String(), leg.Market.QuoteCurrency)
+		}
+
+		quantity = leg.Market.TruncateQuantity(baseQuantity)
+
+	case types.SideTypeSell:
+		levels = book.SideBook(types.SideTypeBuy)
+		quantity = leg.Market.TruncateQuantity(amountIn)
+	}
+
+	price, quoteAmount, ok := consume(levels, quantity)
+	if !ok {
+		return nil, fmt.Errorf("insufficient depth of %s for %s %s", leg.Market.Symbol, quantity.String(), leg.Market.BaseCurrency)
+	}
+
+	if quantity.Compare(leg.Market.MinQuantity) < 0 || quoteAmount.Compare(leg.Market.MinNotional) < 0 {
+		return nil, fmt.Errorf("%s order quantity %s or notional %s is less than the minimal", leg.Market.Symbol, quantity.String(), quoteAmount.String())
+	}
+
+	order := &LegOrder{Leg: leg, Quantity: quantity, Price: price}
+	feeRatio := fixedpoint.One.Sub(takerFeeRate)
+	if leg.Side == types.SideTypeBuy {
+		order.AmountIn = quoteAmount
+		order.AmountOut = quantity.Mul(feeRatio)
+	} else {
+		order.AmountIn = quantity
+		order.AmountOut = quoteAmount.Mul(feeRatio)
+	}
+
+	return order, nil
+}
+
+// baseQuantityOfQuote returns the base quantity that can be bought with the quote amount from the ask levels,
+// false is returned when the depth is not enough
+func baseQuantityOfQuote(asks types.PriceVolumeSlice, quoteAmount fixedpoint.Value) (fixedpoint.Value, bool) {
+	quantity := fixedpoint.Zero
+	for _, pv := range asks {
+		cost := pv.Price.Mul(pv.Volume)
+		if cost.Compare(quoteAmount) >= 0 {
+			return quantity.Add(quoteAmount.Div(pv.Price)), true
+		}
+
+		quantity = quantity.Add(pv.Volume)
+		quoteAmount = quoteAmount.Sub(cost)
+	}
+
+	return quantity, false
+}
+
+// consume returns the worst price and the quote amount of consuming the base quantity from the levels,
+// false is returned when the depth is not enough
+func consume(levels types.PriceVolumeSlice, quantity fixedpoint.Value) (price, quoteAmount fixedpoint.Value, ok bool) {
+	quoteAmount = fixedpoint.Zero
+	remaining := quantity
+	for _, pv := range levels {
+		price = pv.Price
+		if pv.Volume.Compare(remaining) >= 0 {
+			return price, quoteAmount.Add(remaining.Mul(pv.Price)), true
+		}
+
+		remaining = remaining.Sub(pv.Volume)
+		quoteAmount = quoteAmount.Add(pv.Volume.Mul(pv.Price))
+	}
+
+	return price, quoteAmount, false
+}
@@ -0,0 +1,126 @@
+package tri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var number = fixedpoint.MustNewFromString
+
+var (
+	btcusdt = types.Market{
+		Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT",
+		StepSize: number("0.00001"), TickSize: number("0.01"), MinNotional: number("10"),
+	}
+	ethbtc = types.Market{
+		Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC",
+		StepSize: number("0.0001"), TickSize: number("0.000001"), MinNotional: number("0.0001"),
+	}
+	ethusdt = types.Market{
+		Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT",
+		StepSize: number("0.0001"), TickSize: number("0.01"), MinNotional: number("10"),
+	}
+)
+
+func newBook(symbol string, bids, asks types.PriceVolumeSlice) types.OrderBook {
+	return &types.SliceOrderBook{Symbol: symbol, Bids: bids, Asks: asks}
+}
+
+func testBooks() map[string]types.OrderBook {
+	return map[string]types.OrderBook{
+		"BTCUSDT": newBook("BTCUSDT",
+			types.PriceVolumeSlice{{Price: number("19990"), Volume: number("1")}},
+			types.PriceVolumeSlice{{Price: number("20000"), Volume: number("0.02")}, {Price: number("20010"), Volume: number("1")}}),
+		"ETHBTC": newBook("ETHBTC",
+			types.PriceVolumeSlice{{Price: number("0.0699"), Volume: number("10")}},
+			types.PriceVolumeSlice{{Price: number("0.07"), Volume: number("10")}}),
+		"ETHUSDT": newBook("ETHUSDT",
+			types.PriceVolumeSlice{{Price: number("1420"), Volume: number("10")}},
+			types.PriceVolumeSlice{{Price: number("1421"), Volume: number("10")}}),
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	cycles, err := FindCycles([3]types.Market{btcusdt, ethbtc, ethusdt}, "USDT")
+	if !assert.NoError(t, err) || !assert.Len(t, cycles, 2) {
+		return
+	}
+
+	assert.Equal(t, "BUY BTCUSDT (USDT->BTC) -> BUY ETHBTC (BTC->ETH) -> SELL ETHUSDT (ETH->USDT)", cycles[0].String())
+	assert.Equal(t, "BUY ETHUSDT (USDT->ETH) -> SELL ETHBTC (ETH->BTC) -> SELL BTCUSDT (BTC->USDT)", cycles[1].String())
+
+	_, err = FindCycles([3]types.Market{btcusdt, ethbtc, ethusdt}, "TWD")
+	assert.Error(t, err)
+}
+
+func TestSimulate(t *testing.T) {
+	cycles, err := FindCycles([3]types.Market{btcusdt, ethbtc, ethusdt}, "USDT")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	books := testBooks()
+	takerFeeRate := number("0.001")
+
+	// USDT -> BTC -> ETH -> USDT: the asks of BTCUSDT are consumed to the second level
+	opportunity, err := Simulate(cycles[0], books, number("1000"), takerFeeRate)
+	if assert.NoError(t, err) {
+		first := opportunity.Orders[0]
+		assert.Equal(t, number("0.04998"), first.Quantity)
+		assert.Equal(t, number("20010"), first.Price)
+		assert.Equal(t, number("999.8998"), first.AmountIn)
+
+		second := opportunity.Orders[1]
+		assert.Equal(t, types.SideTypeBuy, second.Side)
+		assert.Equal(t, number("0.7132"), second.Quantity)
+
+		third := opportunity.Orders[2]
+		assert.Equal(t, types.SideTypeSell, third.Side)
+		assert.Equal(t, number("0.7124"), third.Quantity)
+		assert.Equal(t, number("1420"), third.Price)
+
+		assert.Equal(t, number("999.8998"), opportunity.AmountIn)
+		assert.InDelta(t, 1010.596392, opportunity.AmountOut.Float64(), 1e-6)
+		assert.True(t, opportunity.Edge().Compare(number("0.01")) > 0)
+	}
+
+	// USDT -> ETH -> BTC -> USDT loses money
+	opportunity, err = Simulate(cycles[1], books, number("1000"), takerFeeRate)
+	if assert.NoError(t, err) {
+		assert.True(t, opportunity.Edge().Sign() < 0)
+	}
+
+	// less than the min notional
+	_, err = Simulate(cycles[0], books, number("5"), takerFeeRate)
+	assert.Error(t, err)
+
+	// insufficient depth
+	_, err = Simulate(cycles[0], books, number("100000"), takerFeeRate)
+	assert.Error(t, err)
+}
+
+func Test_resizeLegOrder(t *testing.T) {
+	leg, _ := newLeg(ethbtc, "BTC")
+	order := LegOrder{Leg: leg, Quantity: number("1"), Price: number("0.07")}
+
+	resized, ok := resizeLegOrder(order, number("0.035"), fixedpoint.Zero)
+	if assert.True(t, ok) {
+		assert.Equal(t, number("0.5"), resized.Quantity)
+		assert.Equal(t, number("0.035"), resized.AmountIn)
+	}
+
+	_, ok = resizeLegOrder(order, number("0.00005"), fixedpoint.Zero)
+	assert.False(t, ok)
+}
+
+func Test_receivedAmount(t *testing.T) {
+	buyLeg, _ := newLeg(btcusdt, "USDT")
+	assert.Equal(t, number("0.999"), receivedAmount(LegOrder{Leg: buyLeg, Price: number("20000")}, number("1"), number("0.001")))
+
+	sellLeg, _ := newLeg(btcusdt, "BTC")
+	assert.Equal(t, number("19980"), receivedAmount(LegOrder{Leg: sellLeg, Price: number("20000")}, number("1"), number("0.001")))
+}
@@ -0,0 +1,482 @@
+package tri
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "tri"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	Cycles            int              `json:"cycles"`
+	FailedCycles      int              `json:"failedCycles"`
+	AccumulatedProfit fixedpoint.Value `json:"accumulatedProfit"`
+}
+
+// Strategy trades the price inconsistency of the three markets on the same exchange,
+// e.g., buying BTC with USDT, buying ETH with BTC and selling ETH for USDT when ETHUSDT is overpriced to BTCUSDT x ETHBTC.
+type Strategy struct {
+	Environment *bbgo.Environment
+
+	// Symbols are the three markets forming the triangle, e.g., BTCUSDT, ETHBTC and ETHUSDT
+	Symbols []string `json:"symbols"`
+
+	// StartCurrency is the currency that the cycles start from and end to, e.g., USDT
+	StartCurrency string `json:"startCurrency"`
+
+	// Amount is the max amount of the start currency to trade in one cycle
+	Amount fixedpoint.Value `json:"amount"`
+
+	// MinEdge is the minimal profit ratio of the cycle after the taker fees, e.g., 0.1%
+	MinEdge fixedpoint.Value `json:"minEdge"`
+
+	// OrderTimeout is the timeout of waiting the IOC order to be closed, defaults to 10s
+	OrderTimeout types.Duration `json:"orderTimeout"`
+
+	// CoolDown is the period to wait after each execution, defaults to 1s
+	CoolDown types.Duration `json:"coolDown"`
+
+	// DryRun only logs the opportunities without sending orders
+	DryRun bool `json:"dryRun"`
+
+	Positions map[string]*types.Position `persistence:"positions"`
+	State     *State                     `persistence:"state"`
+
+	session        *bbgo.ExchangeSession
+	markets        [3]types.Market
+	cycles         []Cycle
+	books          map[string]*types.StreamOrderBook
+	orderExecutors map[string]*bbgo.GeneralOrderExecutor
+
+	// closedOrders delivers the closed order updates to the waiting leg execution
+	closedOrders   map[uint64]chan types.Order
+	closedOrdersMu sync.Mutex
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s:%s-%s-%s", ID, s.StartCurrency, s.Symbols[0], s.Symbols[1], s.Symbols[2])
+}
+
+func (s *Strategy) Defaults() error {
+	if s.OrderTimeout == 0 {
+		s.OrderTimeout = types.Duration(10 * time.Second)
+	}
+
+	if s.CoolDown == 0 {
+		s.CoolDown = types.Duration(time.Second)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbols) != 3 {
+		return fmt.Errorf("3 symbols are required, got %v", s.Symbols)
+	}
+
+	if len(s.StartCurrency) == 0 {
+		return fmt.Errorf("startCurrency is required")
+	}
+
+	if s.Amount.Sign() <= 0 {
+		return fmt.Errorf("amount should be positive")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	for _, symbol := range s.Symbols {
+		session.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+	}
+}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.session = session
+
+	for i, symbol := range s.Symbols {
+		market, ok := session.Market(symbol)
+		if !ok {
+			return fmt.Errorf("market %s is not defined", symbol)
+		}
+
+		s.markets[i] = market
+	}
+
+	cycles, err := FindCycles(s.markets, s.StartCurrency)
+	if err != nil {
+		return err
+	}
+
+	s.cycles = cycles
+	for _, cycle := range cycles {
+		log.Infof("triangular cycle: %s", cycle)
+	}
+
+	if s.Positions == nil {
+		s.Positions = make(map[string]*types.Position)
+	}
+
+	if s.State == nil {
+		s.State = &State{}
+	}
+
+	instanceID := s.InstanceID()
+	s.books = make(map[string]*types.StreamOrderBook)
+	s.orderExecutors = make(map[string]*bbgo.GeneralOrderExecutor)
+	s.closedOrders = make(map[uint64]chan types.Order)
+	for _, market := range s.markets {
+		book := types.NewStreamBook(market.Symbol)
+		book.BindStream(session.MarketDataStream)
+		s.books[market.Symbol] = book
+
+		position, ok := s.Positions[market.Symbol]
+		if !ok {
+			position = types.NewPositionFromMarket(market)
+			s.Positions[market.Symbol] = position
+		}
+
+		orderExecutor := bbgo.NewGeneralOrderExecutor(session, market.Symbol, ID, instanceID, position)
+		orderExecutor.BindEnvironment(s.Environment)
+		orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+			bbgo.Sync(ctx, s)
+		})
+		orderExecutor.Bind()
+		s.orderExecutors[market.Symbol] = orderExecutor
+	}
+
+	session.UserDataStream.OnOrderUpdate(s.handleOrderUpdate)
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		bbgo.Sync(ctx, s)
+	})
+
+	go s.runLoop(ctx)
+	return nil
+}
+
+func (s *Strategy) runLoop(ctx context.Context) {
+	var coolDownUntil time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-s.books[s.markets[0].Symbol].C:
+		case <-s.books[s.markets[1].Symbol].C:
+		case <-s.books[s.markets[2].Symbol].C:
+		}
+
+		if time.Now().Before(coolDownUntil) {
+			continue
+		}
+
+		opportunity := s.findOpportunity()
+		if opportunity == nil {
+			continue
+		}
+
+		log.Infof("found opportunity: %s, amount %s %s, edge %s",
+			opportunity.Cycle, opportunity.AmountIn.String(), s.StartCurrency, opportunity.Edge().Percentage())
+
+		if !s.DryRun {
+			s.execute(ctx, opportunity)
+		}
+
+		coolDownUntil = time.Now().Add(s.CoolDown.Duration())
+	}
+}
+
+// findOpportunity returns the most profitable cycle over the min edge
+func (s *Strategy) findOpportunity() *Opportunity {
+	amount := s.Amount
+	if balance, ok := s.session.GetAccount().Balance(s.StartCurrency); ok {
+		amount = fixedpoint.Min(amount, balance.Available)
+	} else if !s.DryRun {
+		return nil
+	}
+
+	books := make(map[string]types.OrderBook, len(s.books))
+	for symbol, book := range s.books {
+		if ok, _ := book.IsValid(); !ok {
+			return nil
+		}
+
+		books[symbol] = book.Copy()
+	}
+
+	var best *Opportunity
+	for _, cycle := range s.cycles {
+		opportunity, err := Simulate(cycle, books, amount, s.session.TakerFeeRate)
+		if err != nil {
+			log.WithError(err).Debugf("unable to simulate cycle %s", cycle)
+			continue
+		}
+
+		if opportunity.Edge().Compare(s.MinEdge) < 0 {
+			continue
+		}
+
+		if best == nil || opportunity.Edge().Compare(best.Edge()) > 0 {
+			best = opportunity
+		}
+	}
+
+	return best
+}
+
+// execute sends the IOC orders of the legs in sequence, the amount of each leg is resized by the executed quantity of
+// the previous leg. When a leg fails or is partially filled, the currency held in the middle of the cycle is converted
+// back to the start currency with the market orders.
+func (s *Strategy) execute(ctx context.Context, opportunity *Opportunity) {
+	amount := opportunity.AmountIn
+	failed := false
+	for i, legOrder := range opportunity.Orders {
+		if i > 0 {
+			var ok bool
+			legOrder, ok = resizeLegOrder(legOrder, amount, s.session.TakerFeeRate)
+			if !ok {
+				log.Warnf("leg %s amount %s %s is too small, unwinding", legOrder.Leg, amount.String(), legOrder.From)
+				s.unwind(ctx, opportunity.Cycle, i, amount)
+				failed = true
+				break
+			}
+		}
+
+		order, err := s.submitLegOrder(ctx, legOrder)
+		if err != nil {
+			log.WithError(err).Errorf("leg %s failed, unwinding", legOrder.Leg)
+			if i > 0 {
+				s.unwind(ctx, opportunity.Cycle, i, amount)
+			}
+
+			failed = true
+			break
+		}
+
+		executed := order.ExecutedQuantity
+		if executed.IsZero() {
+			log.Warnf("leg %s is not filled, unwinding", legOrder.Leg)
+			if i > 0 {
+				s.unwind(ctx, opportunity.Cycle, i, amount)
+			}
+
+			failed = true
+			break
+		}
+
+		// unwind the unfilled amount of the middle currency
+		if executed.Compare(legOrder.Quantity) < 0 && i > 0 {
+			remaining := amount.Sub(legOrder.AmountIn.Mul(executed).Div(legOrder.Quantity))
+			s.unwind(ctx, opportunity.Cycle, i, remaining)
+		}
+
+		amount = receivedAmount(legOrder, executed, s.session.TakerFeeRate)
+	}
+
+	if failed {
+		s.State.FailedCycles++
+	} else {
+		profit := amount.Sub(opportunity.AmountIn)
+		s.State.Cycles++
+		s.State.AccumulatedProfit = s.State.AccumulatedProfit.Add(profit)
+		bbgo.Notify("%s: %s completed, profit %s %s, accumulated profit %s %s", ID, opportunity.Cycle,
+			profit.String(), s.StartCurrency, s.State.AccumulatedProfit.String(), s.StartCurrency)
+	}
+
+	bbgo.Sync(ctx, s)
+}
+
+// unwind converts the amount of the currency held before the leg of the given index back to the start currency,
+// the currency is converted through the first leg market if it's received from the first leg, otherwise through the last leg market.
+func (s *Strategy) unwind(ctx context.Context, cycle Cycle, legIndex int, amount fixedpoint.Value) {
+	currency := cycle[legIndex].From
+	market := cycle[0].Market
+	if legIndex == 2 {
+		market = cycle[2].Market
+	}
+
+	submitOrder := types.SubmitOrder{
+		Symbol: market.Symbol,
+		Market: market,
+		Type:   types.OrderTypeMarket,
+		Tag:    "triUnwind",
+	}
+
+	if currency == market.BaseCurrency {
+		submitOrder.Side = types.SideTypeSell
+		submitOrder.Quantity = market.TruncateQuantity(amount)
+	} else {
+		book, ok := s.books[market.Symbol]
+		if !ok {
+			return
+		}
+
+		ask, ok := book.BestAsk()
+		if !ok {
+			log.Errorf("unable to unwind %s %s, no ask price of %s", amount.String(), currency, market.Symbol)
+			return
+		}
+
+		submitOrder.Side = types.SideTypeBuy
+		submitOrder.Quantity = market.TruncateQuantity(amount.Div(ask.Price))
+	}
+
+	if submitOrder.Quantity.IsZero() {
+		return
+	}
+
+	bbgo.Notify("%s: unwinding %s %s by %s %s %s", ID, amount.String(), currency, submitOrder.Side, submitOrder.Quantity.String(), market.Symbol)
+	if _, err := s.orderExecutors[market.Symbol].SubmitOrders(ctx, submitOrder); err != nil {
+		log.WithError(err).Errorf("unable to unwind %s %s", amount.String(), currency)
+	}
+}
+
+// submitLegOrder submits the IOC order of the leg and waits until the order is closed
+func (s *Strategy) submitLegOrder(ctx context.Context, legOrder LegOrder) (*types.Order, error) {
+	submitOrder := legOrder.SubmitOrder()
+	submitOrder.Tag = "tri"
+
+	createdOrders, err := s.orderExecutors[legOrder.Market.Symbol].SubmitOrders(ctx, submitOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(createdOrders) == 0 {
+		return nil, fmt.Errorf("no order is created")
+	}
+
+	return s.waitOrderClosed(ctx, createdOrders[0])
+}
+
+func isClosedOrder(order types.Order) bool {
+	switch order.Status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+func (s *Strategy) handleOrderUpdate(order types.Order) {
+	if !isClosedOrder(order) {
+		return
+	}
+
+	s.closedOrdersMu.Lock()
+	ch, ok := s.closedOrders[order.OrderID]
+	s.closedOrdersMu.Unlock()
+
+	if ok {
+		select {
+		case ch <- order:
+		default:
+		}
+	}
+}
+
+// waitOrderClosed waits the closed order update from the user data stream,
+// the order is queried from the exchange when the update is not received before the timeout
+func (s *Strategy) waitOrderClosed(ctx context.Context, order types.Order) (*types.Order, error) {
+	if isClosedOrder(order) {
+		return &order, nil
+	}
+
+	ch := make(chan types.Order, 1)
+	s.closedOrdersMu.Lock()
+	s.closedOrders[order.OrderID] = ch
+	s.closedOrdersMu.Unlock()
+
+	defer func() {
+		s.closedOrdersMu.Lock()
+		delete(s.closedOrders, order.OrderID)
+		s.closedOrdersMu.Unlock()
+	}()
+
+	// the update could be received before the channel is registered
+	if updated, ok := s.orderExecutors[order.Symbol].OrderStore().Get(order.OrderID); ok && isClosedOrder(updated) {
+		return &updated, nil
+	}
+
+	timer := time.NewTimer(s.OrderTimeout.Duration())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case updated := <-ch:
+		return &updated, nil
+
+	case <-timer.C:
+	}
+
+	service, ok := s.session.Exchange.(types.ExchangeOrderQueryService)
+	if !ok {
+		return nil, fmt.Errorf("order %d is not closed in %s", order.OrderID, s.OrderTimeout.Duration())
+	}
+
+	updated, err := service.QueryOrder(ctx, types.OrderQuery{
+		Symbol:    order.Symbol,
+		OrderID:   fmt.Sprintf("%d", order.OrderID),
+		OrderUUID: order.UUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// resizeLegOrder resizes the order by the amount of the from currency received from the previous leg,
+// false is returned when the resized order is less than the minimal quantity or notional
+func resizeLegOrder(order LegOrder, amountIn, takerFeeRate fixedpoint.Value) (LegOrder, bool) {
+	feeRatio := fixedpoint.One.Sub(takerFeeRate)
+	switch order.Side {
+	case types.SideTypeBuy:
+		order.Quantity = order.Market.TruncateQuantity(amountIn.Div(order.Price))
+		order.AmountIn = order.Quantity.Mul(order.Price)
+		order.AmountOut = order.Quantity.Mul(feeRatio)
+
+	case types.SideTypeSell:
+		order.Quantity = order.Market.TruncateQuantity(amountIn)
+		order.AmountIn = order.Quantity
+		order.AmountOut = order.Quantity.Mul(order.Price).Mul(feeRatio)
+	}
+
+	if order.Quantity.Compare(order.Market.MinQuantity) < 0 || order.Quantity.Mul(order.Price).Compare(order.Market.MinNotional) < 0 {
+		return order, false
+	}
+
+	return order, true
+}
+
+// receivedAmount returns the amount of the to currency received from the executed quantity after the taker fee,
+// the price of the IOC order is used for the sell orders, so the amount is the lower bound.
+func receivedAmount(order LegOrder, executed, takerFeeRate fixedpoint.Value) fixedpoint.Value {
+	feeRatio := fixedpoint.One.Sub(takerFeeRate)
+	if order.Side == types.SideTypeBuy {
+		return executed.Mul(feeRatio)
+	}
+
+	return executed.Mul(order.Price).Mul(feeRatio)
+}
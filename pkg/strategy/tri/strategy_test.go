@@ -0,0 +1,296 @@
+package tri
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+// testExchange is the mock exchange supporting the order query
+type testExchange struct {
+	*mocks.MockExchange
+	*mocks.MockExchangeOrderQueryService
+}
+
+// testOrderRecorder records the submitted orders and returns the created orders by the fill function
+type testOrderRecorder struct {
+	mu           sync.Mutex
+	orderID      uint64
+	submitOrders []types.SubmitOrder
+}
+
+func (r *testOrderRecorder) submit(fill func(submitOrder types.SubmitOrder, order *types.Order) error) func(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	return func(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+		r.mu.Lock()
+		r.orderID++
+		r.submitOrders = append(r.submitOrders, submitOrder)
+		order := &types.Order{
+			SubmitOrder:      submitOrder,
+			Exchange:         types.ExchangeBinance,
+			OrderID:          r.orderID,
+			Status:           types.OrderStatusFilled,
+			ExecutedQuantity: submitOrder.Quantity,
+		}
+		r.mu.Unlock()
+
+		if err := fill(submitOrder, order); err != nil {
+			return nil, err
+		}
+
+		return order, nil
+	}
+}
+
+func (r *testOrderRecorder) orders() []types.SubmitOrder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]types.SubmitOrder(nil), r.submitOrders...)
+}
+
+func newTestStrategy(t *testing.T, mockCtrl *gomock.Controller) (*Strategy, *testExchange) {
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	ex := &testExchange{
+		MockExchange:                  mockEx,
+		MockExchangeOrderQueryService: mocks.NewMockExchangeOrderQueryService(mockCtrl),
+	}
+
+	session := bbgo.NewExchangeSession("test", ex)
+	session.TakerFeeRate = number("0.001")
+
+	s := &Strategy{
+		Symbols:       []string{"BTCUSDT", "ETHBTC", "ETHUSDT"},
+		StartCurrency: "USDT",
+		Amount:        number("1000"),
+		OrderTimeout:  types.Duration(50 * time.Millisecond),
+		Positions:     make(map[string]*types.Position),
+		State:         &State{},
+
+		session:        session,
+		markets:        [3]types.Market{btcusdt, ethbtc, ethusdt},
+		books:          make(map[string]*types.StreamOrderBook),
+		orderExecutors: make(map[string]*bbgo.GeneralOrderExecutor),
+		closedOrders:   make(map[uint64]chan types.Order),
+	}
+
+	for symbol, book := range testBooks() {
+		streamBook := types.NewStreamBook(symbol)
+		streamBook.Load(*book.(*types.SliceOrderBook))
+		s.books[symbol] = streamBook
+	}
+
+	for _, market := range s.markets {
+		session.Markets()[market.Symbol] = market
+		s.Positions[market.Symbol] = types.NewPositionFromMarket(market)
+		s.orderExecutors[market.Symbol] = bbgo.NewGeneralOrderExecutor(session, market.Symbol, ID, "test", s.Positions[market.Symbol])
+	}
+
+	return s, ex
+}
+
+// testOpportunity returns the opportunity of USDT -> BTC -> ETH -> USDT
+func testOpportunity(t *testing.T) *Opportunity {
+	cycles, err := FindCycles([3]types.Market{btcusdt, ethbtc, ethusdt}, "USDT")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	opportunity, err := Simulate(cycles[0], testBooks(), number("1000"), number("0.001"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return opportunity
+}
+
+func TestStrategy_execute(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s, ex := newTestStrategy(t, mockCtrl)
+	recorder := &testOrderRecorder{}
+	ex.MockExchange.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).
+		DoAndReturn(recorder.submit(func(submitOrder types.SubmitOrder, order *types.Order) error {
+			return nil
+		})).Times(3)
+
+	opportunity := testOpportunity(t)
+	s.execute(context.Background(), opportunity)
+
+	orders := recorder.orders()
+	if assert.Len(t, orders, 3) {
+		for i, order := range orders {
+			assert.Equal(t, opportunity.Cycle[i].Market.Symbol, order.Symbol)
+			assert.Equal(t, opportunity.Cycle[i].Side, order.Side)
+			assert.Equal(t, types.TimeInForceIOC, order.TimeInForce)
+		}
+
+		// the quantities of the second and the third legs are resized by the received amount after the fee
+		// 0.04998 BTC * 0.999 / 0.07 = 0.71328 ETH
+		assert.Equal(t, number("0.7132"), orders[1].Quantity)
+		assert.Equal(t, number("0.7124"), orders[2].Quantity)
+	}
+
+	assert.Equal(t, 1, s.State.Cycles)
+	assert.Equal(t, 0, s.State.FailedCycles)
+	// 0.7124 ETH * 1420 * 0.999 - 999.8998 USDT
+	assert.InDelta(t, 10.6966, s.State.AccumulatedProfit.Float64(), 1e-4)
+}
+
+func TestStrategy_execute_PartiallyFilled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s, ex := newTestStrategy(t, mockCtrl)
+	recorder := &testOrderRecorder{}
+	ex.MockExchange.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).
+		DoAndReturn(recorder.submit(func(submitOrder types.SubmitOrder, order *types.Order) error {
+			// the IOC order of the second leg is half filled
+			if submitOrder.Symbol == "ETHBTC" {
+				order.Status = types.OrderStatusCanceled
+				order.ExecutedQuantity = number("0.3566")
+			}
+			return nil
+		})).Times(4)
+
+	s.execute(context.Background(), testOpportunity(t))
+
+	orders := recorder.orders()
+	if assert.Len(t, orders, 4) {
+		// the unfilled BTC of the second leg is sold back to USDT by the market order
+		unwind := orders[2]
+		assert.Equal(t, "BTCUSDT", unwind.Symbol)
+		assert.Equal(t, types.SideTypeSell, unwind.Side)
+		assert.Equal(t, types.OrderTypeMarket, unwind.Type)
+		assert.Equal(t, "triUnwind", unwind.Tag)
+		// 0.04998 * 0.999 - 0.3566 * 0.07 = 0.02496602 BTC
+		assert.Equal(t, number("0.02496"), unwind.Quantity)
+
+		// the third leg is resized by the filled ETH
+		third := orders[3]
+		assert.Equal(t, "ETHUSDT", third.Symbol)
+		assert.Equal(t, number("0.3562"), third.Quantity)
+	}
+
+	assert.Equal(t, 1, s.State.Cycles)
+	assert.Equal(t, 0, s.State.FailedCycles)
+}
+
+func TestStrategy_execute_NotFilled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s, ex := newTestStrategy(t, mockCtrl)
+	recorder := &testOrderRecorder{}
+	ex.MockExchange.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).
+		DoAndReturn(recorder.submit(func(submitOrder types.SubmitOrder, order *types.Order) error {
+			if submitOrder.Symbol == "ETHBTC" {
+				order.Status = types.OrderStatusCanceled
+				order.ExecutedQuantity = fixedpoint.Zero
+			}
+			return nil
+		})).Times(3)
+
+	s.execute(context.Background(), testOpportunity(t))
+
+	orders := recorder.orders()
+	if assert.Len(t, orders, 3) {
+		// all the BTC received from the first leg is sold back, the third leg is not submitted
+		unwind := orders[2]
+		assert.Equal(t, "BTCUSDT", unwind.Symbol)
+		assert.Equal(t, types.SideTypeSell, unwind.Side)
+		assert.Equal(t, types.OrderTypeMarket, unwind.Type)
+		assert.Equal(t, number("0.04993"), unwind.Quantity)
+	}
+
+	assert.Equal(t, 0, s.State.Cycles)
+	assert.Equal(t, 1, s.State.FailedCycles)
+	assert.True(t, s.State.AccumulatedProfit.IsZero())
+}
+
+func TestStrategy_execute_LegError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s, ex := newTestStrategy(t, mockCtrl)
+	recorder := &testOrderRecorder{}
+	ex.MockExchange.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).
+		DoAndReturn(recorder.submit(func(submitOrder types.SubmitOrder, order *types.Order) error {
+			if submitOrder.Symbol == "ETHUSDT" && submitOrder.Type == types.OrderTypeLimit {
+				return assert.AnError
+			}
+			return nil
+		})).Times(5)
+
+	s.execute(context.Background(), testOpportunity(t))
+
+	orders := recorder.orders()
+	// the third leg order is retried once by the order executor
+	if assert.Len(t, orders, 5) {
+		assert.Equal(t, orders[2], orders[3])
+
+		// the ETH of the middle of the cycle is sold through the third leg market
+		unwind := orders[4]
+		assert.Equal(t, "ETHUSDT", unwind.Symbol)
+		assert.Equal(t, types.SideTypeSell, unwind.Side)
+		assert.Equal(t, types.OrderTypeMarket, unwind.Type)
+		assert.Equal(t, number("0.7124"), unwind.Quantity)
+	}
+
+	assert.Equal(t, 0, s.State.Cycles)
+	assert.Equal(t, 1, s.State.FailedCycles)
+}
+
+func TestStrategy_waitOrderClosed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	s, ex := newTestStrategy(t, mockCtrl)
+
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: number("0.01")},
+		OrderID:     1,
+		UUID:        "uuid-1",
+		Status:      types.OrderStatusNew,
+	}
+
+	t.Run("closed order update", func(t *testing.T) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			s.handleOrderUpdate(types.Order{SubmitOrder: order.SubmitOrder, OrderID: 1, Status: types.OrderStatusFilled})
+		}()
+
+		closed, err := s.waitOrderClosed(ctx, order)
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.OrderStatusFilled, closed.Status)
+		}
+	})
+
+	t.Run("query the order after the timeout", func(t *testing.T) {
+		ex.MockExchangeOrderQueryService.EXPECT().QueryOrder(gomock.Any(), types.OrderQuery{
+			Symbol:    "BTCUSDT",
+			OrderID:   "1",
+			OrderUUID: "uuid-1",
+		}).Return(&types.Order{SubmitOrder: order.SubmitOrder, OrderID: 1, Status: types.OrderStatusCanceled}, nil)
+
+		closed, err := s.waitOrderClosed(ctx, order)
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.OrderStatusCanceled, closed.Status)
+		}
+
+		s.closedOrdersMu.Lock()
+		assert.Empty(t, s.closedOrders)
+		s.closedOrdersMu.Unlock()
+	})
+}
@@ -0,0 +1,424 @@
+package xfunding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "xfunding"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// PositionState is the state of the delta-neutral position
+type PositionState int
+
+const (
+	PositionClosed PositionState = iota
+	PositionOpening
+	PositionReady
+	PositionClosing
+)
+
+func (s PositionState) String() string {
+	switch s {
+	case PositionClosed:
+		return "closed"
+	case PositionOpening:
+		return "opening"
+	case PositionReady:
+		return "ready"
+	case PositionClosing:
+		return "closing"
+	}
+
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+type State struct {
+	PositionState PositionState `json:"positionState"`
+
+	// NextFundingTime is the funding time that the funding income is not accrued yet
+	NextFundingTime time.Time `json:"nextFundingTime,omitempty"`
+
+	// FundingRate is the last funding rate of the next funding time
+	FundingRate fixedpoint.Value `json:"fundingRate"`
+}
+
+// fundingRateHistoryService queries the last settled funding rate, e.g., binance
+type fundingRateHistoryService interface {
+	QueryFundingRateHistory(ctx context.Context, symbol string) (*types.FundingRate, error)
+}
+
+// Strategy holds the spot long position on the spot session and the perpetual short position on the futures session,
+// so that the funding fee paid by the longs is received while the price exposure is hedged.
+type Strategy struct {
+	Environment *bbgo.Environment
+
+	Symbol         string `json:"symbol"`
+	SpotSession    string `json:"spotSession"`
+	FuturesSession string `json:"futuresSession"`
+
+	// Quantity is the base quantity of the spot long position
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// MinFundingRate is the funding rate to open the position, e.g., 0.0001 means 0.01% per funding period
+	MinFundingRate fixedpoint.Value `json:"minFundingRate"`
+
+	// ExitFundingRate is the funding rate to unwind the position, defaults to 0, which unwinds when the funding rate flips
+	ExitFundingRate fixedpoint.Value `json:"exitFundingRate"`
+
+	// HedgeRatio is the ratio of the futures short quantity to the spot long quantity, defaults to 1.0
+	HedgeRatio fixedpoint.Value `json:"hedgeRatio"`
+
+	// MaxHedgeDeviation is the deviation ratio of the hedge quantity to rebalance the futures position, defaults to 0.01
+	MaxHedgeDeviation fixedpoint.Value `json:"maxHedgeDeviation"`
+
+	// CheckInterval is the interval to check the funding rate and rebalance the hedge, defaults to 1m
+	CheckInterval types.Duration `json:"checkInterval"`
+
+	SpotPosition    *types.Position    `persistence:"spot_position"`
+	FuturesPosition *types.Position    `persistence:"futures_position"`
+	ProfitStats     *types.ProfitStats `persistence:"profit_stats"`
+	State           *State             `persistence:"state"`
+
+	spotSession, futuresSession             *bbgo.ExchangeSession
+	spotMarket, futuresMarket               types.Market
+	spotOrderExecutor, futuresOrderExecutor *bbgo.GeneralOrderExecutor
+	premiumIndexService                     types.PremiumIndexService
+
+	mu sync.Mutex
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s:%s-%s", ID, s.Symbol, s.SpotSession, s.FuturesSession)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.HedgeRatio.IsZero() {
+		s.HedgeRatio = fixedpoint.One
+	}
+
+	if s.MaxHedgeDeviation.IsZero() {
+		s.MaxHedgeDeviation = fixedpoint.NewFromFloat(0.01)
+	}
+
+	if s.CheckInterval == 0 {
+		s.CheckInterval = types.Duration(time.Minute)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.SpotSession) == 0 || len(s.FuturesSession) == 0 {
+		return fmt.Errorf("spotSession and futuresSession are required")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return fmt.Errorf("quantity should be positive")
+	}
+
+	if s.ExitFundingRate.Compare(s.MinFundingRate) > 0 {
+		return fmt.Errorf("exitFundingRate %s should not be greater than minFundingRate %s", s.ExitFundingRate, s.MinFundingRate)
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	// the positions are only driven by the funding rate polling and the user data stream
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	var ok bool
+	s.spotSession, ok = sessions[s.SpotSession]
+	if !ok {
+		return fmt.Errorf("spot session %s is not defined", s.SpotSession)
+	}
+
+	s.futuresSession, ok = sessions[s.FuturesSession]
+	if !ok {
+		return fmt.Errorf("futures session %s is not defined", s.FuturesSession)
+	}
+
+	if s.spotSession.Futures || s.spotSession.IsolatedFutures {
+		return fmt.Errorf("spot session %s should not be a futures session", s.SpotSession)
+	}
+
+	if !s.futuresSession.Futures && !s.futuresSession.IsolatedFutures {
+		return fmt.Errorf("futures session %s should be a futures session", s.FuturesSession)
+	}
+
+	s.premiumIndexService, ok = s.futuresSession.Exchange.(types.PremiumIndexService)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support the premium index api", s.futuresSession.ExchangeName)
+	}
+
+	s.spotMarket, ok = s.spotSession.Market(s.Symbol)
+	if !ok {
+		return fmt.Errorf("spot market %s is not defined", s.Symbol)
+	}
+
+	s.futuresMarket, ok = s.futuresSession.Market(s.Symbol)
+	if !ok {
+		return fmt.Errorf("futures market %s is not defined", s.Symbol)
+	}
+
+	if s.SpotPosition == nil {
+		s.SpotPosition = types.NewPositionFromMarket(s.spotMarket)
+	}
+
+	if s.FuturesPosition == nil {
+		s.FuturesPosition = types.NewPositionFromMarket(s.futuresMarket)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.spotMarket)
+	}
+
+	if s.State == nil {
+		s.State = &State{}
+	}
+
+	instanceID := s.InstanceID()
+	s.spotOrderExecutor = s.newOrderExecutor(ctx, s.spotSession, instanceID, s.SpotPosition)
+	s.futuresOrderExecutor = s.newOrderExecutor(ctx, s.futuresSession, instanceID, s.FuturesPosition)
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		// the positions are kept on shutdown, they are restored from the persistence on the next start
+		bbgo.Sync(ctx, s)
+	})
+
+	go func() {
+		ticker := time.NewTicker(s.CheckInterval.Duration())
+		defer ticker.Stop()
+
+		s.check(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				s.check(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *Strategy) newOrderExecutor(ctx context.Context, session *bbgo.ExchangeSession, instanceID string, position *types.Position) *bbgo.GeneralOrderExecutor {
+	orderExecutor := bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, instanceID, position)
+	orderExecutor.BindEnvironment(s.Environment)
+	orderExecutor.BindProfitStats(s.ProfitStats)
+	orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	orderExecutor.Bind()
+	return orderExecutor
+}
+
+// check updates the position state by the funding rate, accrues the funding income and rebalances the hedge
+func (s *Strategy) check(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, err := s.premiumIndexService.QueryPremiumIndex(ctx, s.Symbol)
+	if err != nil {
+		log.WithError(err).Errorf("unable to query the premium index of %s", s.Symbol)
+		return
+	}
+
+	s.accrueFundingIncome(ctx, index)
+
+	fundingRate := index.LastFundingRate
+	switch s.State.PositionState {
+	case PositionClosed:
+		if fundingRate.Compare(s.MinFundingRate) >= 0 {
+			bbgo.Notify("%s: %s funding rate %s reached %s, opening the position", ID, s.Symbol, fundingRate.Percentage(), s.MinFundingRate.Percentage())
+			s.setPositionState(ctx, PositionOpening)
+		}
+
+	case PositionOpening, PositionReady:
+		if fundingRate.Compare(s.ExitFundingRate) < 0 {
+			bbgo.Notify("%s: %s funding rate %s fell below %s, unwinding the position", ID, s.Symbol, fundingRate.Percentage(), s.ExitFundingRate.Percentage())
+			s.setPositionState(ctx, PositionClosing)
+		}
+	}
+
+	switch s.State.PositionState {
+	case PositionOpening:
+		s.openSpotPosition(ctx, index.MarkPrice)
+
+	case PositionClosing:
+		s.closeSpotPosition(ctx, index.MarkPrice)
+	}
+
+	s.rebalance(ctx, index.MarkPrice)
+
+	spotBase := s.SpotPosition.GetBase()
+	futuresBase := s.FuturesPosition.GetBase()
+	switch s.State.PositionState {
+	case PositionOpening:
+		if spotBase.Compare(s.Quantity.Mul(fixedpoint.One.Sub(s.MaxHedgeDeviation))) >= 0 &&
+			hedgeDelta(spotBase, futuresBase, s.HedgeRatio, s.MaxHedgeDeviation).IsZero() {
+			s.setPositionState(ctx, PositionReady)
+		}
+
+	case PositionClosing:
+		if s.spotMarket.IsDustQuantity(spotBase.Abs(), index.MarkPrice) && s.futuresMarket.IsDustQuantity(futuresBase.Abs(), index.MarkPrice) {
+			s.setPositionState(ctx, PositionClosed)
+		}
+	}
+}
+
+func (s *Strategy) setPositionState(ctx context.Context, state PositionState) {
+	log.Infof("%s position state: %s -> %s", s.Symbol, s.State.PositionState, state)
+	s.State.PositionState = state
+	bbgo.Sync(ctx, s)
+}
+
+// accrueFundingIncome adds the funding fee of the futures position to the profit stats when the funding time is passed
+func (s *Strategy) accrueFundingIncome(ctx context.Context, index *types.PremiumIndex) {
+	defer func() {
+		s.State.FundingRate = index.LastFundingRate
+	}()
+
+	if s.State.NextFundingTime.IsZero() {
+		s.State.NextFundingTime = index.NextFundingTime
+		return
+	}
+
+	if index.Time.Before(s.State.NextFundingTime) {
+		return
+	}
+
+	fundingTime := s.State.NextFundingTime
+	s.State.NextFundingTime = index.NextFundingTime
+
+	futuresBase := s.FuturesPosition.GetBase()
+	if futuresBase.IsZero() {
+		return
+	}
+
+	// use the settled funding rate if the exchange supports it,
+	// otherwise the last funding rate before the funding time is the settled one
+	fundingRate := s.State.FundingRate
+	if service, ok := s.futuresSession.Exchange.(fundingRateHistoryService); ok {
+		rate, err := service.QueryFundingRateHistory(ctx, s.Symbol)
+		if err != nil {
+			log.WithError(err).Warnf("unable to query the funding rate history of %s, using the last funding rate", s.Symbol)
+		} else {
+			fundingRate = rate.FundingRate
+		}
+	}
+
+	income := fundingIncome(futuresBase, index.MarkPrice, fundingRate)
+	s.ProfitStats.AddFundingIncome(income, fundingTime)
+	bbgo.Notify("%s: %s funding income %s %s at rate %s, accumulated %s %s", ID, s.Symbol,
+		income.String(), s.futuresMarket.QuoteCurrency,
+		fundingRate.Percentage(),
+		s.ProfitStats.AccumulatedFundingIncome.String(), s.futuresMarket.QuoteCurrency)
+	bbgo.Sync(ctx, s)
+}
+
+func (s *Strategy) openSpotPosition(ctx context.Context, price fixedpoint.Value) {
+	quantity := s.spotMarket.TruncateQuantity(s.Quantity.Sub(s.SpotPosition.GetBase()))
+	if quantity.Sign() <= 0 || s.spotMarket.IsDustQuantity(quantity, price) {
+		return
+	}
+
+	if _, err := s.spotOrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.spotMarket,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Tag:      "xfundingOpen",
+	}); err != nil {
+		log.WithError(err).Errorf("unable to submit the spot order")
+	}
+}
+
+func (s *Strategy) closeSpotPosition(ctx context.Context, price fixedpoint.Value) {
+	base := s.SpotPosition.GetBase()
+	if base.Sign() <= 0 || s.spotMarket.IsDustQuantity(base, price) {
+		return
+	}
+
+	if err := s.spotOrderExecutor.ClosePosition(ctx, fixedpoint.One, "xfundingClose"); err != nil {
+		log.WithError(err).Errorf("unable to close the spot position")
+	}
+}
+
+// rebalance adjusts the futures short position to the hedge ratio of the spot long position
+func (s *Strategy) rebalance(ctx context.Context, price fixedpoint.Value) {
+	futuresBase := s.FuturesPosition.GetBase()
+	delta := hedgeDelta(s.SpotPosition.GetBase(), futuresBase, s.HedgeRatio, s.MaxHedgeDeviation)
+	quantity := s.futuresMarket.TruncateQuantity(delta.Abs())
+	if quantity.IsZero() || s.futuresMarket.IsDustQuantity(quantity, price) {
+		return
+	}
+
+	side := types.SideTypeBuy
+	if delta.Sign() < 0 {
+		side = types.SideTypeSell
+	}
+
+	// reduce only when the order decreases the futures position
+	reduceOnly := !futuresBase.IsZero() && futuresBase.Sign() != delta.Sign() && quantity.Compare(futuresBase.Abs()) <= 0
+
+	log.Infof("rebalancing %s futures position %s to the spot position %s: %s %s", s.Symbol, futuresBase, s.SpotPosition.GetBase(), side, quantity)
+	if _, err := s.futuresOrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:     s.Symbol,
+		Market:     s.futuresMarket,
+		Side:       side,
+		Type:       types.OrderTypeMarket,
+		Quantity:   quantity,
+		ReduceOnly: reduceOnly,
+		Tag:        "xfundingHedge",
+	}); err != nil {
+		log.WithError(err).Errorf("unable to submit the futures hedge order")
+	}
+}
+
+// hedgeDelta returns the base quantity of the futures order that keeps the futures short at the hedge ratio of the spot long,
+// zero is returned when the deviation is within the max deviation ratio of the spot position
+func hedgeDelta(spotBase, futuresBase, hedgeRatio, maxDeviation fixedpoint.Value) fixedpoint.Value {
+	target := spotBase.Mul(hedgeRatio).Neg()
+	delta := target.Sub(futuresBase)
+	if delta.Abs().Compare(spotBase.Abs().Mul(maxDeviation)) <= 0 {
+		return fixedpoint.Zero
+	}
+
+	return delta
+}
+
+// fundingIncome returns the funding fee of the futures position,
+// the shorts receive the funding fee from the longs when the funding rate is positive
+func fundingIncome(futuresBase, markPrice, fundingRate fixedpoint.Value) fixedpoint.Value {
+	return futuresBase.Neg().Mul(markPrice).Mul(fundingRate)
+}
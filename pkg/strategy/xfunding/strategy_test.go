@@ -0,0 +1,85 @@
+package xfunding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+var number = fixedpoint.MustNewFromString
+
+func Test_hedgeDelta(t *testing.T) {
+	ratio := fixedpoint.One
+	deviation := number("0.01")
+
+	// open the short position for the spot long position
+	assert.Equal(t, number("-1"), hedgeDelta(number("1"), fixedpoint.Zero, ratio, deviation))
+
+	// within the deviation
+	assert.Equal(t, fixedpoint.Zero, hedgeDelta(number("1"), number("-0.995"), ratio, deviation))
+
+	// the spot position is increased
+	assert.Equal(t, number("-0.5"), hedgeDelta(number("1.5"), number("-1"), ratio, deviation))
+
+	// the spot position is closed, close the short position
+	assert.Equal(t, number("1"), hedgeDelta(fixedpoint.Zero, number("-1"), ratio, deviation))
+
+	// half hedged
+	assert.Equal(t, number("-0.5"), hedgeDelta(number("1"), fixedpoint.Zero, number("0.5"), deviation))
+}
+
+func Test_fundingIncome(t *testing.T) {
+	// the short position receives the positive funding rate
+	assert.Equal(t, number("2"), fundingIncome(number("-1"), number("20000"), number("0.0001")))
+
+	// the short position pays the negative funding rate
+	assert.Equal(t, number("-2"), fundingIncome(number("-1"), number("20000"), number("-0.0001")))
+}
+
+func TestStrategy_accrueFundingIncome(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	s := &Strategy{
+		Symbol:          "BTCUSDT",
+		futuresSession:  &bbgo.ExchangeSession{Exchange: mocks.NewMockExchange(mockCtrl)},
+		futuresMarket:   market,
+		FuturesPosition: types.NewPositionFromMarket(market),
+		ProfitStats:     types.NewProfitStats(market),
+		State:           &State{},
+	}
+	s.FuturesPosition.Base = number("-0.5")
+
+	fundingTime := time.Date(2023, 1, 16, 16, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	s.accrueFundingIncome(ctx, &types.PremiumIndex{
+		MarkPrice:       number("20000"),
+		LastFundingRate: number("0.0001"),
+		NextFundingTime: fundingTime,
+		Time:            fundingTime.Add(-time.Hour),
+	})
+	assert.Equal(t, fundingTime, s.State.NextFundingTime)
+	assert.Equal(t, fixedpoint.Zero, s.ProfitStats.AccumulatedFundingIncome)
+
+	// the funding time is passed, the last funding rate before the funding time is settled
+	s.accrueFundingIncome(ctx, &types.PremiumIndex{
+		MarkPrice:       number("20000"),
+		LastFundingRate: number("-0.0002"),
+		NextFundingTime: fundingTime.Add(8 * time.Hour),
+		Time:            fundingTime.Add(time.Minute),
+	})
+	assert.Equal(t, fundingTime.Add(8*time.Hour), s.State.NextFundingTime)
+	assert.Equal(t, number("1"), s.ProfitStats.AccumulatedFundingIncome)
+	assert.Equal(t, number("1"), s.ProfitStats.AccumulatedNetProfit)
+	assert.Equal(t, number("-0.0002"), s.State.FundingRate)
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// Drawdown tracks the drawdown of an equity curve, the drawdown ratio is (peak - value) / peak
+type Drawdown struct {
+	// Peak is the highest equity value so far
+	Peak fixedpoint.Value `json:"peak"`
+
+	// Current is the drawdown ratio of the last equity value
+	Current fixedpoint.Value `json:"current"`
+
+	// Max is the max drawdown ratio so far
+	Max fixedpoint.Value `json:"max"`
+}
+
+// Update updates the drawdown with the new equity value, the non-positive peak values are ignored
+func (d *Drawdown) Update(value fixedpoint.Value) {
+	if value.Compare(d.Peak) > 0 {
+		d.Peak = value
+	}
+
+	if d.Peak.Sign() <= 0 {
+		return
+	}
+
+	d.Current = d.Peak.Sub(value).Div(d.Peak)
+	if d.Current.Compare(d.Max) > 0 {
+		d.Max = d.Current
+	}
+}
@@ -0,0 +1,20 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestDrawdown(t *testing.T) {
+	var dd Drawdown
+	for _, v := range []float64{100, 120, 90, 110, 130, 117} {
+		dd.Update(fixedpoint.NewFromFloat(v))
+	}
+
+	assert.Equal(t, "130", dd.Peak.String())
+	assert.InDelta(t, 0.1, dd.Current.Float64(), 1e-8)
+	assert.InDelta(t, 0.25, dd.Max.Float64(), 1e-8)
+}
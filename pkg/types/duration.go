@@ -102,10 +102,24 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	return d.set(o)
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var o interface{}
+
+	if err := unmarshal(&o); err != nil {
+		return err
+	}
+
+	return d.set(o)
+}
+
+func (d *Duration) set(o interface{}) error {
 	switch t := o.(type) {
 	case string:
 		sd, err := ParseSimpleDuration(t)
-		if err == nil {
+		if err == nil && sd != nil {
 			*d = sd.Duration
 			return nil
 		}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// DefaultEquityCurveMaxSamples is the number of the samples kept by the equity curve when MaxSamples is not set
+const DefaultEquityCurveMaxSamples = 1440
+
+// EquitySample is the mark-to-market equity of the strategy instance at the sampled time
+type EquitySample struct {
+	Time             Time             `json:"time"`
+	RealizedProfit   fixedpoint.Value `json:"realizedProfit"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+	Equity           fixedpoint.Value `json:"equity"`
+}
+
+// EquityCurve tracks the mark-to-market equity of a strategy instance,
+// the equity is the initial capital plus the realized and the unrealized profit in the quote currency.
+// The curve is updated by the market data stream and the user data stream, and read by the interaction commands,
+// so the methods are guarded by the mutex.
+type EquityCurve struct {
+	Symbol        string `json:"symbol"`
+	QuoteCurrency string `json:"quoteCurrency"`
+
+	// InitialCapital is the base of the equity, the drawdown ratios are only calculated when the initial capital is set
+	InitialCapital fixedpoint.Value `json:"initialCapital"`
+
+	// MaxSamples is the max number of the samples kept in the curve, the oldest samples are dropped
+	MaxSamples int `json:"maxSamples,omitempty"`
+
+	// RealizedProfit is the accumulated realized net profit
+	RealizedProfit fixedpoint.Value `json:"realizedProfit"`
+
+	Samples []EquitySample `json:"samples,omitempty"`
+
+	// Peak is the highest equity so far and PeakTime is the time the peak was reached
+	Peak     fixedpoint.Value `json:"peak"`
+	PeakTime Time             `json:"peakTime"`
+
+	// Drawdown and MaxDrawdown are the drawdown amounts from the peak in the quote currency
+	Drawdown    fixedpoint.Value `json:"drawdown"`
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown"`
+
+	// DrawdownRatio tracks the drawdown ratios of the equity
+	DrawdownRatio Drawdown `json:"drawdownRatio"`
+
+	// MaxUnderwaterDuration is the longest duration the equity stayed below the peak
+	MaxUnderwaterDuration time.Duration `json:"maxUnderwaterDuration"`
+
+	sync.Mutex
+}
+
+// equityCurve is the alias type of EquityCurve without the json marshaler
+type equityCurve EquityCurve
+
+// MarshalJSON marshals the curve with the lock, the curve could be updated while it's being persisted
+func (c *EquityCurve) MarshalJSON() ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	return json.Marshal((*equityCurve)(c))
+}
+
+func NewEquityCurve(market Market, initialCapital fixedpoint.Value) *EquityCurve {
+	return &EquityCurve{
+		Symbol:         market.Symbol,
+		QuoteCurrency:  market.QuoteCurrency,
+		InitialCapital: initialCapital,
+	}
+}
+
+// AddProfit adds the realized profit to the curve, the equity is updated on the next sample
+func (c *EquityCurve) AddProfit(profit fixedpoint.Value) {
+	c.Lock()
+	c.RealizedProfit = c.RealizedProfit.Add(profit)
+	c.Unlock()
+}
+
+// Equity returns the equity with the given unrealized profit
+func (c *EquityCurve) Equity(unrealizedProfit fixedpoint.Value) fixedpoint.Value {
+	c.Lock()
+	defer c.Unlock()
+	return c.equity(unrealizedProfit)
+}
+
+func (c *EquityCurve) equity(unrealizedProfit fixedpoint.Value) fixedpoint.Value {
+	return c.InitialCapital.Add(c.RealizedProfit).Add(unrealizedProfit)
+}
+
+// Update samples the equity at the time with the unrealized profit of the current position
+func (c *EquityCurve) Update(t time.Time, unrealizedProfit fixedpoint.Value) EquitySample {
+	c.Lock()
+	defer c.Unlock()
+
+	sample := EquitySample{
+		Time:             Time(t),
+		RealizedProfit:   c.RealizedProfit,
+		UnrealizedProfit: unrealizedProfit,
+		Equity:           c.equity(unrealizedProfit),
+	}
+
+	if len(c.Samples) == 0 || sample.Equity.Compare(c.Peak) >= 0 {
+		c.Peak = sample.Equity
+		c.PeakTime = sample.Time
+	}
+
+	c.Drawdown = c.Peak.Sub(sample.Equity)
+	c.MaxDrawdown = fixedpoint.Max(c.MaxDrawdown, c.Drawdown)
+	if c.InitialCapital.Sign() > 0 {
+		c.DrawdownRatio.Update(sample.Equity)
+	}
+
+	if d := c.underwaterDuration(t); d > c.MaxUnderwaterDuration {
+		c.MaxUnderwaterDuration = d
+	}
+
+	maxSamples := c.MaxSamples
+	if maxSamples <= 0 {
+		maxSamples = DefaultEquityCurveMaxSamples
+	}
+
+	c.Samples = append(c.Samples, sample)
+	if len(c.Samples) > maxSamples {
+		c.Samples = append(c.Samples[:0:0], c.Samples[len(c.Samples)-maxSamples:]...)
+	}
+
+	return sample
+}
+
+// IsUnderwater returns true if the last sampled equity is below the peak
+func (c *EquityCurve) IsUnderwater() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.isUnderwater()
+}
+
+func (c *EquityCurve) isUnderwater() bool {
+	return c.Drawdown.Sign() > 0
+}
+
+// UnderwaterDuration returns the duration from the peak to the given time if the equity is below the peak
+func (c *EquityCurve) UnderwaterDuration(t time.Time) time.Duration {
+	c.Lock()
+	defer c.Unlock()
+	return c.underwaterDuration(t)
+}
+
+func (c *EquityCurve) underwaterDuration(t time.Time) time.Duration {
+	if !c.isUnderwater() {
+		return 0
+	}
+
+	return t.Sub(c.PeakTime.Time())
+}
+
+// Last returns the last sample of the curve
+func (c *EquityCurve) Last() (EquitySample, bool) {
+	c.Lock()
+	defer c.Unlock()
+	return c.last()
+}
+
+func (c *EquityCurve) last() (EquitySample, bool) {
+	if len(c.Samples) == 0 {
+		return EquitySample{}, false
+	}
+
+	return c.Samples[len(c.Samples)-1], true
+}
+
+func (c *EquityCurve) PlainText() string {
+	c.Lock()
+	defer c.Unlock()
+
+	last, ok := c.last()
+	if !ok {
+		return fmt.Sprintf("%s Equity\nNo sample yet", c.Symbol)
+	}
+
+	return fmt.Sprintf("%s Equity %s %s\n"+
+		"Realized Profit %s %s\n"+
+		"Unrealized Profit %s %s\n"+
+		"Peak %s %s at %s\n"+
+		"Drawdown %s %s (%s)\n"+
+		"Max Drawdown %s %s (%s)\n"+
+		"Underwater %s, Max Underwater %s\n"+
+		"Updated at %s",
+		c.Symbol, last.Equity.String(), c.QuoteCurrency,
+		last.RealizedProfit.String(), c.QuoteCurrency,
+		last.UnrealizedProfit.String(), c.QuoteCurrency,
+		c.Peak.String(), c.QuoteCurrency, c.PeakTime.Time().Format(time.RFC822),
+		c.Drawdown.String(), c.QuoteCurrency, c.DrawdownRatio.Current.Percentage(),
+		c.MaxDrawdown.String(), c.QuoteCurrency, c.DrawdownRatio.Max.Percentage(),
+		c.underwaterDuration(last.Time.Time()), c.MaxUnderwaterDuration,
+		last.Time.Time().Format(time.RFC822),
+	)
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestEquityCurve(t *testing.T) {
+	curve := NewEquityCurve(Market{Symbol: "BTCUSDT", QuoteCurrency: "USDT"}, fixedpoint.NewFromInt(1000))
+	curve.MaxSamples = 3
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	curve.Update(startTime, fixedpoint.Zero)
+	curve.Update(startTime.Add(time.Hour), fixedpoint.NewFromInt(200))
+
+	// the position is closed with the profit and a new position is losing
+	curve.AddProfit(fixedpoint.NewFromInt(100))
+	curve.Update(startTime.Add(2*time.Hour), fixedpoint.NewFromInt(-200))
+	assert.True(t, curve.IsUnderwater())
+	assert.InDelta(t, 300.0, curve.Drawdown.Float64(), 1e-6)
+	assert.InDelta(t, 0.25, curve.DrawdownRatio.Current.Float64(), 1e-6)
+	assert.Equal(t, time.Hour, curve.MaxUnderwaterDuration)
+
+	curve.Update(startTime.Add(3*time.Hour), fixedpoint.NewFromInt(-100))
+	assert.InDelta(t, 200.0, curve.Drawdown.Float64(), 1e-6)
+	assert.InDelta(t, 300.0, curve.MaxDrawdown.Float64(), 1e-6)
+	assert.Equal(t, 2*time.Hour, curve.MaxUnderwaterDuration)
+
+	// recovered to the new peak
+	curve.Update(startTime.Add(4*time.Hour), fixedpoint.NewFromInt(250))
+	assert.False(t, curve.IsUnderwater())
+	assert.InDelta(t, 1350.0, curve.Peak.Float64(), 1e-6)
+	assert.Equal(t, startTime.Add(4*time.Hour), curve.PeakTime.Time())
+	assert.InDelta(t, 0.25, curve.DrawdownRatio.Max.Float64(), 1e-6)
+	assert.Equal(t, 2*time.Hour, curve.MaxUnderwaterDuration)
+
+	if assert.Len(t, curve.Samples, 3) {
+		assert.Equal(t, startTime.Add(2*time.Hour), curve.Samples[0].Time.Time())
+	}
+
+	last, ok := curve.Last()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 100.0, last.RealizedProfit.Float64(), 1e-6)
+		assert.InDelta(t, 1350.0, last.Equity.Float64(), 1e-6)
+	}
+}
+
+func TestEquityCurve_WithoutInitialCapital(t *testing.T) {
+	curve := NewEquityCurve(Market{Symbol: "BTCUSDT", QuoteCurrency: "USDT"}, fixedpoint.Zero)
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	curve.Update(startTime, fixedpoint.NewFromInt(-10))
+	curve.Update(startTime.Add(time.Minute), fixedpoint.NewFromInt(-30))
+
+	assert.InDelta(t, -10.0, curve.Peak.Float64(), 1e-6)
+	assert.InDelta(t, 20.0, curve.MaxDrawdown.Float64(), 1e-6)
+	assert.True(t, curve.DrawdownRatio.Max.IsZero())
+	assert.Equal(t, time.Minute, curve.UnderwaterDuration(startTime.Add(time.Minute)))
+}
+
+func TestEquityCurve_Concurrent(t *testing.T) {
+	curve := NewEquityCurve(Market{Symbol: "BTCUSDT", QuoteCurrency: "USDT"}, fixedpoint.NewFromInt(1000))
+	curve.MaxSamples = 10
+
+	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			curve.AddProfit(fixedpoint.One)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			curve.Update(startTime.Add(time.Duration(i)*time.Minute), fixedpoint.NewFromInt(-int64(i)))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = curve.PlainText()
+			_, _ = curve.Last()
+			_, err := json.Marshal(curve)
+			assert.NoError(t, err)
+		}
+	}()
+	wg.Wait()
+
+	assert.InDelta(t, 100.0, curve.RealizedProfit.Float64(), 1e-6)
+	assert.Len(t, curve.Samples, 10)
+}
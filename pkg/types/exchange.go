@@ -26,13 +26,13 @@ func (n *ExchangeName) UnmarshalJSON(data []byte) error {
 	}
 
 	switch s {
-	case "max", "binance", "okex", "kucoin":
+	case "max", "binance", "okex", "kucoin", "bybit":
 		*n = ExchangeName(s)
 		return nil
 
 	}
 
-	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, okex, kucoin", s)
+	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, okex, kucoin, bybit", s)
 }
 
 func (n ExchangeName) String() string {
@@ -44,6 +44,7 @@ const (
 	ExchangeBinance  ExchangeName = "binance"
 	ExchangeOKEx     ExchangeName = "okex"
 	ExchangeKucoin   ExchangeName = "kucoin"
+	ExchangeBybit    ExchangeName = "bybit"
 	ExchangeBacktest ExchangeName = "backtest"
 )
 
@@ -52,6 +53,7 @@ var SupportedExchanges = []ExchangeName{
 	ExchangeBinance,
 	ExchangeOKEx,
 	ExchangeKucoin,
+	ExchangeBybit,
 	// note: we are not using "backtest"
 }
 
@@ -65,6 +67,8 @@ func ValidExchangeName(a string) (ExchangeName, error) {
 		return ExchangeOKEx, nil
 	case "kucoin":
 		return ExchangeKucoin, nil
+	case "bybit":
+		return ExchangeBybit, nil
 	}
 
 	return "", fmt.Errorf("invalid exchange name: %s", a)
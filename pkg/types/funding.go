@@ -0,0 +1,25 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// FundingFee is the funding fee settled for the futures position,
+// the amount is positive for the received funding fee and negative for the paid funding fee
+type FundingFee struct {
+	GID           uint64           `json:"gid" db:"gid"`
+	Exchange      ExchangeName     `json:"exchange" db:"exchange"`
+	Symbol        string           `json:"symbol" db:"symbol"`
+	Asset         string           `json:"asset" db:"asset"`
+	Amount        fixedpoint.Value `json:"amount" db:"amount"`
+	TransactionID uint64           `json:"transactionID" db:"transaction_id"`
+	Time          Time             `json:"time" db:"time"`
+}
+
+// FundingFeeHistory provides the service of querying the funding fee history of the futures positions
+type FundingFeeHistory interface {
+	QueryFundingFeeHistory(ctx context.Context, symbol string, startTime, endTime *time.Time) ([]FundingFee, error)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -11,3 +12,8 @@ type FundingRate struct {
 	FundingTime time.Time
 	Time        time.Time
 }
+
+// FundingRateHistoryService queries the historical funding rates of the perpetual futures contract
+type FundingRateHistoryService interface {
+	QueryFundingRates(ctx context.Context, symbol string, startTime, endTime time.Time) ([]FundingRate, error)
+}
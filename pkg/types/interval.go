@@ -174,3 +174,8 @@ type IntervalWindowBandWidth struct {
 func (iw IntervalWindow) String() string {
 	return fmt.Sprintf("%s (%d)", iw.Interval, iw.Window)
 }
+
+// Lookback returns the number of the klines required by the window
+func (iw IntervalWindow) Lookback() int {
+	return iw.Window + iw.RightWindow
+}
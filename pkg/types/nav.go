@@ -0,0 +1,28 @@
+package types
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// NavHistoryDetail is the asset record of the net asset value history
+type NavHistoryDetail struct {
+	Session    string       `json:"session" db:"session"`
+	Exchange   ExchangeName `json:"exchange" db:"exchange"`
+	SubAccount string       `json:"subAccount" db:"subaccount"`
+	Time       Time         `json:"time" db:"time"`
+	Currency   string       `json:"currency" db:"currency"`
+
+	NetAssetInUSD fixedpoint.Value `json:"netAssetInUSD" db:"net_asset_in_usd"`
+	NetAssetInBTC fixedpoint.Value `json:"netAssetInBTC" db:"net_asset_in_btc"`
+	Balance       fixedpoint.Value `json:"balance" db:"balance"`
+	Available     fixedpoint.Value `json:"available" db:"available"`
+	Locked        fixedpoint.Value `json:"locked" db:"locked"`
+	Borrowed      fixedpoint.Value `json:"borrowed" db:"borrowed"`
+	Interest      fixedpoint.Value `json:"interest" db:"interest"`
+	NetAsset      fixedpoint.Value `json:"netAsset" db:"net_asset"`
+	PriceInUSD    fixedpoint.Value `json:"priceInUSD" db:"price_in_usd"`
+
+	IsMargin       bool   `json:"isMargin" db:"is_margin"`
+	IsIsolated     bool   `json:"isIsolated" db:"is_isolated"`
+	IsolatedSymbol string `json:"isolatedSymbol" db:"isolated_symbol"`
+}
@@ -240,6 +240,10 @@ type OrderQuery struct {
 	Symbol        string
 	OrderID       string
 	ClientOrderID string
+
+	// OrderUUID is the order UUID of the exchanges identifying the orders by the string IDs, e.g., kucoin,
+	// the OrderID of these exchanges is hashed from the UUID and can not be used for querying the order.
+	OrderUUID string
 }
 
 type Order struct {
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -13,3 +14,9 @@ type PremiumIndex struct {
 	NextFundingTime time.Time        `json:"nextFundingTime"`
 	Time            time.Time        `json:"time"`
 }
+
+// PremiumIndexService queries the premium index of the perpetual futures contract,
+// the last funding rate is the funding rate of the next funding time.
+type PremiumIndexService interface {
+	QueryPremiumIndex(ctx context.Context, symbol string) (*PremiumIndex, error)
+}
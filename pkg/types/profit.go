@@ -159,11 +159,16 @@ type ProfitStats struct {
 	AccumulatedVolume      fixedpoint.Value `json:"accumulatedVolume,omitempty"`
 	AccumulatedSince       int64            `json:"accumulatedSince,omitempty"`
 
+	// AccumulatedFundingIncome is the funding fee received (positive) or paid (negative) by the perpetual futures position
+	AccumulatedFundingIncome fixedpoint.Value `json:"accumulatedFundingIncome,omitempty"`
+
 	TodayPnL         fixedpoint.Value `json:"todayPnL,omitempty"`
 	TodayNetProfit   fixedpoint.Value `json:"todayNetProfit,omitempty"`
 	TodayGrossProfit fixedpoint.Value `json:"todayGrossProfit,omitempty"`
 	TodayGrossLoss   fixedpoint.Value `json:"todayGrossLoss,omitempty"`
 	TodaySince       int64            `json:"todaySince,omitempty"`
+
+	TodayFundingIncome fixedpoint.Value `json:"todayFundingIncome,omitempty"`
 }
 
 func NewProfitStats(market Market) *ProfitStats {
@@ -182,6 +187,9 @@ func NewProfitStats(market Market) *ProfitStats {
 		TodayGrossProfit:       fixedpoint.Zero,
 		TodayGrossLoss:         fixedpoint.Zero,
 		TodaySince:             0,
+
+		AccumulatedFundingIncome: fixedpoint.Zero,
+		TodayFundingIncome:       fixedpoint.Zero,
 	}
 }
 
@@ -225,6 +233,23 @@ func (s *ProfitStats) AddProfit(profit Profit) {
 	}
 }
 
+// AddFundingIncome adds the funding fee settled at the given time,
+// the funding income is also counted in the net profit since it's the profit of holding the position.
+func (s *ProfitStats) AddFundingIncome(income fixedpoint.Value, t time.Time) {
+	if s.IsOver24Hours() {
+		s.ResetToday(t)
+	}
+
+	if s.AccumulatedSince == 0 {
+		s.AccumulatedSince = t.Unix()
+	}
+
+	s.AccumulatedFundingIncome = s.AccumulatedFundingIncome.Add(income)
+	s.AccumulatedNetProfit = s.AccumulatedNetProfit.Add(income)
+	s.TodayFundingIncome = s.TodayFundingIncome.Add(income)
+	s.TodayNetProfit = s.TodayNetProfit.Add(income)
+}
+
 func (s *ProfitStats) AddTrade(trade Trade) {
 	if s.IsOver24Hours() {
 		s.ResetToday(trade.Time.Time())
@@ -243,6 +268,7 @@ func (s *ProfitStats) ResetToday(t time.Time) {
 	s.TodayNetProfit = fixedpoint.Zero
 	s.TodayGrossProfit = fixedpoint.Zero
 	s.TodayGrossLoss = fixedpoint.Zero
+	s.TodayFundingIncome = fixedpoint.Zero
 
 	var beginningOfTheDay = BeginningOfTheDay(t.Local())
 	s.TodaySince = beginningOfTheDay.Unix()
@@ -339,6 +365,13 @@ func (s *ProfitStats) SlackAttachment() slack.Attachment {
 		})
 	}
 
+	if !s.AccumulatedFundingIncome.IsZero() {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Accumulated Funding Income",
+			Value: style.PnLSignString(s.AccumulatedFundingIncome) + " " + s.QuoteCurrency,
+		})
+	}
+
 	return slack.Attachment{
 		Color:  color,
 		Title:  title,
@@ -69,6 +69,9 @@ type StandardStream struct {
 	// CloseC is a signal channel for closing stream
 	CloseC chan struct{}
 
+	// reconnectCoolDownPeriod is the waiting period before re-connecting
+	reconnectCoolDownPeriod time.Duration
+
 	Subscriptions []Subscription
 
 	startCallbacks []func()
@@ -130,8 +133,9 @@ type StandardStreamEmitter interface {
 
 func NewStandardStream() StandardStream {
 	return StandardStream{
-		ReconnectC: make(chan struct{}, 1),
-		CloseC:     make(chan struct{}),
+		ReconnectC:              make(chan struct{}, 1),
+		CloseC:                  make(chan struct{}),
+		reconnectCoolDownPeriod: reconnectCoolDownPeriod,
 	}
 }
 
@@ -139,6 +143,11 @@ func (s *StandardStream) SetPublicOnly() {
 	s.PublicOnly = true
 }
 
+// SetReconnectCoolDownPeriod sets the waiting period before re-connecting, it's useful for testing the reconnection
+func (s *StandardStream) SetReconnectCoolDownPeriod(period time.Duration) {
+	s.reconnectCoolDownPeriod = period
+}
+
 func (s *StandardStream) GetPublicOnly() bool {
 	return s.PublicOnly
 }
@@ -325,8 +334,13 @@ func (s *StandardStream) reconnector(ctx context.Context) {
 			return
 
 		case <-s.ReconnectC:
-			log.Warnf("received reconnect signal, cooling for %s...", reconnectCoolDownPeriod)
-			time.Sleep(reconnectCoolDownPeriod)
+			coolDownPeriod := s.reconnectCoolDownPeriod
+			if coolDownPeriod == 0 {
+				coolDownPeriod = reconnectCoolDownPeriod
+			}
+
+			log.Warnf("received reconnect signal, cooling for %s...", coolDownPeriod)
+			time.Sleep(coolDownPeriod)
 
 			log.Warnf("re-connecting...")
 			if err := s.DialAndConnect(ctx); err != nil {